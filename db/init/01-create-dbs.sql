@@ -1,2 +1,4 @@
 CREATE DATABASE go_market_user;
 CREATE DATABASE go_market_product;
+CREATE DATABASE go_market_notification;
+CREATE DATABASE go_market_order;
@@ -3,29 +3,157 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"GoNotification/internal"
+	"GoNotification/internal/channel"
 	"GoNotification/internal/consumer"
 	"GoNotification/internal/email"
+	"GoNotification/internal/http/handlers"
+	"GoNotification/internal/http/middleware"
+	"GoNotification/internal/realtime"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+	"GoNotification/internal/templates"
+	jwtutil "GoNotification/pkg/jwt"
+	"GoNotification/pkg/unsubscribe"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	migr "GoNotification/internal/migrate"
 )
 
 func main() {
 	cfg := internal.MustLoad()
+	migr.Up(cfg.PGURL)
+
+	db, err := gorm.Open(postgres.Open(cfg.PGURL), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("open db: %v", err)
+	}
+
+	renderer, err := templates.New()
+	if err != nil {
+		log.Fatalf("load templates: %v", err)
+	}
+
+	fileSink, err := channel.NewFile(cfg.FileSinkPath)
+	if err != nil {
+		log.Fatalf("open file sink: %v", err)
+	}
+	defer fileSink.Close()
+
+	sender := email.NewSender(email.Options{
+		Host:               cfg.SMTPHost,
+		Port:               cfg.SMTPPort,
+		From:               cfg.SMTPFrom,
+		Username:           cfg.SMTPUsername,
+		Password:           cfg.SMTPPassword,
+		Auth:               cfg.SMTPAuth,
+		TLS:                cfg.SMTPTLS,
+		InsecureSkipVerify: cfg.SMTPInsecureSkipVerify,
+		PoolSize:           cfg.SMTPPoolSize,
+		IdleTimeout:        cfg.SMTPIdleTimeout,
+		DialTimeout:        cfg.SMTPDialTimeout,
+		SendTimeout:        cfg.SMTPSendTimeout,
+	})
+	defer sender.Close()
+
+	unsubSigner := unsubscribe.NewSigner(cfg.UnsubscribeSecret, cfg.PublicBaseURL)
+	prefsRepo := repo.NewPreferences(db)
+	prefSvc := service.NewPreferenceService(prefsRepo, unsubSigner)
+	notificationsRepo := repo.NewNotifications(db)
+	hub := realtime.NewHub()
+	inboxSvc := service.NewInboxService(notificationsRepo, hub)
+
+	// Digests bypass batching and go straight to the (retrying) email channel.
+	smtpCh := channel.WithRetry(channel.NewSMTP(sender, unsubSigner), cfg.Retries("smtp"), cfg.ChannelRetryBackoff)
+	digestSvc := service.NewDigestService(repo.NewDigests(db), renderer, smtpCh, cfg.DigestDailyAt)
+
+	channels := []channel.Channel{
+		channel.NewSMS(cfg.SMSFrom),
+		channel.NewInApp(inboxSvc),
+		fileSink,
+	}
+	if cfg.WebhookURL != "" {
+		channels = append(channels, channel.NewWebhook(cfg.WebhookURL, cfg.WebhookTimeout))
+	}
+	for i, ch := range channels {
+		channels[i] = channel.WithRetry(ch, cfg.Retries(ch.Name()), cfg.ChannelRetryBackoff)
+	}
+	channels = append(channels, channel.WithBatching(smtpCh, digestSvc))
+
+	router, err := channel.NewRouter(cfg.NotifyRoutes, channels...)
+	if err != nil {
+		log.Fatalf("notification routes: %v", err)
+	}
+	router.SetPolicy(prefSvc)
+
+	cons := consumer.New(cfg.KafkaBrokers, cfg.KafkaTopics, cfg.KafkaGroupID, renderer, router, repo.NewLocales(db))
+
+	verifier := jwtutil.NewVerifier(cfg.JWTSecret)
+	prefH := handlers.NewPreferenceHandler(prefSvc)
+	inboxH := handlers.NewInboxHandler(inboxSvc)
+	streamH := handlers.NewStreamHandler(inboxSvc, hub, cfg.SSEHeartbeat)
+	digestH := handlers.NewDigestHandler(digestSvc)
+
+	r := gin.Default()
 
-	sender := email.NewSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom)
-	cons := consumer.New(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroupID, sender)
+	r.GET("/unsubscribe", prefH.UnsubscribePage)
+	r.POST("/unsubscribe", prefH.Unsubscribe)
+
+	prefs := r.Group("/preferences", middleware.AuthRequired(verifier))
+	{
+		prefs.GET("", prefH.Get)
+		prefs.PUT("", prefH.Update)
+		prefs.GET("/digest", digestH.Get)
+		prefs.PUT("/digest", digestH.Update)
+	}
+
+	inbox := r.Group("/notifications", middleware.AuthRequired(verifier))
+	{
+		inbox.GET("", inboxH.List)
+		inbox.GET("/unread-count", inboxH.UnreadCount)
+		inbox.POST("/read-all", inboxH.MarkAllRead)
+		inbox.POST("/:id/read", inboxH.MarkRead)
+	}
+	r.GET("/notifications/stream",
+		middleware.TokenFromQuery("access_token"),
+		middleware.AuthRequired(verifier),
+		streamH.Stream,
+	)
+
+	srv := &http.Server{
+		Addr:         cfg.HTTPAddr,
+		Handler:      r,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	go hub.Run(ctx)
+	go digestSvc.Run(ctx, cfg.DigestInterval)
+
 	go func() {
 		if err := cons.Start(ctx); err != nil {
 			log.Printf("consumer error: %v", err)
 		}
 	}()
 
+	go func() {
+		log.Printf("notification-svc listening on %s", cfg.HTTPAddr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("http server: %v", err)
+		}
+	}()
+
 	log.Println("notification-svc started")
 
 	quit := make(chan os.Signal, 1)
@@ -33,5 +161,17 @@ func main() {
 	<-quit
 
 	cancel()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown error: %v", err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+
 	log.Println("notification-svc stopped")
 }
@@ -0,0 +1,34 @@
+package channel
+
+import (
+	"context"
+	"log"
+)
+
+// Batcher may hold a notification back instead of sending it now, e.g. to
+// fold it into a digest. Batch reports whether it took the notification.
+type Batcher interface {
+	Batch(ctx context.Context, n Notification) (bool, error)
+}
+
+type batching struct {
+	Channel
+	batcher Batcher
+}
+
+// WithBatching offers every notification to b before sending it through ch.
+func WithBatching(ch Channel, b Batcher) Channel {
+	return &batching{Channel: ch, batcher: b}
+}
+
+func (c *batching) Send(ctx context.Context, n Notification) error {
+	held, err := c.batcher.Batch(ctx, n)
+	if err != nil {
+		return err
+	}
+	if held {
+		log.Printf("channel %s: queued %s for user %d into digest", c.Name(), n.Type, n.UserID)
+		return nil
+	}
+	return c.Channel.Send(ctx, n)
+}
@@ -0,0 +1,35 @@
+package channel
+
+import (
+	"context"
+	"errors"
+
+	"GoNotification/internal/templates"
+)
+
+// ErrNoAddress is returned by a channel when the notification carries no
+// address it can deliver to (e.g. no phone number for SMS).
+var ErrNoAddress = errors.New("channel: no address for recipient")
+
+type Notification struct {
+	Type string `json:"type"`
+	// Category groups types for user preferences; mandatory notifications
+	// (account security, receipts) bypass opt-outs.
+	Category  string            `json:"category"`
+	Mandatory bool              `json:"mandatory"`
+	UserID    uint              `json:"user_id"`
+	Email     string            `json:"email,omitempty"`
+	Phone     string            `json:"phone,omitempty"`
+	Locale    string            `json:"locale"`
+	Content   templates.Content `json:"content"`
+}
+
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// Policy decides whether n may be delivered through the named channel.
+type Policy interface {
+	Allow(ctx context.Context, n Notification, channel string) (bool, error)
+}
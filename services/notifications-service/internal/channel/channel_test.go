@@ -0,0 +1,222 @@
+package channel_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"GoNotification/internal/channel"
+	"GoNotification/internal/templates"
+)
+
+func testNotification() channel.Notification {
+	return channel.Notification{
+		Type:    "user.registered",
+		UserID:  7,
+		Email:   "user@example.com",
+		Locale:  "en",
+		Content: templates.Content{Subject: "Hi", Text: "hello"},
+	}
+}
+
+func TestRouter_DispatchUsesRouteOrDefault(t *testing.T) {
+	smtp := channel.NewFake("smtp")
+	file := channel.NewFake("file")
+
+	r, err := channel.NewRouter(map[string][]string{
+		"user.registered":    {"smtp", "file"},
+		channel.DefaultRoute: {"file"},
+	}, smtp, file)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	if err := r.Dispatch(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	other := testNotification()
+	other.Type = "order.created"
+	if err := r.Dispatch(context.Background(), other); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+
+	if got := len(smtp.Notifications()); got != 1 {
+		t.Errorf("smtp sent %d, want 1", got)
+	}
+	if got := len(file.Notifications()); got != 2 {
+		t.Errorf("file sent %d, want 2", got)
+	}
+}
+
+func TestRouter_UnknownChannel(t *testing.T) {
+	_, err := channel.NewRouter(map[string][]string{"x": {"pigeon"}}, channel.NewFake("smtp"))
+	if err == nil {
+		t.Fatalf("NewRouter() error = nil, want unknown channel error")
+	}
+}
+
+func TestRouter_SkipsMissingAddress(t *testing.T) {
+	sms := channel.NewSMS("GoMarket")
+	r, err := channel.NewRouter(map[string][]string{channel.DefaultRoute: {"sms"}}, sms)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	if err := r.Dispatch(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Dispatch() error = %v, want nil for missing phone", err)
+	}
+}
+
+func TestWithRetry_SucceedsAfterFailures(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	fake.Err = errors.New("temporary")
+	fake.FailTimes = 2
+
+	ch := channel.WithRetry(fake, 3, time.Millisecond)
+	if err := ch.Send(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if fake.Calls != 3 {
+		t.Errorf("Calls = %d, want 3", fake.Calls)
+	}
+}
+
+func TestWithRetry_GivesUp(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	fake.Err = errors.New("permanent")
+	fake.FailTimes = 10
+
+	ch := channel.WithRetry(fake, 2, time.Millisecond)
+	if err := ch.Send(context.Background(), testNotification()); !errors.Is(err, fake.Err) {
+		t.Fatalf("Send() error = %v, want %v", err, fake.Err)
+	}
+	if fake.Calls != 2 {
+		t.Errorf("Calls = %d, want 2", fake.Calls)
+	}
+}
+
+func TestWebhook_PostsJSON(t *testing.T) {
+	var got channel.Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	ch := channel.NewWebhook(srv.URL, time.Second)
+	if err := ch.Send(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.UserID != 7 || got.Content.Subject != "Hi" {
+		t.Errorf("webhook received %+v", got)
+	}
+}
+
+func TestWebhook_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ch := channel.NewWebhook(srv.URL, time.Second)
+	if err := ch.Send(context.Background(), testNotification()); err == nil {
+		t.Fatalf("Send() error = nil, want error for 502")
+	}
+}
+
+func TestFile_WritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	ch := channel.NewWriter(&buf)
+
+	for i := 0; i < 2; i++ {
+		if err := ch.Send(context.Background(), testNotification()); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	var n channel.Notification
+	if err := json.Unmarshal(lines[0], &n); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if n.Email != "user@example.com" {
+		t.Errorf("Email = %q, want %q", n.Email, "user@example.com")
+	}
+}
+
+type denyPolicy struct{ channel string }
+
+func (p denyPolicy) Allow(_ context.Context, _ channel.Notification, ch string) (bool, error) {
+	return ch != p.channel, nil
+}
+
+func TestRouter_PolicySuppressesChannel(t *testing.T) {
+	smtp := channel.NewFake("smtp")
+	file := channel.NewFake("file")
+
+	r, err := channel.NewRouter(map[string][]string{channel.DefaultRoute: {"smtp", "file"}}, smtp, file)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+	r.SetPolicy(denyPolicy{channel: "smtp"})
+
+	if err := r.Dispatch(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Dispatch() error = %v", err)
+	}
+	if len(smtp.Notifications()) != 0 {
+		t.Errorf("smtp received a suppressed notification")
+	}
+	if len(file.Notifications()) != 1 {
+		t.Errorf("file sent %d, want 1", len(file.Notifications()))
+	}
+}
+
+type typeBatcher struct {
+	typ  string
+	held []channel.Notification
+}
+
+func (b *typeBatcher) Batch(_ context.Context, n channel.Notification) (bool, error) {
+	if n.Type != b.typ {
+		return false, nil
+	}
+	b.held = append(b.held, n)
+	return true, nil
+}
+
+func TestWithBatching_HoldsOrSends(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	b := &typeBatcher{typ: "product.approved"}
+	ch := channel.WithBatching(fake, b)
+
+	if ch.Name() != "smtp" {
+		t.Errorf("Name() = %q, want smtp", ch.Name())
+	}
+
+	held := testNotification()
+	held.Type = "product.approved"
+	for _, n := range []channel.Notification{held, testNotification()} {
+		if err := ch.Send(context.Background(), n); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	if len(b.held) != 1 || b.held[0].Type != "product.approved" {
+		t.Errorf("held = %+v, want the moderation decision", b.held)
+	}
+	if sent := fake.Notifications(); len(sent) != 1 || sent[0].Type != "user.registered" {
+		t.Errorf("sent = %+v, want only user.registered", sent)
+	}
+}
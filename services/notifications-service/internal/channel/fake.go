@@ -0,0 +1,45 @@
+package channel
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake records every notification it receives. It fails the first FailTimes
+// sends with Err, which lets tests exercise retries and routing.
+type Fake struct {
+	name string
+
+	mu        sync.Mutex
+	Sent      []Notification
+	Calls     int
+	FailTimes int
+	Err       error
+}
+
+func NewFake(name string) *Fake {
+	return &Fake{name: name}
+}
+
+func (f *Fake) Name() string { return f.name }
+
+func (f *Fake) Send(_ context.Context, n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Calls++
+	if f.Err != nil && f.Calls <= f.FailTimes {
+		return f.Err
+	}
+	f.Sent = append(f.Sent, n)
+	return nil
+}
+
+func (f *Fake) Notifications() []Notification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]Notification, len(f.Sent))
+	copy(out, f.Sent)
+	return out
+}
@@ -0,0 +1,60 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// File writes notifications as JSON lines, which is handy for local
+// development without MailHog or webhooks.
+type File struct {
+	mu sync.Mutex
+	w  io.Writer
+	c  io.Closer
+}
+
+// NewFile opens path for appending; "-" or "stdout" selects standard output.
+func NewFile(path string) (*File, error) {
+	if path == "-" || path == "stdout" {
+		return &File{w: os.Stdout}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &File{w: f, c: f}, nil
+}
+
+func NewWriter(w io.Writer) *File {
+	return &File{w: w}
+}
+
+func (c *File) Name() string { return "file" }
+
+func (c *File) Send(_ context.Context, n Notification) error {
+	line, err := json.Marshal(struct {
+		Time time.Time `json:"time"`
+		Notification
+	}{time.Now().UTC(), n})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err = c.w.Write(append(line, '\n'))
+	return err
+}
+
+func (c *File) Close() error {
+	if c.c == nil {
+		return nil
+	}
+	return c.c.Close()
+}
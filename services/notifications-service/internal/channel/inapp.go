@@ -0,0 +1,30 @@
+package channel
+
+import (
+	"context"
+
+	"GoNotification/internal/domain"
+)
+
+type InboxStore interface {
+	Deliver(ctx context.Context, n Notification) (*domain.Notification, error)
+}
+
+// InApp stores notifications in the user's in-app inbox.
+type InApp struct {
+	store InboxStore
+}
+
+func NewInApp(store InboxStore) *InApp {
+	return &InApp{store: store}
+}
+
+func (c *InApp) Name() string { return "inapp" }
+
+func (c *InApp) Send(ctx context.Context, n Notification) error {
+	if n.UserID == 0 {
+		return ErrNoAddress
+	}
+	_, err := c.store.Deliver(ctx, n)
+	return err
+}
@@ -0,0 +1,47 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+type retrying struct {
+	Channel
+	attempts int
+	backoff  time.Duration
+}
+
+// WithRetry retries failed sends up to attempts times in total, doubling the
+// backoff between tries. ErrNoAddress is never retried.
+func WithRetry(ch Channel, attempts int, backoff time.Duration) Channel {
+	if attempts <= 1 {
+		return ch
+	}
+	return &retrying{Channel: ch, attempts: attempts, backoff: backoff}
+}
+
+func (r *retrying) Send(ctx context.Context, n Notification) error {
+	wait := r.backoff
+
+	var err error
+	for attempt := 1; attempt <= r.attempts; attempt++ {
+		if err = r.Channel.Send(ctx, n); err == nil || errors.Is(err, ErrNoAddress) {
+			return err
+		}
+		if attempt == r.attempts {
+			break
+		}
+
+		log.Printf("channel %s: attempt %d/%d failed: %v", r.Name(), attempt, r.attempts, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// DefaultRoute is used for notification types without an explicit route.
+const DefaultRoute = "*"
+
+type Router struct {
+	routes map[string][]Channel
+	policy Policy
+}
+
+// NewRouter resolves routes (notification type -> channel names) against the
+// available channels.
+func NewRouter(routes map[string][]string, channels ...Channel) (*Router, error) {
+	byName := make(map[string]Channel, len(channels))
+	for _, ch := range channels {
+		byName[ch.Name()] = ch
+	}
+
+	r := &Router{routes: make(map[string][]Channel, len(routes))}
+	for typ, names := range routes {
+		for _, name := range names {
+			ch, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("channel: route %q uses unknown channel %q", typ, name)
+			}
+			r.routes[typ] = append(r.routes[typ], ch)
+		}
+	}
+	return r, nil
+}
+
+// SetPolicy installs a policy consulted before each channel send.
+func (r *Router) SetPolicy(p Policy) {
+	r.policy = p
+}
+
+func (r *Router) ChannelsFor(typ string) []Channel {
+	if chs, ok := r.routes[typ]; ok {
+		return chs
+	}
+	return r.routes[DefaultRoute]
+}
+
+// Dispatch sends n through every channel routed for its type. A channel that
+// has no address for the recipient is skipped; other failures are joined.
+func (r *Router) Dispatch(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, ch := range r.ChannelsFor(n.Type) {
+		if r.policy != nil {
+			ok, err := r.policy.Allow(ctx, n, ch.Name())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: policy: %w", ch.Name(), err))
+				continue
+			}
+			if !ok {
+				log.Printf("channel %s: suppressed %s for user %d by preferences", ch.Name(), n.Type, n.UserID)
+				continue
+			}
+		}
+
+		err := ch.Send(ctx, n)
+		switch {
+		case err == nil:
+			log.Printf("channel %s: %s delivered to user %d", ch.Name(), n.Type, n.UserID)
+		case errors.Is(err, ErrNoAddress):
+			log.Printf("channel %s: skipped %s for user %d: no address", ch.Name(), n.Type, n.UserID)
+		default:
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
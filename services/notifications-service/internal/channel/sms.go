@@ -0,0 +1,34 @@
+package channel
+
+import (
+	"context"
+	"log"
+)
+
+const smsMaxLen = 160
+
+// SMS is a provider stub: it validates the recipient and logs the text that
+// would be sent instead of calling a real gateway.
+type SMS struct {
+	from string
+}
+
+func NewSMS(from string) *SMS {
+	return &SMS{from: from}
+}
+
+func (c *SMS) Name() string { return "sms" }
+
+func (c *SMS) Send(_ context.Context, n Notification) error {
+	if n.Phone == "" {
+		return ErrNoAddress
+	}
+
+	text := []rune(n.Content.Subject)
+	if len(text) > smsMaxLen {
+		text = text[:smsMaxLen]
+	}
+
+	log.Printf("sms: %s -> %s: %s", c.from, n.Phone, string(text))
+	return nil
+}
@@ -0,0 +1,35 @@
+package channel
+
+import (
+	"context"
+
+	"GoNotification/internal/email"
+)
+
+// UnsubscribeLinker builds one-click unsubscribe URLs for email headers.
+type UnsubscribeLinker interface {
+	URL(userID uint, category, channel string) string
+}
+
+type SMTP struct {
+	sender *email.Sender
+	links  UnsubscribeLinker
+}
+
+func NewSMTP(sender *email.Sender, links UnsubscribeLinker) *SMTP {
+	return &SMTP{sender: sender, links: links}
+}
+
+func (c *SMTP) Name() string { return "smtp" }
+
+func (c *SMTP) Send(ctx context.Context, n Notification) error {
+	if n.Email == "" {
+		return ErrNoAddress
+	}
+
+	m := email.Mail{To: n.Email, Content: n.Content}
+	if !n.Mandatory && c.links != nil {
+		m.Unsubscribe = c.links.URL(n.UserID, n.Category, c.Name())
+	}
+	return c.sender.Send(ctx, m)
+}
@@ -1,30 +1,144 @@
 package internal
 
 import (
+	"GoNotification/internal/domain"
+	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
+	HTTPAddr          string
+	PGURL             string
+	JWTSecret         string
+	PublicBaseURL     string
+	UnsubscribeSecret string
+	SSEHeartbeat      time.Duration
+
 	KafkaBrokers string
-	KafkaTopic   string
+	KafkaTopics  []string
 	KafkaGroupID string
 	SMTPHost     string
 	SMTPPort     int
 	SMTPFrom     string
+
+	SMTPUsername           string
+	SMTPPassword           string
+	SMTPAuth               string
+	SMTPTLS                string
+	SMTPInsecureSkipVerify bool
+	SMTPPoolSize           int
+	SMTPIdleTimeout        time.Duration
+	SMTPDialTimeout        time.Duration
+	SMTPSendTimeout        time.Duration
+
+	// NotifyRoutes maps a notification type to the channels it is sent
+	// through, e.g. NOTIFY_ROUTES="user.registered=smtp,file;*=smtp,inapp".
+	NotifyRoutes        map[string][]string
+	ChannelRetries      map[string]int
+	DefaultRetries      int
+	ChannelRetryBackoff time.Duration
+	WebhookURL          string
+	WebhookTimeout      time.Duration
+	SMSFrom             string
+	FileSinkPath        string
+
+	// DigestInterval is how often the digest scheduler looks for due
+	// digests; DigestDailyAt is the local time of day daily digests go out.
+	DigestInterval time.Duration
+	DigestDailyAt  int
 }
 
 func MustLoad() *Config {
-	return &Config{
-		KafkaBrokers: getEnv("KAFKA_BROKERS", "localhost:9092"),
-		KafkaTopic:   getEnv("KAFKA_TOPIC", "user.registered"),
-		KafkaGroupID: getEnv("KAFKA_GROUP_ID", "notification-service"),
-		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
-		SMTPPort:     getInt("SMTP_PORT", 1025),
-		SMTPFrom:     getEnv("SMTP_FROM", "noreply@gomarket.local"),
+	cfg := &Config{
+		HTTPAddr:               getEnv("HTTP_ADDR", ":8082"),
+		PGURL:                  mustEnv("PG_URL"),
+		JWTSecret:              mustEnv("JWT_SECRET"),
+		PublicBaseURL:          getEnv("PUBLIC_BASE_URL", "http://localhost:8082"),
+		UnsubscribeSecret:      getEnv("UNSUBSCRIBE_SECRET", ""),
+		SSEHeartbeat:           getDuration("SSE_HEARTBEAT", 25*time.Second),
+		KafkaBrokers:           getEnv("KAFKA_BROKERS", "localhost:9092"),
+		KafkaTopics:            getList("KAFKA_TOPICS", "user.registered,order.created,order.paid,order.shipped,order.delivered,order.cancelled,order.refunded,product.approved,product.rejected"),
+		KafkaGroupID:           getEnv("KAFKA_GROUP_ID", "notification-service"),
+		SMTPHost:               getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:               getInt("SMTP_PORT", 1025),
+		SMTPFrom:               getEnv("SMTP_FROM", "noreply@gomarket.local"),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		SMTPAuth:               getEnv("SMTP_AUTH", ""),
+		SMTPTLS:                getEnv("SMTP_TLS", "none"),
+		SMTPInsecureSkipVerify: getBool("SMTP_INSECURE_SKIP_VERIFY", false),
+		SMTPPoolSize:           getInt("SMTP_POOL_SIZE", 4),
+		SMTPIdleTimeout:        getDuration("SMTP_IDLE_TIMEOUT", 30*time.Second),
+		SMTPDialTimeout:        getDuration("SMTP_DIAL_TIMEOUT", 10*time.Second),
+		SMTPSendTimeout:        getDuration("SMTP_SEND_TIMEOUT", 30*time.Second),
+		NotifyRoutes:           getRoutes("NOTIFY_ROUTES", "user.registered=smtp;*=smtp,inapp"),
+		ChannelRetries:         getIntMap("CHANNEL_RETRIES"),
+		DefaultRetries:         getInt("CHANNEL_DEFAULT_RETRIES", 3),
+		ChannelRetryBackoff:    getDuration("CHANNEL_RETRY_BACKOFF", 500*time.Millisecond),
+		WebhookURL:             getEnv("WEBHOOK_URL", ""),
+		WebhookTimeout:         getDuration("WEBHOOK_TIMEOUT", 5*time.Second),
+		SMSFrom:                getEnv("SMS_FROM", "GoMarket"),
+		FileSinkPath:           getEnv("FILE_SINK_PATH", "-"),
+		DigestInterval:         getDuration("DIGEST_INTERVAL", time.Minute),
+		DigestDailyAt:          getClock("DIGEST_DAILY_AT", "09:00"),
+	}
+	if cfg.UnsubscribeSecret == "" {
+		cfg.UnsubscribeSecret = cfg.JWTSecret
+	}
+
+	if cfg.SMTPAuth == "" {
+		cfg.SMTPAuth = "none"
+		if cfg.SMTPUsername != "" {
+			cfg.SMTPAuth = "plain"
+		}
+	}
+
+	validateConfig(cfg)
+	return cfg
+}
+
+func validateConfig(cfg *Config) {
+	switch cfg.SMTPTLS {
+	case "none", "starttls", "tls":
+	default:
+		log.Fatalf("SMTP_TLS must be one of none, starttls, tls; got %q", cfg.SMTPTLS)
+	}
+	switch cfg.SMTPAuth {
+	case "none":
+	case "plain", "login":
+		if cfg.SMTPUsername == "" {
+			log.Fatalf("SMTP_AUTH=%s requires SMTP_USERNAME", cfg.SMTPAuth)
+		}
+	default:
+		log.Fatalf("SMTP_AUTH must be one of none, plain, login; got %q", cfg.SMTPAuth)
+	}
+	if cfg.SMTPPoolSize <= 0 {
+		log.Fatal("SMTP_POOL_SIZE must be > 0")
+	}
+	if cfg.DigestInterval <= 0 {
+		log.Fatal("DIGEST_INTERVAL must be > 0")
 	}
 }
 
+// Retries returns the number of send attempts configured for a channel.
+func (c *Config) Retries(channel string) int {
+	if n, ok := c.ChannelRetries[channel]; ok {
+		return n
+	}
+	return c.DefaultRetries
+}
+
+func mustEnv(k string) string {
+	v := os.Getenv(k)
+	if v == "" {
+		log.Fatalf("missing required env: %s", k)
+	}
+	return v
+}
+
 func getEnv(k, def string) string {
 	if v := os.Getenv(k); v != "" {
 		return v
@@ -40,3 +154,74 @@ func getInt(k string, def int) int {
 	}
 	return def
 }
+
+func getBool(k string, def bool) bool {
+	if v := os.Getenv(k); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func getDuration(k string, def time.Duration) time.Duration {
+	if v := os.Getenv(k); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+func getClock(k, def string) int {
+	m, err := domain.ParseClock(getEnv(k, def))
+	if err != nil {
+		log.Fatalf("%s: %v", k, err)
+	}
+	return m
+}
+
+func getList(k, def string) []string {
+	var out []string
+	for _, v := range strings.Split(getEnv(k, def), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func getRoutes(k, def string) map[string][]string {
+	routes := make(map[string][]string)
+	for _, route := range strings.Split(getEnv(k, def), ";") {
+		typ, names, ok := strings.Cut(strings.TrimSpace(route), "=")
+		if !ok || typ == "" {
+			if route != "" {
+				log.Fatalf("%s: invalid route %q", k, route)
+			}
+			continue
+		}
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				routes[typ] = append(routes[typ], name)
+			}
+		}
+	}
+	return routes
+}
+
+func getIntMap(k string) map[string]int {
+	out := make(map[string]int)
+	for _, pair := range strings.Split(os.Getenv(k), ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Fatalf("%s: invalid value for %s: %q", k, name, raw)
+		}
+		out[name] = n
+	}
+	return out
+}
@@ -3,10 +3,14 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"strings"
 
-	"GoNotification/internal/email"
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/templates"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -17,18 +21,60 @@ type UserRegisteredEvent struct {
 	Name    string `json:"name"`
 	Token   string `json:"token"`
 	BaseURL string `json:"base_url"`
+	Locale  string `json:"locale"`
+}
+
+// OrderEvent is published by order-service on order.* topics whenever an
+// order is created or changes status.
+type OrderEvent struct {
+	OrderID    uint    `json:"order_id"`
+	BuyerID    uint    `json:"buyer_id"`
+	BuyerEmail string  `json:"buyer_email"`
+	SellerID   uint    `json:"seller_id"`
+	Status     string  `json:"status"`
+	Total      float64 `json:"total"`
+	Items      []struct {
+		ProductID uint    `json:"product_id"`
+		Name      string  `json:"name"`
+		Quantity  int     `json:"quantity"`
+		UnitPrice float64 `json:"unit_price"`
+	} `json:"items"`
+}
+
+// ProductEvent is published by product-service on product.approved and
+// product.rejected when a moderator decides on a listing.
+type ProductEvent struct {
+	ProductID   uint   `json:"product_id"`
+	SellerID    uint   `json:"seller_id"`
+	SellerEmail string `json:"seller_email"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason"`
+}
+
+type Dispatcher interface {
+	Dispatch(ctx context.Context, n channel.Notification) error
+}
+
+// Locales remembers the locale each user registered with, see repo.Locales.
+// Get returns "" for users it has not seen.
+type Locales interface {
+	Save(userID uint, locale string) error
+	Get(userID uint) (string, error)
 }
 
 type Consumer struct {
-	reader *kafka.Reader
-	sender *email.Sender
+	reader     *kafka.Reader
+	renderer   *templates.Renderer
+	dispatcher Dispatcher
+	locales    Locales
 }
 
-func New(brokers, topic, groupID string, sender *email.Sender) *Consumer {
-	log.Printf("Initializing Kafka consumer - brokers: %s, topic: %s, groupID: %s", brokers, topic, groupID)
+func New(brokers string, topics []string, groupID string, renderer *templates.Renderer, dispatcher Dispatcher, locales Locales) *Consumer {
+	log.Printf("Initializing Kafka consumer - brokers: %s, topics: %v, groupID: %s", brokers, topics, groupID)
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:     strings.Split(brokers, ","),
-		Topic:       topic,
+		GroupTopics: topics,
 		GroupID:     groupID,
 		MinBytes:    1,
 		MaxBytes:    10e6,
@@ -36,8 +82,10 @@ func New(brokers, topic, groupID string, sender *email.Sender) *Consumer {
 	})
 
 	return &Consumer{
-		reader: reader,
-		sender: sender,
+		reader:     reader,
+		renderer:   renderer,
+		dispatcher: dispatcher,
+		locales:    locales,
 	}
 }
 
@@ -59,24 +107,170 @@ func (c *Consumer) Start(ctx context.Context) error {
 			}
 
 			log.Printf("consumer: received message from topic %s, partition %d, offset %d", msg.Topic, msg.Partition, msg.Offset)
-			c.handleMessage(msg)
+			if err := c.handleMessage(ctx, msg); err != nil {
+				log.Printf("consumer: %v", err)
+			}
+		}
+	}
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, msg kafka.Message) error {
+	var (
+		ns  []channel.Notification
+		err error
+	)
+	switch {
+	case msg.Topic == "user.registered":
+		ns, err = one(c.userRegistered(msg.Value))
+	case strings.HasPrefix(msg.Topic, "order."):
+		ns, err = c.orderEvent(msg.Topic, msg.Value)
+	case msg.Topic == "product.approved" || msg.Topic == "product.rejected":
+		ns, err = one(c.productEvent(msg.Value))
+	default:
+		return fmt.Errorf("no handler for topic %s", msg.Topic)
+	}
+	if err != nil {
+		return err
+	}
+
+	category := domain.CategoryOf(msg.Topic)
+	var errs []error
+	for _, n := range ns {
+		n.Type = msg.Topic
+		n.Category = string(category)
+		n.Mandatory = domain.IsMandatory(category)
+
+		if err := c.dispatcher.Dispatch(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("dispatch %s to user %d: %w", n.Type, n.UserID, err))
 		}
 	}
+	return errors.Join(errs...)
+}
+
+func one(n channel.Notification, err error) ([]channel.Notification, error) {
+	if err != nil {
+		return nil, err
+	}
+	return []channel.Notification{n}, nil
 }
 
-func (c *Consumer) handleMessage(msg kafka.Message) {
+func (c *Consumer) userRegistered(value []byte) (channel.Notification, error) {
 	var event UserRegisteredEvent
-	if err := json.Unmarshal(msg.Value, &event); err != nil {
-		log.Printf("consumer: unmarshal error: %v", err)
-		return
+	if err := json.Unmarshal(value, &event); err != nil {
+		return channel.Notification{}, fmt.Errorf("unmarshal error: %w", err)
 	}
 
 	log.Printf("consumer: received event for user %d (%s)", event.UserID, event.Email)
 
-	if err := c.sender.SendVerification(event.Email, event.Token, event.BaseURL); err != nil {
-		log.Printf("consumer: send email error: %v", err)
-		return
+	if event.Locale != "" {
+		if err := c.locales.Save(event.UserID, event.Locale); err != nil {
+			log.Printf("consumer: save locale of user %d: %v", event.UserID, err)
+		}
+	}
+
+	content, err := c.renderer.Render(templates.Verification, event.Locale, templates.VerificationData{
+		Name: event.Name,
+		Link: fmt.Sprintf("%s/auth/verify?token=%s", event.BaseURL, event.Token),
+	})
+	if err != nil {
+		return channel.Notification{}, fmt.Errorf("render error: %w", err)
+	}
+
+	return channel.Notification{
+		UserID:  event.UserID,
+		Email:   event.Email,
+		Locale:  event.Locale,
+		Content: content,
+	}, nil
+}
+
+// orderEvent notifies the buyer, and the seller too when an order is placed
+// or paid, each in the locale they registered with. Order events carry no
+// seller email, so the seller is only reached by channels that need no
+// address, such as the in-app inbox.
+func (c *Consumer) orderEvent(topic string, value []byte) ([]channel.Notification, error) {
+	var event OrderEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+
+	log.Printf("consumer: received order %d (%s) for user %d", event.OrderID, event.Status, event.BuyerID)
+
+	data := templates.OrderData{
+		OrderID: event.OrderID,
+		Status:  event.Status,
+		Total:   event.Total,
+		Items:   make([]templates.OrderLine, 0, len(event.Items)),
+	}
+	for _, it := range event.Items {
+		data.Items = append(data.Items, templates.OrderLine{Name: it.Name, Quantity: it.Quantity, UnitPrice: it.UnitPrice})
+	}
+
+	locale := c.localeOf(event.BuyerID)
+	content, err := c.renderer.Render(templates.Order, locale, data)
+	if err != nil {
+		return nil, fmt.Errorf("render error: %w", err)
+	}
+	ns := []channel.Notification{{
+		UserID:  event.BuyerID,
+		Email:   event.BuyerEmail,
+		Locale:  locale,
+		Content: content,
+	}}
+
+	if (topic == "order.created" || topic == "order.paid") && event.SellerID != 0 {
+		locale := c.localeOf(event.SellerID)
+		content, err := c.renderer.Render(templates.Sale, locale, data)
+		if err != nil {
+			return nil, fmt.Errorf("render error: %w", err)
+		}
+		ns = append(ns, channel.Notification{
+			UserID:  event.SellerID,
+			Locale:  locale,
+			Content: content,
+		})
+	}
+	return ns, nil
+}
+
+// localeOf returns the locale the user registered with, or the default one
+// if it is unknown.
+func (c *Consumer) localeOf(userID uint) string {
+	locale, err := c.locales.Get(userID)
+	if err != nil {
+		log.Printf("consumer: locale of user %d: %v", userID, err)
+	}
+	if locale == "" {
+		return templates.DefaultLocale
+	}
+	return locale
+}
+
+// productEvent notifies the seller of a moderation decision, in the locale
+// they registered with.
+func (c *Consumer) productEvent(value []byte) (channel.Notification, error) {
+	var event ProductEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return channel.Notification{}, fmt.Errorf("unmarshal error: %w", err)
+	}
+
+	log.Printf("consumer: received product %d (%s) for seller %d", event.ProductID, event.Status, event.SellerID)
+
+	locale := c.localeOf(event.SellerID)
+	content, err := c.renderer.Render(templates.Product, locale, templates.ProductData{
+		ProductID: event.ProductID,
+		Name:      event.Name,
+		Status:    event.Status,
+		Reason:    event.Reason,
+	})
+	if err != nil {
+		return channel.Notification{}, fmt.Errorf("render error: %w", err)
 	}
 
-	log.Printf("consumer: verification email sent to %s", event.Email)
+	return channel.Notification{
+		UserID:  event.SellerID,
+		Email:   event.SellerEmail,
+		Locale:  locale,
+		Content: content,
+	}, nil
 }
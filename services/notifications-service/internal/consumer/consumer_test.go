@@ -0,0 +1,241 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"GoNotification/internal/channel"
+	"GoNotification/internal/templates"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func newTestConsumer(t *testing.T, routes map[string][]string, channels ...channel.Channel) *Consumer {
+	t.Helper()
+
+	renderer, err := templates.New()
+	if err != nil {
+		t.Fatalf("templates.New() error = %v", err)
+	}
+	router, err := channel.NewRouter(routes, channels...)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+	return &Consumer{renderer: renderer, dispatcher: router, locales: fakeLocales{}}
+}
+
+// fakeLocales хранит локали пользователей в памяти.
+type fakeLocales map[uint]string
+
+func (f fakeLocales) Save(userID uint, locale string) error {
+	f[userID] = locale
+	return nil
+}
+
+func (f fakeLocales) Get(userID uint) (string, error) {
+	return f[userID], nil
+}
+
+func TestHandleMessage_UserRegistered(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	c := newTestConsumer(t, map[string][]string{"user.registered": {"smtp"}}, fake)
+
+	value, _ := json.Marshal(UserRegisteredEvent{
+		UserID:  1,
+		Email:   "user@example.com",
+		Name:    "Dana",
+		Token:   "tok",
+		BaseURL: "http://localhost:8080",
+		Locale:  "en",
+	})
+
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "user.registered", Value: value}); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+
+	sent := fake.Notifications()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(sent))
+	}
+	if sent[0].Content.Subject != "Confirm your registration" {
+		t.Errorf("Subject = %q", sent[0].Content.Subject)
+	}
+	if !strings.Contains(sent[0].Content.Text, "http://localhost:8080/auth/verify?token=tok") {
+		t.Errorf("Text does not contain verification link:\n%s", sent[0].Content.Text)
+	}
+}
+
+func TestHandleMessage_BadPayload(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	c := newTestConsumer(t, map[string][]string{channel.DefaultRoute: {"smtp"}}, fake)
+
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "user.registered", Value: []byte("{")}); err == nil {
+		t.Fatalf("handleMessage() error = nil, want unmarshal error")
+	}
+	if len(fake.Notifications()) != 0 {
+		t.Errorf("nothing should be sent for a bad payload")
+	}
+}
+
+func TestHandleMessage_OrderShipped(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	c := newTestConsumer(t, map[string][]string{channel.DefaultRoute: {"smtp"}}, fake)
+
+	value := []byte(`{"order_id":42,"buyer_id":7,"buyer_email":"buyer@example.com","seller_id":3,
+		"status":"SHIPPED","total":25.5,"items":[{"product_id":1,"name":"Чайник","quantity":1,"unit_price":25.5}]}`)
+
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "order.shipped", Value: value}); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+
+	sent := fake.Notifications()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(sent))
+	}
+	n := sent[0]
+	if n.UserID != 7 || n.Email != "buyer@example.com" {
+		t.Errorf("recipient = %d %q, want buyer", n.UserID, n.Email)
+	}
+	if n.Category != "orders" || n.Mandatory {
+		t.Errorf("Category = %q Mandatory = %v, want non-mandatory orders", n.Category, n.Mandatory)
+	}
+	if !strings.Contains(n.Content.Subject, "42") {
+		t.Errorf("Subject = %q, want order number", n.Content.Subject)
+	}
+	if !strings.Contains(n.Content.Text, "Чайник") {
+		t.Errorf("Text does not list items:\n%s", n.Content.Text)
+	}
+}
+
+func TestHandleMessage_OrderPaidNotifiesSeller(t *testing.T) {
+	fake := channel.NewFake("inapp")
+	c := newTestConsumer(t, map[string][]string{channel.DefaultRoute: {"inapp"}}, fake)
+
+	value := []byte(`{"order_id":42,"buyer_id":7,"buyer_email":"buyer@example.com","seller_id":3,
+		"status":"PAID","total":25.5,"items":[{"product_id":1,"name":"Чайник","quantity":1,"unit_price":25.5}]}`)
+
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "order.paid", Value: value}); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+
+	sent := fake.Notifications()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d notifications, want buyer and seller", len(sent))
+	}
+	if sent[0].UserID != 7 {
+		t.Errorf("first recipient = %d, want buyer", sent[0].UserID)
+	}
+	seller := sent[1]
+	if seller.UserID != 3 || seller.Email != "" {
+		t.Errorf("second recipient = %d %q, want seller without email", seller.UserID, seller.Email)
+	}
+	if seller.Type != "order.paid" || seller.Category != "orders" {
+		t.Errorf("Type = %q Category = %q", seller.Type, seller.Category)
+	}
+	if !strings.Contains(seller.Content.Subject, "42") || !strings.Contains(seller.Content.Text, "Чайник") {
+		t.Errorf("seller content = %+v", seller.Content)
+	}
+
+	// об отправке продавцу не сообщаем
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "order.shipped", Value: value}); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if got := len(fake.Notifications()); got != 3 {
+		t.Errorf("sent %d notifications after order.shipped, want 3", got)
+	}
+}
+
+func TestHandleMessage_OrderUsesRegisteredLocale(t *testing.T) {
+	fake := channel.NewFake("inapp")
+	c := newTestConsumer(t, map[string][]string{channel.DefaultRoute: {"inapp"}}, fake)
+	ctx := context.Background()
+
+	for _, u := range []UserRegisteredEvent{
+		{UserID: 7, Email: "buyer@example.com", Locale: "en"},
+		{UserID: 3, Email: "seller@example.com", Locale: "kk"},
+	} {
+		value, _ := json.Marshal(u)
+		if err := c.handleMessage(ctx, kafka.Message{Topic: "user.registered", Value: value}); err != nil {
+			t.Fatalf("handleMessage(user.registered) error = %v", err)
+		}
+	}
+
+	value := []byte(`{"order_id":42,"buyer_id":7,"buyer_email":"buyer@example.com","seller_id":3,"status":"PAID","total":10}`)
+	if err := c.handleMessage(ctx, kafka.Message{Topic: "order.paid", Value: value}); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+
+	sent := fake.Notifications()
+	if len(sent) != 4 {
+		t.Fatalf("sent %d notifications, want 4", len(sent))
+	}
+	if buyer := sent[2]; buyer.Locale != "en" || buyer.Content.Subject != "Order #42 paid" {
+		t.Errorf("buyer = %q %q, want English", buyer.Locale, buyer.Content.Subject)
+	}
+	if seller := sent[3]; seller.Locale != "kk" || !strings.Contains(seller.Content.Subject, "төленді") {
+		t.Errorf("seller = %q %q, want Kazakh", seller.Locale, seller.Content.Subject)
+	}
+}
+
+func TestHandleMessage_ProductRejected(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	c := newTestConsumer(t, map[string][]string{channel.DefaultRoute: {"smtp"}}, fake)
+
+	value, _ := json.Marshal(ProductEvent{
+		ProductID:   12,
+		SellerID:    3,
+		SellerEmail: "seller@example.com",
+		Name:        "Чайник",
+		Status:      "REJECTED",
+		Reason:      "размытые фото",
+	})
+
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "product.rejected", Value: value}); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+
+	sent := fake.Notifications()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(sent))
+	}
+	n := sent[0]
+	if n.UserID != 3 || n.Email != "seller@example.com" {
+		t.Errorf("recipient = %d %q, want seller", n.UserID, n.Email)
+	}
+	if n.Category != "products" || n.Mandatory {
+		t.Errorf("Category = %q Mandatory = %v, want non-mandatory products", n.Category, n.Mandatory)
+	}
+	if !strings.Contains(n.Content.Text, "размытые фото") {
+		t.Errorf("Text does not contain the reason:\n%s", n.Content.Text)
+	}
+}
+
+func TestHandleMessage_ProductUsesRegisteredLocale(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	c := newTestConsumer(t, map[string][]string{"product.approved": {"smtp"}}, fake)
+	c.locales = fakeLocales{3: "en"}
+
+	value, _ := json.Marshal(ProductEvent{ProductID: 12, SellerID: 3, SellerEmail: "seller@example.com", Name: "Kettle", Status: "PUBLISHED"})
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "product.approved", Value: value}); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+
+	sent := fake.Notifications()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(sent))
+	}
+	if sent[0].Locale != "en" || sent[0].Content.Subject != `Product "Kettle" is published` {
+		t.Errorf("notification = %q %q, want English", sent[0].Locale, sent[0].Content.Subject)
+	}
+}
+
+func TestHandleMessage_UnknownTopic(t *testing.T) {
+	fake := channel.NewFake("smtp")
+	c := newTestConsumer(t, map[string][]string{channel.DefaultRoute: {"smtp"}}, fake)
+
+	if err := c.handleMessage(context.Background(), kafka.Message{Topic: "payment.failed", Value: []byte("{}")}); err == nil {
+		t.Fatalf("handleMessage() error = nil, want error for unknown topic")
+	}
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type DigestFrequency string
+
+const (
+	DigestImmediate DigestFrequency = "immediate"
+	DigestHourly    DigestFrequency = "hourly"
+	DigestDaily     DigestFrequency = "daily"
+)
+
+func IsKnownFrequency(f DigestFrequency) bool {
+	switch f {
+	case DigestImmediate, DigestHourly, DigestDaily:
+		return true
+	}
+	return false
+}
+
+// DigestSettings controls how a user's email for digest categories is
+// batched. Quiet hours are minutes since local midnight; a window may wrap
+// past midnight (e.g. 22:00-07:00). Equal start and end disable it.
+type DigestSettings struct {
+	UserID       uint            `gorm:"primaryKey;autoIncrement:false"`
+	Frequency    DigestFrequency `gorm:"size:16;not null;default:immediate"`
+	Timezone     string          `gorm:"size:64;not null;default:UTC"`
+	QuietStart   int             `gorm:"not null;default:0"`
+	QuietEnd     int             `gorm:"not null;default:0"`
+	LastDigestAt *time.Time
+	UpdatedAt    time.Time
+}
+
+// DefaultDigestSettings applies to users who never saved their settings.
+func DefaultDigestSettings(userID uint) DigestSettings {
+	return DigestSettings{UserID: userID, Frequency: DigestImmediate, Timezone: "UTC"}
+}
+
+// Location returns the user's timezone, falling back to UTC when the stored
+// name is unknown to the tz database.
+func (s DigestSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (s DigestSettings) HasQuietHours() bool {
+	return s.QuietStart != s.QuietEnd
+}
+
+func (s DigestSettings) InQuietHours(t time.Time) bool {
+	if !s.HasQuietHours() {
+		return false
+	}
+	local := t.In(s.Location())
+	m := local.Hour()*60 + local.Minute()
+	if s.QuietStart < s.QuietEnd {
+		return m >= s.QuietStart && m < s.QuietEnd
+	}
+	return m >= s.QuietStart || m < s.QuietEnd
+}
+
+// DigestDue reports whether a digest with pending items may go out at now.
+// Hourly digests go at most once an hour, daily ones once per local day at
+// or after dailyAt (minutes since local midnight). Immediate users only
+// accumulate items during quiet hours, so theirs go as soon as it ends.
+func (s DigestSettings) DigestDue(now time.Time, dailyAt int) bool {
+	if s.InQuietHours(now) {
+		return false
+	}
+	if s.LastDigestAt == nil {
+		return true
+	}
+	last := *s.LastDigestAt
+
+	switch s.Frequency {
+	case DigestHourly:
+		return now.Sub(last) >= time.Hour
+	case DigestDaily:
+		local := now.In(s.Location())
+		slot := time.Date(local.Year(), local.Month(), local.Day(), dailyAt/60, dailyAt%60, 0, 0, local.Location())
+		if local.Before(slot) {
+			slot = slot.AddDate(0, 0, -1)
+		}
+		return last.Before(slot)
+	default:
+		return true
+	}
+}
+
+// ParseClock parses "HH:MM" into minutes since midnight.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// DigestItem is a notification held back for the user's next digest. Items
+// live in the database so pending digests survive restarts.
+type DigestItem struct {
+	ID        uint     `gorm:"primaryKey;autoIncrement"`
+	UserID    uint     `gorm:"not null;index"`
+	Email     string   `gorm:"size:255;not null"`
+	Locale    string   `gorm:"size:8;not null"`
+	Type      string   `gorm:"size:64;not null"`
+	Category  Category `gorm:"size:64;not null"`
+	Title     string   `gorm:"size:255;not null"`
+	Body      string   `gorm:"type:text"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func mustClock(t *testing.T, s string) int {
+	t.Helper()
+	m, err := ParseClock(s)
+	if err != nil {
+		t.Fatalf("ParseClock(%q) error = %v", s, err)
+	}
+	return m
+}
+
+func TestDigestSettings_InQuietHours(t *testing.T) {
+	s := DigestSettings{Timezone: "Asia/Almaty", QuietStart: mustClock(t, "22:00"), QuietEnd: mustClock(t, "07:00")}
+	loc := s.Location()
+
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 3, 1, 21, 59, 0, 0, loc), false},
+		{time.Date(2026, 3, 1, 22, 0, 0, 0, loc), true},
+		{time.Date(2026, 3, 2, 3, 0, 0, 0, loc), true},
+		{time.Date(2026, 3, 2, 7, 0, 0, 0, loc), false},
+		{time.Date(2026, 3, 2, 12, 0, 0, 0, loc), false},
+	}
+	for _, c := range cases {
+		// Evaluate in UTC to make sure the user's timezone is applied.
+		if got := s.InQuietHours(c.at.UTC()); got != c.want {
+			t.Errorf("InQuietHours(%s) = %v, want %v", c.at.Format("15:04"), got, c.want)
+		}
+	}
+
+	if (DigestSettings{Timezone: "UTC"}).InQuietHours(time.Now()) {
+		t.Errorf("equal start and end should disable quiet hours")
+	}
+}
+
+func TestDigestSettings_DigestDue(t *testing.T) {
+	dailyAt := mustClock(t, "09:00")
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC) }
+	sent := func(tm time.Time) *time.Time { return &tm }
+
+	cases := []struct {
+		name string
+		s    DigestSettings
+		now  time.Time
+		want bool
+	}{
+		{"never sent", DigestSettings{Frequency: DigestDaily}, at(1, 10), true},
+		{"hourly too soon", DigestSettings{Frequency: DigestHourly, LastDigestAt: sent(at(1, 10))}, at(1, 10).Add(30 * time.Minute), false},
+		{"hourly due", DigestSettings{Frequency: DigestHourly, LastDigestAt: sent(at(1, 10))}, at(1, 11), true},
+		{"daily before slot", DigestSettings{Frequency: DigestDaily, LastDigestAt: sent(at(1, 9))}, at(2, 8), false},
+		{"daily after slot", DigestSettings{Frequency: DigestDaily, LastDigestAt: sent(at(1, 9))}, at(2, 9), true},
+		{"daily already sent today", DigestSettings{Frequency: DigestDaily, LastDigestAt: sent(at(2, 9))}, at(2, 20), false},
+		{"quiet hours", DigestSettings{Frequency: DigestHourly, QuietStart: 0, QuietEnd: mustClock(t, "12:00")}, at(1, 10), false},
+		{"immediate after quiet hours", DigestSettings{Frequency: DigestImmediate, LastDigestAt: sent(at(1, 10))}, at(1, 10), true},
+	}
+	for _, c := range cases {
+		if c.s.Timezone == "" {
+			c.s.Timezone = "UTC"
+		}
+		if got := c.s.DigestDue(c.now, dailyAt); got != c.want {
+			t.Errorf("%s: DigestDue() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	if m := mustClock(t, "07:30"); m != 450 || FormatClock(m) != "07:30" {
+		t.Errorf("ParseClock(07:30) = %d (%s), want 450", m, FormatClock(m))
+	}
+	for _, bad := range []string{"", "24:00", "7pm", "12:60"} {
+		if _, err := ParseClock(bad); err == nil {
+			t.Errorf("ParseClock(%q) error = nil", bad)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// UserLocale is the language a user registered with. Events from other
+// services carry no locale, so mail they trigger is rendered in this one.
+type UserLocale struct {
+	UserID    uint   `gorm:"primaryKey;autoIncrement:false"`
+	Locale    string `gorm:"size:8;not null"`
+	UpdatedAt time.Time
+}
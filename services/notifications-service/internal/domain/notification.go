@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// Notification is an entry in a user's in-app inbox.
+type Notification struct {
+	ID        uint     `gorm:"primaryKey;autoIncrement"`
+	UserID    uint     `gorm:"not null;index"`
+	Type      string   `gorm:"size:64;not null"`
+	Category  Category `gorm:"size:64;not null"`
+	Title     string   `gorm:"size:255;not null"`
+	Body      string   `gorm:"type:text"`
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,101 @@
+package domain
+
+import "time"
+
+type Category string
+
+const (
+	CategoryAccount   Category = "account"
+	CategoryOrders    Category = "orders"
+	CategoryProducts  Category = "products"
+	CategoryMarketing Category = "marketing"
+)
+
+// Categories lists every category a user can see in their preferences.
+// Mandatory categories carry transactional mail and cannot be switched off.
+// Digest categories are high-volume and may be batched into digest emails.
+var Categories = []struct {
+	Name      Category
+	Mandatory bool
+	Digest    bool
+}{
+	{CategoryAccount, true, false},
+	{CategoryOrders, false, false},
+	{CategoryProducts, false, true},
+	{CategoryMarketing, false, true},
+}
+
+var categoryByType = map[string]Category{
+	"user.registered":  CategoryAccount,
+	"order.created":    CategoryOrders,
+	"order.paid":       CategoryOrders,
+	"order.shipped":    CategoryOrders,
+	"order.delivered":  CategoryOrders,
+	"order.cancelled":  CategoryOrders,
+	"order.refunded":   CategoryOrders,
+	"product.approved": CategoryProducts,
+	"product.rejected": CategoryProducts,
+}
+
+// CategorySeparator joins the categories of a mixed digest in its
+// notification and unsubscribe link.
+const CategorySeparator = ","
+
+// CategoryOf maps a notification type (Kafka topic) to its category. Unknown
+// types are treated as marketing so they are never forced on a user.
+func CategoryOf(typ string) Category {
+	if c, ok := categoryByType[typ]; ok {
+		return c
+	}
+	return CategoryMarketing
+}
+
+func IsKnownCategory(c Category) bool {
+	for _, cat := range Categories {
+		if cat.Name == c {
+			return true
+		}
+	}
+	return false
+}
+
+func IsMandatory(c Category) bool {
+	for _, cat := range Categories {
+		if cat.Name == c {
+			return cat.Mandatory
+		}
+	}
+	return false
+}
+
+func IsDigestible(c Category) bool {
+	for _, cat := range Categories {
+		if cat.Name == c {
+			return cat.Digest
+		}
+	}
+	return false
+}
+
+// Preference is an explicit opt-in/out of one channel for one category.
+// The absence of a row means the channel is enabled.
+type Preference struct {
+	UserID    uint     `gorm:"primaryKey;autoIncrement:false"`
+	Category  Category `gorm:"primaryKey;size:64"`
+	Channel   string   `gorm:"primaryKey;size:32"`
+	Enabled   bool     `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// Channels are the delivery channels users control. Operational sinks such
+// as the file channel or partner webhooks are not subject to preferences.
+var Channels = []string{"smtp", "sms", "inapp"}
+
+func IsUserChannel(ch string) bool {
+	for _, c := range Channels {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package email
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal in-process SMTP server supporting EHLO, STARTTLS,
+// AUTH PLAIN/LOGIN, MAIL/RCPT/DATA, RSET, NOOP and QUIT.
+type fakeSMTP struct {
+	ln       net.Listener
+	tls      *tls.Config
+	implicit bool
+	username string
+	password string
+
+	mu       sync.Mutex
+	messages []string
+	conns    int
+	authed   []string
+}
+
+type fakeSMTPOptions struct {
+	startTLS bool
+	implicit bool
+	username string
+	password string
+}
+
+func newFakeSMTP(t *testing.T, opts fakeSMTPOptions) (*fakeSMTP, *tls.Config) {
+	t.Helper()
+
+	serverTLS, clientTLS := testTLSConfigs(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if opts.implicit {
+		ln = tls.NewListener(ln, serverTLS)
+	}
+
+	s := &fakeSMTP{
+		ln:       ln,
+		implicit: opts.implicit,
+		username: opts.username,
+		password: opts.password,
+	}
+	if opts.startTLS {
+		s.tls = serverTLS
+	}
+
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+
+	return s, clientTLS
+}
+
+func (s *fakeSMTP) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns++
+		s.mu.Unlock()
+		go s.handle(c)
+	}
+}
+
+func (s *fakeSMTP) handle(c net.Conn) {
+	defer c.Close()
+
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	reply := func(line string) {
+		_, _ = w.WriteString(line + "\r\n")
+		_ = w.Flush()
+	}
+	readLine := func() (string, bool) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", false
+		}
+		return strings.TrimRight(line, "\r\n"), true
+	}
+
+	secure := s.implicit
+	reply("220 fake ESMTP")
+
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			exts := []string{"250-fake"}
+			if s.tls != nil && !secure {
+				exts = append(exts, "250-STARTTLS")
+			}
+			if s.username != "" {
+				exts = append(exts, "250-AUTH PLAIN LOGIN")
+			}
+			exts = append(exts, "250 8BITMIME")
+			for _, e := range exts {
+				_, _ = w.WriteString(e + "\r\n")
+			}
+			_ = w.Flush()
+
+		case cmd == "STARTTLS":
+			reply("220 ready to start TLS")
+			tc := tls.Server(c, s.tls)
+			if err := tc.Handshake(); err != nil {
+				return
+			}
+			c = tc
+			r = bufio.NewReader(c)
+			w = bufio.NewWriter(c)
+			secure = true
+
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			raw, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(line[len("AUTH PLAIN"):]))
+			parts := strings.Split(string(raw), "\x00")
+			if len(parts) == 3 && parts[1] == s.username && parts[2] == s.password {
+				s.recordAuth("PLAIN")
+				reply("235 authenticated")
+			} else {
+				reply("535 bad credentials")
+			}
+
+		case cmd == "AUTH LOGIN":
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+			u, _ := readLine()
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+			p, _ := readLine()
+			user, _ := base64.StdEncoding.DecodeString(u)
+			pass, _ := base64.StdEncoding.DecodeString(p)
+			if string(user) == s.username && string(pass) == s.password {
+				s.recordAuth("LOGIN")
+				reply("235 authenticated")
+			} else {
+				reply("535 bad credentials")
+			}
+
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			if s.username != "" && len(s.authMechs()) == 0 {
+				reply("530 authentication required")
+				continue
+			}
+			reply("250 ok")
+
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			reply("250 ok")
+
+		case cmd == "DATA":
+			reply("354 end with .")
+			var sb strings.Builder
+			for {
+				l, ok := readLine()
+				if !ok {
+					return
+				}
+				if l == "." {
+					break
+				}
+				sb.WriteString(strings.TrimPrefix(l, ".") + "\r\n")
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, sb.String())
+			s.mu.Unlock()
+			reply("250 queued")
+
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 ok")
+
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTP) recordAuth(mech string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authed = append(s.authed, mech)
+}
+
+func (s *fakeSMTP) authMechs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.authed...)
+}
+
+func (s *fakeSMTP) stats() (messages []string, conns int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...), s.conns
+}
+
+func testTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	server = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+	client = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+	return server, client
+}
@@ -0,0 +1,115 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"GoNotification/internal/templates"
+)
+
+type message struct {
+	From        string
+	To          string
+	Content     templates.Content
+	Unsubscribe string
+	Date        time.Time
+	ID          string
+	Boundary    string
+}
+
+// build renders the message as multipart/alternative with quoted-printable
+// text and HTML parts. Non-ASCII subjects are encoded per RFC 2047.
+func (m message) build() ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := []string{
+		"From: " + m.From,
+		"To: " + m.To,
+		"Subject: " + mime.QEncoding.Encode("UTF-8", m.Content.Subject),
+		"Date: " + m.Date.Format(time.RFC1123Z),
+		"Message-ID: <" + m.ID + ">",
+	}
+	if m.Unsubscribe != "" {
+		header = append(header,
+			"List-Unsubscribe: <"+m.Unsubscribe+">",
+			"List-Unsubscribe-Post: List-Unsubscribe=One-Click",
+		)
+	}
+	header = append(header,
+		"MIME-Version: 1.0",
+		fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q", m.Boundary),
+	)
+	buf.WriteString(strings.Join(header, "\r\n"))
+	buf.WriteString("\r\n\r\n")
+
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary(m.Boundary); err != nil {
+		return nil, err
+	}
+
+	if err := writePart(mw, "text/plain; charset=UTF-8", m.Content.Text); err != nil {
+		return nil, err
+	}
+	if m.Content.HTML != "" {
+		if err := writePart(mw, "text/html; charset=UTF-8", m.Content.HTML); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writePart(mw *multipart.Writer, contentType, body string) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	qw := quotedprintable.NewWriter(pw)
+	if _, err := qw.Write([]byte(toCRLF(body))); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+func toCRLF(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func newMessage(from string, m Mail) message {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+	return message{
+		From:        from,
+		To:          m.To,
+		Content:     m.Content,
+		Unsubscribe: m.Unsubscribe,
+		Date:        time.Now(),
+		ID:          randomToken() + "@" + domain,
+		Boundary:    "gomarket-" + randomToken(),
+	}
+}
@@ -0,0 +1,138 @@
+package email
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"GoNotification/internal/templates"
+)
+
+func TestMessage_BuildMultipartAlternative(t *testing.T) {
+	m := message{
+		From: "noreply@gomarket.local",
+		To:   "user@example.com",
+		Content: templates.Content{
+			Subject: "Подтвердите вашу регистрацию",
+			Text:    "Здравствуйте!\nСсылка: http://x",
+			HTML:    "<p>Здравствуйте!</p>",
+		},
+		Date:     time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC),
+		ID:       "id@gomarket.local",
+		Boundary: "test-boundary",
+	}
+
+	raw, err := m.build()
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	rawSubject := parsed.Header.Get("Subject")
+	if !strings.HasPrefix(rawSubject, "=?UTF-8?q?") {
+		t.Errorf("Subject = %q, want RFC 2047 encoded word", rawSubject)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+	if err != nil {
+		t.Fatalf("DecodeHeader() error = %v", err)
+	}
+	if subject != m.Content.Subject {
+		t.Errorf("decoded Subject = %q, want %q", subject, m.Content.Subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("ParseMediaType() error = %v", err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+	}
+
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	wantParts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", "Здравствуйте!\r\nСсылка: http://x"},
+		{"text/html; charset=UTF-8", "<p>Здравствуйте!</p>"},
+	}
+	for i, want := range wantParts {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: NextPart() error = %v", i, err)
+		}
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("part %d Content-Type = %q, want %q", i, got, want.contentType)
+		}
+		// multipart.Reader transparently decodes quoted-printable parts.
+		body, _ := io.ReadAll(part)
+		if string(body) != want.body {
+			t.Errorf("part %d body = %q, want %q", i, body, want.body)
+		}
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("expected exactly %d parts, err = %v", len(wantParts), err)
+	}
+}
+
+func TestMessage_ASCIISubjectNotEncoded(t *testing.T) {
+	m := newMessage("noreply@gomarket.local", Mail{
+		To:      "user@example.com",
+		Content: templates.Content{Subject: "Confirm your registration", Text: "hi"},
+	})
+
+	raw, err := m.build()
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+	if !strings.Contains(string(raw), "\r\nSubject: Confirm your registration\r\n") {
+		t.Errorf("ASCII subject should be sent as-is:\n%s", raw)
+	}
+	if !strings.HasSuffix(m.ID, "@gomarket.local") {
+		t.Errorf("Message-ID = %q, want sender domain", m.ID)
+	}
+}
+
+func TestMessage_ListUnsubscribeHeaders(t *testing.T) {
+	m := newMessage("noreply@gomarket.local", Mail{
+		To:          "user@example.com",
+		Content:     templates.Content{Subject: "Deals", Text: "hi"},
+		Unsubscribe: "http://localhost:8082/unsubscribe?token=abc",
+	})
+
+	raw, err := m.build()
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	if got := parsed.Header.Get("List-Unsubscribe"); got != "<http://localhost:8082/unsubscribe?token=abc>" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if got := parsed.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+}
+
+func TestMessage_NoUnsubscribeHeadersByDefault(t *testing.T) {
+	m := newMessage("noreply@gomarket.local", Mail{To: "user@example.com", Content: templates.Content{Text: "hi"}})
+
+	raw, err := m.build()
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+	if strings.Contains(string(raw), "List-Unsubscribe") {
+		t.Errorf("unexpected List-Unsubscribe header:\n%s", raw)
+	}
+}
@@ -0,0 +1,174 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type conn struct {
+	client   *smtp.Client
+	raw      net.Conn
+	lastUsed time.Time
+}
+
+func (c *conn) close() {
+	_ = c.client.Close()
+}
+
+// pool keeps up to size idle SMTP sessions open so consecutive messages reuse
+// the same authenticated connection. Idle sessions are probed with NOOP before
+// reuse and dropped once they exceed idleTimeout.
+type pool struct {
+	opts Options
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+func newPool(opts Options) *pool {
+	return &pool{opts: opts}
+}
+
+func (p *pool) get(ctx context.Context) (*conn, error) {
+	for {
+		c := p.popIdle()
+		if c == nil {
+			break
+		}
+		if p.opts.IdleTimeout > 0 && time.Since(c.lastUsed) > p.opts.IdleTimeout {
+			c.close()
+			continue
+		}
+		_ = c.raw.SetDeadline(time.Now().Add(p.opts.DialTimeout))
+		if err := c.client.Noop(); err != nil {
+			c.close()
+			continue
+		}
+		return c, nil
+	}
+	return p.dial(ctx)
+}
+
+func (p *pool) popIdle() *conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.idle)
+	if n == 0 {
+		return nil
+	}
+	c := p.idle[n-1]
+	p.idle = p.idle[:n-1]
+	return c
+}
+
+func (p *pool) put(c *conn) {
+	if err := c.client.Reset(); err != nil {
+		c.close()
+		return
+	}
+	_ = c.raw.SetDeadline(time.Time{})
+	c.lastUsed = time.Now()
+
+	p.mu.Lock()
+	if p.closed || len(p.idle) >= p.opts.PoolSize {
+		p.mu.Unlock()
+		_ = c.client.Quit()
+		return
+	}
+	p.idle = append(p.idle, c)
+	p.mu.Unlock()
+}
+
+func (p *pool) close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, c := range idle {
+		_ = c.raw.SetDeadline(time.Now().Add(p.opts.DialTimeout))
+		_ = c.client.Quit()
+	}
+}
+
+func (p *pool) dial(ctx context.Context) (*conn, error) {
+	addr := net.JoinHostPort(p.opts.Host, strconv.Itoa(p.opts.Port))
+	dialer := &net.Dialer{Timeout: p.opts.DialTimeout, KeepAlive: 30 * time.Second}
+
+	var (
+		raw net.Conn
+		err error
+	)
+	if p.opts.TLS == TLSImplicit {
+		td := &tls.Dialer{NetDialer: dialer, Config: p.tlsConfig()}
+		raw, err = td.DialContext(ctx, "tcp", addr)
+	} else {
+		raw, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp: dial %s: %w", addr, err)
+	}
+	_ = raw.SetDeadline(time.Now().Add(p.opts.DialTimeout))
+
+	client, err := smtp.NewClient(raw, p.opts.Host)
+	if err != nil {
+		_ = raw.Close()
+		return nil, fmt.Errorf("smtp: handshake: %w", err)
+	}
+	c := &conn{client: client, raw: raw}
+
+	if p.opts.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			c.close()
+			return nil, fmt.Errorf("smtp: server %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(p.tlsConfig()); err != nil {
+			c.close()
+			return nil, fmt.Errorf("smtp: starttls: %w", err)
+		}
+	}
+
+	if auth := p.auth(); auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			c.close()
+			return nil, fmt.Errorf("smtp: server %s does not support AUTH", addr)
+		}
+		if err := client.Auth(auth); err != nil {
+			c.close()
+			return nil, fmt.Errorf("smtp: auth: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func (p *pool) tlsConfig() *tls.Config {
+	if p.opts.TLSConfig != nil {
+		return p.opts.TLSConfig
+	}
+	return &tls.Config{
+		ServerName:         p.opts.Host,
+		InsecureSkipVerify: p.opts.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+}
+
+func (p *pool) auth() smtp.Auth {
+	switch p.opts.Auth {
+	case AuthPlain:
+		return smtp.PlainAuth("", p.opts.Username, p.opts.Password, p.opts.Host)
+	case AuthLogin:
+		return LoginAuth(p.opts.Username, p.opts.Password, p.opts.Host)
+	default:
+		return nil
+	}
+}
@@ -1,42 +1,123 @@
 package email
 
 import (
-	"fmt"
-	"net/smtp"
+	"context"
+	"crypto/tls"
+	"time"
+
+	"GoNotification/internal/templates"
+)
+
+const (
+	TLSNone     = "none"
+	TLSStartTLS = "starttls"
+	TLSImplicit = "tls"
+
+	AuthNone  = "none"
+	AuthPlain = "plain"
+	AuthLogin = "login"
 )
 
+type Options struct {
+	Host string
+	Port int
+	From string
+
+	Username string
+	Password string
+	Auth     string
+	TLS      string
+
+	InsecureSkipVerify bool
+	// TLSConfig overrides the config derived from Host and
+	// InsecureSkipVerify; tests use it to trust a local certificate.
+	TLSConfig *tls.Config
+
+	PoolSize    int
+	IdleTimeout time.Duration
+	DialTimeout time.Duration
+	SendTimeout time.Duration
+}
+
+type Mail struct {
+	To      string
+	Content templates.Content
+	// Unsubscribe is a one-click unsubscribe URL (RFC 8058). When set, the
+	// message carries List-Unsubscribe and List-Unsubscribe-Post headers.
+	Unsubscribe string
+}
+
 type Sender struct {
-	host string
-	port int
-	from string
+	from        string
+	sendTimeout time.Duration
+	pool        *pool
 }
 
-func NewSender(host string, port int, from string) *Sender {
+func NewSender(opts Options) *Sender {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 1
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 10 * time.Second
+	}
+	if opts.SendTimeout <= 0 {
+		opts.SendTimeout = 30 * time.Second
+	}
+
 	return &Sender{
-		host: host,
-		port: port,
-		from: from,
+		from:        opts.From,
+		sendTimeout: opts.SendTimeout,
+		pool:        newPool(opts),
 	}
 }
 
-func (s *Sender) SendVerification(to, token, baseURL string) error {
-	link := fmt.Sprintf("%s/auth/verify?token=%s", baseURL, token)
+func (s *Sender) Send(ctx context.Context, m Mail) error {
+	msg, err := newMessage(s.from, m).build()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.sendTimeout)
+	defer cancel()
 
-	subject := "Подтвердите вашу регистрацию"
-	body := fmt.Sprintf(`Здравствуйте!
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return err
+	}
 
-Для подтверждения регистрации перейдите по ссылке:
-%s
+	deadline, _ := ctx.Deadline()
+	_ = c.raw.SetDeadline(deadline)
 
-Если вы не регистрировались, просто проигнорируйте это письмо.
+	if err := s.transact(c, m.To, msg); err != nil {
+		c.close()
+		return err
+	}
 
-С уважением,
-GoMarket Team`, link)
+	s.pool.put(c)
+	return nil
+}
 
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
-		s.from, to, subject, body)
+func (s *Sender) transact(c *conn, to string, msg []byte) error {
+	if err := c.client.Mail(s.from); err != nil {
+		return err
+	}
+	if err := c.client.Rcpt(to); err != nil {
+		return err
+	}
 
-	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
 
-	return smtp.SendMail(addr, nil, s.from, []string{to}, []byte(msg))
+// Close sends QUIT on every pooled connection.
+func (s *Sender) Close() error {
+	s.pool.close()
+	return nil
 }
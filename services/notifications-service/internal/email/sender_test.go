@@ -0,0 +1,152 @@
+package email
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"GoNotification/internal/templates"
+)
+
+var testMail = Mail{
+	To:      "user@example.com",
+	Content: templates.Content{Subject: "Hello", Text: "body", HTML: "<p>body</p>"},
+}
+
+func newTestSender(srv *fakeSMTP, opts Options) *Sender {
+	opts.Host = "127.0.0.1"
+	opts.Port = srv.port()
+	opts.From = "noreply@gomarket.local"
+	if opts.PoolSize == 0 {
+		opts.PoolSize = 2
+	}
+	opts.SendTimeout = 5 * time.Second
+	opts.DialTimeout = 5 * time.Second
+	return NewSender(opts)
+}
+
+func TestSender_PlainConnection(t *testing.T) {
+	srv, _ := newFakeSMTP(t, fakeSMTPOptions{})
+	s := newTestSender(srv, Options{TLS: TLSNone, Auth: AuthNone})
+	defer s.Close()
+
+	if err := s.Send(context.Background(), testMail); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	msgs, _ := srv.stats()
+	if len(msgs) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(msgs))
+	}
+	if !strings.Contains(msgs[0], "To: user@example.com") {
+		t.Errorf("message missing recipient header:\n%s", msgs[0])
+	}
+}
+
+func TestSender_StartTLSWithAuth(t *testing.T) {
+	for _, mech := range []string{AuthPlain, AuthLogin} {
+		t.Run(mech, func(t *testing.T) {
+			srv, clientTLS := newFakeSMTP(t, fakeSMTPOptions{startTLS: true, username: "bob", password: "secret"})
+			s := newTestSender(srv, Options{
+				TLS:       TLSStartTLS,
+				TLSConfig: clientTLS,
+				Auth:      mech,
+				Username:  "bob",
+				Password:  "secret",
+			})
+			defer s.Close()
+
+			if err := s.Send(context.Background(), testMail); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			got := srv.authMechs()
+			if len(got) != 1 || got[0] != strings.ToUpper(mech) {
+				t.Errorf("auth mechanisms = %v, want [%s]", got, strings.ToUpper(mech))
+			}
+		})
+	}
+}
+
+func TestSender_ImplicitTLS(t *testing.T) {
+	srv, clientTLS := newFakeSMTP(t, fakeSMTPOptions{implicit: true, username: "bob", password: "secret"})
+	s := newTestSender(srv, Options{
+		TLS:       TLSImplicit,
+		TLSConfig: clientTLS,
+		Auth:      AuthPlain,
+		Username:  "bob",
+		Password:  "secret",
+	})
+	defer s.Close()
+
+	if err := s.Send(context.Background(), testMail); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if msgs, _ := srv.stats(); len(msgs) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(msgs))
+	}
+}
+
+func TestSender_BadCredentials(t *testing.T) {
+	srv, clientTLS := newFakeSMTP(t, fakeSMTPOptions{startTLS: true, username: "bob", password: "secret"})
+	s := newTestSender(srv, Options{
+		TLS:       TLSStartTLS,
+		TLSConfig: clientTLS,
+		Auth:      AuthPlain,
+		Username:  "bob",
+		Password:  "wrong",
+	})
+	defer s.Close()
+
+	if err := s.Send(context.Background(), testMail); err == nil {
+		t.Fatalf("Send() error = nil, want auth failure")
+	}
+}
+
+func TestSender_StartTLSUnsupported(t *testing.T) {
+	srv, clientTLS := newFakeSMTP(t, fakeSMTPOptions{})
+	s := newTestSender(srv, Options{TLS: TLSStartTLS, TLSConfig: clientTLS})
+	defer s.Close()
+
+	if err := s.Send(context.Background(), testMail); err == nil {
+		t.Fatalf("Send() error = nil, want STARTTLS unsupported error")
+	}
+}
+
+func TestSender_ReusesPooledConnection(t *testing.T) {
+	srv, _ := newFakeSMTP(t, fakeSMTPOptions{})
+	s := newTestSender(srv, Options{TLS: TLSNone, PoolSize: 1, IdleTimeout: time.Minute})
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := s.Send(context.Background(), testMail); err != nil {
+			t.Fatalf("Send() #%d error = %v", i, err)
+		}
+	}
+
+	msgs, conns := srv.stats()
+	if len(msgs) != 3 {
+		t.Errorf("messages = %d, want 3", len(msgs))
+	}
+	if conns != 1 {
+		t.Errorf("connections = %d, want 1", conns)
+	}
+}
+
+func TestSender_IdleTimeoutRedials(t *testing.T) {
+	srv, _ := newFakeSMTP(t, fakeSMTPOptions{})
+	s := newTestSender(srv, Options{TLS: TLSNone, PoolSize: 1, IdleTimeout: time.Millisecond})
+	defer s.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := s.Send(context.Background(), testMail); err != nil {
+			t.Fatalf("Send() #%d error = %v", i, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, conns := srv.stats(); conns != 2 {
+		t.Errorf("connections = %d, want 2", conns)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"GoNotification/internal/domain"
+	"GoNotification/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DigestHandler struct {
+	svc *service.DigestService
+}
+
+func NewDigestHandler(svc *service.DigestService) *DigestHandler {
+	return &DigestHandler{svc: svc}
+}
+
+type quietHoursDTO struct {
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+}
+
+type digestSettingsReq struct {
+	Frequency  string         `json:"frequency" binding:"required,oneof=immediate hourly daily"`
+	Timezone   string         `json:"timezone" binding:"required"`
+	QuietHours *quietHoursDTO `json:"quiet_hours"`
+}
+
+type digestSettingsResp struct {
+	Frequency    string         `json:"frequency"`
+	Timezone     string         `json:"timezone"`
+	QuietHours   *quietHoursDTO `json:"quiet_hours"`
+	LastDigestAt *time.Time     `json:"last_digest_at,omitempty"`
+}
+
+func (h *DigestHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.svc.Settings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toDigestSettingsResp(settings))
+}
+
+func (h *DigestHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req digestSettingsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	in := service.DigestSettingsInput{
+		Frequency: domain.DigestFrequency(req.Frequency),
+		Timezone:  req.Timezone,
+	}
+	if req.QuietHours != nil {
+		start, err := domain.ParseClock(req.QuietHours.Start)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		end, err := domain.ParseClock(req.QuietHours.End)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		in.QuietStart, in.QuietEnd = start, end
+	}
+
+	settings, err := h.svc.UpdateSettings(userID, in)
+	if err != nil {
+		switch {
+		case service.IsUnknownFrequency(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown frequency"})
+		case service.IsUnknownTimezone(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown timezone"})
+		case service.IsInvalidQuietHours(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quiet hours"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toDigestSettingsResp(settings))
+}
+
+func toDigestSettingsResp(s domain.DigestSettings) digestSettingsResp {
+	resp := digestSettingsResp{
+		Frequency:    string(s.Frequency),
+		Timezone:     s.Timezone,
+		LastDigestAt: s.LastDigestAt,
+	}
+	if s.HasQuietHours() {
+		resp.QuietHours = &quietHoursDTO{
+			Start: domain.FormatClock(s.QuietStart),
+			End:   domain.FormatClock(s.QuietEnd),
+		}
+	}
+	return resp
+}
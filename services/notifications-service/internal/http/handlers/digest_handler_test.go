@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/http/handlers"
+	"GoNotification/internal/http/middleware"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+	"GoNotification/internal/templates"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDigestServer(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.DigestSettings{}, &domain.DigestItem{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	renderer, err := templates.New()
+	if err != nil {
+		t.Fatalf("templates.New() error = %v", err)
+	}
+
+	svc := service.NewDigestService(repo.NewDigests(db), renderer, channel.NewFake("smtp"), 9*60)
+	h := handlers.NewDigestHandler(svc)
+
+	r := gin.New()
+	g := r.Group("/preferences/digest", func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, uint(1))
+		c.Next()
+	})
+	{
+		g.GET("", h.Get)
+		g.PUT("", h.Update)
+	}
+	return r
+}
+
+type digestBody struct {
+	Frequency  string `json:"frequency"`
+	Timezone   string `json:"timezone"`
+	QuietHours *struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"quiet_hours"`
+}
+
+func putDigest(t *testing.T, r *gin.Engine, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/preferences/digest", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDigestHandler_DefaultsAndUpdate(t *testing.T) {
+	r := setupDigestServer(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/preferences/digest", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var got digestBody
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Frequency != "immediate" || got.Timezone != "UTC" || got.QuietHours != nil {
+		t.Errorf("defaults = %+v", got)
+	}
+
+	w = putDigest(t, r, `{"frequency":"daily","timezone":"Asia/Almaty","quiet_hours":{"start":"22:00","end":"07:30"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body = %s", w.Code, w.Body.String())
+	}
+	got = digestBody{}
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Frequency != "daily" || got.Timezone != "Asia/Almaty" || got.QuietHours == nil ||
+		got.QuietHours.Start != "22:00" || got.QuietHours.End != "07:30" {
+		t.Errorf("updated = %+v", got)
+	}
+}
+
+func TestDigestHandler_Validation(t *testing.T) {
+	r := setupDigestServer(t)
+
+	for _, body := range []string{
+		`{"frequency":"weekly","timezone":"UTC"}`,
+		`{"frequency":"daily","timezone":"Nowhere/City"}`,
+		`{"frequency":"daily","timezone":"UTC","quiet_hours":{"start":"25:00","end":"07:00"}}`,
+	} {
+		if w := putDigest(t, r, body); w.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s status = %d, want 400", body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"GoNotification/internal/domain"
+	"GoNotification/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InboxHandler struct {
+	svc *service.InboxService
+}
+
+func NewInboxHandler(svc *service.InboxService) *InboxHandler {
+	return &InboxHandler{svc: svc}
+}
+
+type listInboxQuery struct {
+	Unread bool `form:"unread"`
+	Before uint `form:"before"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type notificationResp struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Category  string     `json:"category"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (h *InboxHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var q listInboxQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.svc.List(service.ListInboxInput{
+		UserID:     userID,
+		UnreadOnly: q.Unread,
+		BeforeID:   q.Before,
+		Limit:      q.Limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	resp := make([]notificationResp, 0, len(list))
+	for _, n := range list {
+		resp = append(resp, toNotificationResp(n))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *InboxHandler) MarkRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.svc.MarkRead(userID, uint(id)); err != nil {
+		if service.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *InboxHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.svc.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+func (h *InboxHandler) UnreadCount(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	n, err := h.svc.UnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": n})
+}
+
+func toNotificationResp(n domain.Notification) notificationResp {
+	return notificationResp{
+		ID:        n.ID,
+		Type:      n.Type,
+		Category:  string(n.Category),
+		Title:     n.Title,
+		Body:      n.Body,
+		Read:      n.ReadAt != nil,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/http/handlers"
+	"GoNotification/internal/http/middleware"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+	"GoNotification/internal/templates"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInboxServer(t *testing.T) (*gin.Engine, *service.InboxService) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Notification{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+
+	svc := service.NewInboxService(repo.NewNotifications(db), nil)
+	h := handlers.NewInboxHandler(svc)
+
+	r := gin.New()
+
+	// middleware-заглушка: пользователь с id = 1
+	authBypass := func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, uint(1))
+		c.Next()
+	}
+
+	g := r.Group("/notifications", authBypass)
+	{
+		g.GET("", h.List)
+		g.GET("/unread-count", h.UnreadCount)
+		g.POST("/read-all", h.MarkAllRead)
+		g.POST("/:id/read", h.MarkRead)
+	}
+	return r, svc
+}
+
+func deliver(t *testing.T, svc *service.InboxService, userID uint, title string) uint {
+	t.Helper()
+
+	n, err := svc.Deliver(context.Background(), channel.Notification{
+		Type:     "order.paid",
+		Category: "orders",
+		UserID:   userID,
+		Content:  templates.Content{Subject: title, Text: title},
+	})
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	return n.ID
+}
+
+func unreadCount(t *testing.T, r *gin.Engine) int {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/notifications/unread-count", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /notifications/unread-count status = %d", w.Code)
+	}
+
+	var resp struct {
+		Unread int `json:"unread"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.Unread
+}
+
+func TestInboxHandler_ListAndMarkRead(t *testing.T) {
+	r, svc := setupInboxServer(t)
+
+	first := deliver(t, svc, 1, "first")
+	deliver(t, svc, 1, "second")
+	deliver(t, svc, 2, "someone else")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/notifications", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /notifications status = %d; body = %s", w.Code, w.Body.String())
+	}
+
+	var list []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("len(notifications) = %d, want 2", len(list))
+	}
+	if list[0]["title"] != "second" {
+		t.Errorf("first entry = %v, want newest first", list[0]["title"])
+	}
+
+	if got := unreadCount(t, r); got != 2 {
+		t.Fatalf("unread = %d, want 2", got)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/notifications/"+strconv.Itoa(int(first))+"/read", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("POST /notifications/:id/read status = %d; body = %s", w.Code, w.Body.String())
+	}
+
+	if got := unreadCount(t, r); got != 1 {
+		t.Errorf("unread after mark-read = %d, want 1", got)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/notifications/read-all", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /notifications/read-all status = %d", w.Code)
+	}
+	if got := unreadCount(t, r); got != 0 {
+		t.Errorf("unread after read-all = %d, want 0", got)
+	}
+}
+
+func TestInboxHandler_MarkReadForeign(t *testing.T) {
+	r, svc := setupInboxServer(t)
+
+	foreign := deliver(t, svc, 2, "not yours")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/notifications/"+strconv.Itoa(int(foreign))+"/read", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestInboxHandler_List_BadLimit(t *testing.T) {
+	r, _ := setupInboxServer(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/notifications?limit=1000", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
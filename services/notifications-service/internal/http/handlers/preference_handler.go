@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"GoNotification/internal/domain"
+	"GoNotification/internal/http/middleware"
+	"GoNotification/internal/service"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PreferenceHandler struct {
+	svc *service.PreferenceService
+}
+
+func NewPreferenceHandler(svc *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{svc: svc}
+}
+
+type preferenceResp struct {
+	Category  string          `json:"category"`
+	Mandatory bool            `json:"mandatory"`
+	Channels  map[string]bool `json:"channels"`
+}
+
+type preferenceItemReq struct {
+	Category string `json:"category" binding:"required"`
+	Channel  string `json:"channel" binding:"required"`
+	Enabled  *bool  `json:"enabled" binding:"required"`
+}
+
+type updatePreferencesReq struct {
+	Preferences []preferenceItemReq `json:"preferences" binding:"required,min=1,dive"`
+}
+
+func (h *PreferenceHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	views, err := h.svc.Get(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toPreferenceResp(views))
+}
+
+func (h *PreferenceHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req updatePreferencesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := make([]service.PreferenceUpdate, 0, len(req.Preferences))
+	for _, p := range req.Preferences {
+		updates = append(updates, service.PreferenceUpdate{
+			Category: domain.Category(p.Category),
+			Channel:  p.Channel,
+			Enabled:  *p.Enabled,
+		})
+	}
+
+	views, err := h.svc.Update(userID, updates)
+	if err != nil {
+		switch {
+		case service.IsUnknownCategory(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown category"})
+		case service.IsUnknownChannel(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown channel"})
+		case service.IsMandatory(err):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "category cannot be disabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toPreferenceResp(views))
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<form method="post" action="/unsubscribe?token={{.}}">
+<p>Stop receiving these emails from GoMarket?</p>
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+// UnsubscribePage renders a confirmation form. Unsubscribing happens only on
+// POST so that link scanners following the URL do not opt users out.
+func (h *PreferenceHandler) UnsubscribePage(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token required"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	_ = unsubscribePage.Execute(c.Writer, token)
+}
+
+// Unsubscribe handles both the confirmation form and RFC 8058 one-click
+// requests sent by mail clients.
+func (h *PreferenceHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token required"})
+		return
+	}
+
+	claims, err := h.svc.Unsubscribe(token)
+	if err != nil {
+		switch {
+		case service.IsInvalidToken(err), service.IsUnknownCategory(err), service.IsUnknownChannel(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unsubscribe link"})
+		case service.IsMandatory(err):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "category cannot be disabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "unsubscribed",
+		"category": claims.Category,
+		"channel":  claims.Channel,
+	})
+}
+
+func toPreferenceResp(views []service.PreferenceView) []preferenceResp {
+	resp := make([]preferenceResp, 0, len(views))
+	for _, v := range views {
+		resp = append(resp, preferenceResp{
+			Category:  string(v.Category),
+			Mandatory: v.Mandatory,
+			Channels:  v.Channels,
+		})
+	}
+	return resp
+}
+
+func currentUserID(c *gin.Context) (uint, bool) {
+	userIDRaw, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return 0, false
+	}
+
+	userID, ok := userIDRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id type"})
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,156 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"GoNotification/internal/domain"
+	"GoNotification/internal/http/handlers"
+	"GoNotification/internal/http/middleware"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+	"GoNotification/pkg/unsubscribe"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPreferenceServer(t *testing.T) (*gin.Engine, *unsubscribe.Signer) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Preference{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+
+	signer := unsubscribe.NewSigner("test-secret", "http://localhost:8082")
+	svc := service.NewPreferenceService(repo.NewPreferences(db), signer)
+	h := handlers.NewPreferenceHandler(svc)
+
+	r := gin.New()
+
+	authBypass := func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, uint(1))
+		c.Next()
+	}
+
+	r.GET("/unsubscribe", h.UnsubscribePage)
+	r.POST("/unsubscribe", h.Unsubscribe)
+	g := r.Group("/preferences", authBypass)
+	{
+		g.GET("", h.Get)
+		g.PUT("", h.Update)
+	}
+	return r, signer
+}
+
+type preferenceBody struct {
+	Category  string          `json:"category"`
+	Mandatory bool            `json:"mandatory"`
+	Channels  map[string]bool `json:"channels"`
+}
+
+func getPreferences(t *testing.T, r *gin.Engine) map[string]preferenceBody {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/preferences", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /preferences status = %d; body = %s", w.Code, w.Body.String())
+	}
+
+	var list []preferenceBody
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out := make(map[string]preferenceBody, len(list))
+	for _, p := range list {
+		out[p.Category] = p
+	}
+	return out
+}
+
+func TestPreferenceHandler_UpdateAndGet(t *testing.T) {
+	r, _ := setupPreferenceServer(t)
+
+	body := `{"preferences":[{"category":"marketing","channel":"smtp","enabled":false}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/preferences", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT /preferences status = %d; body = %s", w.Code, w.Body.String())
+	}
+
+	prefs := getPreferences(t, r)
+	if prefs["marketing"].Channels["smtp"] {
+		t.Errorf("marketing/smtp still enabled")
+	}
+	if !prefs["account"].Mandatory {
+		t.Errorf("account should be reported as mandatory")
+	}
+}
+
+func TestPreferenceHandler_UpdateMandatory(t *testing.T) {
+	r, _ := setupPreferenceServer(t)
+
+	body := `{"preferences":[{"category":"account","channel":"smtp","enabled":false}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/preferences", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusUnprocessableEntity, w.Body.String())
+	}
+}
+
+func TestPreferenceHandler_OneClickUnsubscribe(t *testing.T) {
+	r, signer := setupPreferenceServer(t)
+
+	token := url.QueryEscape(signer.Token(1, "orders", "smtp"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/unsubscribe?token="+token, nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /unsubscribe status = %d", w.Code)
+	}
+	if !getPreferences(t, r)["orders"].Channels["smtp"] {
+		t.Fatalf("GET must not unsubscribe")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/unsubscribe?token="+token, bytes.NewBufferString("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /unsubscribe status = %d; body = %s", w.Code, w.Body.String())
+	}
+	if getPreferences(t, r)["orders"].Channels["smtp"] {
+		t.Errorf("orders/smtp still enabled after unsubscribe")
+	}
+}
+
+func TestPreferenceHandler_UnsubscribeBadToken(t *testing.T) {
+	r, _ := setupPreferenceServer(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/unsubscribe?token=bad", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"GoNotification/internal/domain"
+	"GoNotification/internal/realtime"
+	"GoNotification/internal/service"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StreamHandler struct {
+	inbox     *service.InboxService
+	hub       *realtime.Hub
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(inbox *service.InboxService, hub *realtime.Hub, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{inbox: inbox, hub: hub, heartbeat: heartbeat}
+}
+
+// Stream pushes new inbox entries as Server-Sent Events. Clients resuming
+// with Last-Event-ID first receive everything they missed.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	lastID, err := lastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+		return
+	}
+
+	// Subscribe before replaying so nothing published in between is lost;
+	// duplicates are filtered by id below.
+	sub := h.hub.Subscribe(userID)
+	if sub == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "shutting down"})
+		return
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	var missed []domain.Notification
+	if lastID > 0 {
+		missed, err = h.inbox.Since(userID, lastID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+			return
+		}
+	}
+
+	// Streams outlive the server-wide write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	_, _ = io.WriteString(w, "retry: 3000\n\n")
+	for _, n := range missed {
+		if err := writeEvent(w, n); err != nil {
+			return
+		}
+		lastID = n.ID
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case n, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if n.ID <= lastID {
+				continue
+			}
+			if err := writeEvent(w, n); err != nil {
+				return
+			}
+			lastID = n.ID
+			w.Flush()
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, n domain.Notification) error {
+	data, err := json.Marshal(toNotificationResp(n))
+	if err != nil {
+		log.Printf("stream: marshal notification %d: %v", n.ID, err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: notification\ndata: %s\n\n", n.ID, data)
+	return err
+}
+
+func lastEventID(c *gin.Context) (uint, error) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,224 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"GoNotification/internal/domain"
+	"GoNotification/internal/http/handlers"
+	"GoNotification/internal/http/middleware"
+	"GoNotification/internal/realtime"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupStreamServer(t *testing.T, heartbeat time.Duration) (*httptest.Server, *service.InboxService, *realtime.Hub) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared&_busy_timeout=5000"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Notification{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM notifications") })
+
+	hub := realtime.NewHub()
+	svc := service.NewInboxService(repo.NewNotifications(db), hub)
+	h := handlers.NewStreamHandler(svc, hub, heartbeat)
+
+	r := gin.New()
+	r.GET("/notifications/stream", func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, uint(1))
+		c.Next()
+	}, h.Stream)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, svc, hub
+}
+
+type sseEvent struct {
+	id   string
+	data string
+}
+
+// readEvents parses the stream in the background, skipping comments.
+func readEvents(t *testing.T, resp *http.Response) <-chan sseEvent {
+	t.Helper()
+
+	out := make(chan sseEvent, 16)
+	go func() {
+		defer close(out)
+		sc := bufio.NewScanner(resp.Body)
+		var ev sseEvent
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case line == "":
+				if ev.id != "" {
+					out <- ev
+				}
+				ev = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				ev.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return out
+}
+
+func openStream(t *testing.T, url, lastEventID string) *http.Response {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, url+"/notifications/stream", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /notifications/stream: %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	return resp
+}
+
+func waitEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatalf("stream closed before event")
+		}
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for event")
+	}
+	return sseEvent{}
+}
+
+func waitSubscribed(t *testing.T, hub *realtime.Hub, userID uint) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Subscribers(userID) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stream never subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamHandler_PushesLiveNotifications(t *testing.T) {
+	srv, svc, hub := setupStreamServer(t, time.Minute)
+
+	events := readEvents(t, openStream(t, srv.URL, ""))
+	waitSubscribed(t, hub, 1)
+
+	id := deliver(t, svc, 1, "your product was purchased")
+	deliver(t, svc, 2, "not for this stream")
+
+	ev := waitEvent(t, events)
+	if ev.id != strconv.Itoa(int(id)) {
+		t.Errorf("event id = %q, want %d", ev.id, id)
+	}
+	if !strings.Contains(ev.data, "your product was purchased") {
+		t.Errorf("event data = %s", ev.data)
+	}
+}
+
+func TestStreamHandler_ResumesFromLastEventID(t *testing.T) {
+	srv, svc, _ := setupStreamServer(t, time.Minute)
+
+	first := deliver(t, svc, 1, "seen")
+	second := deliver(t, svc, 1, "missed one")
+	third := deliver(t, svc, 1, "missed two")
+
+	events := readEvents(t, openStream(t, srv.URL, strconv.Itoa(int(first))))
+
+	for _, want := range []uint{second, third} {
+		if ev := waitEvent(t, events); ev.id != strconv.Itoa(int(want)) {
+			t.Errorf("replayed id = %q, want %d", ev.id, want)
+		}
+	}
+}
+
+func TestStreamHandler_ClosesOnShutdown(t *testing.T) {
+	srv, _, hub := setupStreamServer(t, time.Minute)
+
+	events := readEvents(t, openStream(t, srv.URL, ""))
+	waitSubscribed(t, hub, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go hub.Run(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatalf("unexpected event after shutdown")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("stream not closed after hub shutdown")
+	}
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	srv, _, _ := setupStreamServer(t, 10*time.Millisecond)
+
+	resp := openStream(t, srv.URL, "")
+	sc := bufio.NewScanner(resp.Body)
+
+	deadline := time.After(2 * time.Second)
+	got := make(chan struct{})
+	go func() {
+		for sc.Scan() {
+			if sc.Text() == ": ping" {
+				close(got)
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-got:
+	case <-deadline:
+		t.Fatalf("no heartbeat received")
+	}
+}
+
+func TestStreamHandler_BadLastEventID(t *testing.T) {
+	srv, _, _ := setupStreamServer(t, time.Minute)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/notifications/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	jwtutil "GoNotification/pkg/jwt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const UserIDKey = "user_id"
+
+func AuthRequired(verifier *jwtutil.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+			return
+		}
+
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format"})
+			return
+		}
+
+		claims, err := verifier.Verify(parts[1])
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+
+		c.Set(UserIDKey, claims.UserID)
+		c.Next()
+	}
+}
+
+// TokenFromQuery lets clients that cannot set headers, such as the browser
+// EventSource API, pass the access token as a query parameter.
+func TokenFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if tok := c.Query(param); tok != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+tok)
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,114 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+
+	"GoNotification/internal/domain"
+)
+
+const subscriberBuffer = 16
+
+type Subscriber struct {
+	userID uint
+	ch     chan domain.Notification
+}
+
+// C delivers notifications for the subscriber's user. It is closed when the
+// hub shuts down or the subscriber falls too far behind; clients are
+// expected to reconnect with Last-Event-ID in the latter case.
+func (s *Subscriber) C() <-chan domain.Notification { return s.ch }
+
+// Hub fans in-app notifications out to every open stream of a user.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[uint]map[*Subscriber]struct{}
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[uint]map[*Subscriber]struct{})}
+}
+
+// Subscribe returns nil once the hub is closed.
+func (h *Hub) Subscribe(userID uint) *Subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+
+	s := &Subscriber{userID: userID, ch: make(chan domain.Notification, subscriberBuffer)}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscriber]struct{})
+	}
+	h.subs[userID][s] = struct{}{}
+	return s
+}
+
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(s)
+}
+
+// Publish never blocks: a subscriber whose buffer is full is dropped.
+func (h *Hub) Publish(userID uint, n domain.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs[userID] {
+		select {
+		case s.ch <- n:
+		default:
+			h.remove(s)
+		}
+	}
+}
+
+func (h *Hub) Subscribers(userID uint) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subs[userID])
+}
+
+// Run closes every subscription once ctx is cancelled so open streams end
+// and the HTTP server can shut down.
+func (h *Hub) Run(ctx context.Context) {
+	<-ctx.Done()
+	h.Close()
+}
+
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, byUser := range h.subs {
+		for s := range byUser {
+			h.remove(s)
+		}
+	}
+}
+
+// remove must be called with h.mu held.
+func (h *Hub) remove(s *Subscriber) {
+	byUser, ok := h.subs[s.userID]
+	if !ok {
+		return
+	}
+	if _, ok := byUser[s]; !ok {
+		return
+	}
+	delete(byUser, s)
+	if len(byUser) == 0 {
+		delete(h.subs, s.userID)
+	}
+	close(s.ch)
+}
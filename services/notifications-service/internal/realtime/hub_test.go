@@ -0,0 +1,97 @@
+package realtime_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"GoNotification/internal/domain"
+	"GoNotification/internal/realtime"
+)
+
+func TestHub_PublishFansOutToUser(t *testing.T) {
+	h := realtime.NewHub()
+
+	a := h.Subscribe(1)
+	b := h.Subscribe(1)
+	other := h.Subscribe(2)
+
+	h.Publish(1, domain.Notification{ID: 10, UserID: 1})
+
+	for _, s := range []*realtime.Subscriber{a, b} {
+		select {
+		case n := <-s.C():
+			if n.ID != 10 {
+				t.Errorf("got id %d, want 10", n.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("subscriber did not receive notification")
+		}
+	}
+
+	select {
+	case n := <-other.C():
+		t.Errorf("user 2 received notification %d meant for user 1", n.ID)
+	default:
+	}
+}
+
+func TestHub_UnsubscribeClosesChannel(t *testing.T) {
+	h := realtime.NewHub()
+
+	s := h.Subscribe(1)
+	h.Unsubscribe(s)
+	h.Unsubscribe(s)
+
+	if _, ok := <-s.C(); ok {
+		t.Errorf("channel still open after Unsubscribe")
+	}
+	if n := h.Subscribers(1); n != 0 {
+		t.Errorf("Subscribers() = %d, want 0", n)
+	}
+}
+
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	h := realtime.NewHub()
+	s := h.Subscribe(1)
+
+	for i := 0; i < 100; i++ {
+		h.Publish(1, domain.Notification{ID: uint(i + 1), UserID: 1})
+	}
+
+	if n := h.Subscribers(1); n != 0 {
+		t.Errorf("Subscribers() = %d, want slow subscriber dropped", n)
+	}
+	count := 0
+	for range s.C() {
+		count++
+	}
+	if count == 0 || count >= 100 {
+		t.Errorf("buffered %d notifications before drop", count)
+	}
+}
+
+func TestHub_RunClosesOnCancel(t *testing.T) {
+	h := realtime.NewHub()
+	s := h.Subscribe(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Run() did not return after cancel")
+	}
+	if _, ok := <-s.C(); ok {
+		t.Errorf("subscription still open after shutdown")
+	}
+	if h.Subscribe(1) != nil {
+		t.Errorf("Subscribe() after shutdown should return nil")
+	}
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"GoNotification/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Digests struct {
+	db *gorm.DB
+}
+
+func NewDigests(db *gorm.DB) *Digests {
+	return &Digests{db: db}
+}
+
+func (r *Digests) GetSettings(userID uint) (*domain.DigestSettings, error) {
+	var s domain.DigestSettings
+	if err := r.db.Where("user_id = ?", userID).First(&s).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SaveSettings upserts the user's settings without touching LastDigestAt,
+// which is owned by the scheduler.
+func (r *Digests) SaveSettings(s *domain.DigestSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"frequency", "timezone", "quiet_start", "quiet_end", "updated_at"}),
+	}).Omit("last_digest_at").Create(s).Error
+}
+
+func (r *Digests) Enqueue(item *domain.DigestItem) error {
+	return r.db.Create(item).Error
+}
+
+// PendingUsers returns the ids of users with at least one queued item.
+func (r *Digests) PendingUsers() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.DigestItem{}).
+		Distinct("user_id").
+		Order("user_id").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *Digests) Pending(userID uint) ([]domain.DigestItem, error) {
+	var items []domain.DigestItem
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Complete removes the items included in a sent digest and records the send
+// time in one transaction. Items queued after upToID stay pending.
+func (r *Digests) Complete(userID uint, upToID uint, sentAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND id <= ?", userID, upToID).
+			Delete(&domain.DigestItem{}).Error; err != nil {
+			return err
+		}
+
+		s := domain.DefaultDigestSettings(userID)
+		s.LastDigestAt = &sentAt
+		s.UpdatedAt = sentAt
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_digest_at"}),
+		}).Create(&s).Error
+	})
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"GoNotification/internal/domain"
+)
+
+func TestDigests_SettingsRoundTrip(t *testing.T) {
+	r := NewDigests(newTestDB(t))
+
+	s := &domain.DigestSettings{UserID: 1, Frequency: domain.DigestDaily, Timezone: "Asia/Almaty", QuietStart: 1320, QuietEnd: 420}
+	if err := r.SaveSettings(s); err != nil {
+		t.Fatalf("SaveSettings() error = %v", err)
+	}
+	if err := r.Complete(1, 0, time.Now()); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	s.Frequency = domain.DigestHourly
+	if err := r.SaveSettings(s); err != nil {
+		t.Fatalf("SaveSettings() error = %v", err)
+	}
+
+	got, err := r.GetSettings(1)
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if got.Frequency != domain.DigestHourly || got.QuietStart != 1320 || got.Timezone != "Asia/Almaty" {
+		t.Errorf("GetSettings() = %+v", got)
+	}
+	if got.LastDigestAt == nil {
+		t.Errorf("SaveSettings() cleared LastDigestAt")
+	}
+}
+
+func TestDigests_PendingAndComplete(t *testing.T) {
+	r := NewDigests(newTestDB(t))
+
+	for _, userID := range []uint{2, 1, 2} {
+		item := &domain.DigestItem{UserID: userID, Email: "u@example.com", Locale: "en", Type: "t", Category: domain.CategoryProducts, Title: "n"}
+		if err := r.Enqueue(item); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	users, err := r.PendingUsers()
+	if err != nil {
+		t.Fatalf("PendingUsers() error = %v", err)
+	}
+	if len(users) != 2 || users[0] != 1 || users[1] != 2 {
+		t.Fatalf("PendingUsers() = %v, want [1 2]", users)
+	}
+
+	items, _ := r.Pending(2)
+	if len(items) != 2 {
+		t.Fatalf("len(Pending(2)) = %d, want 2", len(items))
+	}
+
+	// Only items up to the first one are completed; the later one stays queued.
+	if err := r.Complete(2, items[0].ID, time.Now()); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if left, _ := r.Pending(2); len(left) != 1 || left[0].ID != items[1].ID {
+		t.Errorf("Pending(2) after Complete = %+v, want only item %d", left, items[1].ID)
+	}
+
+	s, err := r.GetSettings(2)
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if s.LastDigestAt == nil || s.Frequency != domain.DigestImmediate {
+		t.Errorf("Complete() settings = %+v, want defaults with LastDigestAt", s)
+	}
+}
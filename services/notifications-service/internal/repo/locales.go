@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"GoNotification/internal/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Locales struct {
+	db *gorm.DB
+}
+
+func NewLocales(db *gorm.DB) *Locales {
+	return &Locales{db: db}
+}
+
+// Save records the user's locale, replacing an earlier one.
+func (r *Locales) Save(userID uint, locale string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"locale", "updated_at"}),
+	}).Create(&domain.UserLocale{UserID: userID, Locale: locale, UpdatedAt: time.Now()}).Error
+}
+
+// Get returns the user's locale, or "" if none was recorded.
+func (r *Locales) Get(userID uint) (string, error) {
+	var l domain.UserLocale
+	if err := r.db.First(&l, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return l.Locale, nil
+}
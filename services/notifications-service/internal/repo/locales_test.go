@@ -0,0 +1,19 @@
+package repo
+
+import "testing"
+
+func TestLocales_SaveAndGet(t *testing.T) {
+	r := NewLocales(newTestDB(t))
+
+	if l, err := r.Get(1); err != nil || l != "" {
+		t.Fatalf("Get() of unknown user = %q, %v; want empty", l, err)
+	}
+
+	_ = r.Save(1, "en")
+	if err := r.Save(1, "kk"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if l, err := r.Get(1); err != nil || l != "kk" {
+		t.Errorf("Get() = %q, %v; want kk", l, err)
+	}
+}
@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"GoNotification/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Notifications struct {
+	db *gorm.DB
+}
+
+func NewNotifications(db *gorm.DB) *Notifications {
+	return &Notifications{db: db}
+}
+
+type NotificationFilter struct {
+	UnreadOnly bool
+	// BeforeID returns entries older than the given id (keyset pagination).
+	BeforeID uint
+	Limit    int
+}
+
+func (r *Notifications) Create(n *domain.Notification) error {
+	return r.db.Create(n).Error
+}
+
+func (r *Notifications) ListByUser(userID uint, f NotificationFilter) ([]domain.Notification, error) {
+	q := r.db.Where("user_id = ?", userID)
+	if f.UnreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if f.BeforeID > 0 {
+		q = q.Where("id < ?", f.BeforeID)
+	}
+
+	var list []domain.Notification
+	if err := q.Order("id DESC").Limit(f.Limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Notifications) ListAfter(userID, afterID uint, limit int) ([]domain.Notification, error) {
+	var list []domain.Notification
+	err := r.db.
+		Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// MarkRead returns gorm.ErrRecordNotFound when the notification does not
+// exist or belongs to another user.
+func (r *Notifications) MarkRead(userID, id uint, at time.Time) error {
+	res := r.db.Model(&domain.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", at))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Notifications) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	res := r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return res.RowsAffected, res.Error
+}
+
+func (r *Notifications) UnreadCount(userID uint) (int64, error) {
+	var n int64
+	err := r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
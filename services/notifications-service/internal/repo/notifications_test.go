@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"GoNotification/internal/domain"
+)
+
+func TestNotifications_ListAndUnread(t *testing.T) {
+	r := NewNotifications(newTestDB(t))
+
+	for i := 0; i < 3; i++ {
+		if err := r.Create(&domain.Notification{UserID: 1, Type: "t", Category: domain.CategoryOrders, Title: "n"}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	_ = r.Create(&domain.Notification{UserID: 2, Type: "t", Category: domain.CategoryOrders, Title: "other"})
+
+	list, err := r.ListByUser(1, NotificationFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("len(ListByUser()) = %d, want 3", len(list))
+	}
+	if list[0].ID < list[1].ID {
+		t.Errorf("list is not newest first: %d before %d", list[0].ID, list[1].ID)
+	}
+
+	page, _ := r.ListByUser(1, NotificationFilter{BeforeID: list[0].ID, Limit: 10})
+	if len(page) != 2 {
+		t.Errorf("len(page before %d) = %d, want 2", list[0].ID, len(page))
+	}
+
+	if err := r.MarkRead(1, list[0].ID, time.Now()); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+	if n, _ := r.UnreadCount(1); n != 2 {
+		t.Errorf("UnreadCount() = %d, want 2", n)
+	}
+
+	unread, _ := r.ListByUser(1, NotificationFilter{UnreadOnly: true, Limit: 10})
+	if len(unread) != 2 {
+		t.Errorf("len(unread) = %d, want 2", len(unread))
+	}
+
+	if updated, _ := r.MarkAllRead(1, time.Now()); updated != 2 {
+		t.Errorf("MarkAllRead() = %d, want 2", updated)
+	}
+	if n, _ := r.UnreadCount(2); n != 1 {
+		t.Errorf("UnreadCount(other user) = %d, want 1", n)
+	}
+}
+
+func TestNotifications_MarkReadOtherUser(t *testing.T) {
+	r := NewNotifications(newTestDB(t))
+
+	n := &domain.Notification{UserID: 1, Type: "t", Category: domain.CategoryOrders, Title: "n"}
+	_ = r.Create(n)
+
+	if err := r.MarkRead(2, n.ID, time.Now()); err == nil {
+		t.Fatalf("MarkRead() by another user error = nil, want not found")
+	}
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"GoNotification/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Preferences struct {
+	db *gorm.DB
+}
+
+func NewPreferences(db *gorm.DB) *Preferences {
+	return &Preferences{db: db}
+}
+
+func (r *Preferences) ListByUser(userID uint) ([]domain.Preference, error) {
+	var prefs []domain.Preference
+	if err := r.db.Where("user_id = ?", userID).Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (r *Preferences) Get(userID uint, category domain.Category, channel string) (*domain.Preference, error) {
+	var p domain.Preference
+	err := r.db.
+		Where("user_id = ? AND category = ? AND channel = ?", userID, category, channel).
+		First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *Preferences) Upsert(prefs ...domain.Preference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&prefs).Error
+}
@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"testing"
+
+	"GoNotification/internal/domain"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Preference{}, &domain.Notification{}, &domain.DigestSettings{}, &domain.DigestItem{}, &domain.UserLocale{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	return db
+}
+
+func TestPreferences_UpsertAndGet(t *testing.T) {
+	r := NewPreferences(newTestDB(t))
+
+	if err := r.Upsert(domain.Preference{UserID: 1, Category: domain.CategoryMarketing, Channel: "smtp", Enabled: false}); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if err := r.Upsert(domain.Preference{UserID: 1, Category: domain.CategoryMarketing, Channel: "smtp", Enabled: true}); err != nil {
+		t.Fatalf("Upsert() second error = %v", err)
+	}
+
+	p, err := r.Get(1, domain.CategoryMarketing, "smtp")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !p.Enabled {
+		t.Errorf("Enabled = false, want true after second upsert")
+	}
+
+	list, err := r.ListByUser(1)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("len(ListByUser()) = %d, want 1", len(list))
+	}
+}
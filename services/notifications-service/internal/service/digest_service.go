@@ -0,0 +1,216 @@
+package service
+
+import (
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/templates"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DigestType is the notification type of digest emails.
+const DigestType = "digest"
+
+var (
+	errUnknownFrequency  = errors.New("unknown_frequency")
+	errUnknownTimezone   = errors.New("unknown_timezone")
+	errInvalidQuietHours = errors.New("invalid_quiet_hours")
+)
+
+func IsUnknownFrequency(err error) bool  { return errors.Is(err, errUnknownFrequency) }
+func IsUnknownTimezone(err error) bool   { return errors.Is(err, errUnknownTimezone) }
+func IsInvalidQuietHours(err error) bool { return errors.Is(err, errInvalidQuietHours) }
+
+type DigestSettingsInput struct {
+	Frequency domain.DigestFrequency
+	Timezone  string
+	// QuietStart and QuietEnd are minutes since local midnight; equal values
+	// disable quiet hours.
+	QuietStart int
+	QuietEnd   int
+}
+
+type DigestService struct {
+	digests  *repo.Digests
+	renderer *templates.Renderer
+	sender   channel.Channel
+	dailyAt  int
+}
+
+// NewDigestService sends digests through sender, which must be the raw email
+// channel rather than one wrapped with WithBatching. Daily digests go out at
+// dailyAt minutes past local midnight.
+func NewDigestService(digests *repo.Digests, renderer *templates.Renderer, sender channel.Channel, dailyAt int) *DigestService {
+	return &DigestService{digests: digests, renderer: renderer, sender: sender, dailyAt: dailyAt}
+}
+
+func (s *DigestService) Settings(userID uint) (domain.DigestSettings, error) {
+	settings, err := s.digests.GetSettings(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.DefaultDigestSettings(userID), nil
+		}
+		return domain.DigestSettings{}, err
+	}
+	return *settings, nil
+}
+
+func (s *DigestService) UpdateSettings(userID uint, in DigestSettingsInput) (domain.DigestSettings, error) {
+	if !domain.IsKnownFrequency(in.Frequency) {
+		return domain.DigestSettings{}, errUnknownFrequency
+	}
+	if in.Timezone == "" || in.Timezone == "Local" {
+		return domain.DigestSettings{}, errUnknownTimezone
+	}
+	if _, err := time.LoadLocation(in.Timezone); err != nil {
+		return domain.DigestSettings{}, errUnknownTimezone
+	}
+	if !validClock(in.QuietStart) || !validClock(in.QuietEnd) {
+		return domain.DigestSettings{}, errInvalidQuietHours
+	}
+
+	settings := domain.DigestSettings{
+		UserID:     userID,
+		Frequency:  in.Frequency,
+		Timezone:   in.Timezone,
+		QuietStart: in.QuietStart,
+		QuietEnd:   in.QuietEnd,
+		UpdatedAt:  time.Now(),
+	}
+	if err := s.digests.SaveSettings(&settings); err != nil {
+		return domain.DigestSettings{}, err
+	}
+	return s.Settings(userID)
+}
+
+func validClock(m int) bool {
+	return m >= 0 && m < 24*60
+}
+
+// Batch implements channel.Batcher. Digest categories are queued for users
+// who chose hourly or daily digests, and any non-mandatory email is queued
+// during the user's quiet hours.
+func (s *DigestService) Batch(_ context.Context, n channel.Notification) (bool, error) {
+	if n.Mandatory || n.Email == "" {
+		return false, nil
+	}
+
+	settings, err := s.Settings(n.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	digest := domain.IsDigestible(domain.Category(n.Category)) && settings.Frequency != domain.DigestImmediate
+	if !digest && !settings.InQuietHours(time.Now()) {
+		return false, nil
+	}
+
+	err = s.digests.Enqueue(&domain.DigestItem{
+		UserID:   n.UserID,
+		Email:    n.Email,
+		Locale:   n.Locale,
+		Type:     n.Type,
+		Category: domain.Category(n.Category),
+		Title:    n.Content.Subject,
+		Body:     n.Content.Text,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Run flushes due digests every interval until ctx is cancelled.
+func (s *DigestService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Flush(ctx, time.Now()); err != nil {
+			log.Printf("digest: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush sends a digest to every user with pending items whose digest is due
+// at now. Failed users keep their items and are retried on the next flush.
+func (s *DigestService) Flush(ctx context.Context, now time.Time) error {
+	users, err := s.digests.PendingUsers()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, userID := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.flushUser(ctx, userID, now); err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *DigestService) flushUser(ctx context.Context, userID uint, now time.Time) error {
+	settings, err := s.Settings(userID)
+	if err != nil {
+		return err
+	}
+	if !settings.DigestDue(now, s.dailyAt) {
+		return nil
+	}
+
+	items, err := s.digests.Pending(userID)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+	latest := items[len(items)-1]
+
+	data := templates.DigestData{Items: make([]templates.DigestEntry, 0, len(items))}
+	var categories []string
+	for _, it := range items {
+		data.Items = append(data.Items, templates.DigestEntry{Title: it.Title, Body: it.Body})
+		if !slices.Contains(categories, string(it.Category)) {
+			categories = append(categories, string(it.Category))
+		}
+	}
+
+	content, err := s.renderer.Render(templates.Digest, latest.Locale, data)
+	if err != nil {
+		return err
+	}
+
+	// A mixed digest carries all of its categories, so its unsubscribe link
+	// opts the user out of email for each of them. Its items already passed
+	// the preference check when they were queued.
+	n := channel.Notification{
+		Type:     DigestType,
+		Category: strings.Join(categories, domain.CategorySeparator),
+		UserID:   userID,
+		Email:    latest.Email,
+		Locale:   latest.Locale,
+		Content:  content,
+	}
+
+	if err := s.sender.Send(ctx, n); err != nil {
+		return err
+	}
+	log.Printf("digest: sent %d items to user %d", len(items), userID)
+
+	return s.digests.Complete(userID, latest.ID, now)
+}
@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+	"GoNotification/internal/templates"
+)
+
+func newTestDigestService(t *testing.T) (*service.DigestService, *channel.Fake) {
+	t.Helper()
+
+	renderer, err := templates.New()
+	if err != nil {
+		t.Fatalf("templates.New() error = %v", err)
+	}
+	sender := channel.NewFake("smtp")
+	return service.NewDigestService(repo.NewDigests(newTestDB(t)), renderer, sender, 9*60), sender
+}
+
+func digestNotification(category domain.Category, title string) channel.Notification {
+	return channel.Notification{
+		Type:     "product.approved",
+		Category: string(category),
+		UserID:   1,
+		Email:    "user@example.com",
+		Locale:   "en",
+		Content:  templates.Content{Subject: title, Text: title + " body"},
+	}
+}
+
+func TestDigestService_ImmediateUsersAreNotBatched(t *testing.T) {
+	svc, _ := newTestDigestService(t)
+
+	held, err := svc.Batch(context.Background(), digestNotification(domain.CategoryProducts, "approved"))
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	if held {
+		t.Errorf("Batch() held a notification for an immediate user")
+	}
+}
+
+func TestDigestService_BatchesAndFlushes(t *testing.T) {
+	svc, sender := newTestDigestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.UpdateSettings(1, service.DigestSettingsInput{Frequency: domain.DigestHourly, Timezone: "UTC"}); err != nil {
+		t.Fatalf("UpdateSettings() error = %v", err)
+	}
+
+	for _, n := range []channel.Notification{
+		digestNotification(domain.CategoryProducts, "Laptop is published"),
+		digestNotification(domain.CategoryMarketing, "Spring sale"),
+	} {
+		held, err := svc.Batch(ctx, n)
+		if err != nil || !held {
+			t.Fatalf("Batch() = %v, %v; want held", held, err)
+		}
+	}
+
+	// Orders are not a digest category and go out right away.
+	if held, _ := svc.Batch(ctx, digestNotification(domain.CategoryOrders, "Shipped")); held {
+		t.Errorf("Batch() held an orders notification")
+	}
+
+	now := time.Now()
+	if err := svc.Flush(ctx, now); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	sent := sender.Notifications()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d digests, want 1", len(sent))
+	}
+	d := sent[0]
+	if d.Type != service.DigestType || d.Email != "user@example.com" || d.Mandatory {
+		t.Errorf("digest = %+v", d)
+	}
+	// A mixed digest unsubscribes from both categories.
+	if d.Category != "products,marketing" {
+		t.Errorf("Category = %q, want products,marketing", d.Category)
+	}
+	if !strings.Contains(d.Content.Text, "Laptop is published") || !strings.Contains(d.Content.Text, "Spring sale") {
+		t.Errorf("digest text misses items:\n%s", d.Content.Text)
+	}
+
+	// A new item within the hour waits for the next slot.
+	_, _ = svc.Batch(ctx, digestNotification(domain.CategoryProducts, "Phone is published"))
+	if err := svc.Flush(ctx, now.Add(10*time.Minute)); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sender.Notifications()) != 1 {
+		t.Fatalf("digest sent again within the hour")
+	}
+	if err := svc.Flush(ctx, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	sent = sender.Notifications()
+	if len(sent) != 2 || sent[1].Category != string(domain.CategoryProducts) || sent[1].Mandatory {
+		t.Errorf("second digest = %+v, want a products digest with unsubscribe", sent[len(sent)-1])
+	}
+}
+
+func TestDigestService_QuietHoursHoldImmediateMail(t *testing.T) {
+	svc, sender := newTestDigestService(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	minute := now.Hour()*60 + now.Minute()
+	_, err := svc.UpdateSettings(1, service.DigestSettingsInput{
+		Frequency:  domain.DigestImmediate,
+		Timezone:   "UTC",
+		QuietStart: (minute + 24*60 - 60) % (24 * 60),
+		QuietEnd:   (minute + 60) % (24 * 60),
+	})
+	if err != nil {
+		t.Fatalf("UpdateSettings() error = %v", err)
+	}
+
+	held, err := svc.Batch(ctx, digestNotification(domain.CategoryOrders, "Shipped"))
+	if err != nil || !held {
+		t.Fatalf("Batch() = %v, %v; want held during quiet hours", held, err)
+	}
+
+	n := digestNotification(domain.CategoryAccount, "Password changed")
+	n.Mandatory = true
+	if held, _ := svc.Batch(ctx, n); held {
+		t.Errorf("Batch() held a mandatory notification")
+	}
+
+	if err := svc.Flush(ctx, now); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sender.Notifications()) != 0 {
+		t.Fatalf("digest sent during quiet hours")
+	}
+	if err := svc.Flush(ctx, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sender.Notifications()) != 1 {
+		t.Errorf("held mail not sent after quiet hours")
+	}
+}
+
+func TestDigestService_FailedSendKeepsItems(t *testing.T) {
+	svc, sender := newTestDigestService(t)
+	ctx := context.Background()
+
+	_, _ = svc.UpdateSettings(1, service.DigestSettingsInput{Frequency: domain.DigestDaily, Timezone: "UTC"})
+	_, _ = svc.Batch(ctx, digestNotification(domain.CategoryMarketing, "Spring sale"))
+
+	sender.Err = context.DeadlineExceeded
+	sender.FailTimes = 1
+	if err := svc.Flush(ctx, time.Now()); err == nil {
+		t.Fatalf("Flush() error = nil, want send failure")
+	}
+	if err := svc.Flush(ctx, time.Now()); err != nil {
+		t.Fatalf("Flush() retry error = %v", err)
+	}
+	if len(sender.Notifications()) != 1 {
+		t.Errorf("sent %d digests after retry, want 1", len(sender.Notifications()))
+	}
+}
+
+func TestDigestService_UpdateSettingsValidation(t *testing.T) {
+	svc, _ := newTestDigestService(t)
+
+	cases := []struct {
+		in    service.DigestSettingsInput
+		check func(error) bool
+	}{
+		{service.DigestSettingsInput{Frequency: "weekly", Timezone: "UTC"}, service.IsUnknownFrequency},
+		{service.DigestSettingsInput{Frequency: domain.DigestDaily, Timezone: "Mars/Olympus"}, service.IsUnknownTimezone},
+		{service.DigestSettingsInput{Frequency: domain.DigestDaily, Timezone: "UTC", QuietEnd: 24 * 60}, service.IsInvalidQuietHours},
+	}
+	for _, c := range cases {
+		if _, err := svc.UpdateSettings(1, c.in); !c.check(err) {
+			t.Errorf("UpdateSettings(%+v) error = %v", c.in, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/repo"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+)
+
+var errNotFound = errors.New("notification_not_found")
+
+func IsNotFound(err error) bool { return errors.Is(err, errNotFound) }
+
+type ListInboxInput struct {
+	UserID     uint
+	UnreadOnly bool
+	BeforeID   uint
+	Limit      int
+}
+
+// Publisher pushes freshly stored notifications to connected clients.
+type Publisher interface {
+	Publish(userID uint, n domain.Notification)
+}
+
+type InboxService struct {
+	notifications *repo.Notifications
+	publisher     Publisher
+}
+
+func NewInboxService(notifications *repo.Notifications, publisher Publisher) *InboxService {
+	return &InboxService{notifications: notifications, publisher: publisher}
+}
+
+// Deliver stores a dispatched notification in the recipient's inbox. It
+// backs the "inapp" channel.
+func (s *InboxService) Deliver(_ context.Context, n channel.Notification) (*domain.Notification, error) {
+	entry := &domain.Notification{
+		UserID:   n.UserID,
+		Type:     n.Type,
+		Category: domain.Category(n.Category),
+		Title:    n.Content.Subject,
+		Body:     n.Content.Text,
+	}
+	if err := s.notifications.Create(entry); err != nil {
+		return nil, err
+	}
+	if s.publisher != nil {
+		s.publisher.Publish(entry.UserID, *entry)
+	}
+	return entry, nil
+}
+
+func (s *InboxService) List(in ListInboxInput) ([]domain.Notification, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultInboxLimit
+	}
+	if limit > maxInboxLimit {
+		limit = maxInboxLimit
+	}
+
+	return s.notifications.ListByUser(in.UserID, repo.NotificationFilter{
+		UnreadOnly: in.UnreadOnly,
+		BeforeID:   in.BeforeID,
+		Limit:      limit,
+	})
+}
+
+// Since returns notifications newer than afterID, oldest first, so a
+// reconnecting stream can replay what it missed.
+func (s *InboxService) Since(userID, afterID uint) ([]domain.Notification, error) {
+	return s.notifications.ListAfter(userID, afterID, maxInboxLimit)
+}
+
+func (s *InboxService) MarkRead(userID, id uint) error {
+	if err := s.notifications.MarkRead(userID, id, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *InboxService) MarkAllRead(userID uint) (int64, error) {
+	return s.notifications.MarkAllRead(userID, time.Now())
+}
+
+func (s *InboxService) UnreadCount(userID uint) (int64, error) {
+	return s.notifications.UnreadCount(userID)
+}
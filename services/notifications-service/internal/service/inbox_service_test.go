@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"GoNotification/internal/channel"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+	"GoNotification/internal/templates"
+)
+
+func TestInboxService_DeliverAndRead(t *testing.T) {
+	svc := service.NewInboxService(repo.NewNotifications(newTestDB(t)), nil)
+
+	stored, err := svc.Deliver(context.Background(), channel.Notification{
+		Type:     "order.paid",
+		Category: "orders",
+		UserID:   3,
+		Content:  templates.Content{Subject: "Order paid", Text: "Your order #1 was paid"},
+	})
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if stored.Title != "Order paid" {
+		t.Errorf("Title = %q, want %q", stored.Title, "Order paid")
+	}
+
+	if n, _ := svc.UnreadCount(3); n != 1 {
+		t.Fatalf("UnreadCount() = %d, want 1", n)
+	}
+
+	if err := svc.MarkRead(3, stored.ID); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+	if n, _ := svc.UnreadCount(3); n != 0 {
+		t.Errorf("UnreadCount() after MarkRead = %d, want 0", n)
+	}
+
+	if err := svc.MarkRead(4, stored.ID); !service.IsNotFound(err) {
+		t.Errorf("MarkRead() by another user error = %v, want not found", err)
+	}
+}
+
+func TestInboxService_ListClampsLimit(t *testing.T) {
+	svc := service.NewInboxService(repo.NewNotifications(newTestDB(t)), nil)
+
+	for i := 0; i < 25; i++ {
+		_, _ = svc.Deliver(context.Background(), channel.Notification{Type: "t", Category: "orders", UserID: 1})
+	}
+
+	list, err := svc.List(service.ListInboxInput{UserID: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 20 {
+		t.Errorf("len(List()) = %d, want default limit 20", len(list))
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/repo"
+	"GoNotification/pkg/unsubscribe"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errUnknownCategory = errors.New("unknown_category")
+	errUnknownChannel  = errors.New("unknown_channel")
+	errMandatory       = errors.New("mandatory_category")
+	errInvalidToken    = errors.New("invalid_token")
+)
+
+func IsUnknownCategory(err error) bool { return errors.Is(err, errUnknownCategory) }
+func IsUnknownChannel(err error) bool  { return errors.Is(err, errUnknownChannel) }
+func IsMandatory(err error) bool       { return errors.Is(err, errMandatory) }
+func IsInvalidToken(err error) bool    { return errors.Is(err, errInvalidToken) }
+
+type PreferenceView struct {
+	Category  domain.Category
+	Mandatory bool
+	Channels  map[string]bool
+}
+
+type PreferenceUpdate struct {
+	Category domain.Category
+	Channel  string
+	Enabled  bool
+}
+
+type PreferenceService struct {
+	prefs  *repo.Preferences
+	signer *unsubscribe.Signer
+}
+
+func NewPreferenceService(prefs *repo.Preferences, signer *unsubscribe.Signer) *PreferenceService {
+	return &PreferenceService{prefs: prefs, signer: signer}
+}
+
+// Get returns the full category x channel matrix for a user, with defaults
+// filled in for combinations the user never touched.
+func (s *PreferenceService) Get(userID uint) ([]PreferenceView, error) {
+	stored, err := s.prefs.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := make(map[domain.Category]map[string]bool)
+	for _, p := range stored {
+		if p.Enabled {
+			continue
+		}
+		if disabled[p.Category] == nil {
+			disabled[p.Category] = make(map[string]bool)
+		}
+		disabled[p.Category][p.Channel] = true
+	}
+
+	views := make([]PreferenceView, 0, len(domain.Categories))
+	for _, cat := range domain.Categories {
+		v := PreferenceView{
+			Category:  cat.Name,
+			Mandatory: cat.Mandatory,
+			Channels:  make(map[string]bool, len(domain.Channels)),
+		}
+		for _, ch := range domain.Channels {
+			v.Channels[ch] = cat.Mandatory || !disabled[cat.Name][ch]
+		}
+		views = append(views, v)
+	}
+	return views, nil
+}
+
+func (s *PreferenceService) Update(userID uint, updates []PreferenceUpdate) ([]PreferenceView, error) {
+	now := time.Now()
+	rows := make([]domain.Preference, 0, len(updates))
+	for _, u := range updates {
+		if !domain.IsKnownCategory(u.Category) {
+			return nil, errUnknownCategory
+		}
+		if !domain.IsUserChannel(u.Channel) {
+			return nil, errUnknownChannel
+		}
+		if domain.IsMandatory(u.Category) && !u.Enabled {
+			return nil, errMandatory
+		}
+		rows = append(rows, domain.Preference{
+			UserID:    userID,
+			Category:  u.Category,
+			Channel:   u.Channel,
+			Enabled:   u.Enabled,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := s.prefs.Upsert(rows...); err != nil {
+		return nil, err
+	}
+	return s.Get(userID)
+}
+
+// Unsubscribe opts the user out of the categories and channel encoded in a
+// signed unsubscribe token. Links from mixed digests carry several
+// categories.
+func (s *PreferenceService) Unsubscribe(token string) (unsubscribe.Claims, error) {
+	claims, err := s.signer.Verify(token)
+	if err != nil {
+		return unsubscribe.Claims{}, errInvalidToken
+	}
+
+	var updates []PreferenceUpdate
+	for _, category := range strings.Split(claims.Category, domain.CategorySeparator) {
+		updates = append(updates, PreferenceUpdate{
+			Category: domain.Category(category),
+			Channel:  claims.Channel,
+			Enabled:  false,
+		})
+	}
+	if _, err := s.Update(claims.UserID, updates); err != nil {
+		return unsubscribe.Claims{}, err
+	}
+	return claims, nil
+}
+
+// Allow implements channel.Policy: mandatory notifications and operational
+// channels always pass, everything else honours the user's opt-outs.
+func (s *PreferenceService) Allow(_ context.Context, n channel.Notification, ch string) (bool, error) {
+	if n.Mandatory || !domain.IsUserChannel(ch) {
+		return true, nil
+	}
+
+	p, err := s.prefs.Get(n.UserID, domain.Category(n.Category), ch)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	return p.Enabled, nil
+}
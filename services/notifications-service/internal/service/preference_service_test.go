@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"GoNotification/internal/channel"
+	"GoNotification/internal/domain"
+	"GoNotification/internal/repo"
+	"GoNotification/internal/service"
+	"GoNotification/pkg/unsubscribe"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Preference{}, &domain.Notification{}, &domain.DigestSettings{}, &domain.DigestItem{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	return db
+}
+
+func newTestPreferenceService(t *testing.T) (*service.PreferenceService, *unsubscribe.Signer) {
+	t.Helper()
+
+	signer := unsubscribe.NewSigner("test-secret", "http://localhost:8082")
+	return service.NewPreferenceService(repo.NewPreferences(newTestDB(t)), signer), signer
+}
+
+func TestPreferenceService_DefaultsAllEnabled(t *testing.T) {
+	svc, _ := newTestPreferenceService(t)
+
+	views, err := svc.Get(1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(views) != len(domain.Categories) {
+		t.Fatalf("len(views) = %d, want %d", len(views), len(domain.Categories))
+	}
+	for _, v := range views {
+		for ch, enabled := range v.Channels {
+			if !enabled {
+				t.Errorf("%s/%s disabled by default", v.Category, ch)
+			}
+		}
+	}
+}
+
+func TestPreferenceService_UpdateValidation(t *testing.T) {
+	svc, _ := newTestPreferenceService(t)
+
+	cases := []struct {
+		name  string
+		in    service.PreferenceUpdate
+		check func(error) bool
+	}{
+		{"unknown category", service.PreferenceUpdate{Category: "nope", Channel: "smtp"}, service.IsUnknownCategory},
+		{"unknown channel", service.PreferenceUpdate{Category: domain.CategoryOrders, Channel: "pigeon"}, service.IsUnknownChannel},
+		{"mandatory", service.PreferenceUpdate{Category: domain.CategoryAccount, Channel: "smtp"}, service.IsMandatory},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.Update(1, []service.PreferenceUpdate{tc.in}); !tc.check(err) {
+				t.Errorf("Update() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPreferenceService_AllowHonoursOptOut(t *testing.T) {
+	svc, _ := newTestPreferenceService(t)
+	ctx := context.Background()
+
+	_, err := svc.Update(1, []service.PreferenceUpdate{{Category: domain.CategoryMarketing, Channel: "smtp", Enabled: false}})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	marketing := channel.Notification{UserID: 1, Category: string(domain.CategoryMarketing)}
+	if ok, _ := svc.Allow(ctx, marketing, "smtp"); ok {
+		t.Errorf("Allow(marketing, smtp) = true, want false after opt-out")
+	}
+	if ok, _ := svc.Allow(ctx, marketing, "sms"); !ok {
+		t.Errorf("Allow(marketing, sms) = false, want true")
+	}
+	if ok, _ := svc.Allow(ctx, marketing, "file"); !ok {
+		t.Errorf("Allow(marketing, file) = false, operational channels must not be filtered")
+	}
+
+	account := channel.Notification{UserID: 1, Category: string(domain.CategoryAccount), Mandatory: true}
+	if ok, _ := svc.Allow(ctx, account, "smtp"); !ok {
+		t.Errorf("Allow(account, smtp) = false, mandatory notifications must pass")
+	}
+}
+
+func TestPreferenceService_Unsubscribe(t *testing.T) {
+	svc, signer := newTestPreferenceService(t)
+
+	claims, err := svc.Unsubscribe(signer.Token(5, string(domain.CategoryOrders), "smtp"))
+	if err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	if claims.UserID != 5 {
+		t.Errorf("UserID = %d, want 5", claims.UserID)
+	}
+
+	n := channel.Notification{UserID: 5, Category: string(domain.CategoryOrders)}
+	if ok, _ := svc.Allow(context.Background(), n, "smtp"); ok {
+		t.Errorf("Allow() = true after unsubscribe")
+	}
+
+	// Links from mixed digests opt out of every category in the digest.
+	mixed := string(domain.CategoryProducts) + domain.CategorySeparator + string(domain.CategoryMarketing)
+	if _, err := svc.Unsubscribe(signer.Token(5, mixed, "smtp")); err != nil {
+		t.Fatalf("Unsubscribe(mixed) error = %v", err)
+	}
+	for _, c := range []domain.Category{domain.CategoryProducts, domain.CategoryMarketing} {
+		n := channel.Notification{UserID: 5, Category: string(c)}
+		if ok, _ := svc.Allow(context.Background(), n, "smtp"); ok {
+			t.Errorf("Allow(%s) = true after unsubscribing from a mixed digest", c)
+		}
+	}
+
+	if _, err := svc.Unsubscribe("bogus"); !service.IsInvalidToken(err) {
+		t.Errorf("Unsubscribe(bogus) error = %v, want invalid token", err)
+	}
+}
@@ -0,0 +1,175 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed files
+var files embed.FS
+
+const (
+	Verification = "verification"
+	Digest       = "digest"
+	Order        = "order"
+	Sale         = "sale"
+	Product      = "product"
+
+	DefaultLocale = "ru"
+)
+
+var Locales = []string{"ru", "en", "kk"}
+
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type VerificationData struct {
+	Name string
+	Link string
+}
+
+type DigestData struct {
+	Items []DigestEntry
+}
+
+type DigestEntry struct {
+	Title string
+	Body  string
+}
+
+// OrderData renders order status emails; Status is the order status the
+// event announces, e.g. "SHIPPED". Sale renders the same data for the
+// seller when an order is placed or paid.
+type OrderData struct {
+	OrderID uint
+	Status  string
+	Total   float64
+	Items   []OrderLine
+}
+
+type OrderLine struct {
+	Name      string
+	Quantity  int
+	UnitPrice float64
+}
+
+// ProductData renders moderation decisions for sellers; Status is the
+// product's new status, PUBLISHED or REJECTED.
+type ProductData struct {
+	ProductID uint
+	Name      string
+	Status    string
+	Reason    string
+}
+
+type set struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+type Renderer struct {
+	sets map[string]map[string]*set
+}
+
+func New() (*Renderer, error) {
+	r := &Renderer{sets: make(map[string]map[string]*set)}
+
+	kinds, err := fs.ReadDir(files, "files")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, kind := range kinds {
+		if !kind.IsDir() {
+			continue
+		}
+		locales, err := fs.ReadDir(files, path.Join("files", kind.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, loc := range locales {
+			if !loc.IsDir() {
+				continue
+			}
+			s, err := parseSet(path.Join("files", kind.Name(), loc.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("templates: %s/%s: %w", kind.Name(), loc.Name(), err)
+			}
+			if r.sets[kind.Name()] == nil {
+				r.sets[kind.Name()] = make(map[string]*set)
+			}
+			r.sets[kind.Name()][loc.Name()] = s
+		}
+	}
+
+	return r, nil
+}
+
+func parseSet(dir string) (*set, error) {
+	subject, err := texttemplate.ParseFS(files, path.Join(dir, "subject.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.ParseFS(files, path.Join(dir, "text.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.ParseFS(files, path.Join(dir, "html.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	return &set{subject: subject, text: text, html: html}, nil
+}
+
+// Render executes the templates of the given kind for locale, falling back to
+// DefaultLocale when the locale has no translation.
+func (r *Renderer) Render(kind, locale string, data any) (Content, error) {
+	byLocale, ok := r.sets[kind]
+	if !ok {
+		return Content{}, fmt.Errorf("templates: unknown template %q", kind)
+	}
+
+	s, ok := byLocale[NormalizeLocale(locale)]
+	if !ok {
+		s, ok = byLocale[DefaultLocale]
+		if !ok {
+			return Content{}, fmt.Errorf("templates: %q has no %s translation", kind, DefaultLocale)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := s.subject.Execute(&subject, data); err != nil {
+		return Content{}, err
+	}
+	if err := s.text.Execute(&text, data); err != nil {
+		return Content{}, err
+	}
+	if err := s.html.Execute(&html, data); err != nil {
+		return Content{}, err
+	}
+
+	return Content{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// NormalizeLocale reduces tags like "en-US" or "KK_kz" to their language part.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
@@ -0,0 +1,233 @@
+package templates_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"GoNotification/internal/templates"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func newTestRenderer(t *testing.T) *templates.Renderer {
+	t.Helper()
+
+	r, err := templates.New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return r
+}
+
+func assertGolden(t *testing.T, name string, c templates.Content) {
+	t.Helper()
+
+	got := []byte("Subject: " + c.Subject + "\n\n--- text ---\n" + c.Text + "\n--- html ---\n" + c.HTML)
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden: %v (run with -update to create it)", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s mismatch\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestRender_VerificationGolden(t *testing.T) {
+	r := newTestRenderer(t)
+
+	for _, locale := range templates.Locales {
+		t.Run(locale, func(t *testing.T) {
+			c, err := r.Render(templates.Verification, locale, templates.VerificationData{
+				Name: "Aigerim",
+				Link: "http://localhost:8080/auth/verify?token=abc&x=<1>",
+			})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertGolden(t, templates.Verification+"."+locale, c)
+		})
+	}
+}
+
+func TestRender_FallsBackToDefaultLocale(t *testing.T) {
+	r := newTestRenderer(t)
+
+	data := templates.VerificationData{Link: "http://x"}
+	want, err := r.Render(templates.Verification, templates.DefaultLocale, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, locale := range []string{"", "de", "fr-FR"} {
+		got, err := r.Render(templates.Verification, locale, data)
+		if err != nil {
+			t.Fatalf("Render(%q) error = %v", locale, err)
+		}
+		if got != want {
+			t.Errorf("Render(%q) = %+v, want default locale content", locale, got)
+		}
+	}
+}
+
+func TestRender_UnknownTemplate(t *testing.T) {
+	r := newTestRenderer(t)
+
+	if _, err := r.Render("nope", "en", nil); err == nil {
+		t.Fatalf("Render() error = nil, want error for unknown template")
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	cases := map[string]string{
+		"en":    "en",
+		"en-US": "en",
+		"KK_kz": "kk",
+		" ru ":  "ru",
+		"":      "",
+	}
+	for in, want := range cases {
+		if got := templates.NormalizeLocale(in); got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRender_DigestGolden(t *testing.T) {
+	r := newTestRenderer(t)
+
+	data := templates.DigestData{Items: []templates.DigestEntry{
+		{Title: "Phone X is published", Body: "Buyers can see it: <now>"},
+		{Title: "Spring sale"},
+	}}
+	for _, locale := range templates.Locales {
+		t.Run(locale, func(t *testing.T) {
+			c, err := r.Render(templates.Digest, locale, data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertGolden(t, templates.Digest+"."+locale, c)
+		})
+	}
+}
+
+func TestRender_OrderGolden(t *testing.T) {
+	r := newTestRenderer(t)
+
+	data := templates.OrderData{
+		OrderID: 42,
+		Status:  "SHIPPED",
+		Total:   1049.5,
+		Items: []templates.OrderLine{
+			{Name: "Phone <X>", Quantity: 1, UnitPrice: 999.5},
+			{Name: "Case", Quantity: 2, UnitPrice: 25},
+		},
+	}
+	for _, locale := range templates.Locales {
+		t.Run(locale, func(t *testing.T) {
+			c, err := r.Render(templates.Order, locale, data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertGolden(t, templates.Order+"."+locale, c)
+		})
+	}
+}
+
+func TestRender_OrderSubjectPerStatus(t *testing.T) {
+	r := newTestRenderer(t)
+
+	cases := map[string]string{
+		"PENDING_PAYMENT": "Order #7 placed",
+		"PAID":            "Order #7 paid",
+		"DELIVERED":       "Order #7 delivered",
+		"CANCELLED":       "Order #7 cancelled",
+		"REFUNDED":        "Order #7 refunded",
+	}
+	for status, want := range cases {
+		c, err := r.Render(templates.Order, "en", templates.OrderData{OrderID: 7, Status: status})
+		if err != nil {
+			t.Fatalf("Render(%s) error = %v", status, err)
+		}
+		if c.Subject != want {
+			t.Errorf("Subject(%s) = %q, want %q", status, c.Subject, want)
+		}
+	}
+}
+
+func TestRender_SaleGolden(t *testing.T) {
+	r := newTestRenderer(t)
+
+	data := templates.OrderData{
+		OrderID: 42,
+		Status:  "PAID",
+		Total:   1049.5,
+		Items: []templates.OrderLine{
+			{Name: "Phone <X>", Quantity: 1, UnitPrice: 999.5},
+			{Name: "Case", Quantity: 2, UnitPrice: 25},
+		},
+	}
+	for _, locale := range templates.Locales {
+		t.Run(locale, func(t *testing.T) {
+			c, err := r.Render(templates.Sale, locale, data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertGolden(t, templates.Sale+"."+locale, c)
+		})
+	}
+
+	c, err := r.Render(templates.Sale, "en", templates.OrderData{OrderID: 7, Status: "PENDING_PAYMENT"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if c.Subject != "New order #7" {
+		t.Errorf("Subject = %q, want %q", c.Subject, "New order #7")
+	}
+}
+
+func TestRender_ProductGolden(t *testing.T) {
+	r := newTestRenderer(t)
+
+	data := templates.ProductData{
+		ProductID: 12,
+		Name:      "Phone <X>",
+		Status:    "REJECTED",
+		Reason:    "Photos show a <different> model",
+	}
+	for _, locale := range templates.Locales {
+		t.Run(locale, func(t *testing.T) {
+			c, err := r.Render(templates.Product, locale, data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertGolden(t, templates.Product+"."+locale, c)
+		})
+	}
+}
+
+func TestRender_ProductApproved(t *testing.T) {
+	r := newTestRenderer(t)
+
+	c, err := r.Render(templates.Product, "en", templates.ProductData{ProductID: 12, Name: "Kettle", Status: "PUBLISHED"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if c.Subject != `Product "Kettle" is published` {
+		t.Errorf("Subject = %q", c.Subject)
+	}
+	if strings.Contains(c.Text, "Reason") {
+		t.Errorf("approval mentions a rejection reason:\n%s", c.Text)
+	}
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS preferences
+(
+    user_id    INTEGER     NOT NULL,
+    category   VARCHAR(64) NOT NULL,
+    channel    VARCHAR(32) NOT NULL,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, category, channel)
+);
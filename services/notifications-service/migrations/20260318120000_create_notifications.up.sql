@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER      NOT NULL,
+    type       VARCHAR(64)  NOT NULL,
+    category   VARCHAR(64)  NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT,
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_digest_items_user_id;
+DROP TABLE IF EXISTS digest_items;
+DROP TABLE IF EXISTS digest_settings;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS digest_settings
+(
+    user_id        INTEGER PRIMARY KEY,
+    frequency      VARCHAR(16) NOT NULL DEFAULT 'immediate',
+    timezone       VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    quiet_start    INTEGER     NOT NULL DEFAULT 0,
+    quiet_end      INTEGER     NOT NULL DEFAULT 0,
+    last_digest_at TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS digest_items
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER      NOT NULL,
+    email      VARCHAR(255) NOT NULL,
+    locale     VARCHAR(8)   NOT NULL,
+    type       VARCHAR(64)  NOT NULL,
+    category   VARCHAR(64)  NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_items_user_id ON digest_items (user_id, id);
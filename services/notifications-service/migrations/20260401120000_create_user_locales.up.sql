@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_locales
+(
+    user_id    INTEGER PRIMARY KEY,
+    locale     VARCHAR(8)  NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Verifier struct {
+	secret []byte
+}
+
+func NewVerifier(secret string) *Verifier {
+	return &Verifier{secret: []byte(secret)}
+}
+
+type Claims struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	jwt.RegisteredClaims
+}
+
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return v.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := parsed.Claims.(*Claims); ok && parsed.Valid {
+		if c.ExpiresAt != nil && c.ExpiresAt.Time.Before(time.Now()) {
+			return nil, errors.New("token expired")
+		}
+		return c, nil
+	}
+	return nil, errors.New("invalid claims")
+}
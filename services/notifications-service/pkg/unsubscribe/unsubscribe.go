@@ -0,0 +1,75 @@
+package unsubscribe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+type Claims struct {
+	UserID   uint
+	Category string
+	Channel  string
+}
+
+// Signer issues and verifies HMAC-signed tokens for one-click unsubscribe
+// links. Tokens do not expire: an old email must still let a user opt out.
+type Signer struct {
+	secret  []byte
+	baseURL string
+}
+
+func NewSigner(secret, baseURL string) *Signer {
+	return &Signer{secret: []byte(secret), baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (s *Signer) Token(userID uint, category, channel string) string {
+	payload := fmt.Sprintf("%d:%s:%s", userID, category, channel)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(s.mac(payload))
+}
+
+func (s *Signer) URL(userID uint, category, channel string) string {
+	return s.baseURL + "/unsubscribe?token=" + url.QueryEscape(s.Token(userID, category, channel))
+}
+
+func (s *Signer) Verify(token string) (Claims, error) {
+	enc := base64.RawURLEncoding
+
+	rawPayload, rawSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	sig, err := enc.DecodeString(rawSig)
+	if err != nil || !hmac.Equal(sig, s.mac(string(payload))) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	parts := strings.SplitN(string(payload), ":", 3)
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidToken
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	return Claims{UserID: uint(userID), Category: parts[1], Channel: parts[2]}, nil
+}
+
+func (s *Signer) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
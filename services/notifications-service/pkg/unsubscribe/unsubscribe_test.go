@@ -0,0 +1,54 @@
+package unsubscribe_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"GoNotification/pkg/unsubscribe"
+)
+
+func TestSigner_RoundTrip(t *testing.T) {
+	s := unsubscribe.NewSigner("secret", "http://localhost:8082/")
+
+	claims, err := s.Verify(s.Token(42, "marketing", "smtp"))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if claims.UserID != 42 || claims.Category != "marketing" || claims.Channel != "smtp" {
+		t.Errorf("Verify() = %+v", claims)
+	}
+}
+
+func TestSigner_URL(t *testing.T) {
+	s := unsubscribe.NewSigner("secret", "http://localhost:8082/")
+
+	raw := s.URL(1, "orders", "smtp")
+	if !strings.HasPrefix(raw, "http://localhost:8082/unsubscribe?token=") {
+		t.Fatalf("URL() = %q", raw)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if _, err := s.Verify(u.Query().Get("token")); err != nil {
+		t.Errorf("token from URL does not verify: %v", err)
+	}
+}
+
+func TestSigner_RejectsTampering(t *testing.T) {
+	s := unsubscribe.NewSigner("secret", "http://x")
+	other := unsubscribe.NewSigner("other-secret", "http://x")
+
+	tok := s.Token(1, "marketing", "smtp")
+	forged := other.Token(2, "marketing", "smtp")
+	payload, _, _ := strings.Cut(forged, ".")
+	_, sig, _ := strings.Cut(tok, ".")
+
+	for _, bad := range []string{"", "garbage", payload + "." + sig, other.Token(1, "marketing", "smtp")} {
+		if _, err := s.Verify(bad); err == nil {
+			t.Errorf("Verify(%q) error = nil, want error", bad)
+		}
+	}
+}
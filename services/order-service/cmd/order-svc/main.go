@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	cfgpkg "GoOrder/internal"
+	"GoOrder/internal/clients"
+	"GoOrder/internal/consumer"
+	"GoOrder/internal/http/handlers"
+	"GoOrder/internal/http/middleware"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/repo"
+	"GoOrder/internal/service"
+	jwtutil "GoOrder/pkg/jwt"
+	"GoOrder/pkg/kafka"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	migr "GoOrder/internal/migrate"
+)
+
+func main() {
+	cfg := cfgpkg.MustLoad()
+	migr.Up(cfg.PGURL)
+
+	db, err := gorm.Open(postgres.Open(cfg.PGURL), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("open db: %v", err)
+	}
+
+	producer := kafka.NewProducer(cfg.KafkaBrokers)
+	defer producer.Close()
+
+	verifier := jwtutil.NewVerifier(cfg.JWTSecret)
+	products := clients.NewProducts(cfg.ProductServiceURL, cfg.InternalToken, cfg.ProductTimeout)
+
+	orderSvc := service.NewOrderService(repo.NewOrders(db), products, producer)
+	h := handlers.NewOrderHandler(orderSvc)
+
+	provider := payments.NewFake(cfg.PaymentWebhookSecret, cfg.PaymentWebhookURL, cfg.PaymentProcessingDelay)
+	paymentSvc := service.NewPaymentService(repo.NewPayments(db), orderSvc, provider, cfg.PaymentCurrency)
+	payH := handlers.NewPaymentHandler(paymentSvc)
+
+	checkoutSvc := service.NewCheckoutService(repo.NewSagas(db), orderSvc, paymentSvc, products, producer, service.SagaConfig{
+		StepTimeout: cfg.SagaStepTimeout,
+		MaxAttempts: cfg.SagaMaxAttempts,
+	})
+	checkoutH := handlers.NewCheckoutHandler(checkoutSvc)
+
+	r := gin.Default()
+
+	orders := r.Group("/orders", middleware.AuthRequired(verifier))
+	{
+		orders.GET("", h.ListMine)
+		orders.GET("/:id", h.Get)
+		orders.PATCH("/:id/status", h.ChangeStatus)
+		orders.POST("/checkout", middleware.RequireActive(), checkoutH.Start)
+		orders.GET("/checkout/:id", checkoutH.Get)
+		orders.GET("/:id/payment", payH.Get)
+		orders.POST("/:id/payment", middleware.RequireActive(), payH.Pay)
+	}
+
+	seller := r.Group("/seller", middleware.AuthRequired(verifier))
+	{
+		seller.GET("/orders", h.ListSelling)
+		seller.POST("/orders/:id/refund", payH.Refund)
+	}
+
+	r.POST("/payments/webhook", payH.Webhook)
+
+	srv := &http.Server{
+		Addr:         cfg.HTTPAddr,
+		Handler:      r,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	go paymentSvc.RunReconcile(bgCtx, cfg.PaymentReconcileInterval, cfg.PaymentStuckAfter)
+	go checkoutSvc.RunRecovery(bgCtx, cfg.SagaRecoveryInterval)
+
+	replies := consumer.NewReplies(cfg.KafkaBrokers, service.TopicCheckoutReplies, cfg.KafkaGroupID, checkoutSvc)
+	go func() {
+		if err := replies.Start(bgCtx); err != nil {
+			log.Printf("reply consumer: %v", err)
+		}
+	}()
+
+	go func() {
+		log.Printf("order-svc listening on %s", cfg.HTTPAddr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("http server: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	stopBackground()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("server shutdown error: %v", err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+
+	log.Println("order-svc stopped cleanly")
+}
@@ -0,0 +1,47 @@
+package clients
+
+// Commands and replies exchanged with product-service over Kafka. They
+// mirror product-service's internal/commands package.
+
+// CommandsTopic receives commands for product-service.
+const CommandsTopic = "product.commands"
+
+const (
+	CommandReserveStock = "stock.reserve"
+	CommandReleaseStock = "stock.release"
+	CommandClearCart    = "cart.clear"
+)
+
+// Reply error codes.
+const (
+	ReplyOutOfStock      = "out_of_stock"
+	ReplyProductNotFound = "product_not_found"
+	ReplyClosed          = "reservation_closed"
+	ReplyInvalid         = "invalid_command"
+	ReplyInternal        = "internal"
+)
+
+type Reservation struct {
+	Reference string        `json:"reference"`
+	Items     []ReserveItem `json:"items"`
+}
+
+// Command asks product-service to do one thing. Commands are idempotent:
+// product-service may receive the same command more than once.
+type Command struct {
+	ID      string `json:"id"`
+	SagaID  string `json:"saga_id"`
+	Type    string `json:"type"`
+	ReplyTo string `json:"reply_to"`
+
+	UserID       uint          `json:"user_id,omitempty"`
+	Reservations []Reservation `json:"reservations,omitempty"`
+}
+
+type Reply struct {
+	CommandID string `json:"command_id"`
+	SagaID    string `json:"saga_id"`
+	Type      string `json:"type"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,167 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned when product-service rejects the buyer's
+// credentials forwarded with a request.
+var ErrUnauthorized = errors.New("product service: unauthorized")
+
+var (
+	// ErrOutOfStock is returned when a reservation cannot be made because a
+	// product does not have enough stock left.
+	ErrOutOfStock = errors.New("product service: out of stock")
+	// ErrReservationClosed is returned when committing a reservation that was
+	// already released or expired, or releasing one already committed.
+	ErrReservationClosed = errors.New("product service: reservation closed")
+
+	errConflict = errors.New("product service: conflict")
+)
+
+// internalTokenHeader must match product-service's middleware.
+const internalTokenHeader = "X-Internal-Token"
+
+type CartItem struct {
+	ProductID    uint    `json:"product_id"`
+	SellerID     uint    `json:"seller_id"`
+	Name         string  `json:"name"`
+	Quantity     int     `json:"quantity"`
+	UnitPrice    float64 `json:"unit_price"`
+	CurrentPrice float64 `json:"current_price"`
+	Available    bool    `json:"available"`
+	PriceChanged bool    `json:"price_changed"`
+}
+
+type Cart struct {
+	Items    []CartItem `json:"items"`
+	Subtotal float64    `json:"subtotal"`
+}
+
+type ReserveItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// Products talks to product-service. Cart calls act on behalf of the
+// signed-in buyer by forwarding their Authorization header; stock
+// reservations are internal calls authenticated with internalToken.
+type Products struct {
+	baseURL       string
+	internalToken string
+	client        *http.Client
+}
+
+func NewProducts(baseURL, internalToken string, timeout time.Duration) *Products {
+	return &Products{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		internalToken: internalToken,
+		client:        &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *Products) Cart(ctx context.Context, authorization string) (*Cart, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/cart/items", "Authorization", authorization, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var cart Cart
+	if err := json.NewDecoder(resp.Body).Decode(&cart); err != nil {
+		return nil, fmt.Errorf("product service: decode cart: %w", err)
+	}
+	return &cart, nil
+}
+
+func (c *Products) ClearCart(ctx context.Context, authorization string) error {
+	resp, err := c.do(ctx, http.MethodDelete, "/cart/items", "Authorization", authorization, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Reserve holds stock for items under ref. Reserving an existing ref again
+// is a no-op, so retries are safe.
+func (c *Products) Reserve(ctx context.Context, ref string, items []ReserveItem) error {
+	body := map[string]any{"reference": ref, "items": items}
+	resp, err := c.do(ctx, http.MethodPost, "/internal/reservations", internalTokenHeader, c.internalToken, body)
+	if errors.Is(err, errConflict) {
+		return ErrOutOfStock
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *Products) CommitReservation(ctx context.Context, ref string) error {
+	return c.closeReservation(ctx, ref, "commit")
+}
+
+func (c *Products) ReleaseReservation(ctx context.Context, ref string) error {
+	return c.closeReservation(ctx, ref, "release")
+}
+
+func (c *Products) closeReservation(ctx context.Context, ref, action string) error {
+	path := "/internal/reservations/" + url.PathEscape(ref) + "/" + action
+	resp, err := c.do(ctx, http.MethodPost, path, internalTokenHeader, c.internalToken, nil)
+	if errors.Is(err, errConflict) {
+		return ErrReservationClosed
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends a request authenticated by a single header and, when body is not
+// nil, a JSON payload.
+func (c *Products) do(ctx context.Context, method, path, authHeader, authValue string, body any) (*http.Response, error) {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(authHeader, authValue)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("product service: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	case resp.StatusCode == http.StatusConflict:
+		resp.Body.Close()
+		return nil, errConflict
+	case resp.StatusCode >= 300:
+		resp.Body.Close()
+		return nil, fmt.Errorf("product service: %s %s: status %d", method, path, resp.StatusCode)
+	}
+	return resp, nil
+}
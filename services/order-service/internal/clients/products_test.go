@@ -0,0 +1,94 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProducts_CartForwardsAuthorization(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer buyer" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"items":[{"product_id":3,"seller_id":9,"name":"Phone","quantity":2,"current_price":50,"available":true}],"subtotal":100}`))
+		case http.MethodDelete:
+			_, _ = w.Write([]byte(`{"items":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewProducts(srv.URL+"/", "", time.Second)
+
+	cart, err := c.Cart(context.Background(), "Bearer buyer")
+	if err != nil {
+		t.Fatalf("Cart() error = %v", err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].SellerID != 9 || cart.Subtotal != 100 {
+		t.Errorf("Cart() = %+v", cart)
+	}
+	if err := c.ClearCart(context.Background(), "Bearer buyer"); err != nil {
+		t.Errorf("ClearCart() error = %v", err)
+	}
+
+	if _, err := c.Cart(context.Background(), "Bearer someone"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Cart() with bad token error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestProducts_Reservations(t *testing.T) {
+	var reserved map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Internal-Token") != "internal" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/internal/reservations":
+			_ = json.NewDecoder(r.Body).Decode(&reserved)
+			if reserved["reference"] == "sold-out" {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case "/internal/reservations/r1/commit":
+			w.WriteHeader(http.StatusOK)
+		case "/internal/reservations/r1/release":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewProducts(srv.URL, "internal", time.Second)
+	ctx := context.Background()
+
+	if err := c.Reserve(ctx, "r1", []ReserveItem{{ProductID: 3, Quantity: 2}}); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if items, _ := reserved["items"].([]any); len(items) != 1 {
+		t.Errorf("reserve body = %v", reserved)
+	}
+	if err := c.Reserve(ctx, "sold-out", []ReserveItem{{ProductID: 3, Quantity: 1}}); !errors.Is(err, ErrOutOfStock) {
+		t.Errorf("Reserve() error = %v, want ErrOutOfStock", err)
+	}
+	if err := c.CommitReservation(ctx, "r1"); err != nil {
+		t.Errorf("CommitReservation() error = %v", err)
+	}
+	if err := c.ReleaseReservation(ctx, "r1"); !errors.Is(err, ErrReservationClosed) {
+		t.Errorf("ReleaseReservation() error = %v, want ErrReservationClosed", err)
+	}
+
+	bad := NewProducts(srv.URL, "wrong", time.Second)
+	if err := bad.CommitReservation(ctx, "r1"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("CommitReservation() with bad token error = %v, want ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	HTTPAddr     string
+	PGURL        string
+	JWTSecret    string
+	KafkaBrokers string
+	KafkaGroupID string
+
+	// ProductServiceURL is where buyer carts are read from at checkout.
+	ProductServiceURL string
+	ProductTimeout    time.Duration
+	// InternalToken authenticates stock reservation calls to product-service.
+	InternalToken string
+
+	// Payments go through a local fake provider that signs its webhooks
+	// with PaymentWebhookSecret and posts them to PaymentWebhookURL.
+	PaymentCurrency        string
+	PaymentWebhookSecret   string
+	PaymentWebhookURL      string
+	PaymentProcessingDelay time.Duration
+	// Payments pending longer than PaymentStuckAfter are checked with the
+	// provider every PaymentReconcileInterval.
+	PaymentReconcileInterval time.Duration
+	PaymentStuckAfter        time.Duration
+
+	// Checkout saga steps not answered within SagaStepTimeout are retried,
+	// up to SagaMaxAttempts times, by a sweep every SagaRecoveryInterval.
+	SagaStepTimeout      time.Duration
+	SagaMaxAttempts      int
+	SagaRecoveryInterval time.Duration
+}
+
+func MustLoad() *Config {
+	_ = godotenv.Load(".env")
+
+	cfg := &Config{
+		HTTPAddr:          getEnv("HTTP_ADDR", ":8083"),
+		PGURL:             mustEnv("PG_URL"),
+		JWTSecret:         mustEnv("JWT_SECRET"),
+		KafkaBrokers:      getEnv("KAFKA_BROKERS", "kafka:9092"),
+		KafkaGroupID:      getEnv("KAFKA_GROUP_ID", "order-service"),
+		ProductServiceURL: getEnv("PRODUCT_SERVICE_URL", "http://product-service:8081"),
+		ProductTimeout:    getDuration("PRODUCT_TIMEOUT", 5*time.Second),
+		InternalToken:     getEnv("INTERNAL_TOKEN", ""),
+
+		PaymentCurrency:          getEnv("PAYMENT_CURRENCY", "KZT"),
+		PaymentWebhookSecret:     mustEnv("PAYMENT_WEBHOOK_SECRET"),
+		PaymentWebhookURL:        getEnv("PAYMENT_WEBHOOK_URL", "http://localhost:8083/payments/webhook"),
+		PaymentProcessingDelay:   getDuration("PAYMENT_PROCESSING_DELAY", 5*time.Second),
+		PaymentReconcileInterval: getDuration("PAYMENT_RECONCILE_INTERVAL", time.Minute),
+		PaymentStuckAfter:        getDuration("PAYMENT_STUCK_AFTER", 10*time.Minute),
+
+		SagaStepTimeout:      getDuration("SAGA_STEP_TIMEOUT", 30*time.Second),
+		SagaMaxAttempts:      getInt("SAGA_MAX_ATTEMPTS", 5),
+		SagaRecoveryInterval: getDuration("SAGA_RECOVERY_INTERVAL", 10*time.Second),
+	}
+
+	return cfg
+}
+
+func mustEnv(k string) string {
+	v := os.Getenv(k)
+	if v == "" {
+		log.Fatalf("missing required env: %s", k)
+	}
+	return v
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+func getDuration(k string, def time.Duration) time.Duration {
+	if v := os.Getenv(k); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+func getInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return def
+}
@@ -0,0 +1,68 @@
+// Package consumer reads the Kafka topics order-service subscribes to.
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"GoOrder/internal/clients"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ReplyHandler moves checkout sagas on with command replies.
+type ReplyHandler interface {
+	HandleReply(ctx context.Context, r clients.Reply) error
+}
+
+// Replies reads product-service's replies to checkout commands.
+type Replies struct {
+	reader  *kafka.Reader
+	handler ReplyHandler
+}
+
+func NewReplies(brokers, topic, groupID string, handler ReplyHandler) *Replies {
+	log.Printf("Initializing reply consumer - brokers: %s, topic: %s, groupID: %s", brokers, topic, groupID)
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     strings.Split(brokers, ","),
+		Topic:       topic,
+		GroupID:     groupID,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		StartOffset: kafka.FirstOffset,
+	})
+	return &Replies{reader: reader, handler: handler}
+}
+
+func (c *Replies) Start(ctx context.Context) error {
+	for {
+		msg, err := c.reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return c.reader.Close()
+			}
+			log.Printf("replies: read error: %v", err)
+			continue
+		}
+		if err := c.handleMessage(ctx, msg.Value); err != nil {
+			log.Printf("replies: %v", err)
+		}
+	}
+}
+
+func (c *Replies) handleMessage(ctx context.Context, value []byte) error {
+	var r clients.Reply
+	if err := json.Unmarshal(value, &r); err != nil {
+		return fmt.Errorf("unmarshal error: %w", err)
+	}
+	if r.SagaID == "" {
+		return fmt.Errorf("reply %s without saga id", r.CommandID)
+	}
+	if err := c.handler.HandleReply(ctx, r); err != nil {
+		return fmt.Errorf("saga %s: %w", r.SagaID, err)
+	}
+	return nil
+}
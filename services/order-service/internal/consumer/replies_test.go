@@ -0,0 +1,38 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+
+	"GoOrder/internal/clients"
+)
+
+type recordingHandler struct {
+	got []clients.Reply
+}
+
+func (h *recordingHandler) HandleReply(_ context.Context, r clients.Reply) error {
+	h.got = append(h.got, r)
+	return nil
+}
+
+func TestReplies_HandleMessage(t *testing.T) {
+	h := &recordingHandler{}
+	c := &Replies{handler: h}
+	ctx := context.Background()
+
+	value := []byte(`{"command_id":"s1:reserve_stock:0","saga_id":"s1","type":"stock.reserve","ok":false,"error":"out_of_stock"}`)
+	if err := c.handleMessage(ctx, value); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if len(h.got) != 1 || h.got[0].SagaID != "s1" || h.got[0].OK || h.got[0].Error != clients.ReplyOutOfStock {
+		t.Fatalf("handled = %+v", h.got)
+	}
+
+	if err := c.handleMessage(ctx, []byte(`{"ok":true}`)); err == nil {
+		t.Errorf("reply without saga id accepted")
+	}
+	if err := c.handleMessage(ctx, []byte(`not json`)); err == nil {
+		t.Errorf("invalid payload accepted")
+	}
+}
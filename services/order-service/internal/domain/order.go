@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPendingPayment Status = "PENDING_PAYMENT"
+	StatusPaid           Status = "PAID"
+	StatusShipped        Status = "SHIPPED"
+	StatusDelivered      Status = "DELIVERED"
+	StatusCancelled      Status = "CANCELLED"
+	StatusRefunded       Status = "REFUNDED"
+)
+
+// ErrInvalidTransition is returned when a status change is not allowed by
+// the order state machine.
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// transitions is the order state machine. CANCELLED and REFUNDED are final;
+// money that has been taken is returned via REFUNDED, never CANCELLED.
+var transitions = map[Status][]Status{
+	StatusPendingPayment: {StatusPaid, StatusCancelled},
+	StatusPaid:           {StatusShipped, StatusRefunded},
+	StatusShipped:        {StatusDelivered, StatusRefunded},
+	StatusDelivered:      {StatusRefunded},
+}
+
+func IsKnownStatus(s Status) bool {
+	switch s {
+	case StatusPendingPayment, StatusPaid, StatusShipped, StatusDelivered, StatusCancelled, StatusRefunded:
+		return true
+	}
+	return false
+}
+
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Order is what a buyer owes one seller; checkout splits a cart into one
+// order per seller.
+type Order struct {
+	ID         uint        `gorm:"primaryKey;autoIncrement"`
+	BuyerID    uint        `gorm:"not null;index"`
+	BuyerEmail string      `gorm:"size:255;not null"`
+	SellerID   uint        `gorm:"not null;index"`
+	Status     Status      `gorm:"size:32;not null"`
+	Total      float64     `gorm:"not null"`
+	Items      []OrderItem `gorm:"foreignKey:OrderID"`
+	// ReservationRef names the product-service stock reservation holding
+	// this order's items until it is paid or cancelled.
+	ReservationRef string `gorm:"size:64"`
+	// SagaID is the checkout saga that created the order.
+	SagaID    string `gorm:"size:36;index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Transition moves the order to status to, enforcing the state machine.
+func (o *Order) Transition(to Status) error {
+	if !CanTransition(o.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, o.Status, to)
+	}
+	o.Status = to
+	return nil
+}
+
+// OrderItem snapshots the product name and price at checkout.
+type OrderItem struct {
+	ID        uint    `gorm:"primaryKey;autoIncrement"`
+	OrderID   uint    `gorm:"not null;index"`
+	ProductID uint    `gorm:"not null"`
+	Name      string  `gorm:"size:255;not null"`
+	UnitPrice float64 `gorm:"not null"`
+	Quantity  int     `gorm:"not null"`
+}
+
+func (i OrderItem) LineTotal() float64 {
+	return i.UnitPrice * float64(i.Quantity)
+}
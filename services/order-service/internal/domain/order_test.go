@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrder_Transition(t *testing.T) {
+	allowed := []struct{ from, to Status }{
+		{StatusPendingPayment, StatusPaid},
+		{StatusPendingPayment, StatusCancelled},
+		{StatusPaid, StatusShipped},
+		{StatusPaid, StatusRefunded},
+		{StatusShipped, StatusDelivered},
+		{StatusDelivered, StatusRefunded},
+	}
+	for _, tc := range allowed {
+		o := Order{Status: tc.from}
+		if err := o.Transition(tc.to); err != nil || o.Status != tc.to {
+			t.Errorf("%s -> %s: error = %v, status = %s", tc.from, tc.to, err, o.Status)
+		}
+	}
+
+	rejected := []struct{ from, to Status }{
+		{StatusPendingPayment, StatusShipped},
+		{StatusPaid, StatusCancelled},
+		{StatusShipped, StatusPaid},
+		{StatusDelivered, StatusShipped},
+		{StatusCancelled, StatusPaid},
+		{StatusRefunded, StatusDelivered},
+		{StatusPaid, StatusPaid},
+	}
+	for _, tc := range rejected {
+		o := Order{Status: tc.from}
+		if err := o.Transition(tc.to); !errors.Is(err, ErrInvalidTransition) || o.Status != tc.from {
+			t.Errorf("%s -> %s: error = %v, status = %s; want rejected", tc.from, tc.to, err, o.Status)
+		}
+	}
+}
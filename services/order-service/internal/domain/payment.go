@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentFailed    PaymentStatus = "failed"
+	PaymentRefunded  PaymentStatus = "refunded"
+)
+
+// Payment is one attempt to pay an order through a provider. A failed
+// attempt may be followed by another; at most one attempt succeeds.
+type Payment struct {
+	ID            uint          `gorm:"primaryKey;autoIncrement"`
+	OrderID       uint          `gorm:"not null;index"`
+	Provider      string        `gorm:"size:32;not null"`
+	IntentID      string        `gorm:"size:64;not null;uniqueIndex"`
+	Amount        int64         `gorm:"not null"`
+	Currency      string        `gorm:"size:3;not null"`
+	Status        PaymentStatus `gorm:"size:16;not null;index"`
+	FailureReason string        `gorm:"size:64"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// WebhookEvent records a processed provider event so redelivered webhooks
+// are ignored.
+type WebhookEvent struct {
+	ID         string `gorm:"primaryKey;size:64"`
+	Provider   string `gorm:"size:32;not null"`
+	Type       string `gorm:"size:32;not null"`
+	ReceivedAt time.Time
+}
+
+// MinorUnits converts an order total to the provider's integer amount,
+// e.g. 12.34 to 1234.
+func MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
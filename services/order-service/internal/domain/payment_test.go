@@ -0,0 +1,18 @@
+package domain
+
+import "testing"
+
+func TestMinorUnits(t *testing.T) {
+	cases := map[float64]int64{
+		0:      0,
+		12.34:  1234,
+		0.1:    10,
+		19.999: 2000,
+		1.005:  100, // 1.005 is stored as 1.00499..., like in the order total
+	}
+	for in, want := range cases {
+		if got := MinorUnits(in); got != want {
+			t.Errorf("MinorUnits(%v) = %d, want %d", in, got, want)
+		}
+	}
+}
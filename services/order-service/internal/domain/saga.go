@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+type SagaStatus string
+
+const (
+	SagaRunning      SagaStatus = "running"
+	SagaCompensating SagaStatus = "compensating"
+	SagaCompleted    SagaStatus = "completed"
+	SagaFailed       SagaStatus = "failed"
+)
+
+// SagaStep is what a checkout saga is doing or waiting for. Forward steps
+// run in order reserve, authorize, capture, clear cart; when one fails the
+// saga undoes its work with void, release and cancel.
+type SagaStep string
+
+const (
+	StepReserveStock     SagaStep = "reserve_stock"
+	StepAuthorizePayment SagaStep = "authorize_payment"
+	StepCapturePayment   SagaStep = "capture_payment"
+	StepClearCart        SagaStep = "clear_cart"
+
+	StepVoidPayment  SagaStep = "void_payment"
+	StepReleaseStock SagaStep = "release_stock"
+	StepCancelOrders SagaStep = "cancel_orders"
+)
+
+// Saga is the persisted state of one checkout. It outlives restarts: a step
+// whose Deadline passes is retried or, after too many attempts, compensated.
+type Saga struct {
+	ID         string `gorm:"primaryKey;size:36"`
+	BuyerID    uint   `gorm:"not null;index"`
+	BuyerEmail string `gorm:"size:255;not null"`
+	// PaymentMethod is the provider's reference for the buyer's card, see
+	// payments.Provider.CreatePaymentMethod. The card number itself is never
+	// stored. Without one the orders are left for the buyer to pay later.
+	PaymentMethod string     `gorm:"size:64"`
+	Status        SagaStatus `gorm:"size:16;not null;index"`
+	Step          SagaStep   `gorm:"size:32;not null"`
+	// Placed is set once stock is reserved and the orders are announced.
+	Placed   bool      `gorm:"not null"`
+	Attempts int       `gorm:"not null"`
+	Deadline time.Time `gorm:"index"`
+	// Error is why the saga is compensating, e.g. out_of_stock.
+	Error     string `gorm:"size:64"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (s *Saga) Finished() bool {
+	return s.Status == SagaCompleted || s.Status == SagaFailed
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"GoOrder/internal/http/middleware"
+	"GoOrder/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CheckoutHandler struct {
+	svc *service.CheckoutService
+}
+
+func NewCheckoutHandler(svc *service.CheckoutService) *CheckoutHandler {
+	return &CheckoutHandler{svc: svc}
+}
+
+type checkoutReq struct {
+	Card string `json:"card" binding:"omitempty,numeric,min=12,max=19"`
+}
+
+type checkoutResp struct {
+	ID        string      `json:"id"`
+	Status    string      `json:"status"`
+	Step      string      `json:"step"`
+	Error     string      `json:"error,omitempty"`
+	Orders    []orderResp `json:"orders"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Start begins a checkout and answers 202: the saga goes on in the
+// background and its progress is polled at GET /orders/checkout/:id.
+func (h *CheckoutHandler) Start(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	email, _ := c.Get(middleware.EmailKey)
+	buyerEmail, _ := email.(string)
+
+	// Тело необязательно: без карты заказы оплачиваются позже.
+	var req checkoutReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	co, err := h.svc.Start(c.Request.Context(), service.CheckoutInput{
+		BuyerID:       userID,
+		BuyerEmail:    buyerEmail,
+		Authorization: c.GetHeader("Authorization"),
+		Card:          req.Card,
+	})
+	if err != nil {
+		switch {
+		case service.IsEmptyCart(err):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "cart is empty"})
+		case service.IsUnavailableItems(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "cart has unavailable items"})
+		case service.IsCheckoutInProgress(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "another checkout is in progress"})
+		case service.IsProductUnauthorized(err):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "checkout failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toCheckoutResp(co))
+}
+
+func (h *CheckoutHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	co, err := h.svc.Get(userID, c.Param("id"))
+	if err != nil {
+		if service.IsCheckoutNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toCheckoutResp(co))
+}
+
+func toCheckoutResp(co *service.Checkout) checkoutResp {
+	resp := checkoutResp{
+		ID:        co.Saga.ID,
+		Status:    string(co.Saga.Status),
+		Step:      string(co.Saga.Step),
+		Error:     co.Saga.Error,
+		Orders:    make([]orderResp, 0, len(co.Orders)),
+		CreatedAt: co.Saga.CreatedAt,
+		UpdatedAt: co.Saga.UpdatedAt,
+	}
+	for i := range co.Orders {
+		resp.Orders = append(resp.Orders, toOrderResp(&co.Orders[i]))
+	}
+	return resp
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"GoOrder/internal/domain"
+	"GoOrder/internal/http/middleware"
+	"GoOrder/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler struct {
+	svc *service.OrderService
+}
+
+func NewOrderHandler(svc *service.OrderService) *OrderHandler {
+	return &OrderHandler{svc: svc}
+}
+
+type changeStatusReq struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type orderItemResp struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	UnitPrice float64 `json:"unit_price"`
+	Quantity  int     `json:"quantity"`
+	LineTotal float64 `json:"line_total"`
+}
+
+type orderResp struct {
+	ID        uint            `json:"id"`
+	BuyerID   uint            `json:"buyer_id"`
+	SellerID  uint            `json:"seller_id"`
+	Status    string          `json:"status"`
+	Total     float64         `json:"total"`
+	Items     []orderItemResp `json:"items"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (h *OrderHandler) ListMine(c *gin.Context) {
+	h.list(c, h.svc.ListForBuyer)
+}
+
+func (h *OrderHandler) ListSelling(c *gin.Context) {
+	h.list(c, h.svc.ListForSeller)
+}
+
+func (h *OrderHandler) list(c *gin.Context, fetch func(service.ListOrdersInput) ([]domain.Order, error)) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	in := service.ListOrdersInput{UserID: userID}
+	if raw := c.Query("status"); raw != "" {
+		in.Status = domain.Status(raw)
+		if !domain.IsKnownStatus(in.Status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+	}
+	if raw := c.Query("seller_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid seller_id"})
+			return
+		}
+		in.SellerID = uint(id)
+	}
+	if raw := c.Query("before"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid before"})
+			return
+		}
+		in.BeforeID = uint(id)
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		in.Limit = limit
+	}
+
+	orders, err := fetch(in)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	resp := make([]orderResp, 0, len(orders))
+	for i := range orders {
+		resp = append(resp, toOrderResp(&orders[i]))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OrderHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	o, err := h.svc.Get(userID, id)
+	if err != nil {
+		if service.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toOrderResp(o))
+}
+
+func (h *OrderHandler) ChangeStatus(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req changeStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status := domain.Status(req.Status)
+	if !domain.IsKnownStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	o, err := h.svc.ChangeStatus(c.Request.Context(), service.ChangeStatusInput{
+		OrderID: id,
+		ActorID: userID,
+		Status:  status,
+	})
+	if err != nil {
+		switch {
+		case service.IsNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case service.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to set this status"})
+		case service.IsInvalidTransition(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "invalid status transition"})
+		case service.IsConflict(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "order status changed, reload and retry"})
+		case service.IsCheckoutInProgress(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "order checkout is still in progress"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toOrderResp(o))
+}
+
+func toOrderResp(o *domain.Order) orderResp {
+	resp := orderResp{
+		ID:        o.ID,
+		BuyerID:   o.BuyerID,
+		SellerID:  o.SellerID,
+		Status:    string(o.Status),
+		Total:     o.Total,
+		Items:     make([]orderItemResp, 0, len(o.Items)),
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+	for _, it := range o.Items {
+		resp.Items = append(resp.Items, orderItemResp{
+			ProductID: it.ProductID,
+			Name:      it.Name,
+			UnitPrice: it.UnitPrice,
+			Quantity:  it.Quantity,
+			LineTotal: it.LineTotal(),
+		})
+	}
+	return resp
+}
+
+func orderIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func currentUserID(c *gin.Context) (uint, bool) {
+	userIDRaw, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return 0, false
+	}
+
+	userID, ok := userIDRaw.(uint)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id type"})
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,224 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"GoOrder/internal/clients"
+	"GoOrder/internal/domain"
+	"GoOrder/internal/http/handlers"
+	"GoOrder/internal/http/middleware"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/repo"
+	"GoOrder/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type stubCarts struct {
+	cart clients.Cart
+}
+
+func (s *stubCarts) Cart(_ context.Context, auth string) (*clients.Cart, error) {
+	if auth != "Bearer buyer" {
+		return nil, clients.ErrUnauthorized
+	}
+	c := s.cart
+	return &c, nil
+}
+
+// syncCommands answers checkout commands at once, the way product-service
+// would when every item is in stock.
+type syncCommands struct {
+	carts    *stubCarts
+	checkout *service.CheckoutService
+}
+
+func (s *syncCommands) Send(ctx context.Context, _, _ string, value interface{}) error {
+	cmd := value.(clients.Command)
+	if cmd.Type == clients.CommandClearCart {
+		s.carts.cart.Items = nil
+	}
+	return s.checkout.HandleReply(ctx, clients.Reply{CommandID: cmd.ID, SagaID: cmd.SagaID, Type: cmd.Type, OK: true})
+}
+
+func setupOrderServer(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Order{}, &domain.OrderItem{}, &domain.Payment{}, &domain.WebhookEvent{}, &domain.Saga{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+
+	carts := &stubCarts{cart: clients.Cart{Items: []clients.CartItem{
+		{ProductID: 1, SellerID: 10, Name: "Phone", Quantity: 2, CurrentPrice: 50, Available: true},
+	}}}
+	orderSvc := service.NewOrderService(repo.NewOrders(db), nil, nil)
+	h := handlers.NewOrderHandler(orderSvc)
+	paySvc := service.NewPaymentService(repo.NewPayments(db), orderSvc, payments.NewFake(testWebhookSecret, "", 0), "KZT")
+	payH := handlers.NewPaymentHandler(paySvc)
+
+	commands := &syncCommands{carts: carts}
+	commands.checkout = service.NewCheckoutService(repo.NewSagas(db), orderSvc, paySvc, carts, commands, service.SagaConfig{
+		StepTimeout: time.Minute,
+		MaxAttempts: 3,
+	})
+	checkoutH := handlers.NewCheckoutHandler(commands.checkout)
+
+	r := gin.New()
+
+	// заглушка авторизации: пользователь задаётся заголовком X-User-ID
+	authBypass := func(c *gin.Context) {
+		id, _ := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64)
+		c.Set(middleware.UserIDKey, uint(id))
+		c.Set(middleware.EmailKey, "buyer@example.com")
+		c.Next()
+	}
+
+	orders := r.Group("/orders", authBypass)
+	{
+		orders.GET("", h.ListMine)
+		orders.GET("/:id", h.Get)
+		orders.PATCH("/:id/status", h.ChangeStatus)
+		orders.POST("/checkout", checkoutH.Start)
+		orders.GET("/checkout/:id", checkoutH.Get)
+		orders.GET("/:id/payment", payH.Get)
+		orders.POST("/:id/payment", payH.Pay)
+	}
+	r.GET("/seller/orders", authBypass, h.ListSelling)
+	r.POST("/seller/orders/:id/refund", authBypass, payH.Refund)
+	r.POST("/payments/webhook", payH.Webhook)
+	return r
+}
+
+func doOrderRequest(r *gin.Engine, method, path, userID string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer buyer")
+	req.Header.Set("X-User-ID", userID)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+type orderBody struct {
+	ID       uint    `json:"id"`
+	SellerID uint    `json:"seller_id"`
+	Status   string  `json:"status"`
+	Total    float64 `json:"total"`
+}
+
+type checkoutBody struct {
+	ID     string      `json:"id"`
+	Status string      `json:"status"`
+	Error  string      `json:"error"`
+	Orders []orderBody `json:"orders"`
+}
+
+func TestOrderHandler_CheckoutAndLifecycle(t *testing.T) {
+	r := setupOrderServer(t)
+
+	w := doOrderRequest(r, http.MethodPost, "/orders/checkout", "1", nil)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("checkout status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var created checkoutBody
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Status != "completed" || len(created.Orders) != 1 || created.Orders[0].Total != 100 || created.Orders[0].Status != "PENDING_PAYMENT" {
+		t.Fatalf("checkout = %+v", created)
+	}
+	id := strconv.Itoa(int(created.Orders[0].ID))
+
+	var polled checkoutBody
+	w = doOrderRequest(r, http.MethodGet, "/orders/checkout/"+created.ID, "1", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &polled)
+	if w.Code != http.StatusOK || polled.ID != created.ID || len(polled.Orders) != 1 {
+		t.Errorf("GET checkout = %d, body = %s", w.Code, w.Body.String())
+	}
+	if w := doOrderRequest(r, http.MethodGet, "/orders/checkout/"+created.ID, "2", nil); w.Code != http.StatusNotFound {
+		t.Errorf("stranger GET checkout status = %d, want 404", w.Code)
+	}
+
+	if w := doOrderRequest(r, http.MethodPost, "/orders/checkout", "1", nil); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("second checkout status = %d, want 422", w.Code)
+	}
+
+	var list []orderBody
+	w = doOrderRequest(r, http.MethodGet, "/seller/orders?status=PENDING_PAYMENT", "10", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 1 {
+		t.Fatalf("seller list status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	if w := doOrderRequest(r, http.MethodGet, "/orders/"+id, "2", nil); w.Code != http.StatusNotFound {
+		t.Errorf("stranger GET status = %d, want 404", w.Code)
+	}
+	if w := doOrderRequest(r, http.MethodPatch, "/orders/"+id+"/status", "10", gin.H{"status": "SHIPPED"}); w.Code != http.StatusConflict {
+		t.Errorf("ship unpaid status = %d, want 409", w.Code)
+	}
+	if w := doOrderRequest(r, http.MethodPatch, "/orders/"+id+"/status", "1", gin.H{"status": "PAID"}); w.Code != http.StatusForbidden {
+		t.Errorf("buyer pay status = %d, want 403", w.Code)
+	}
+	if w := doOrderRequest(r, http.MethodPatch, "/orders/"+id+"/status", "1", gin.H{"status": "LOST"}); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown status = %d, want 400", w.Code)
+	}
+
+	w = doOrderRequest(r, http.MethodPatch, "/orders/"+id+"/status", "1", gin.H{"status": "CANCELLED"})
+	var got orderBody
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != http.StatusOK || got.Status != "CANCELLED" {
+		t.Fatalf("cancel status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = doOrderRequest(r, http.MethodGet, "/orders", "1", nil)
+	list = nil
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Status != "CANCELLED" {
+		t.Errorf("buyer list = %+v", list)
+	}
+	// заказы покупателя у одного продавца
+	for query, want := range map[string]int{"seller_id=10": 1, "seller_id=11": 0} {
+		list = nil
+		w = doOrderRequest(r, http.MethodGet, "/orders?"+query, "1", nil)
+		_ = json.Unmarshal(w.Body.Bytes(), &list)
+		if w.Code != http.StatusOK || len(list) != want {
+			t.Errorf("buyer list ?%s = %d %s, want %d orders", query, w.Code, w.Body.String(), want)
+		}
+	}
+	if w := doOrderRequest(r, http.MethodGet, "/orders?seller_id=x", "1", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid seller_id status = %d, want 400", w.Code)
+	}
+}
+
+func TestOrderHandler_CheckoutWithCard(t *testing.T) {
+	r := setupOrderServer(t)
+
+	if w := doOrderRequest(r, http.MethodPost, "/orders/checkout", "1", gin.H{"card": "not-a-card"}); w.Code != http.StatusBadRequest {
+		t.Errorf("bad card status = %d, want 400", w.Code)
+	}
+
+	var co checkoutBody
+	w := doOrderRequest(r, http.MethodPost, "/orders/checkout", "1", gin.H{"card": payments.CardSuccess})
+	_ = json.Unmarshal(w.Body.Bytes(), &co)
+	if w.Code != http.StatusAccepted || co.Status != "completed" || len(co.Orders) != 1 || co.Orders[0].Status != "PAID" {
+		t.Fatalf("checkout = %d, body = %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"GoOrder/internal/domain"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/service"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody bounds webhook payloads; provider events are small.
+const maxWebhookBody = 64 << 10
+
+type PaymentHandler struct {
+	svc *service.PaymentService
+}
+
+func NewPaymentHandler(svc *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{svc: svc}
+}
+
+type payReq struct {
+	Card string `json:"card" binding:"required,numeric,min=12,max=19"`
+}
+
+type paymentResp struct {
+	ID            uint      `json:"id"`
+	OrderID       uint      `json:"order_id"`
+	Status        string    `json:"status"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (h *PaymentHandler) Pay(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req payReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.svc.Pay(c.Request.Context(), service.PayInput{BuyerID: userID, OrderID: id, Card: req.Card})
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if p.Status == domain.PaymentFailed {
+		status = http.StatusPaymentRequired
+	}
+	c.JSON(status, toPaymentResp(p))
+}
+
+func (h *PaymentHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.svc.Latest(userID, id)
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toPaymentResp(p))
+}
+
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.svc.Refund(c.Request.Context(), userID, id)
+	if err != nil {
+		writePaymentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toPaymentResp(p))
+}
+
+// Webhook receives provider events. It is not behind auth; the signature
+// is the authentication.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read body"})
+		return
+	}
+
+	err = h.svc.HandleWebhook(c.Request.Context(), payload, c.GetHeader(payments.SignatureHeader))
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case service.IsInvalidSignature(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid signature"})
+	case service.IsPaymentNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown payment"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
+
+func writePaymentError(c *gin.Context, err error) {
+	switch {
+	case service.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case service.IsPaymentNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "no payment for this order"})
+	case service.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the seller can refund"})
+	case service.IsCheckoutInProgress(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "order checkout is still in progress"})
+	case service.IsNotPayable(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "order is not awaiting payment"})
+	case service.IsNotRefundable(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "order cannot be refunded"})
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider error"})
+	}
+}
+
+func toPaymentResp(p *domain.Payment) paymentResp {
+	return paymentResp{
+		ID:            p.ID,
+		OrderID:       p.OrderID,
+		Status:        string(p.Status),
+		Amount:        p.Amount,
+		Currency:      p.Currency,
+		FailureReason: p.FailureReason,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"GoOrder/internal/payments"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testWebhookSecret = "whsec"
+
+type paymentBody struct {
+	Status        string `json:"status"`
+	Amount        int64  `json:"amount"`
+	FailureReason string `json:"failure_reason"`
+}
+
+func TestPaymentHandler_PayAndRefund(t *testing.T) {
+	r := setupOrderServer(t)
+
+	w := doOrderRequest(r, http.MethodPost, "/orders/checkout", "1", nil)
+	var created struct {
+		Orders []orderBody `json:"orders"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	id := strconv.Itoa(int(created.Orders[0].ID))
+
+	if w := doOrderRequest(r, http.MethodGet, "/orders/"+id+"/payment", "1", nil); w.Code != http.StatusNotFound {
+		t.Errorf("payment before paying status = %d, want 404", w.Code)
+	}
+	if w := doOrderRequest(r, http.MethodPost, "/orders/"+id+"/payment", "1", gin.H{"card": "not-a-card"}); w.Code != http.StatusBadRequest {
+		t.Errorf("bad card status = %d, want 400", w.Code)
+	}
+
+	var p paymentBody
+	w = doOrderRequest(r, http.MethodPost, "/orders/"+id+"/payment", "1", gin.H{"card": payments.CardInsufficientFunds})
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusPaymentRequired || p.FailureReason != "insufficient_funds" {
+		t.Fatalf("declined pay = %d %s", w.Code, w.Body.String())
+	}
+
+	w = doOrderRequest(r, http.MethodPost, "/orders/"+id+"/payment", "1", gin.H{"card": payments.CardSuccess})
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusOK || p.Status != "succeeded" || p.Amount != 10000 {
+		t.Fatalf("pay = %d %s", w.Code, w.Body.String())
+	}
+	if w := doOrderRequest(r, http.MethodPost, "/orders/"+id+"/payment", "1", gin.H{"card": payments.CardSuccess}); w.Code != http.StatusConflict {
+		t.Errorf("second pay status = %d, want 409", w.Code)
+	}
+
+	if w := doOrderRequest(r, http.MethodPost, "/seller/orders/"+id+"/refund", "1", nil); w.Code != http.StatusForbidden {
+		t.Errorf("buyer refund status = %d, want 403", w.Code)
+	}
+	w = doOrderRequest(r, http.MethodPost, "/seller/orders/"+id+"/refund", "10", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusOK || p.Status != "refunded" {
+		t.Fatalf("refund = %d %s", w.Code, w.Body.String())
+	}
+
+	var o orderBody
+	w = doOrderRequest(r, http.MethodGet, "/orders/"+id, "1", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &o)
+	if o.Status != "REFUNDED" {
+		t.Errorf("order status = %s, want REFUNDED", o.Status)
+	}
+}
+
+func TestPaymentHandler_Webhook(t *testing.T) {
+	r := setupOrderServer(t)
+
+	post := func(payload []byte, sig string) int {
+		req := httptest.NewRequest(http.MethodPost, "/payments/webhook", bytes.NewReader(payload))
+		req.Header.Set(payments.SignatureHeader, sig)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	payload, _ := json.Marshal(payments.Event{ID: "evt_1", Type: payments.EventSucceeded, Intent: payments.Intent{ID: "pi_unknown"}})
+	if code := post(payload, "t=1,v1=00"); code != http.StatusBadRequest {
+		t.Errorf("unsigned webhook status = %d, want 400", code)
+	}
+	if code := post(payload, payments.Sign(testWebhookSecret, payload, time.Now())); code != http.StatusNotFound {
+		t.Errorf("unknown intent status = %d, want 404", code)
+	}
+}
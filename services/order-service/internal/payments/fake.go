@@ -0,0 +1,256 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Test cards understood by Fake. Any other number behaves like CardSuccess.
+const (
+	CardSuccess           = "4242424242424242"
+	CardDeclined          = "4000000000000002"
+	CardInsufficientFunds = "4000000000009995"
+	// CardProcessing stays processing for the fake's processing delay, then
+	// succeeds and the webhook is sent.
+	CardProcessing = "4000000000000077"
+	// CardNoWebhook settles like CardProcessing but its webhook is never
+	// sent, so only reconciliation notices the payment.
+	CardNoWebhook = "4000000000000341"
+)
+
+// Fake is an in-memory provider for development and tests. It signs and
+// POSTs webhooks to webhookURL when one is set.
+type Fake struct {
+	secret     string
+	webhookURL string
+	delay      time.Duration
+	client     *http.Client
+
+	mu      sync.Mutex
+	intents map[string]*fakeIntent
+	byRef   map[string]string
+	methods map[string]string
+}
+
+type fakeIntent struct {
+	Intent
+	card    string
+	readyAt time.Time
+}
+
+func NewFake(secret, webhookURL string, processingDelay time.Duration) *Fake {
+	return &Fake{
+		secret:     secret,
+		webhookURL: webhookURL,
+		delay:      processingDelay,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		intents:    make(map[string]*fakeIntent),
+		byRef:      make(map[string]string),
+		methods:    make(map[string]string),
+	}
+}
+
+func (f *Fake) Name() string { return "fake" }
+
+func (f *Fake) CreatePaymentMethod(_ context.Context, card string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := "pm_" + randomHex(12)
+	f.methods[id] = card
+	return id, nil
+}
+
+func (f *Fake) CreateIntent(_ context.Context, in CreateIntentInput) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := f.byRef[in.Reference]; ok {
+		i := f.intents[id].Intent
+		return &i, nil
+	}
+	if in.PaymentMethod != "" {
+		card, ok := f.methods[in.PaymentMethod]
+		if !ok {
+			return nil, ErrUnknownPaymentMethod
+		}
+		in.Card = card
+	}
+
+	fi := &fakeIntent{
+		Intent: Intent{
+			ID:        "pi_" + randomHex(12),
+			Reference: in.Reference,
+			Amount:    in.Amount,
+			Currency:  in.Currency,
+		},
+		card: in.Card,
+	}
+	switch in.Card {
+	case CardDeclined:
+		fi.Status, fi.FailureReason = IntentFailed, "card_declined"
+	case CardInsufficientFunds:
+		fi.Status, fi.FailureReason = IntentFailed, "insufficient_funds"
+	case CardProcessing, CardNoWebhook:
+		fi.Status = IntentProcessing
+		fi.readyAt = time.Now().Add(f.delay)
+		if in.Card == CardProcessing {
+			time.AfterFunc(f.delay, func() { f.settleAndNotify(fi.ID) })
+		}
+	default:
+		fi.Status = IntentRequiresCapture
+	}
+	f.intents[fi.ID] = fi
+	f.byRef[in.Reference] = fi.ID
+
+	if fi.Status == IntentFailed {
+		f.notify(EventFailed, fi.Intent)
+	}
+	i := fi.Intent
+	return &i, nil
+}
+
+func (f *Fake) Capture(_ context.Context, id string) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, ok := f.intents[id]
+	if !ok {
+		return nil, ErrUnknownIntent
+	}
+	switch fi.Status {
+	case IntentSucceeded:
+	case IntentRequiresCapture:
+		fi.Status = IntentSucceeded
+		f.notify(EventSucceeded, fi.Intent)
+	default:
+		return nil, ErrNotCapturable
+	}
+	i := fi.Intent
+	return &i, nil
+}
+
+func (f *Fake) Cancel(_ context.Context, id string) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, ok := f.intents[id]
+	if !ok {
+		return nil, ErrUnknownIntent
+	}
+	switch fi.Status {
+	case IntentCanceled:
+	case IntentRequiresCapture:
+		fi.Status, fi.FailureReason = IntentCanceled, "voided"
+		f.notify(EventCanceled, fi.Intent)
+	default:
+		return nil, ErrNotCancelable
+	}
+	i := fi.Intent
+	return &i, nil
+}
+
+func (f *Fake) Refund(_ context.Context, id string) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, ok := f.intents[id]
+	if !ok {
+		return nil, ErrUnknownIntent
+	}
+	f.settle(fi)
+	switch fi.Status {
+	case IntentRefunded:
+	case IntentSucceeded:
+		fi.Status = IntentRefunded
+		f.notify(EventRefunded, fi.Intent)
+	default:
+		return nil, ErrNotRefundable
+	}
+	i := fi.Intent
+	return &i, nil
+}
+
+func (f *Fake) Intent(_ context.Context, id string) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, ok := f.intents[id]
+	if !ok {
+		return nil, ErrUnknownIntent
+	}
+	f.settle(fi)
+	i := fi.Intent
+	return &i, nil
+}
+
+func (f *Fake) VerifyWebhook(payload []byte, signature string) (*Event, error) {
+	if err := Verify(f.secret, payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+	var ev Event
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return &ev, nil
+}
+
+// settle finishes a processing intent once its delay has passed.
+func (f *Fake) settle(fi *fakeIntent) bool {
+	if fi.Status != IntentProcessing || time.Now().Before(fi.readyAt) {
+		return false
+	}
+	fi.Status = IntentSucceeded
+	return true
+}
+
+func (f *Fake) settleAndNotify(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi := f.intents[id]
+	f.settle(fi)
+	if fi.Status == IntentSucceeded && fi.card != CardNoWebhook {
+		f.notify(EventSucceeded, fi.Intent)
+	}
+}
+
+// notify delivers an event in the background, like a real provider would.
+// Delivery is attempted once; a lost webhook is what reconciliation is for.
+func (f *Fake) notify(typ EventType, i Intent) {
+	if f.webhookURL == "" {
+		return
+	}
+	payload, err := json.Marshal(Event{ID: "evt_" + randomHex(12), Type: typ, Intent: i, CreatedAt: time.Now()})
+	if err != nil {
+		return
+	}
+	go func() {
+		req, err := http.NewRequest(http.MethodPost, f.webhookURL, bytes.NewReader(payload))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SignatureHeader, Sign(f.secret, payload, time.Now()))
+
+		resp, err := f.client.Do(req)
+		if err != nil {
+			log.Printf("fake payments: webhook %s: %v", typ, err)
+			return
+		}
+		resp.Body.Close()
+	}()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
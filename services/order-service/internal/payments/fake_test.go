@@ -0,0 +1,141 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+	sig := Sign("secret", payload, now)
+
+	if err := Verify("secret", payload, sig, now); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	cases := map[string]struct {
+		secret  string
+		payload []byte
+		header  string
+		now     time.Time
+	}{
+		"wrong secret":     {"other", payload, sig, now},
+		"tampered payload": {"secret", []byte(`{"id":"evt_2"}`), sig, now},
+		"too old":          {"secret", payload, sig, now.Add(SignatureTolerance + time.Second)},
+		"garbage header":   {"secret", payload, "v1=abc", now},
+	}
+	for name, tc := range cases {
+		if err := Verify(tc.secret, tc.payload, tc.header, tc.now); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Verify() error = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+}
+
+func TestFake_PaymentMethods(t *testing.T) {
+	f := NewFake("secret", "", 0)
+	ctx := context.Background()
+
+	pm, err := f.CreatePaymentMethod(ctx, CardDeclined)
+	if err != nil || !strings.HasPrefix(pm, "pm_") {
+		t.Fatalf("CreatePaymentMethod() = %q, %v", pm, err)
+	}
+	i, err := f.CreateIntent(ctx, CreateIntentInput{Reference: "a", Amount: 100, PaymentMethod: pm})
+	if err != nil || i.Status != IntentFailed {
+		t.Errorf("intent by payment method = %+v, %v; want the declined card's outcome", i, err)
+	}
+	if _, err := f.CreateIntent(ctx, CreateIntentInput{Reference: "b", Amount: 100, PaymentMethod: "pm_unknown"}); !errors.Is(err, ErrUnknownPaymentMethod) {
+		t.Errorf("unknown payment method error = %v", err)
+	}
+}
+
+func TestFake_TestCards(t *testing.T) {
+	f := NewFake("secret", "", 0)
+	ctx := context.Background()
+
+	ok, _ := f.CreateIntent(ctx, CreateIntentInput{Reference: "a", Amount: 100, Currency: "KZT", Card: CardSuccess})
+	if ok.Status != IntentRequiresCapture {
+		t.Fatalf("success card status = %s, want requires_capture", ok.Status)
+	}
+	again, _ := f.CreateIntent(ctx, CreateIntentInput{Reference: "a", Amount: 100, Currency: "KZT", Card: CardDeclined})
+	if again.ID != ok.ID {
+		t.Errorf("CreateIntent() with the same reference made a new intent")
+	}
+	captured, err := f.Capture(ctx, ok.ID)
+	if err != nil || captured.Status != IntentSucceeded {
+		t.Fatalf("Capture() = %+v, %v", captured, err)
+	}
+	refunded, err := f.Refund(ctx, ok.ID)
+	if err != nil || refunded.Status != IntentRefunded {
+		t.Fatalf("Refund() = %+v, %v", refunded, err)
+	}
+
+	declined, _ := f.CreateIntent(ctx, CreateIntentInput{Reference: "b", Amount: 100, Card: CardDeclined})
+	if declined.Status != IntentFailed || declined.FailureReason != "card_declined" {
+		t.Errorf("declined card = %+v", declined)
+	}
+	if _, err := f.Capture(ctx, declined.ID); !errors.Is(err, ErrNotCapturable) {
+		t.Errorf("Capture() of declined intent error = %v", err)
+	}
+	poor, _ := f.CreateIntent(ctx, CreateIntentInput{Reference: "c", Amount: 100, Card: CardInsufficientFunds})
+	if poor.FailureReason != "insufficient_funds" {
+		t.Errorf("insufficient funds card = %+v", poor)
+	}
+
+	slow, _ := f.CreateIntent(ctx, CreateIntentInput{Reference: "d", Amount: 100, Card: CardNoWebhook})
+	if slow.Status != IntentProcessing {
+		t.Errorf("processing card status = %s", slow.Status)
+	}
+	if got, _ := f.Intent(ctx, slow.ID); got.Status != IntentSucceeded {
+		t.Errorf("processing intent after delay = %s, want succeeded", got.Status)
+	}
+
+	held, _ := f.CreateIntent(ctx, CreateIntentInput{Reference: "e", Amount: 100, Card: CardSuccess})
+	voided, err := f.Cancel(ctx, held.ID)
+	if err != nil || voided.Status != IntentCanceled {
+		t.Fatalf("Cancel() = %+v, %v", voided, err)
+	}
+	if _, err := f.Capture(ctx, held.ID); !errors.Is(err, ErrNotCapturable) {
+		t.Errorf("Capture() of canceled intent error = %v", err)
+	}
+	if _, err := f.Cancel(ctx, ok.ID); !errors.Is(err, ErrNotCancelable) {
+		t.Errorf("Cancel() of refunded intent error = %v", err)
+	}
+
+	if _, err := f.Intent(ctx, "pi_missing"); !errors.Is(err, ErrUnknownIntent) {
+		t.Errorf("Intent() of unknown id error = %v", err)
+	}
+}
+
+func TestFake_DeliversSignedWebhooks(t *testing.T) {
+	got := make(chan *Event, 1)
+	var f *Fake
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ev, err := f.VerifyWebhook(body, r.Header.Get(SignatureHeader))
+		if err != nil {
+			t.Errorf("VerifyWebhook() error = %v", err)
+			return
+		}
+		got <- ev
+	}))
+	defer srv.Close()
+
+	f = NewFake("secret", srv.URL, 10*time.Millisecond)
+	i, _ := f.CreateIntent(context.Background(), CreateIntentInput{Reference: "a", Amount: 100, Card: CardProcessing})
+
+	select {
+	case ev := <-got:
+		if ev.Type != EventSucceeded || ev.Intent.ID != i.ID {
+			t.Errorf("event = %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("webhook not delivered")
+	}
+}
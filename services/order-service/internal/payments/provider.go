@@ -0,0 +1,143 @@
+// Package payments abstracts the payment provider behind Provider so order
+// processing does not depend on a particular gateway.
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownIntent    = errors.New("payments: unknown intent")
+	ErrNotCapturable    = errors.New("payments: intent cannot be captured")
+	ErrNotRefundable    = errors.New("payments: intent cannot be refunded")
+	ErrNotCancelable    = errors.New("payments: intent cannot be canceled")
+	ErrInvalidSignature = errors.New("payments: invalid webhook signature")
+	// ErrUnknownPaymentMethod is returned for a payment method reference
+	// the provider did not issue.
+	ErrUnknownPaymentMethod = errors.New("payments: unknown payment method")
+)
+
+type IntentStatus string
+
+const (
+	// IntentProcessing is waiting for the provider; the final status arrives
+	// by webhook or is found by polling.
+	IntentProcessing      IntentStatus = "processing"
+	IntentRequiresCapture IntentStatus = "requires_capture"
+	IntentSucceeded       IntentStatus = "succeeded"
+	IntentFailed          IntentStatus = "failed"
+	IntentRefunded        IntentStatus = "refunded"
+	// IntentCanceled was voided before capture; no money was taken.
+	IntentCanceled IntentStatus = "canceled"
+)
+
+// Intent is one attempt to take Amount minor units (e.g. tiyn) from a card.
+type Intent struct {
+	ID            string       `json:"id"`
+	Reference     string       `json:"reference"`
+	Amount        int64        `json:"amount"`
+	Currency      string       `json:"currency"`
+	Status        IntentStatus `json:"status"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+}
+
+type CreateIntentInput struct {
+	// Reference is the idempotency key: creating an intent with a known
+	// reference returns the existing intent.
+	Reference string
+	Amount    int64
+	Currency  string
+	// Card is charged directly. PaymentMethod, a reference returned by
+	// CreatePaymentMethod, is used instead when set.
+	Card          string
+	PaymentMethod string
+}
+
+type EventType string
+
+const (
+	EventSucceeded EventType = "payment.succeeded"
+	EventFailed    EventType = "payment.failed"
+	EventRefunded  EventType = "payment.refunded"
+	EventCanceled  EventType = "payment.canceled"
+)
+
+// Event is a webhook notification about an intent. Providers may deliver
+// the same event more than once.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Intent    Intent    `json:"intent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Provider interface {
+	Name() string
+	// CreatePaymentMethod hands a card to the provider and returns a
+	// reference to charge it by later, so callers never keep the number.
+	CreatePaymentMethod(ctx context.Context, card string) (string, error)
+	CreateIntent(ctx context.Context, in CreateIntentInput) (*Intent, error)
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	// Cancel voids an intent that has not been captured yet.
+	Cancel(ctx context.Context, intentID string) (*Intent, error)
+	// Refund returns the full amount of a succeeded intent.
+	Refund(ctx context.Context, intentID string) (*Intent, error)
+	Intent(ctx context.Context, intentID string) (*Intent, error)
+	// VerifyWebhook checks signature against payload and decodes the event.
+	VerifyWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// SignatureHeader carries the webhook signature, "t=<unix>,v1=<hex hmac>".
+const SignatureHeader = "X-Payment-Signature"
+
+// SignatureTolerance bounds how old a signed webhook may be, which limits
+// replays of captured requests.
+const SignatureTolerance = 5 * time.Minute
+
+// Sign returns the SignatureHeader value for payload sent at t. The HMAC
+// covers the timestamp so it cannot be swapped.
+func Sign(secret string, payload []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, payload)
+}
+
+// Verify checks a SignatureHeader value produced by Sign.
+func Verify(secret string, payload []byte, header string, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, ts, payload))) {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func mac(secret, ts string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
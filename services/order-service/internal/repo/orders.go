@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"GoOrder/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Orders struct {
+	db *gorm.DB
+}
+
+func NewOrders(db *gorm.DB) *Orders {
+	return &Orders{db: db}
+}
+
+type OrderFilter struct {
+	Status domain.Status
+	// SellerID narrows a buyer's orders to those from one seller.
+	SellerID uint
+	// BeforeID returns orders older than the given id (keyset pagination).
+	BeforeID uint
+	Limit    int
+}
+
+// CreateAll stores the orders of one checkout atomically.
+func (r *Orders) CreateAll(orders []*domain.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, o := range orders {
+			if err := tx.Create(o).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Orders) GetByID(id uint) (*domain.Order, error) {
+	var o domain.Order
+	if err := r.withItems(r.db).First(&o, id).Error; err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ListBySaga returns the orders created by one checkout, by seller.
+func (r *Orders) ListBySaga(sagaID string) ([]domain.Order, error) {
+	var orders []domain.Order
+	if err := r.withItems(r.db.Where("saga_id = ?", sagaID)).Order("seller_id").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// InCheckout reports whether the order's checkout saga is still running.
+// Such orders are left alone until the saga finishes with them.
+func (r *Orders) InCheckout(o *domain.Order) (bool, error) {
+	if o.SagaID == "" {
+		return false, nil
+	}
+	var n int64
+	err := r.db.Model(&domain.Saga{}).
+		Where("id = ? AND status IN ?", o.SagaID, unfinished).
+		Count(&n).Error
+	return n > 0, err
+}
+
+func (r *Orders) ListByBuyer(buyerID uint, f OrderFilter) ([]domain.Order, error) {
+	return r.list(r.db.Where("buyer_id = ?", buyerID), f)
+}
+
+func (r *Orders) ListBySeller(sellerID uint, f OrderFilter) ([]domain.Order, error) {
+	return r.list(r.db.Where("seller_id = ?", sellerID), f)
+}
+
+func (r *Orders) list(q *gorm.DB, f OrderFilter) ([]domain.Order, error) {
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.SellerID > 0 {
+		q = q.Where("seller_id = ?", f.SellerID)
+	}
+	if f.BeforeID > 0 {
+		q = q.Where("id < ?", f.BeforeID)
+	}
+
+	var orders []domain.Order
+	if err := r.withItems(q).Order("id DESC").Limit(f.Limit).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// UpdateStatus moves an order from one status to another only if it is still
+// in from, so concurrent transitions cannot both win. It returns
+// gorm.ErrRecordNotFound when the order is missing or no longer in from.
+func (r *Orders) UpdateStatus(id uint, from, to domain.Status, at time.Time) error {
+	res := r.db.Model(&domain.Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{"status": to, "updated_at": at})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Orders) withItems(q *gorm.DB) *gorm.DB {
+	return q.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("order_items.id")
+	})
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoOrder/internal/domain"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Order{}, &domain.OrderItem{}, &domain.Payment{}, &domain.WebhookEvent{}, &domain.Saga{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	return db
+}
+
+func newOrder(buyerID, sellerID uint) *domain.Order {
+	return &domain.Order{
+		BuyerID:    buyerID,
+		BuyerEmail: "buyer@example.com",
+		SellerID:   sellerID,
+		Status:     domain.StatusPendingPayment,
+		Total:      20,
+		Items:      []domain.OrderItem{{ProductID: 1, Name: "A", UnitPrice: 10, Quantity: 2}},
+	}
+}
+
+func TestOrders_CreateAndList(t *testing.T) {
+	r := NewOrders(newTestDB(t))
+
+	if err := r.CreateAll([]*domain.Order{newOrder(1, 10), newOrder(1, 11), newOrder(2, 10)}); err != nil {
+		t.Fatalf("CreateAll() error = %v", err)
+	}
+
+	mine, err := r.ListByBuyer(1, OrderFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListByBuyer() error = %v", err)
+	}
+	if len(mine) != 2 || mine[0].ID < mine[1].ID {
+		t.Fatalf("ListByBuyer() = %+v, want 2 orders newest first", mine)
+	}
+	if len(mine[0].Items) != 1 {
+		t.Errorf("items not loaded: %+v", mine[0])
+	}
+
+	selling, _ := r.ListBySeller(10, OrderFilter{Limit: 10})
+	if len(selling) != 2 {
+		t.Errorf("len(ListBySeller(10)) = %d, want 2", len(selling))
+	}
+	page, _ := r.ListBySeller(10, OrderFilter{BeforeID: selling[0].ID, Limit: 10})
+	if len(page) != 1 {
+		t.Errorf("len(page) = %d, want 1", len(page))
+	}
+}
+
+func TestOrders_UpdateStatusIsConditional(t *testing.T) {
+	r := NewOrders(newTestDB(t))
+
+	o := newOrder(1, 10)
+	if err := r.CreateAll([]*domain.Order{o}); err != nil {
+		t.Fatalf("CreateAll() error = %v", err)
+	}
+
+	if err := r.UpdateStatus(o.ID, domain.StatusPendingPayment, domain.StatusPaid, time.Now()); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	// A second writer still believing the order is pending must lose.
+	err := r.UpdateStatus(o.ID, domain.StatusPendingPayment, domain.StatusCancelled, time.Now())
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("stale UpdateStatus() error = %v, want ErrRecordNotFound", err)
+	}
+
+	got, _ := r.GetByID(o.ID)
+	if got.Status != domain.StatusPaid {
+		t.Errorf("status = %s, want PAID", got.Status)
+	}
+	if paid, _ := r.ListByBuyer(1, OrderFilter{Status: domain.StatusPaid, Limit: 10}); len(paid) != 1 {
+		t.Errorf("status filter returned %d orders, want 1", len(paid))
+	}
+}
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"GoOrder/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Payments struct {
+	db *gorm.DB
+}
+
+func NewPayments(db *gorm.DB) *Payments {
+	return &Payments{db: db}
+}
+
+func (r *Payments) Create(p *domain.Payment) error {
+	return r.db.Create(p).Error
+}
+
+func (r *Payments) GetByIntent(intentID string) (*domain.Payment, error) {
+	var p domain.Payment
+	if err := r.db.Where("intent_id = ?", intentID).First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Latest returns the most recent payment attempt for an order.
+func (r *Payments) Latest(orderID uint) (*domain.Payment, error) {
+	var p domain.Payment
+	if err := r.db.Where("order_id = ?", orderID).Order("id DESC").First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *Payments) CountForOrder(orderID uint) (int64, error) {
+	var n int64
+	err := r.db.Model(&domain.Payment{}).Where("order_id = ?", orderID).Count(&n).Error
+	return n, err
+}
+
+// Stuck lists pending payments created before olderThan.
+func (r *Payments) Stuck(olderThan time.Time, limit int) ([]domain.Payment, error) {
+	var out []domain.Payment
+	err := r.db.Where("status = ? AND created_at < ?", domain.PaymentPending, olderThan).
+		Order("id").Limit(limit).Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateStatus moves a payment from one status to another, returning
+// gorm.ErrRecordNotFound when it is no longer in from.
+func (r *Payments) UpdateStatus(id uint, from, to domain.PaymentStatus, reason string) error {
+	res := r.db.Model(&domain.Payment{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{"status": to, "failure_reason": reason, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Payments) EventSeen(id string) (bool, error) {
+	var n int64
+	err := r.db.Model(&domain.WebhookEvent{}).Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *Payments) RecordEvent(e *domain.WebhookEvent) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(e).Error
+}
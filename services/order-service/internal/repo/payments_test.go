@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoOrder/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+func TestPayments_StatusAndStuck(t *testing.T) {
+	db := newTestDB(t)
+	r := NewPayments(db)
+
+	p := &domain.Payment{OrderID: 1, Provider: "fake", IntentID: "pi_1", Amount: 100, Currency: "KZT", Status: domain.PaymentPending}
+	if err := r.Create(p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	stuck, err := r.Stuck(time.Now().Add(time.Minute), 10)
+	if err != nil || len(stuck) != 1 {
+		t.Fatalf("Stuck() = %v, %v; want the pending payment", stuck, err)
+	}
+	if stuck, _ := r.Stuck(time.Now().Add(-time.Minute), 10); len(stuck) != 0 {
+		t.Errorf("Stuck() returned a fresh payment")
+	}
+
+	if err := r.UpdateStatus(p.ID, domain.PaymentPending, domain.PaymentFailed, "card_declined"); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if err := r.UpdateStatus(p.ID, domain.PaymentPending, domain.PaymentSucceeded, ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("second UpdateStatus() error = %v, want ErrRecordNotFound", err)
+	}
+
+	got, _ := r.Latest(1)
+	if got.Status != domain.PaymentFailed || got.FailureReason != "card_declined" {
+		t.Errorf("Latest() = %+v", got)
+	}
+}
+
+func TestPayments_EventsRecordedOnce(t *testing.T) {
+	r := NewPayments(newTestDB(t))
+
+	e := &domain.WebhookEvent{ID: "evt_1", Provider: "fake", Type: "payment.succeeded", ReceivedAt: time.Now()}
+	if err := r.RecordEvent(e); err != nil {
+		t.Fatalf("RecordEvent() error = %v", err)
+	}
+	if err := r.RecordEvent(e); err != nil {
+		t.Fatalf("duplicate RecordEvent() error = %v", err)
+	}
+	if seen, _ := r.EventSeen("evt_1"); !seen {
+		t.Errorf("EventSeen() = false")
+	}
+}
@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"GoOrder/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var unfinished = []domain.SagaStatus{domain.SagaRunning, domain.SagaCompensating}
+
+type Sagas struct {
+	db *gorm.DB
+}
+
+func NewSagas(db *gorm.DB) *Sagas {
+	return &Sagas{db: db}
+}
+
+// Create stores a new saga together with the orders it checks out.
+func (r *Sagas) Create(s *domain.Saga, orders []*domain.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+		for _, o := range orders {
+			o.SagaID = s.ID
+			if err := tx.Create(o).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Sagas) Get(id string) (*domain.Saga, error) {
+	var s domain.Saga
+	if err := r.db.Where("id = ?", id).First(&s).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Unfinished reports whether the buyer has a checkout still in progress.
+func (r *Sagas) Unfinished(buyerID uint) (bool, error) {
+	var n int64
+	err := r.db.Model(&domain.Saga{}).
+		Where("buyer_id = ? AND status IN ?", buyerID, unfinished).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// Advance stores a saga that has left step from, starting its new step's
+// attempts afresh. It returns gorm.ErrRecordNotFound when the saga is no
+// longer at from, so each step is left only once.
+func (r *Sagas) Advance(from domain.SagaStep, s *domain.Saga) error {
+	res := r.db.Model(&domain.Saga{}).
+		Where("id = ? AND step = ? AND status IN ?", s.ID, from, unfinished).
+		Updates(map[string]any{
+			"status":     s.Status,
+			"step":       s.Step,
+			"placed":     s.Placed,
+			"attempts":   0,
+			"deadline":   s.Deadline,
+			"error":      s.Error,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Retry counts another attempt at the saga's current step, returning
+// gorm.ErrRecordNotFound when the saga moved on or was retried meanwhile.
+func (r *Sagas) Retry(id string, step domain.SagaStep, attempts int, deadline time.Time) error {
+	res := r.db.Model(&domain.Saga{}).
+		Where("id = ? AND step = ? AND attempts = ? AND status IN ?", id, step, attempts, unfinished).
+		Updates(map[string]any{"attempts": attempts + 1, "deadline": deadline, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Due lists unfinished sagas whose current step is past its deadline.
+func (r *Sagas) Due(now time.Time, limit int) ([]domain.Saga, error) {
+	var out []domain.Saga
+	err := r.db.Where("status IN ? AND deadline < ?", unfinished, now).
+		Order("deadline").Limit(limit).Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoOrder/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+func TestSagas_CreateAndAdvance(t *testing.T) {
+	db := newTestDB(t)
+	sagas, orders := NewSagas(db), NewOrders(db)
+	now := time.Now()
+
+	s := &domain.Saga{ID: "s1", BuyerID: 1, BuyerEmail: "b@example.com", Status: domain.SagaRunning, Step: domain.StepReserveStock, Deadline: now}
+	if err := sagas.Create(s, []*domain.Order{newOrder(1, 10), newOrder(1, 20)}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	list, err := orders.ListBySaga("s1")
+	if err != nil || len(list) != 2 || len(list[0].Items) != 1 {
+		t.Fatalf("ListBySaga() = %+v, %v", list, err)
+	}
+	if busy, _ := orders.InCheckout(&list[0]); !busy {
+		t.Errorf("InCheckout() = false while the saga runs")
+	}
+	if busy, _ := sagas.Unfinished(1); !busy {
+		t.Errorf("Unfinished() = false while the saga runs")
+	}
+
+	next := *s
+	next.Step = domain.StepClearCart
+	next.Placed = true
+	if err := sagas.Advance(domain.StepReserveStock, &next); err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	// второй переход с того же шага не проходит
+	if err := sagas.Advance(domain.StepReserveStock, &next); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("repeated Advance() error = %v, want ErrRecordNotFound", err)
+	}
+
+	if err := sagas.Retry("s1", domain.StepClearCart, 0, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if err := sagas.Retry("s1", domain.StepClearCart, 0, now.Add(time.Minute)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("concurrent Retry() error = %v, want ErrRecordNotFound", err)
+	}
+	got, _ := sagas.Get("s1")
+	if got.Step != domain.StepClearCart || !got.Placed || got.Attempts != 1 {
+		t.Errorf("saga = %+v, want clear_cart, placed, 1 attempt", got)
+	}
+
+	done := *got
+	done.Status = domain.SagaCompleted
+	if err := sagas.Advance(domain.StepClearCart, &done); err != nil {
+		t.Fatalf("Advance() to completed error = %v", err)
+	}
+	if busy, _ := orders.InCheckout(&list[0]); busy {
+		t.Errorf("InCheckout() = true after the saga completed")
+	}
+}
+
+func TestSagas_Due(t *testing.T) {
+	db := newTestDB(t)
+	sagas := NewSagas(db)
+	now := time.Now()
+
+	_ = sagas.Create(&domain.Saga{ID: "late", BuyerID: 1, BuyerEmail: "b", Status: domain.SagaCompensating, Step: domain.StepReleaseStock, Deadline: now.Add(-time.Minute)}, nil)
+	_ = sagas.Create(&domain.Saga{ID: "fresh", BuyerID: 2, BuyerEmail: "b", Status: domain.SagaRunning, Step: domain.StepReserveStock, Deadline: now.Add(time.Minute)}, nil)
+	_ = sagas.Create(&domain.Saga{ID: "done", BuyerID: 3, BuyerEmail: "b", Status: domain.SagaFailed, Step: domain.StepCancelOrders, Deadline: now.Add(-time.Hour)}, nil)
+
+	due, err := sagas.Due(now, 10)
+	if err != nil || len(due) != 1 || due[0].ID != "late" {
+		t.Fatalf("Due() = %+v, %v; want only late", due, err)
+	}
+}
@@ -0,0 +1,560 @@
+package service
+
+import (
+	"GoOrder/internal/clients"
+	"GoOrder/internal/domain"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/repo"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TopicCheckoutReplies receives product-service's replies to checkout
+// commands.
+const TopicCheckoutReplies = "checkout.replies"
+
+const recoverBatch = 100
+
+// Saga error codes set by order-service; the rest come from product-service
+// replies and payment failures.
+const (
+	codeTimeout            = "timeout"
+	codePaymentFailed      = "payment_failed"
+	codeReservationExpired = "reservation_expired"
+)
+
+var (
+	errCheckoutNotFound   = errors.New("checkout_not_found")
+	errCheckoutInProgress = errors.New("checkout_in_progress")
+)
+
+func IsCheckoutNotFound(err error) bool   { return errors.Is(err, errCheckoutNotFound) }
+func IsCheckoutInProgress(err error) bool { return errors.Is(err, errCheckoutInProgress) }
+
+// CartSource reads the buyer's cart from product-service.
+type CartSource interface {
+	Cart(ctx context.Context, authorization string) (*clients.Cart, error)
+}
+
+type CheckoutInput struct {
+	BuyerID    uint
+	BuyerEmail string
+	// Authorization is the buyer's own header, forwarded to product-service.
+	Authorization string
+	// Card is charged by the checkout when set. Without it the orders wait
+	// for the buyer to pay them one by one.
+	Card string
+}
+
+type SagaConfig struct {
+	// StepTimeout is how long a step may wait for a reply or the payment
+	// provider before it is tried again.
+	StepTimeout time.Duration
+	// MaxAttempts bounds the tries of one step. A forward step that runs
+	// out is compensated; a compensating step is skipped.
+	MaxAttempts int
+}
+
+// Checkout is a checkout saga and the orders it created.
+type Checkout struct {
+	Saga   *domain.Saga
+	Orders []domain.Order
+}
+
+// CheckoutService runs checkout as a saga across services. Orders are
+// stored first, then stock is reserved, the card charged and the cart
+// cleared. Stock and cart belong to product-service and are changed by
+// Kafka commands answered on TopicCheckoutReplies; payments run locally.
+// A failure before the charge is captured is undone in reverse: the
+// payment voided, the stock released and the orders cancelled.
+//
+// Progress is persisted after every step. Steps that outlive their
+// deadline — a lost reply, a crash mid-step — are picked up by Recover,
+// and every step can run more than once without harm.
+type CheckoutService struct {
+	sagas    *repo.Sagas
+	orders   *OrderService
+	payments *PaymentService
+	carts    CartSource
+	commands Publisher
+	cfg      SagaConfig
+}
+
+func NewCheckoutService(sagas *repo.Sagas, orders *OrderService, payments *PaymentService, carts CartSource, commands Publisher, cfg SagaConfig) *CheckoutService {
+	return &CheckoutService{sagas: sagas, orders: orders, payments: payments, carts: carts, commands: commands, cfg: cfg}
+}
+
+// Start creates one order per seller from the buyer's cart, priced at the
+// products' current prices, and starts the saga. It returns as soon as the
+// first step is under way; the outcome is read with Get.
+func (s *CheckoutService) Start(ctx context.Context, in CheckoutInput) (*Checkout, error) {
+	busy, err := s.sagas.Unfinished(in.BuyerID)
+	if err != nil {
+		return nil, err
+	}
+	if busy {
+		return nil, errCheckoutInProgress
+	}
+
+	cart, err := s.carts.Cart(ctx, in.Authorization)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := ordersFromCart(in, cart)
+	if err != nil {
+		return nil, err
+	}
+	// номер карты не сохраняем: в саге лежит только ссылка провайдера
+	method := ""
+	if in.Card != "" {
+		if method, err = s.payments.paymentMethod(ctx, in.Card); err != nil {
+			return nil, err
+		}
+	}
+
+	sg := &domain.Saga{
+		ID:            newSagaID(),
+		BuyerID:       in.BuyerID,
+		BuyerEmail:    in.BuyerEmail,
+		PaymentMethod: method,
+		Status:        domain.SagaRunning,
+		Step:          domain.StepReserveStock,
+		Deadline:      time.Now().Add(s.cfg.StepTimeout),
+	}
+	if err := s.sagas.Create(sg, orders); err != nil {
+		return nil, err
+	}
+
+	s.run(ctx, sg)
+	return s.Get(in.BuyerID, sg.ID)
+}
+
+// Get returns a checkout started by buyerID.
+func (s *CheckoutService) Get(buyerID uint, id string) (*Checkout, error) {
+	sg, err := s.sagas.Get(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errCheckoutNotFound
+		}
+		return nil, err
+	}
+	if sg.BuyerID != buyerID {
+		return nil, errCheckoutNotFound
+	}
+	orders, err := s.orders.orders.ListBySaga(sg.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Checkout{Saga: sg, Orders: orders}, nil
+}
+
+// replySteps maps a command type to the step waiting for its reply.
+var replySteps = map[string]domain.SagaStep{
+	clients.CommandReserveStock: domain.StepReserveStock,
+	clients.CommandClearCart:    domain.StepClearCart,
+	clients.CommandReleaseStock: domain.StepReleaseStock,
+}
+
+// HandleReply moves a saga on with product-service's answer to one of its
+// commands. Replies for a step the saga has already left are ignored; they
+// are redeliveries or answers to retried commands.
+func (s *CheckoutService) HandleReply(ctx context.Context, r clients.Reply) error {
+	sg, err := s.sagas.Get(r.SagaID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("checkout: reply %s for unknown saga %s", r.CommandID, r.SagaID)
+			return nil
+		}
+		return err
+	}
+	if sg.Finished() || replySteps[r.Type] != sg.Step {
+		return nil
+	}
+
+	switch sg.Step {
+	case domain.StepReserveStock:
+		if !r.OK {
+			// Reservations are all or nothing, so nothing is held.
+			return s.compensate(ctx, sg, domain.StepCancelOrders, r.Error)
+		}
+		return s.placed(ctx, sg)
+	case domain.StepClearCart:
+		if !r.OK {
+			// Not worth undoing a checkout for; the buyer can empty the
+			// cart themselves.
+			log.Printf("checkout %s: cart not cleared: %s", sg.ID, r.Error)
+		}
+		return s.finish(sg, domain.SagaCompleted)
+	case domain.StepReleaseStock:
+		if !r.OK {
+			log.Printf("checkout %s: stock not released: %s", sg.ID, r.Error)
+		}
+		return s.advance(ctx, sg, domain.SagaCompensating, domain.StepCancelOrders)
+	}
+	return nil
+}
+
+// Recover retries the steps of sagas past their deadline, which covers lost
+// commands and replies as well as steps interrupted by a restart. A step
+// out of attempts is given up: compensated when going forward, skipped when
+// compensating. It returns how many sagas were touched.
+func (s *CheckoutService) Recover(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.sagas.Due(now, recoverBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range due {
+		sg := &due[i]
+		if err := s.recoverOne(ctx, sg); err != nil {
+			log.Printf("checkout %s: recover %s: %v", sg.ID, sg.Step, err)
+		}
+	}
+	return len(due), nil
+}
+
+func (s *CheckoutService) recoverOne(ctx context.Context, sg *domain.Saga) error {
+	if sg.Attempts+1 < s.cfg.MaxAttempts {
+		deadline := time.Now().Add(s.cfg.StepTimeout)
+		err := s.sagas.Retry(sg.ID, sg.Step, sg.Attempts, deadline)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		sg.Attempts++
+		sg.Deadline = deadline
+		return s.step(ctx, sg)
+	}
+
+	log.Printf("checkout %s: giving up on %s after %d attempts", sg.ID, sg.Step, sg.Attempts+1)
+	switch sg.Step {
+	case domain.StepReserveStock:
+		// A late reply may still have reserved the stock.
+		return s.compensate(ctx, sg, domain.StepReleaseStock, codeTimeout)
+	case domain.StepAuthorizePayment, domain.StepCapturePayment:
+		return s.compensate(ctx, sg, domain.StepVoidPayment, codeTimeout)
+	case domain.StepClearCart:
+		return s.finish(sg, domain.SagaCompleted)
+	case domain.StepVoidPayment:
+		// Payments settling later are refunded when applied to a cancelled
+		// order.
+		return s.advance(ctx, sg, domain.SagaCompensating, domain.StepReleaseStock)
+	case domain.StepReleaseStock:
+		// Unreleased reservations expire in product-service.
+		return s.advance(ctx, sg, domain.SagaCompensating, domain.StepCancelOrders)
+	}
+	// Cancelling is local and always retried.
+	return s.step(ctx, sg)
+}
+
+// RunRecovery calls Recover right away, picking up sagas interrupted by the
+// last shutdown, and then every interval until ctx is cancelled.
+func (s *CheckoutService) RunRecovery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.Recover(ctx, time.Now())
+		if err != nil {
+			log.Printf("checkout recovery: %v", err)
+		} else if n > 0 {
+			log.Printf("checkout recovery: retried %d sagas", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run starts the saga's current step. Errors are only logged: the step is
+// retried once its deadline passes.
+func (s *CheckoutService) run(ctx context.Context, sg *domain.Saga) {
+	if err := s.step(ctx, sg); err != nil {
+		log.Printf("checkout %s: %s: %v", sg.ID, sg.Step, err)
+	}
+}
+
+func (s *CheckoutService) step(ctx context.Context, sg *domain.Saga) error {
+	if sg.Finished() {
+		return nil
+	}
+
+	switch sg.Step {
+	case domain.StepReserveStock, domain.StepReleaseStock:
+		orders, err := s.orders.orders.ListBySaga(sg.ID)
+		if err != nil {
+			return err
+		}
+		typ := clients.CommandReserveStock
+		if sg.Step == domain.StepReleaseStock {
+			typ = clients.CommandReleaseStock
+		}
+		return s.send(ctx, sg, clients.Command{Type: typ, Reservations: reservations(orders)})
+	case domain.StepAuthorizePayment:
+		return s.authorize(ctx, sg)
+	case domain.StepCapturePayment:
+		return s.capture(ctx, sg)
+	case domain.StepClearCart:
+		return s.send(ctx, sg, clients.Command{Type: clients.CommandClearCart, UserID: sg.BuyerID})
+	case domain.StepVoidPayment:
+		return s.void(ctx, sg)
+	case domain.StepCancelOrders:
+		return s.cancel(ctx, sg)
+	}
+	return fmt.Errorf("unknown step %q", sg.Step)
+}
+
+func (s *CheckoutService) send(ctx context.Context, sg *domain.Saga, cmd clients.Command) error {
+	cmd.ID = fmt.Sprintf("%s:%s:%d", sg.ID, sg.Step, sg.Attempts)
+	cmd.SagaID = sg.ID
+	cmd.ReplyTo = TopicCheckoutReplies
+	return s.commands.Send(ctx, clients.CommandsTopic, sg.ID, cmd)
+}
+
+// placed follows a successful reservation: the orders are announced and the
+// saga goes on to payment, or straight to the cart when there is no card.
+func (s *CheckoutService) placed(ctx context.Context, sg *domain.Saga) error {
+	next := *sg
+	next.Placed = true
+	next.Step = domain.StepClearCart
+	if sg.PaymentMethod != "" {
+		next.Step = domain.StepAuthorizePayment
+	}
+	moved, err := s.moveTo(sg, next)
+	if err != nil || !moved {
+		return err
+	}
+
+	orders, err := s.orders.orders.ListBySaga(sg.ID)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		s.orders.publish(ctx, TopicOrderCreated, &orders[i])
+	}
+	return s.step(ctx, sg)
+}
+
+// authorize holds the amount of every order on the card. Payments the
+// provider is still processing keep the saga at this step until they
+// settle or the step runs out of attempts.
+func (s *CheckoutService) authorize(ctx context.Context, sg *domain.Saga) error {
+	orders, err := s.orders.orders.ListBySaga(sg.ID)
+	if err != nil {
+		return err
+	}
+
+	waiting := false
+	for i := range orders {
+		o := &orders[i]
+		ref := fmt.Sprintf("checkout-%s-%d", sg.ID, o.ID)
+		p, intent, err := s.payments.authorize(ctx, o, ref, sg.PaymentMethod)
+		if err != nil {
+			return err
+		}
+		switch {
+		case p.Status == domain.PaymentFailed:
+			return s.compensate(ctx, sg, domain.StepVoidPayment, failureCode(p))
+		case intent.Status == payments.IntentProcessing:
+			waiting = true
+		}
+	}
+	if waiting {
+		return nil
+	}
+	return s.advance(ctx, sg, domain.SagaRunning, domain.StepCapturePayment)
+}
+
+// capture takes the held amounts, which commits the stock reservations. An
+// order whose reservation expired in the meantime has its money refunded by
+// PaymentService, and the whole checkout is undone.
+func (s *CheckoutService) capture(ctx context.Context, sg *domain.Saga) error {
+	orders, err := s.orders.orders.ListBySaga(sg.ID)
+	if err != nil {
+		return err
+	}
+
+	failed := ""
+	for i := range orders {
+		p, err := s.payments.capture(ctx, orders[i].ID)
+		if err != nil {
+			return err
+		}
+		if p.Status != domain.PaymentSucceeded && failed == "" {
+			failed = failureCode(p)
+		}
+	}
+	if failed != "" {
+		return s.compensate(ctx, sg, domain.StepVoidPayment, failed)
+	}
+	return s.advance(ctx, sg, domain.SagaRunning, domain.StepClearCart)
+}
+
+// void releases held amounts and refunds captured ones.
+func (s *CheckoutService) void(ctx context.Context, sg *domain.Saga) error {
+	orders, err := s.orders.orders.ListBySaga(sg.ID)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		if err := s.payments.void(ctx, orders[i].ID); err != nil {
+			return err
+		}
+	}
+	return s.advance(ctx, sg, domain.SagaCompensating, domain.StepReleaseStock)
+}
+
+// cancel cancels the orders still awaiting payment; refunded orders keep
+// their status. Buyers only hear about orders that were announced.
+func (s *CheckoutService) cancel(ctx context.Context, sg *domain.Saga) error {
+	orders, err := s.orders.orders.ListBySaga(sg.ID)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		if err := s.orders.cancelCheckout(ctx, &orders[i], sg.Placed); err != nil {
+			return err
+		}
+	}
+	return s.finish(sg, domain.SagaFailed)
+}
+
+func (s *CheckoutService) compensate(ctx context.Context, sg *domain.Saga, step domain.SagaStep, code string) error {
+	log.Printf("checkout %s: %s failed (%s), compensating", sg.ID, sg.Step, code)
+	next := *sg
+	next.Status = domain.SagaCompensating
+	next.Step = step
+	next.Error = code
+	moved, err := s.moveTo(sg, next)
+	if err != nil || !moved {
+		return err
+	}
+	return s.step(ctx, sg)
+}
+
+// advance moves the saga to step and starts it.
+func (s *CheckoutService) advance(ctx context.Context, sg *domain.Saga, status domain.SagaStatus, step domain.SagaStep) error {
+	next := *sg
+	next.Status = status
+	next.Step = step
+	moved, err := s.moveTo(sg, next)
+	if err != nil || !moved {
+		return err
+	}
+	return s.step(ctx, sg)
+}
+
+func (s *CheckoutService) finish(sg *domain.Saga, status domain.SagaStatus) error {
+	next := *sg
+	next.Status = status
+	_, err := s.moveTo(sg, next)
+	return err
+}
+
+// moveTo stores next as the saga's state after its current step. It
+// reports false when someone else — a reply, the recovery loop — moved the
+// saga first; only the winner goes on with the new step.
+func (s *CheckoutService) moveTo(sg *domain.Saga, next domain.Saga) (bool, error) {
+	next.Attempts = 0
+	next.Deadline = time.Now().Add(s.cfg.StepTimeout)
+
+	err := s.sagas.Advance(sg.Step, &next)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	*sg = next
+	return true, nil
+}
+
+// ordersFromCart splits the cart into one pending order per seller, each
+// with its own stock reservation reference.
+func ordersFromCart(in CheckoutInput, cart *clients.Cart) ([]*domain.Order, error) {
+	if len(cart.Items) == 0 {
+		return nil, errEmptyCart
+	}
+
+	bySeller := make(map[uint]*domain.Order)
+	for _, it := range cart.Items {
+		if !it.Available {
+			return nil, errUnavailableItems
+		}
+		o, ok := bySeller[it.SellerID]
+		if !ok {
+			o = &domain.Order{
+				BuyerID:        in.BuyerID,
+				BuyerEmail:     in.BuyerEmail,
+				SellerID:       it.SellerID,
+				Status:         domain.StatusPendingPayment,
+				ReservationRef: newReservationRef(),
+			}
+			bySeller[it.SellerID] = o
+		}
+		item := domain.OrderItem{
+			ProductID: it.ProductID,
+			Name:      it.Name,
+			UnitPrice: it.CurrentPrice,
+			Quantity:  it.Quantity,
+		}
+		o.Items = append(o.Items, item)
+		o.Total += item.LineTotal()
+	}
+
+	orders := make([]*domain.Order, 0, len(bySeller))
+	for _, o := range bySeller {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].SellerID < orders[j].SellerID })
+	return orders, nil
+}
+
+func reservations(orders []domain.Order) []clients.Reservation {
+	out := make([]clients.Reservation, 0, len(orders))
+	for _, o := range orders {
+		r := clients.Reservation{Reference: o.ReservationRef, Items: make([]clients.ReserveItem, 0, len(o.Items))}
+		for _, it := range o.Items {
+			r.Items = append(r.Items, clients.ReserveItem{ProductID: it.ProductID, Quantity: it.Quantity})
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func failureCode(p *domain.Payment) string {
+	switch {
+	case p.Status == domain.PaymentRefunded && p.FailureReason == codeReservationExpired:
+		return codeReservationExpired
+	case p.Status == domain.PaymentFailed && p.FailureReason != "":
+		return p.FailureReason
+	}
+	return codePaymentFailed
+}
+
+func newSagaID() string {
+	return randomHex(16)
+}
+
+func newReservationRef() string {
+	return "ord-" + randomHex(16)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
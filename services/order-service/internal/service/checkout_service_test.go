@@ -0,0 +1,197 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"GoOrder/internal/clients"
+	"GoOrder/internal/domain"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/service"
+)
+
+func TestCheckout_WithCardPaysOrders(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 2), cartItem(2, 20, 7, 1))
+	env.products.stock[1] = 2
+
+	co := env.start(t, payments.CardSuccess)
+	if co.Saga.Status != domain.SagaCompleted || !co.Saga.Placed {
+		t.Fatalf("checkout = %+v, want completed", co.Saga)
+	}
+	// номер карты в саге не хранится
+	if !strings.HasPrefix(co.Saga.PaymentMethod, "pm_") {
+		t.Errorf("saga payment method = %q, want a provider reference", co.Saga.PaymentMethod)
+	}
+	for _, o := range co.Orders {
+		if o.Status != domain.StatusPaid {
+			t.Errorf("order %d status = %s, want PAID", o.ID, o.Status)
+		}
+		if env.products.status[o.ReservationRef] != "committed" {
+			t.Errorf("order %d reservation = %s, want committed", o.ID, env.products.status[o.ReservationRef])
+		}
+		if p, _ := env.payments.Latest(1, o.ID); p == nil || p.Status != domain.PaymentSucceeded {
+			t.Errorf("order %d payment = %+v, want succeeded", o.ID, p)
+		}
+	}
+	if !env.products.cleared {
+		t.Errorf("cart not cleared")
+	}
+
+	want := []string{"order.created", "order.created", "order.paid", "order.paid"}
+	if got := env.pub.topics(); len(got) != len(want) || got[0] != want[0] || got[3] != want[3] {
+		t.Errorf("topics = %v, want %v", got, want)
+	}
+}
+
+func TestCheckout_DeclinedCardCompensates(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 2), cartItem(2, 20, 7, 1))
+	env.products.stock[1] = 2
+	env.products.stock[2] = 1
+
+	co := env.start(t, payments.CardDeclined)
+	if co.Saga.Status != domain.SagaFailed || co.Saga.Error != "card_declined" {
+		t.Fatalf("checkout = %+v, want failed with card_declined", co.Saga)
+	}
+	for _, o := range co.Orders {
+		if o.Status != domain.StatusCancelled {
+			t.Errorf("order %d status = %s, want CANCELLED", o.ID, o.Status)
+		}
+		if env.products.status[o.ReservationRef] != "released" {
+			t.Errorf("order %d reservation = %s, want released", o.ID, env.products.status[o.ReservationRef])
+		}
+	}
+	if env.products.stock[1] != 2 || env.products.stock[2] != 1 {
+		t.Errorf("stock = %v, want everything back", env.products.stock)
+	}
+	if env.products.cleared {
+		t.Errorf("failed checkout cleared the cart")
+	}
+
+	// Заказы уже были объявлены, поэтому покупатель узнаёт и об отмене.
+	want := []string{"order.created", "order.created", "order.cancelled", "order.cancelled"}
+	if got := env.pub.topics(); len(got) != len(want) || got[2] != want[2] {
+		t.Errorf("topics = %v, want %v", got, want)
+	}
+}
+
+func TestCheckout_ExpiredReservationRefundsAndCompensates(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 1), cartItem(2, 20, 7, 1))
+	ctx := context.Background()
+
+	co, err := env.checkout.Start(ctx, service.CheckoutInput{BuyerID: 1, Card: payments.CardSuccess})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	// резерв второго заказа истекает до списания денег
+	reserve := env.products.commands[0]
+	env.products.commands = nil
+	reply := env.products.handle(reserve)
+	env.products.status[co.Orders[1].ReservationRef] = "released"
+	if err := env.checkout.HandleReply(ctx, reply); err != nil {
+		t.Fatalf("HandleReply() error = %v", err)
+	}
+	env.deliver(t)
+
+	co, _ = env.checkout.Get(1, co.Saga.ID)
+	if co.Saga.Status != domain.SagaFailed || co.Saga.Error != "reservation_expired" {
+		t.Fatalf("checkout = %+v, want failed with reservation_expired", co.Saga)
+	}
+	if co.Orders[0].Status != domain.StatusRefunded || co.Orders[1].Status != domain.StatusCancelled {
+		t.Errorf("orders = %s, %s; want REFUNDED and CANCELLED", co.Orders[0].Status, co.Orders[1].Status)
+	}
+	for _, o := range co.Orders {
+		if p, _ := env.payments.Latest(1, o.ID); p == nil || p.Status != domain.PaymentRefunded {
+			t.Errorf("order %d payment = %+v, want refunded", o.ID, p)
+		}
+	}
+	if env.products.cleared {
+		t.Errorf("failed checkout cleared the cart")
+	}
+}
+
+func TestCheckout_LostCommandsAreRetriedThenCompensated(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 1))
+	env.products.stock[1] = 1
+	ctx := context.Background()
+	now := time.Now()
+
+	env.products.drop = true
+	co, err := env.checkout.Start(ctx, service.CheckoutInput{BuyerID: 1})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if _, err := env.checkout.Start(ctx, service.CheckoutInput{BuyerID: 1}); !service.IsCheckoutInProgress(err) {
+		t.Errorf("second Start() error = %v, want in progress", err)
+	}
+	if _, err := env.payments.Pay(ctx, service.PayInput{BuyerID: 1, OrderID: co.Orders[0].ID, Card: payments.CardSuccess}); !service.IsCheckoutInProgress(err) {
+		t.Errorf("Pay() during checkout error = %v, want in progress", err)
+	}
+
+	if n, _ := env.checkout.Recover(ctx, now); n != 0 {
+		t.Errorf("Recover() before the deadline touched %d sagas", n)
+	}
+
+	// Первая повторная попытка тоже теряется, вторая исчерпывает лимит.
+	if n, err := env.checkout.Recover(ctx, now.Add(stepTimeout+time.Second)); err != nil || n != 1 {
+		t.Fatalf("Recover() = %d, %v; want 1", n, err)
+	}
+	env.products.drop = false
+	if n, _ := env.checkout.Recover(ctx, now.Add(3*stepTimeout)); n != 1 {
+		t.Fatalf("Recover() after retries touched %d sagas, want 1", n)
+	}
+	env.deliver(t)
+
+	co, _ = env.checkout.Get(1, co.Saga.ID)
+	if co.Saga.Status != domain.SagaFailed || co.Saga.Error != "timeout" {
+		t.Fatalf("checkout = %+v, want failed with timeout", co.Saga)
+	}
+	if co.Orders[0].Status != domain.StatusCancelled || env.products.stock[1] != 1 {
+		t.Errorf("order %s, stock %d; want CANCELLED and 1", co.Orders[0].Status, env.products.stock[1])
+	}
+}
+
+func TestCheckout_RecoverResendsAndIgnoresStaleReplies(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 1))
+	ctx := context.Background()
+
+	co, err := env.checkout.Start(ctx, service.CheckoutInput{BuyerID: 1})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	// Ответ на первую попытку задерживается; после перезапуска команда
+	// отправляется снова.
+	late := env.products.commands[0]
+	env.products.commands = nil
+	if _, err := env.checkout.Recover(ctx, time.Now().Add(stepTimeout+time.Second)); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if len(env.products.commands) != 1 || env.products.commands[0].ID == late.ID {
+		t.Fatalf("commands after Recover() = %+v, want one retry", env.products.commands)
+	}
+
+	env.deliver(t)
+	// опоздавший ответ и повторная доставка ничего не меняют
+	for i := 0; i < 2; i++ {
+		if err := env.checkout.HandleReply(ctx, env.products.handle(late)); err != nil {
+			t.Fatalf("HandleReply() of stale reply error = %v", err)
+		}
+	}
+	if err := env.checkout.HandleReply(ctx, clients.Reply{SagaID: "missing", Type: clients.CommandReserveStock, OK: true}); err != nil {
+		t.Errorf("HandleReply() for unknown saga error = %v", err)
+	}
+
+	co, _ = env.checkout.Get(1, co.Saga.ID)
+	if co.Saga.Status != domain.SagaCompleted {
+		t.Fatalf("checkout = %+v, want completed", co.Saga)
+	}
+	if got := env.pub.topics(); len(got) != 1 {
+		t.Errorf("topics = %v, want a single order.created", got)
+	}
+
+	if _, err := env.checkout.Get(2, co.Saga.ID); !service.IsCheckoutNotFound(err) {
+		t.Errorf("Get() by a stranger error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"GoOrder/internal/clients"
+	"GoOrder/internal/domain"
+	"GoOrder/internal/repo"
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+var (
+	errNotFound          = errors.New("order_not_found")
+	errEmptyCart         = errors.New("cart_empty")
+	errUnavailableItems  = errors.New("cart_has_unavailable_items")
+	errForbidden         = errors.New("transition_not_allowed")
+	errInvalidTransition = errors.New("invalid_transition")
+	errConflict          = errors.New("order_status_changed")
+	errReservationClosed = errors.New("reservation_expired")
+)
+
+func IsNotFound(err error) bool            { return errors.Is(err, errNotFound) }
+func IsEmptyCart(err error) bool           { return errors.Is(err, errEmptyCart) }
+func IsUnavailableItems(err error) bool    { return errors.Is(err, errUnavailableItems) }
+func IsForbidden(err error) bool           { return errors.Is(err, errForbidden) }
+func IsInvalidTransition(err error) bool   { return errors.Is(err, errInvalidTransition) }
+func IsConflict(err error) bool            { return errors.Is(err, errConflict) }
+func IsProductUnauthorized(err error) bool { return errors.Is(err, clients.ErrUnauthorized) }
+func IsReservationClosed(err error) bool   { return errors.Is(err, errReservationClosed) }
+
+// Inventory settles the stock reservation a checkout made for an order once
+// it is paid (commit) or cancelled (release).
+type Inventory interface {
+	CommitReservation(ctx context.Context, ref string) error
+	ReleaseReservation(ctx context.Context, ref string) error
+}
+
+// Publisher sends order events to Kafka.
+type Publisher interface {
+	Send(ctx context.Context, topic string, key string, value interface{}) error
+}
+
+type ListOrdersInput struct {
+	UserID   uint
+	Status   domain.Status
+	SellerID uint
+	BeforeID uint
+	Limit    int
+}
+
+type ChangeStatusInput struct {
+	OrderID uint
+	ActorID uint
+	Status  domain.Status
+}
+
+type OrderService struct {
+	orders    *repo.Orders
+	inventory Inventory
+	publisher Publisher
+}
+
+// NewOrderService wires the service. Orders are created by CheckoutService.
+// A nil inventory skips stock reservations and a nil publisher skips events.
+func NewOrderService(orders *repo.Orders, inventory Inventory, publisher Publisher) *OrderService {
+	return &OrderService{orders: orders, inventory: inventory, publisher: publisher}
+}
+
+// Get returns an order visible to userID as its buyer or seller. Other
+// users get errNotFound so order ids are not disclosed.
+func (s *OrderService) Get(userID, id uint) (*domain.Order, error) {
+	o, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if o.BuyerID != userID && o.SellerID != userID {
+		return nil, errNotFound
+	}
+	return o, nil
+}
+
+func (s *OrderService) ListForBuyer(in ListOrdersInput) ([]domain.Order, error) {
+	return s.orders.ListByBuyer(in.UserID, filter(in))
+}
+
+func (s *OrderService) ListForSeller(in ListOrdersInput) ([]domain.Order, error) {
+	return s.orders.ListBySeller(in.UserID, filter(in))
+}
+
+func filter(in ListOrdersInput) repo.OrderFilter {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return repo.OrderFilter{Status: in.Status, SellerID: in.SellerID, BeforeID: in.BeforeID, Limit: limit}
+}
+
+// ChangeStatus applies a status change requested by a user. Buyers may only
+// cancel; sellers ship, deliver and cancel. PAID and REFUNDED follow money
+// movements and are set by payment processing through MarkPaid and
+// MarkRefunded, never by users.
+func (s *OrderService) ChangeStatus(ctx context.Context, in ChangeStatusInput) (*domain.Order, error) {
+	o, err := s.Get(in.ActorID, in.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !mayChange(o, in.ActorID, in.Status) {
+		return nil, errForbidden
+	}
+	if err := s.notInCheckout(o); err != nil {
+		return nil, err
+	}
+	return s.transition(ctx, o, in.Status)
+}
+
+// MarkPaid records a successful payment for an order and makes its stock
+// reservation final. A reservation that has already expired fails with
+// errReservationClosed; the order stays unpaid.
+func (s *OrderService) MarkPaid(ctx context.Context, orderID uint) (*domain.Order, error) {
+	o, err := s.get(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.CanTransition(o.Status, domain.StatusPaid) {
+		return nil, errInvalidTransition
+	}
+	if s.inventory != nil && o.ReservationRef != "" {
+		if err := s.inventory.CommitReservation(ctx, o.ReservationRef); err != nil {
+			if errors.Is(err, clients.ErrReservationClosed) {
+				return nil, errReservationClosed
+			}
+			return nil, err
+		}
+	}
+	return s.transition(ctx, o, domain.StatusPaid)
+}
+
+// MarkRefunded records that the payment for an order was returned.
+func (s *OrderService) MarkRefunded(ctx context.Context, orderID uint) (*domain.Order, error) {
+	o, err := s.get(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(ctx, o, domain.StatusRefunded)
+}
+
+func mayChange(o *domain.Order, actorID uint, to domain.Status) bool {
+	switch {
+	case actorID == o.SellerID:
+		return to == domain.StatusShipped || to == domain.StatusDelivered || to == domain.StatusCancelled
+	case actorID == o.BuyerID:
+		return to == domain.StatusCancelled
+	}
+	return false
+}
+
+func (s *OrderService) transition(ctx context.Context, o *domain.Order, to domain.Status) (*domain.Order, error) {
+	from := o.Status
+	if err := o.Transition(to); err != nil {
+		return nil, errInvalidTransition
+	}
+
+	if err := s.orders.UpdateStatus(o.ID, from, to, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errConflict
+		}
+		return nil, err
+	}
+
+	updated, err := s.get(o.ID)
+	if err != nil {
+		return nil, err
+	}
+	if to == domain.StatusCancelled {
+		s.release(ctx, []*domain.Order{updated})
+	}
+	s.publish(ctx, StatusTopic(to), updated)
+	return updated, nil
+}
+
+// cancelCheckout cancels an order of a checkout that failed. The saga gives
+// the stock back itself; announce is false for orders that were never
+// announced as created.
+func (s *OrderService) cancelCheckout(ctx context.Context, o *domain.Order, announce bool) error {
+	if o.Status != domain.StatusPendingPayment {
+		return nil
+	}
+	err := s.orders.UpdateStatus(o.ID, o.Status, domain.StatusCancelled, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if announce {
+		updated, err := s.get(o.ID)
+		if err != nil {
+			return err
+		}
+		s.publish(ctx, StatusTopic(domain.StatusCancelled), updated)
+	}
+	return nil
+}
+
+// notInCheckout fails with errCheckoutInProgress while the order's checkout
+// saga still owns it.
+func (s *OrderService) notInCheckout(o *domain.Order) error {
+	busy, err := s.orders.InCheckout(o)
+	if err != nil {
+		return err
+	}
+	if busy {
+		return errCheckoutInProgress
+	}
+	return nil
+}
+
+// release gives reserved stock back. Failures are only logged: unreleased
+// reservations expire on their own in product-service.
+func (s *OrderService) release(ctx context.Context, orders []*domain.Order) {
+	if s.inventory == nil {
+		return
+	}
+	for _, o := range orders {
+		if o.ReservationRef == "" {
+			continue
+		}
+		if err := s.inventory.ReleaseReservation(ctx, o.ReservationRef); err != nil {
+			log.Printf("order %d: release reservation %s: %v", o.ID, o.ReservationRef, err)
+		}
+	}
+}
+
+func (s *OrderService) get(id uint) (*domain.Order, error) {
+	o, err := s.orders.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+func (s *OrderService) publish(ctx context.Context, topic string, o *domain.Order) {
+	if s.publisher == nil {
+		return
+	}
+	key := strconv.FormatUint(uint64(o.ID), 10)
+	if err := s.publisher.Send(ctx, topic, key, NewOrderEvent(o)); err != nil {
+		log.Printf("order %d: publish %s: %v", o.ID, topic, err)
+	}
+}
+
+const TopicOrderCreated = "order.created"
+
+// StatusTopic is the Kafka topic announcing that an order entered status,
+// e.g. order.shipped for SHIPPED.
+func StatusTopic(status domain.Status) string {
+	if status == domain.StatusPendingPayment {
+		return TopicOrderCreated
+	}
+	return "order." + strings.ToLower(string(status))
+}
+
+type OrderEventItem struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+}
+
+type OrderEvent struct {
+	OrderID    uint             `json:"order_id"`
+	BuyerID    uint             `json:"buyer_id"`
+	BuyerEmail string           `json:"buyer_email"`
+	SellerID   uint             `json:"seller_id"`
+	Status     domain.Status    `json:"status"`
+	Total      float64          `json:"total"`
+	Items      []OrderEventItem `json:"items"`
+	OccurredAt time.Time        `json:"occurred_at"`
+}
+
+func NewOrderEvent(o *domain.Order) OrderEvent {
+	e := OrderEvent{
+		OrderID:    o.ID,
+		BuyerID:    o.BuyerID,
+		BuyerEmail: o.BuyerEmail,
+		SellerID:   o.SellerID,
+		Status:     o.Status,
+		Total:      o.Total,
+		Items:      make([]OrderEventItem, 0, len(o.Items)),
+		OccurredAt: o.UpdatedAt,
+	}
+	for _, it := range o.Items {
+		e.Items = append(e.Items, OrderEventItem{
+			ProductID: it.ProductID,
+			Name:      it.Name,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+		})
+	}
+	return e
+}
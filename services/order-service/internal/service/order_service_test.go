@@ -0,0 +1,426 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"GoOrder/internal/clients"
+	"GoOrder/internal/domain"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/repo"
+	"GoOrder/internal/service"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const stepTimeout = time.Minute
+
+// fakeProducts stands in for product-service: the buyer's cart, stock
+// reservations and the Kafka commands of checkout sagas. Commands are queued
+// until deliver answers them. Products missing from stock are unlimited.
+type fakeProducts struct {
+	cart    clients.Cart
+	cleared bool
+
+	stock    map[uint]int
+	reserved map[string][]clients.ReserveItem
+	status   map[string]string
+
+	commands []clients.Command
+	// drop loses commands instead of queueing them.
+	drop bool
+}
+
+func (f *fakeProducts) Cart(_ context.Context, _ string) (*clients.Cart, error) {
+	c := f.cart
+	return &c, nil
+}
+
+func (f *fakeProducts) Send(_ context.Context, topic, _ string, value interface{}) error {
+	if topic != clients.CommandsTopic {
+		return nil
+	}
+	if !f.drop {
+		f.commands = append(f.commands, value.(clients.Command))
+	}
+	return nil
+}
+
+// handle answers a command the way product-service does.
+func (f *fakeProducts) handle(cmd clients.Command) clients.Reply {
+	reply := clients.Reply{CommandID: cmd.ID, SagaID: cmd.SagaID, Type: cmd.Type, OK: true}
+	switch cmd.Type {
+	case clients.CommandReserveStock:
+		for i, r := range cmd.Reservations {
+			if err := f.reserve(r.Reference, r.Items); err != nil {
+				for _, done := range cmd.Reservations[:i] {
+					_ = f.ReleaseReservation(context.Background(), done.Reference)
+				}
+				reply.OK, reply.Error = false, clients.ReplyOutOfStock
+				break
+			}
+		}
+	case clients.CommandReleaseStock:
+		for _, r := range cmd.Reservations {
+			_ = f.ReleaseReservation(context.Background(), r.Reference)
+		}
+	case clients.CommandClearCart:
+		f.cleared = true
+		f.cart.Items = nil
+	}
+	return reply
+}
+
+func (f *fakeProducts) reserve(ref string, items []clients.ReserveItem) error {
+	if _, ok := f.status[ref]; ok {
+		return nil
+	}
+	for _, it := range items {
+		if left, ok := f.stock[it.ProductID]; ok && left < it.Quantity {
+			return clients.ErrOutOfStock
+		}
+	}
+	for _, it := range items {
+		if _, ok := f.stock[it.ProductID]; ok {
+			f.stock[it.ProductID] -= it.Quantity
+		}
+	}
+	f.reserved[ref] = items
+	f.status[ref] = "active"
+	return nil
+}
+
+func (f *fakeProducts) CommitReservation(_ context.Context, ref string) error {
+	if f.status[ref] != "active" {
+		return clients.ErrReservationClosed
+	}
+	f.status[ref] = "committed"
+	return nil
+}
+
+func (f *fakeProducts) ReleaseReservation(_ context.Context, ref string) error {
+	if f.status[ref] != "active" {
+		return clients.ErrReservationClosed
+	}
+	for _, it := range f.reserved[ref] {
+		if _, ok := f.stock[it.ProductID]; ok {
+			f.stock[it.ProductID] += it.Quantity
+		}
+	}
+	f.status[ref] = "released"
+	return nil
+}
+
+type sentEvent struct {
+	topic string
+	event service.OrderEvent
+}
+
+type fakePublisher struct {
+	mu   sync.Mutex
+	sent []sentEvent
+}
+
+func (p *fakePublisher) Send(_ context.Context, topic, _ string, value interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, sentEvent{topic: topic, event: value.(service.OrderEvent)})
+	return nil
+}
+
+func (p *fakePublisher) topics() []string {
+	var out []string
+	for _, e := range p.sent {
+		out = append(out, e.topic)
+	}
+	return out
+}
+
+type testEnv struct {
+	orders   *service.OrderService
+	payments *service.PaymentService
+	checkout *service.CheckoutService
+	provider *payments.Fake
+	products *fakeProducts
+	pub      *fakePublisher
+}
+
+func newTestEnv(t *testing.T, items ...clients.CartItem) *testEnv {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Order{}, &domain.OrderItem{}, &domain.Payment{}, &domain.WebhookEvent{}, &domain.Saga{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+
+	products := &fakeProducts{
+		cart:     clients.Cart{Items: items},
+		stock:    map[uint]int{},
+		reserved: map[string][]clients.ReserveItem{},
+		status:   map[string]string{},
+	}
+	pub := &fakePublisher{}
+	orders := service.NewOrderService(repo.NewOrders(db), products, pub)
+	provider := payments.NewFake(webhookSecret, "", 0)
+	paySvc := service.NewPaymentService(repo.NewPayments(db), orders, provider, "KZT")
+
+	return &testEnv{
+		orders:   orders,
+		payments: paySvc,
+		checkout: service.NewCheckoutService(repo.NewSagas(db), orders, paySvc, products, products, service.SagaConfig{
+			StepTimeout: stepTimeout,
+			MaxAttempts: 2,
+		}),
+		provider: provider,
+		products: products,
+		pub:      pub,
+	}
+}
+
+// start begins a checkout for buyer 1 and lets product-service answer.
+func (e *testEnv) start(t *testing.T, card string) *service.Checkout {
+	t.Helper()
+
+	co, err := e.checkout.Start(context.Background(), service.CheckoutInput{BuyerID: 1, BuyerEmail: "buyer@example.com", Card: card})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	e.deliver(t)
+
+	co, err = e.checkout.Get(1, co.Saga.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return co
+}
+
+// deliver answers queued commands until the sagas stop sending new ones.
+func (e *testEnv) deliver(t *testing.T) {
+	t.Helper()
+
+	for len(e.products.commands) > 0 {
+		cmd := e.products.commands[0]
+		e.products.commands = e.products.commands[1:]
+		if err := e.checkout.HandleReply(context.Background(), e.products.handle(cmd)); err != nil {
+			t.Fatalf("HandleReply(%s) error = %v", cmd.Type, err)
+		}
+	}
+}
+
+// checkoutOrders runs a checkout without a card, leaving the orders to be
+// paid later.
+func checkoutOrders(t *testing.T, e *testEnv) []domain.Order {
+	t.Helper()
+
+	co := e.start(t, "")
+	if co.Saga.Status != domain.SagaCompleted {
+		t.Fatalf("checkout = %+v, want completed", co.Saga)
+	}
+	return co.Orders
+}
+
+func cartItem(productID, sellerID uint, price float64, qty int) clients.CartItem {
+	return clients.CartItem{
+		ProductID:    productID,
+		SellerID:     sellerID,
+		Name:         "product",
+		Quantity:     qty,
+		UnitPrice:    price,
+		CurrentPrice: price,
+		Available:    true,
+	}
+}
+
+func TestCheckout_SplitsOrdersPerSeller(t *testing.T) {
+	env := newTestEnv(t,
+		cartItem(1, 10, 5, 2),
+		cartItem(2, 20, 100, 1),
+		cartItem(3, 10, 1.5, 4),
+	)
+
+	orders := checkoutOrders(t, env)
+	if len(orders) != 2 {
+		t.Fatalf("len(orders) = %d, want 2", len(orders))
+	}
+	if orders[0].SellerID != 10 || len(orders[0].Items) != 2 || orders[0].Total != 16 {
+		t.Errorf("seller 10 order = %+v, want 2 items totalling 16", orders[0])
+	}
+	if orders[1].SellerID != 20 || orders[1].Total != 100 {
+		t.Errorf("seller 20 order = %+v, want total 100", orders[1])
+	}
+	for _, o := range orders {
+		if o.Status != domain.StatusPendingPayment {
+			t.Errorf("order %d status = %s, want PENDING_PAYMENT", o.ID, o.Status)
+		}
+	}
+
+	if !env.products.cleared {
+		t.Errorf("cart not cleared after checkout")
+	}
+	if len(env.pub.sent) != 2 || env.pub.sent[0].topic != "order.created" || env.pub.sent[0].event.BuyerEmail != "buyer@example.com" {
+		t.Errorf("events = %+v, want two order.created", env.pub.sent)
+	}
+
+	if _, err := env.checkout.Start(context.Background(), service.CheckoutInput{BuyerID: 1}); !service.IsEmptyCart(err) {
+		t.Errorf("second Start() error = %v, want empty cart", err)
+	}
+}
+
+func TestCheckout_RejectsUnavailableItems(t *testing.T) {
+	gone := cartItem(2, 10, 5, 1)
+	gone.Available = false
+	env := newTestEnv(t, cartItem(1, 10, 5, 1), gone)
+
+	if _, err := env.checkout.Start(context.Background(), service.CheckoutInput{BuyerID: 1}); !service.IsUnavailableItems(err) {
+		t.Fatalf("Start() error = %v, want unavailable items", err)
+	}
+	if len(env.products.commands) != 0 {
+		t.Errorf("rejected checkout sent commands")
+	}
+}
+
+func TestChangeStatus_EnforcesActorsAndStateMachine(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 1))
+	svc, pub := env.orders, env.pub
+	o := checkoutOrders(t, env)[0]
+	ctx := context.Background()
+
+	change := func(actor uint, to domain.Status) error {
+		_, err := svc.ChangeStatus(ctx, service.ChangeStatusInput{OrderID: o.ID, ActorID: actor, Status: to})
+		return err
+	}
+
+	if err := change(99, domain.StatusCancelled); !service.IsNotFound(err) {
+		t.Errorf("stranger: error = %v, want not found", err)
+	}
+	if err := change(1, domain.StatusPaid); !service.IsForbidden(err) {
+		t.Errorf("buyer paying: error = %v, want forbidden", err)
+	}
+	if err := change(10, domain.StatusShipped); !service.IsInvalidTransition(err) {
+		t.Errorf("ship unpaid: error = %v, want invalid transition", err)
+	}
+
+	if _, err := svc.MarkPaid(ctx, o.ID); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	if err := change(1, domain.StatusCancelled); !service.IsInvalidTransition(err) {
+		t.Errorf("cancel paid: error = %v, want invalid transition", err)
+	}
+	if err := change(10, domain.StatusShipped); err != nil {
+		t.Fatalf("ship: error = %v", err)
+	}
+	if err := change(1, domain.StatusDelivered); !service.IsForbidden(err) {
+		t.Errorf("buyer delivering: error = %v, want forbidden", err)
+	}
+	if err := change(10, domain.StatusDelivered); err != nil {
+		t.Fatalf("deliver: error = %v", err)
+	}
+
+	topics := pub.topics()
+	want := []string{"order.created", "order.paid", "order.shipped", "order.delivered"}
+	if len(topics) != len(want) {
+		t.Fatalf("topics = %v, want %v", topics, want)
+	}
+	for i := range want {
+		if topics[i] != want[i] {
+			t.Errorf("topics = %v, want %v", topics, want)
+			break
+		}
+	}
+	if last := pub.sent[len(pub.sent)-1].event; last.Status != domain.StatusDelivered || len(last.Items) != 1 {
+		t.Errorf("last event = %+v", last)
+	}
+}
+
+func TestBuyerCanCancelPendingOrder(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 1))
+	svc := env.orders
+	o := checkoutOrders(t, env)[0]
+
+	got, err := svc.ChangeStatus(context.Background(), service.ChangeStatusInput{OrderID: o.ID, ActorID: 1, Status: domain.StatusCancelled})
+	if err != nil {
+		t.Fatalf("ChangeStatus() error = %v", err)
+	}
+	if got.Status != domain.StatusCancelled {
+		t.Errorf("status = %s, want CANCELLED", got.Status)
+	}
+
+	seller, _ := svc.ListForSeller(service.ListOrdersInput{UserID: 10, Status: domain.StatusCancelled})
+	if len(seller) != 1 {
+		t.Errorf("seller sees %d cancelled orders, want 1", len(seller))
+	}
+}
+
+func TestCheckout_ReservesStock(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 2), cartItem(2, 20, 7, 1))
+	svc, products := env.orders, env.products
+	products.stock[1] = 2
+	products.stock[2] = 1
+
+	orders := checkoutOrders(t, env)
+	for _, o := range orders {
+		if o.ReservationRef == "" || products.status[o.ReservationRef] != "active" {
+			t.Fatalf("order %d has no active reservation", o.ID)
+		}
+	}
+	if products.stock[1] != 0 || products.stock[2] != 0 {
+		t.Errorf("stock = %v, want everything reserved", products.stock)
+	}
+
+	if _, err := svc.MarkPaid(context.Background(), orders[0].ID); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	if products.status[orders[0].ReservationRef] != "committed" {
+		t.Errorf("reservation not committed after payment")
+	}
+
+	if _, err := svc.ChangeStatus(context.Background(), service.ChangeStatusInput{OrderID: orders[1].ID, ActorID: 1, Status: domain.StatusCancelled}); err != nil {
+		t.Fatalf("cancel error = %v", err)
+	}
+	if products.status[orders[1].ReservationRef] != "released" || products.stock[2] != 1 {
+		t.Errorf("cancelled order did not return stock: %v", products.stock)
+	}
+}
+
+func TestCheckout_OutOfStockCancelsOrders(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 1), cartItem(2, 20, 7, 3))
+	env.products.stock[1] = 5
+	env.products.stock[2] = 2
+
+	co := env.start(t, "")
+	if co.Saga.Status != domain.SagaFailed || co.Saga.Error != clients.ReplyOutOfStock {
+		t.Fatalf("checkout = %+v, want failed with out_of_stock", co.Saga)
+	}
+	for _, o := range co.Orders {
+		if o.Status != domain.StatusCancelled {
+			t.Errorf("order %d status = %s, want CANCELLED", o.ID, o.Status)
+		}
+	}
+	if env.products.stock[1] != 5 {
+		t.Errorf("stock of the first seller's item = %d, want 5 after rollback", env.products.stock[1])
+	}
+	if env.products.cleared || len(env.pub.sent) != 0 {
+		t.Errorf("failed checkout cleared the cart or published events")
+	}
+}
+
+func TestMarkPaid_ExpiredReservation(t *testing.T) {
+	env := newTestEnv(t, cartItem(1, 10, 5, 1))
+	svc, products := env.orders, env.products
+	o := checkoutOrders(t, env)[0]
+	products.status[o.ReservationRef] = "released"
+
+	if _, err := svc.MarkPaid(context.Background(), o.ID); !service.IsReservationClosed(err) {
+		t.Fatalf("MarkPaid() error = %v, want reservation closed", err)
+	}
+	got, _ := svc.Get(1, o.ID)
+	if got.Status != domain.StatusPendingPayment {
+		t.Errorf("status = %s, want PENDING_PAYMENT", got.Status)
+	}
+}
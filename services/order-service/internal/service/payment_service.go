@@ -0,0 +1,438 @@
+package service
+
+import (
+	"GoOrder/internal/domain"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const reconcileBatch = 100
+
+var (
+	errNotPayable       = errors.New("order_not_payable")
+	errPaymentNotFound  = errors.New("payment_not_found")
+	errNotRefundable    = errors.New("order_not_refundable")
+	errInvalidSignature = errors.New("invalid_webhook_signature")
+	errStillProcessing  = errors.New("payment_processing")
+)
+
+func IsNotPayable(err error) bool       { return errors.Is(err, errNotPayable) }
+func IsPaymentNotFound(err error) bool  { return errors.Is(err, errPaymentNotFound) }
+func IsNotRefundable(err error) bool    { return errors.Is(err, errNotRefundable) }
+func IsInvalidSignature(err error) bool { return errors.Is(err, errInvalidSignature) }
+
+type PayInput struct {
+	BuyerID uint
+	OrderID uint
+	Card    string
+}
+
+// PaymentService takes payments for orders through a payments.Provider.
+// Provider results reach it three ways — the synchronous response, signed
+// webhooks and reconciliation — and all go through apply, which is safe to
+// run any number of times for the same intent.
+type PaymentService struct {
+	payments *repo.Payments
+	orders   *OrderService
+	provider payments.Provider
+	currency string
+}
+
+func NewPaymentService(p *repo.Payments, orders *OrderService, provider payments.Provider, currency string) *PaymentService {
+	return &PaymentService{payments: p, orders: orders, provider: provider, currency: currency}
+}
+
+// Pay charges the buyer's card for a pending order. While an attempt is
+// still pending it is returned instead of charging again.
+func (s *PaymentService) Pay(ctx context.Context, in PayInput) (*domain.Payment, error) {
+	o, err := s.orders.Get(in.BuyerID, in.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.BuyerID != in.BuyerID {
+		return nil, errNotFound
+	}
+	if o.Status != domain.StatusPendingPayment {
+		return nil, errNotPayable
+	}
+	if err := s.orders.notInCheckout(o); err != nil {
+		return nil, err
+	}
+
+	latest, err := s.payments.Latest(o.ID)
+	switch {
+	case err == nil && latest.Status == domain.PaymentPending:
+		return latest, nil
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	attempt, err := s.payments.CountForOrder(o.ID)
+	if err != nil {
+		return nil, err
+	}
+	intent, err := s.provider.CreateIntent(ctx, payments.CreateIntentInput{
+		Reference: fmt.Sprintf("order-%d-%d", o.ID, attempt+1),
+		Amount:    domain.MinorUnits(o.Total),
+		Currency:  s.currency,
+		Card:      in.Card,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &domain.Payment{
+		OrderID:  o.ID,
+		Provider: s.provider.Name(),
+		IntentID: intent.ID,
+		Amount:   intent.Amount,
+		Currency: intent.Currency,
+		Status:   domain.PaymentPending,
+	}
+	if err := s.payments.Create(p); err != nil {
+		return nil, err
+	}
+
+	if intent.Status == payments.IntentRequiresCapture {
+		if intent, err = s.provider.Capture(ctx, intent.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.apply(ctx, p, intent)
+}
+
+// Refund returns the money for a paid order on the seller's request.
+func (s *PaymentService) Refund(ctx context.Context, sellerID, orderID uint) (*domain.Payment, error) {
+	o, err := s.orders.Get(sellerID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.SellerID != sellerID {
+		return nil, errForbidden
+	}
+	if !domain.CanTransition(o.Status, domain.StatusRefunded) {
+		return nil, errNotRefundable
+	}
+
+	p, err := s.payments.Latest(o.ID)
+	if err != nil || (p.Status != domain.PaymentSucceeded && p.Status != domain.PaymentRefunded) {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, errNotRefundable
+	}
+
+	intent, err := s.provider.Refund(ctx, p.IntentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.apply(ctx, p, intent)
+}
+
+// Latest returns the newest payment attempt for an order the user can see.
+func (s *PaymentService) Latest(userID, orderID uint) (*domain.Payment, error) {
+	if _, err := s.orders.Get(userID, orderID); err != nil {
+		return nil, err
+	}
+	p, err := s.payments.Latest(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errPaymentNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// HandleWebhook verifies and applies a provider event. Events already
+// processed are acknowledged without doing anything.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	ev, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return errInvalidSignature
+	}
+
+	seen, err := s.payments.EventSeen(ev.ID)
+	if err != nil || seen {
+		return err
+	}
+
+	p, err := s.payments.GetByIntent(ev.Intent.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errPaymentNotFound
+		}
+		return err
+	}
+	if _, err := s.apply(ctx, p, &ev.Intent); err != nil {
+		return err
+	}
+
+	return s.payments.RecordEvent(&domain.WebhookEvent{
+		ID:         ev.ID,
+		Provider:   s.provider.Name(),
+		Type:       string(ev.Type),
+		ReceivedAt: time.Now(),
+	})
+}
+
+// Reconcile asks the provider about payments pending for longer than
+// stuckAfter, which covers lost webhooks and crashes between charging and
+// recording the result. It returns how many payments were resolved.
+func (s *PaymentService) Reconcile(ctx context.Context, now time.Time, stuckAfter time.Duration) (int, error) {
+	stuck, err := s.payments.Stuck(now.Add(-stuckAfter), reconcileBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for i := range stuck {
+		p := &stuck[i]
+		intent, err := s.provider.Intent(ctx, p.IntentID)
+		if err != nil {
+			log.Printf("reconcile payment %d: %v", p.ID, err)
+			continue
+		}
+		if intent.Status == payments.IntentRequiresCapture {
+			if intent, err = s.provider.Capture(ctx, p.IntentID); err != nil {
+				log.Printf("reconcile payment %d: capture: %v", p.ID, err)
+				continue
+			}
+		}
+
+		updated, err := s.apply(ctx, p, intent)
+		if err != nil {
+			log.Printf("reconcile payment %d: %v", p.ID, err)
+			continue
+		}
+		if updated.Status != domain.PaymentPending {
+			resolved++
+		}
+	}
+	return resolved, nil
+}
+
+// RunReconcile calls Reconcile every interval until ctx is cancelled.
+func (s *PaymentService) RunReconcile(ctx context.Context, interval, stuckAfter time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Reconcile(ctx, time.Now(), stuckAfter)
+			if err != nil {
+				log.Printf("payment reconciliation: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("payment reconciliation: resolved %d payments", n)
+			}
+		}
+	}
+}
+
+// paymentMethod hands a card to the provider and returns the reference a
+// checkout keeps in its place.
+func (s *PaymentService) paymentMethod(ctx context.Context, card string) (string, error) {
+	return s.provider.CreatePaymentMethod(ctx, card)
+}
+
+// authorize puts a hold on a payment method, see paymentMethod, for an
+// order without capturing it. ref is the provider's idempotency key, so a
+// retried call finds the same intent and payment again. Intents the
+// provider settles on its own (processing cards) are applied right away.
+func (s *PaymentService) authorize(ctx context.Context, o *domain.Order, ref, method string) (*domain.Payment, *payments.Intent, error) {
+	intent, err := s.provider.CreateIntent(ctx, payments.CreateIntentInput{
+		Reference:     ref,
+		Amount:        domain.MinorUnits(o.Total),
+		Currency:      s.currency,
+		PaymentMethod: method,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if intent.Status == payments.IntentProcessing {
+		if intent, err = s.provider.Intent(ctx, intent.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	p, err := s.payments.GetByIntent(intent.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		p = &domain.Payment{
+			OrderID:  o.ID,
+			Provider: s.provider.Name(),
+			IntentID: intent.ID,
+			Amount:   intent.Amount,
+			Currency: intent.Currency,
+			Status:   domain.PaymentPending,
+		}
+		err = s.payments.Create(p)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if intent.Status != payments.IntentRequiresCapture && intent.Status != payments.IntentProcessing {
+		if p, err = s.apply(ctx, p, intent); err != nil {
+			return nil, nil, err
+		}
+	}
+	return p, intent, nil
+}
+
+// capture takes the money held for an order's latest payment and marks the
+// order paid. A payment that already succeeded is applied again, in case
+// the order was not marked paid the first time.
+func (s *PaymentService) capture(ctx context.Context, orderID uint) (*domain.Payment, error) {
+	p, err := s.payments.Latest(orderID)
+	if err != nil {
+		return nil, err
+	}
+	switch p.Status {
+	case domain.PaymentPending:
+		intent, err := s.provider.Capture(ctx, p.IntentID)
+		if err != nil {
+			return nil, err
+		}
+		return s.apply(ctx, p, intent)
+	case domain.PaymentSucceeded:
+		if err := s.succeeded(ctx, p); err != nil {
+			return nil, err
+		}
+		return s.payments.GetByIntent(p.IntentID)
+	}
+	return p, nil
+}
+
+// void undoes an order's latest payment: a held amount is released and a
+// captured one refunded. It fails with errStillProcessing while the
+// provider has not decided yet.
+func (s *PaymentService) void(ctx context.Context, orderID uint) error {
+	p, err := s.payments.Latest(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var intent *payments.Intent
+	switch p.Status {
+	case domain.PaymentPending:
+		if intent, err = s.provider.Intent(ctx, p.IntentID); err != nil {
+			return err
+		}
+		switch intent.Status {
+		case payments.IntentProcessing:
+			return errStillProcessing
+		case payments.IntentRequiresCapture:
+			intent, err = s.provider.Cancel(ctx, p.IntentID)
+		case payments.IntentSucceeded:
+			// Settled after all. Record the success without paying the
+			// order, then give the money back.
+			err = s.payments.UpdateStatus(p.ID, domain.PaymentPending, domain.PaymentSucceeded, "")
+			if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+				intent, err = s.provider.Refund(ctx, p.IntentID)
+			}
+		}
+	case domain.PaymentSucceeded:
+		intent, err = s.provider.Refund(ctx, p.IntentID)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.apply(ctx, p, intent)
+	return err
+}
+
+// apply brings the payment and its order in line with the provider's view
+// of the intent.
+func (s *PaymentService) apply(ctx context.Context, p *domain.Payment, intent *payments.Intent) (*domain.Payment, error) {
+	var err error
+	switch intent.Status {
+	case payments.IntentSucceeded:
+		err = s.succeeded(ctx, p)
+	case payments.IntentFailed, payments.IntentCanceled:
+		err = s.payments.UpdateStatus(p.ID, domain.PaymentPending, domain.PaymentFailed, intent.FailureReason)
+	case payments.IntentRefunded:
+		err = s.refunded(ctx, p)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return s.payments.GetByIntent(p.IntentID)
+}
+
+func (s *PaymentService) succeeded(ctx context.Context, p *domain.Payment) error {
+	err := s.payments.UpdateStatus(p.ID, domain.PaymentPending, domain.PaymentSucceeded, "")
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	_, err = s.orders.MarkPaid(ctx, p.OrderID)
+	switch {
+	case err == nil:
+		return nil
+	case IsReservationClosed(err):
+		return s.refundUnusable(ctx, p, "reservation_expired")
+	case IsInvalidTransition(err), IsConflict(err):
+		o, getErr := s.orders.get(p.OrderID)
+		if getErr != nil {
+			return getErr
+		}
+		if o.Status == domain.StatusCancelled {
+			return s.refundUnusable(ctx, p, "order_cancelled")
+		}
+		// Already paid (or further along): this is a repeated success.
+		return nil
+	}
+	return err
+}
+
+// refundUnusable gives the money back for an order that can no longer be
+// fulfilled, e.g. cancelled while the payment was processing.
+func (s *PaymentService) refundUnusable(ctx context.Context, p *domain.Payment, reason string) error {
+	log.Printf("payment %d for order %d: refunding, %s", p.ID, p.OrderID, reason)
+	if _, err := s.provider.Refund(ctx, p.IntentID); err != nil {
+		return err
+	}
+	err := s.payments.UpdateStatus(p.ID, domain.PaymentSucceeded, domain.PaymentRefunded, reason)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *PaymentService) refunded(ctx context.Context, p *domain.Payment) error {
+	err := s.payments.UpdateStatus(p.ID, domain.PaymentSucceeded, domain.PaymentRefunded, "")
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	o, err := s.orders.get(p.OrderID)
+	if err != nil {
+		return err
+	}
+	if !domain.CanTransition(o.Status, domain.StatusRefunded) {
+		// Refund of a payment the order never used, or a repeated event.
+		return nil
+	}
+	_, err = s.orders.MarkRefunded(ctx, p.OrderID)
+	if IsConflict(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"GoOrder/internal/domain"
+	"GoOrder/internal/payments"
+	"GoOrder/internal/service"
+)
+
+const webhookSecret = "whsec"
+
+type paymentEnv struct {
+	*testEnv
+	order domain.Order
+}
+
+func newPaymentEnv(t *testing.T) *paymentEnv {
+	t.Helper()
+
+	env := newTestEnv(t, cartItem(1, 10, 12.5, 2))
+	return &paymentEnv{testEnv: env, order: checkoutOrders(t, env)[0]}
+}
+
+func (e *paymentEnv) status(t *testing.T) domain.Status {
+	t.Helper()
+	o, err := e.orders.Get(1, e.order.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return o.Status
+}
+
+func (e *paymentEnv) pay(t *testing.T, card string) *domain.Payment {
+	t.Helper()
+	p, err := e.payments.Pay(context.Background(), service.PayInput{BuyerID: 1, OrderID: e.order.ID, Card: card})
+	if err != nil {
+		t.Fatalf("Pay() error = %v", err)
+	}
+	return p
+}
+
+func webhook(t *testing.T, typ payments.EventType, intent *payments.Intent, id string) ([]byte, string) {
+	t.Helper()
+	payload, _ := json.Marshal(payments.Event{ID: id, Type: typ, Intent: *intent, CreatedAt: time.Now()})
+	return payload, payments.Sign(webhookSecret, payload, time.Now())
+}
+
+func TestPay_SuccessMarksOrderPaid(t *testing.T) {
+	env := newPaymentEnv(t)
+
+	p := env.pay(t, payments.CardSuccess)
+	if p.Status != domain.PaymentSucceeded || p.Amount != 2500 || p.Currency != "KZT" {
+		t.Fatalf("payment = %+v", p)
+	}
+	if got := env.status(t); got != domain.StatusPaid {
+		t.Errorf("order status = %s, want PAID", got)
+	}
+	if env.products.status[env.order.ReservationRef] != "committed" {
+		t.Errorf("reservation not committed")
+	}
+	if _, err := env.payments.Pay(context.Background(), service.PayInput{BuyerID: 1, OrderID: env.order.ID, Card: payments.CardSuccess}); !service.IsNotPayable(err) {
+		t.Errorf("paying twice error = %v, want not payable", err)
+	}
+}
+
+func TestPay_DeclinedThenRetry(t *testing.T) {
+	env := newPaymentEnv(t)
+
+	p := env.pay(t, payments.CardDeclined)
+	if p.Status != domain.PaymentFailed || p.FailureReason != "card_declined" {
+		t.Fatalf("payment = %+v, want declined", p)
+	}
+	if got := env.status(t); got != domain.StatusPendingPayment {
+		t.Fatalf("order status = %s after decline", got)
+	}
+
+	if p := env.pay(t, payments.CardSuccess); p.Status != domain.PaymentSucceeded {
+		t.Fatalf("retry payment = %+v", p)
+	}
+	if got := env.status(t); got != domain.StatusPaid {
+		t.Errorf("order status = %s, want PAID", got)
+	}
+}
+
+func TestHandleWebhook_IsIdempotent(t *testing.T) {
+	env := newPaymentEnv(t)
+	ctx := context.Background()
+
+	p := env.pay(t, payments.CardProcessing)
+	if p.Status != domain.PaymentPending {
+		t.Fatalf("payment = %+v, want pending", p)
+	}
+
+	payload, sig := webhook(t, payments.EventSucceeded, &payments.Intent{ID: p.IntentID, Status: payments.IntentSucceeded}, "evt_1")
+	if err := env.payments.HandleWebhook(ctx, payload, "t=1,v1=bad"); !service.IsInvalidSignature(err) {
+		t.Fatalf("bad signature error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := env.payments.HandleWebhook(ctx, payload, sig); err != nil {
+			t.Fatalf("HandleWebhook() #%d error = %v", i+1, err)
+		}
+	}
+	// то же событие под другим id тоже ничего не ломает
+	payload, sig = webhook(t, payments.EventSucceeded, &payments.Intent{ID: p.IntentID, Status: payments.IntentSucceeded}, "evt_2")
+	if err := env.payments.HandleWebhook(ctx, payload, sig); err != nil {
+		t.Fatalf("HandleWebhook() redelivery error = %v", err)
+	}
+
+	if got := env.status(t); got != domain.StatusPaid {
+		t.Errorf("order status = %s, want PAID", got)
+	}
+}
+
+func TestReconcile_ResolvesLostWebhook(t *testing.T) {
+	env := newPaymentEnv(t)
+
+	p := env.pay(t, payments.CardNoWebhook)
+	if p.Status != domain.PaymentPending {
+		t.Fatalf("payment = %+v, want pending", p)
+	}
+
+	if n, _ := env.payments.Reconcile(context.Background(), time.Now(), time.Hour); n != 0 {
+		t.Errorf("Reconcile() touched a fresh payment")
+	}
+	n, err := env.payments.Reconcile(context.Background(), time.Now().Add(time.Hour), time.Minute)
+	if err != nil || n != 1 {
+		t.Fatalf("Reconcile() = %d, %v; want 1", n, err)
+	}
+	if got := env.status(t); got != domain.StatusPaid {
+		t.Errorf("order status = %s, want PAID", got)
+	}
+}
+
+func TestPayment_CancelledWhileProcessingIsRefunded(t *testing.T) {
+	env := newPaymentEnv(t)
+	ctx := context.Background()
+
+	p := env.pay(t, payments.CardNoWebhook)
+	if _, err := env.orders.ChangeStatus(ctx, service.ChangeStatusInput{OrderID: env.order.ID, ActorID: 1, Status: domain.StatusCancelled}); err != nil {
+		t.Fatalf("cancel error = %v", err)
+	}
+
+	if _, err := env.payments.Reconcile(ctx, time.Now().Add(time.Hour), time.Minute); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	got, _ := env.payments.Latest(1, env.order.ID)
+	if got.Status != domain.PaymentRefunded || got.FailureReason != "order_cancelled" {
+		t.Errorf("payment = %+v, want refunded", got)
+	}
+	if intent, _ := env.provider.Intent(ctx, p.IntentID); intent.Status != payments.IntentRefunded {
+		t.Errorf("intent status = %s, want refunded", intent.Status)
+	}
+}
+
+func TestRefund_BySeller(t *testing.T) {
+	env := newPaymentEnv(t)
+	ctx := context.Background()
+
+	if _, err := env.payments.Refund(ctx, 10, env.order.ID); !service.IsNotRefundable(err) {
+		t.Fatalf("refund unpaid error = %v, want not refundable", err)
+	}
+	env.pay(t, payments.CardSuccess)
+
+	if _, err := env.payments.Refund(ctx, 1, env.order.ID); !service.IsForbidden(err) {
+		t.Fatalf("buyer refund error = %v, want forbidden", err)
+	}
+	if _, err := env.orders.ChangeStatus(ctx, service.ChangeStatusInput{OrderID: env.order.ID, ActorID: 10, Status: domain.StatusRefunded}); !service.IsForbidden(err) {
+		t.Fatalf("refund via status change error = %v, want forbidden", err)
+	}
+
+	p, err := env.payments.Refund(ctx, 10, env.order.ID)
+	if err != nil || p.Status != domain.PaymentRefunded {
+		t.Fatalf("Refund() = %+v, %v", p, err)
+	}
+	if got := env.status(t); got != domain.StatusRefunded {
+		t.Errorf("order status = %s, want REFUNDED", got)
+	}
+}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id          SERIAL PRIMARY KEY,
+    buyer_id    INTEGER          NOT NULL,
+    buyer_email VARCHAR(255)     NOT NULL,
+    seller_id   INTEGER          NOT NULL,
+    status      VARCHAR(32)      NOT NULL,
+    total       DOUBLE PRECISION NOT NULL,
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_buyer_id ON orders(buyer_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_seller_id ON orders(seller_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         SERIAL PRIMARY KEY,
+    order_id   INTEGER          NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id INTEGER          NOT NULL,
+    name       VARCHAR(255)     NOT NULL,
+    unit_price DOUBLE PRECISION NOT NULL,
+    quantity   INTEGER          NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN reservation_ref VARCHAR(64) NOT NULL DEFAULT '';
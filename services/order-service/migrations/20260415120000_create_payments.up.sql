@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id             SERIAL PRIMARY KEY,
+    order_id       INTEGER     NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    provider       VARCHAR(32) NOT NULL,
+    intent_id      VARCHAR(64) NOT NULL UNIQUE,
+    amount         BIGINT      NOT NULL CHECK (amount > 0),
+    currency       VARCHAR(3)  NOT NULL,
+    status         VARCHAR(16) NOT NULL,
+    failure_reason VARCHAR(64) NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id);
+CREATE INDEX IF NOT EXISTS idx_payments_status ON payments(status, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id          VARCHAR(64) PRIMARY KEY,
+    provider    VARCHAR(32) NOT NULL,
+    type        VARCHAR(32) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
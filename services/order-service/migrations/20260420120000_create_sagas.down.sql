@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_orders_saga_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS saga_id;
+DROP TABLE IF EXISTS sagas;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS sagas (
+    id             VARCHAR(36)  PRIMARY KEY,
+    buyer_id       INTEGER      NOT NULL,
+    buyer_email    VARCHAR(255) NOT NULL,
+    payment_method VARCHAR(64)  NOT NULL DEFAULT '',
+    status         VARCHAR(16)  NOT NULL,
+    step           VARCHAR(32)  NOT NULL,
+    placed         BOOLEAN      NOT NULL DEFAULT FALSE,
+    attempts       INTEGER      NOT NULL DEFAULT 0,
+    deadline       TIMESTAMPTZ  NOT NULL,
+    error          VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sagas_buyer_id ON sagas(buyer_id, status);
+CREATE INDEX IF NOT EXISTS idx_sagas_deadline ON sagas(status, deadline);
+
+ALTER TABLE orders ADD COLUMN saga_id VARCHAR(36) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_orders_saga_id ON orders(saga_id);
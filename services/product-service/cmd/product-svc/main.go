@@ -10,11 +10,17 @@ import (
 	"time"
 
 	cfgpkg "GoProduct/internal"
+	"GoProduct/internal/clients"
+	"GoProduct/internal/commands"
+	"GoProduct/internal/domain"
+	"GoProduct/internal/export"
 	"GoProduct/internal/http/handlers"
 	"GoProduct/internal/http/middleware"
 	"GoProduct/internal/repo"
 	"GoProduct/internal/service"
+	"GoProduct/internal/storage"
 	jwtutil "GoProduct/pkg/jwt"
+	"GoProduct/pkg/kafka"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/postgres"
@@ -34,25 +40,142 @@ func main() {
 
 	verifier := jwtutil.NewVerifier(cfg.JWTSecret)
 
+	var media storage.Storage
+	if cfg.MediaStorage == "s3" {
+		media = storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PublicURL: cfg.S3PublicURL,
+		})
+	} else {
+		media = storage.NewLocal(cfg.MediaDir, cfg.MediaBaseURL)
+	}
+
+	producer := kafka.NewProducer(cfg.KafkaBrokers)
+	defer producer.Close()
+
 	productsRepo := repo.NewProducts(db)
-	productSvc := service.NewProductService(productsRepo)
-	h := handlers.NewProductHandler(productSvc)
+	categoriesRepo := repo.NewCategories(db)
+	productSvc := service.NewProductService(productsRepo, categoriesRepo, producer)
+	imageSvc := service.NewImageService(productsRepo, repo.NewImages(db), media, service.ImageLimits{
+		MaxBytes:      int64(cfg.ImageMaxBytes),
+		MaxPixels:     cfg.ImageMaxPixels,
+		MaxPerProduct: cfg.ImageMaxPerProduct,
+	})
+	h := handlers.NewProductHandler(productSvc, imageSvc)
+	imageH := handlers.NewImageHandler(imageSvc)
+	categoryH := handlers.NewCategoryHandler(service.NewCategoryService(categoriesRepo))
+	variantH := handlers.NewVariantHandler(service.NewVariantService(productsRepo, repo.NewVariants(db)))
+	moderationH := handlers.NewModerationHandler(productSvc, h)
+	trashSvc := service.NewTrashService(productsRepo, cfg.ProductRestoreGrace, imageSvc.DeleteFiles)
+	trashH := handlers.NewTrashHandler(trashSvc, h)
+	historyH := handlers.NewHistoryHandler(productSvc, h)
+	importSvc := service.NewImportService(productSvc, repo.NewImports(db))
+	importH := handlers.NewImportHandler(importSvc, int64(cfg.ImportMaxBytes))
+	exportH := handlers.NewExportHandler(productSvc, export.Feed{
+		Title:    cfg.FeedTitle,
+		BaseURL:  cfg.FeedBaseURL,
+		Currency: cfg.FeedCurrency,
+		ImageURL: func(img *domain.ProductImage) string { return imageSvc.URLs(img).Original },
+	})
+	sellerSvc := service.NewSellerService(productsRepo, repo.NewRatings(db),
+		clients.NewUsers(cfg.UserServiceURL, cfg.UserTimeout),
+		clients.NewOrders(cfg.OrderServiceURL, cfg.OrderTimeout))
+	sellerH := handlers.NewSellerHandler(sellerSvc, h)
+
+	cartSvc := service.NewCartService(repo.NewCarts(db), productsRepo)
+	cartH := handlers.NewCartHandler(cartSvc)
+
+	inventorySvc := service.NewInventoryService(repo.NewInventory(db), cfg.ReservationTTL)
+	inventoryH := handlers.NewInventoryHandler(inventorySvc)
 
 	r := gin.Default()
 
+	if local, ok := media.(*storage.Local); ok {
+		r.Static("/media", local.Dir())
+	}
+
+	catalog := r.Group("", middleware.OptionalAuth(verifier), middleware.PublicCache(cfg.CatalogCacheMaxAge))
+	{
+		catalog.GET("/products/", h.List)
+		catalog.GET("/products/export", exportH.Export)
+		catalog.GET("/products/:id", h.Get)
+		catalog.GET("/products/:id/images", imageH.List)
+		catalog.GET("/products/:id/variants", variantH.List)
+		catalog.GET("/products/:id/price-history", historyH.PriceHistory)
+		catalog.GET("/sellers/:id", sellerH.Profile)
+		catalog.GET("/sellers/:id/products", sellerH.Products)
+		catalog.GET("/categories/", categoryH.List)
+		catalog.GET("/categories/:slug", categoryH.Get)
+	}
+
 	products := r.Group("/products", middleware.AuthRequired(verifier))
 	{
-		products.GET("/", h.List)
-		products.GET("/:id", h.Get)
+		products.GET("/:id/history", historyH.History)
+		products.GET("/import/:job_id", importH.Get)
+		products.GET("/import/:job_id/errors", importH.Errors)
 
 		write := products.Group("", middleware.RequireActive())
 		{
 			write.POST("/", h.Create)
+			write.POST("/import", importH.Start)
+			write.POST("/bulk", h.Bulk)
 			write.PUT("/:id", h.Update)
+			write.PATCH("/:id", h.Patch)
 			write.DELETE("/:id", h.Delete)
+			write.POST("/:id/restore", trashH.Restore)
+			write.POST("/:id/revert", historyH.Revert)
+			write.POST("/:id/stock", h.AdjustStock)
+			write.POST("/:id/status", h.ChangeStatus)
+			write.POST("/:id/images", imageH.Upload)
+			write.PUT("/:id/images/order", imageH.Reorder)
+			write.DELETE("/:id/images/:image_id", imageH.Delete)
+			write.POST("/:id/variants", variantH.Create)
+			write.PUT("/:id/variants/:variant_id", variantH.Update)
+			write.DELETE("/:id/variants/:variant_id", variantH.Delete)
 		}
 	}
 
+	me := r.Group("/me", middleware.AuthRequired(verifier))
+	{
+		me.GET("/products", h.Mine)
+	}
+
+	sellers := r.Group("/sellers", middleware.AuthRequired(verifier), middleware.RequireActive())
+	{
+		sellers.PUT("/:id/rating", sellerH.Rate)
+	}
+
+	admin := r.Group("/admin", middleware.AuthRequired(verifier), middleware.RequireAdmin(cfg.AdminUserIDs))
+	{
+		admin.GET("/moderation", moderationH.Queue)
+		admin.POST("/moderation/:id/approve", moderationH.Approve)
+		admin.POST("/moderation/:id/reject", moderationH.Reject)
+		admin.GET("/products/deleted", trashH.Deleted)
+		admin.GET("/products/:id/history", historyH.AdminHistory)
+	}
+
+	internal := r.Group("/internal", middleware.InternalOnly(cfg.InternalToken))
+	{
+		internal.PUT("/categories/:slug", categoryH.Save)
+		internal.POST("/reservations", inventoryH.Reserve)
+		internal.GET("/reservations/:ref", inventoryH.Get)
+		internal.POST("/reservations/:ref/commit", inventoryH.Commit)
+		internal.POST("/reservations/:ref/release", inventoryH.Release)
+	}
+
+	cart := r.Group("/cart", middleware.OptionalAuth(verifier))
+	{
+		cart.GET("/items", cartH.Get)
+		cart.POST("/items", cartH.AddItem)
+		cart.DELETE("/items", cartH.Clear)
+		cart.PATCH("/items/:product_id", cartH.UpdateItem)
+		cart.DELETE("/items/:product_id", cartH.RemoveItem)
+	}
+
 	srv := &http.Server{
 		Addr:         cfg.HTTPAddr,
 		Handler:      r,
@@ -60,6 +183,19 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 	}
 
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	go cartSvc.RunCleanup(bgCtx, cfg.CartCleanupInterval, cfg.CartGuestTTL, cfg.CartUserTTL)
+	go inventorySvc.RunExpiry(bgCtx, cfg.ReservationSweepInterval)
+	go trashSvc.RunPurge(bgCtx, cfg.ProductPurgeInterval, cfg.ProductRetention)
+	go importSvc.RunImports(bgCtx, cfg.ImportPollInterval)
+
+	cmdConsumer := commands.NewConsumer(cfg.KafkaBrokers, cfg.KafkaGroupID, commands.NewHandler(inventorySvc, cartSvc), producer)
+	go func() {
+		if err := cmdConsumer.Start(bgCtx); err != nil {
+			log.Printf("command consumer: %v", err)
+		}
+	}()
+
 	go func() {
 		log.Printf("product-svc listening on %s", cfg.HTTPAddr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -71,6 +207,8 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	stopBackground()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
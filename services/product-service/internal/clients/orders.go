@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned when order-service rejects the buyer's
+// credentials forwarded with a request.
+var ErrUnauthorized = errors.New("order service: unauthorized")
+
+// Orders reads a buyer's orders from order-service on their behalf, by
+// forwarding their Authorization header.
+type Orders struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewOrders(baseURL string, timeout time.Duration) *Orders {
+	return &Orders{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// DeliveredFrom reports whether the buyer behind authorization has at least
+// one delivered order from sellerID.
+func (c *Orders) DeliveredFrom(ctx context.Context, authorization string, sellerID uint) (bool, error) {
+	q := url.Values{
+		"status":    {"DELIVERED"},
+		"seller_id": {strconv.FormatUint(uint64(sellerID), 10)},
+		"limit":     {"1"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/orders?"+q.Encode(), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("order service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return false, ErrUnauthorized
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("order service: GET /orders: status %d", resp.StatusCode)
+	}
+
+	var orders []struct {
+		ID uint `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&orders); err != nil {
+		return false, fmt.Errorf("order service: decode orders: %w", err)
+	}
+	return len(orders) > 0, nil
+}
@@ -0,0 +1,45 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOrders_DeliveredFrom(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path != "/orders" || q.Get("status") != "DELIVERED" || q.Get("limit") != "1":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Header.Get("Authorization") != "Bearer buyer":
+			w.WriteHeader(http.StatusUnauthorized)
+		case q.Get("seller_id") == "7":
+			_, _ = w.Write([]byte(`[{"id":3,"seller_id":7,"status":"DELIVERED"}]`))
+		case q.Get("seller_id") == "9":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewOrders(srv.URL+"/", time.Second)
+	ctx := context.Background()
+
+	if ok, err := c.DeliveredFrom(ctx, "Bearer buyer", 7); err != nil || !ok {
+		t.Errorf("DeliveredFrom(7) = %v, %v; want true", ok, err)
+	}
+	if ok, err := c.DeliveredFrom(ctx, "Bearer buyer", 8); err != nil || ok {
+		t.Errorf("DeliveredFrom(8) = %v, %v; want false", ok, err)
+	}
+	if _, err := c.DeliveredFrom(ctx, "Bearer stranger", 7); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("DeliveredFrom() with bad token error = %v, want ErrUnauthorized", err)
+	}
+	if _, err := c.DeliveredFrom(ctx, "Bearer buyer", 9); err == nil || errors.Is(err, ErrUnauthorized) {
+		t.Errorf("DeliveredFrom() on server error = %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUserNotFound is returned for users user-service does not show, either
+// unknown or not yet activated.
+var ErrUserNotFound = errors.New("user service: user not found")
+
+// User is a user's public profile as served by user-service.
+type User struct {
+	ID          uint      `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	MemberSince time.Time `json:"member_since"`
+}
+
+// Users reads public profiles from user-service.
+type Users struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewUsers(baseURL string, timeout time.Duration) *Users {
+	return &Users{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *Users) Get(ctx context.Context, id uint) (*User, error) {
+	path := "/users/" + strconv.FormatUint(uint64(id), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrUserNotFound
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("user service: GET %s: status %d", path, resp.StatusCode)
+	}
+
+	var u User
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return nil, fmt.Errorf("user service: decode user: %w", err)
+	}
+	return &u, nil
+}
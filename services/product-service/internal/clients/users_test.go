@@ -0,0 +1,40 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUsers_Get(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/7":
+			_, _ = w.Write([]byte(`{"user_id":7,"display_name":"Aigerim S.","member_since":"2026-01-02T00:00:00Z"}`))
+		case "/users/8":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewUsers(srv.URL+"/", time.Second)
+
+	u, err := c.Get(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if u.ID != 7 || u.DisplayName != "Aigerim S." || u.MemberSince.Year() != 2026 {
+		t.Errorf("Get() = %+v", u)
+	}
+	if _, err := c.Get(context.Background(), 8); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Get(unknown) error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := c.Get(context.Background(), 9); err == nil || errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Get() on server error = %v", err)
+	}
+}
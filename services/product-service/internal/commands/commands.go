@@ -0,0 +1,201 @@
+// Package commands executes stock and cart commands sent over Kafka by
+// other services' sagas and replies with the outcome.
+package commands
+
+import (
+	"GoProduct/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Topic receives commands for product-service.
+const Topic = "product.commands"
+
+const (
+	TypeReserveStock = "stock.reserve"
+	TypeReleaseStock = "stock.release"
+	TypeClearCart    = "cart.clear"
+)
+
+// Reply error codes.
+const (
+	ErrOutOfStock      = "out_of_stock"
+	ErrProductNotFound = "product_not_found"
+	ErrClosed          = "reservation_closed"
+	ErrInvalid         = "invalid_command"
+	ErrInternal        = "internal"
+)
+
+type Item struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+type Reservation struct {
+	Reference string `json:"reference"`
+	Items     []Item `json:"items"`
+}
+
+type Command struct {
+	ID      string `json:"id"`
+	SagaID  string `json:"saga_id"`
+	Type    string `json:"type"`
+	ReplyTo string `json:"reply_to"`
+
+	UserID       uint          `json:"user_id,omitempty"`
+	Reservations []Reservation `json:"reservations,omitempty"`
+}
+
+type Reply struct {
+	CommandID string `json:"command_id"`
+	SagaID    string `json:"saga_id"`
+	Type      string `json:"type"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Publisher interface {
+	Send(ctx context.Context, topic string, key string, value interface{}) error
+}
+
+// Handler runs commands. Every command is idempotent, so redelivered or
+// retried commands are safe.
+type Handler struct {
+	inventory *service.InventoryService
+	carts     *service.CartService
+}
+
+func NewHandler(inventory *service.InventoryService, carts *service.CartService) *Handler {
+	return &Handler{inventory: inventory, carts: carts}
+}
+
+func (h *Handler) Handle(cmd Command) Reply {
+	reply := Reply{CommandID: cmd.ID, SagaID: cmd.SagaID, Type: cmd.Type, OK: true}
+
+	var err error
+	switch cmd.Type {
+	case TypeReserveStock:
+		err = h.reserve(cmd.Reservations)
+	case TypeReleaseStock:
+		h.release(cmd.Reservations)
+	case TypeClearCart:
+		if cmd.UserID == 0 {
+			err = errInvalid
+			break
+		}
+		_, err = h.carts.Clear(service.CartOwner{UserID: cmd.UserID})
+	default:
+		err = errInvalid
+	}
+
+	if err != nil {
+		reply.OK = false
+		reply.Error = errorCode(err)
+	}
+	return reply
+}
+
+// reserve makes every reservation or none: on failure the ones already
+// made are released again.
+func (h *Handler) reserve(reservations []Reservation) error {
+	if len(reservations) == 0 {
+		return errInvalid
+	}
+	for i, r := range reservations {
+		items := make([]service.ReserveItem, 0, len(r.Items))
+		for _, it := range r.Items {
+			items = append(items, service.ReserveItem{ProductID: it.ProductID, Quantity: it.Quantity})
+		}
+		if _, err := h.inventory.Reserve(r.Reference, items); err != nil {
+			h.release(reservations[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// release is best effort: unknown references were never reserved and
+// committed ones are no longer ours to release.
+func (h *Handler) release(reservations []Reservation) {
+	for _, r := range reservations {
+		_, err := h.inventory.Release(r.Reference)
+		if err != nil && !service.IsReservationNotFound(err) && !service.IsReservationClosed(err) {
+			log.Printf("commands: release %s: %v", r.Reference, err)
+		}
+	}
+}
+
+var errInvalid = fmt.Errorf("commands: %s", ErrInvalid)
+
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, errInvalid), service.IsInvalidReservation(err):
+		return ErrInvalid
+	case service.IsInsufficientStock(err):
+		return ErrOutOfStock
+	case service.IsNotFound(err):
+		return ErrProductNotFound
+	case service.IsReservationClosed(err):
+		return ErrClosed
+	}
+	return ErrInternal
+}
+
+// Consumer reads commands from Topic and publishes replies to each
+// command's ReplyTo topic.
+type Consumer struct {
+	reader    *kafka.Reader
+	handler   *Handler
+	publisher Publisher
+}
+
+func NewConsumer(brokers, groupID string, handler *Handler, publisher Publisher) *Consumer {
+	log.Printf("Initializing command consumer - brokers: %s, topic: %s, groupID: %s", brokers, Topic, groupID)
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     strings.Split(brokers, ","),
+		Topic:       Topic,
+		GroupID:     groupID,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		StartOffset: kafka.FirstOffset,
+	})
+	return &Consumer{reader: reader, handler: handler, publisher: publisher}
+}
+
+func (c *Consumer) Start(ctx context.Context) error {
+	for {
+		msg, err := c.reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return c.reader.Close()
+			}
+			log.Printf("commands: read error: %v", err)
+			continue
+		}
+		if err := c.handleMessage(ctx, msg.Value); err != nil {
+			log.Printf("commands: %v", err)
+		}
+	}
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, value []byte) error {
+	var cmd Command
+	if err := json.Unmarshal(value, &cmd); err != nil {
+		return fmt.Errorf("unmarshal error: %w", err)
+	}
+
+	reply := c.handler.Handle(cmd)
+	if cmd.ReplyTo == "" {
+		return nil
+	}
+	if err := c.publisher.Send(ctx, cmd.ReplyTo, cmd.SagaID, reply); err != nil {
+		return fmt.Errorf("reply to %s for %s: %w", cmd.ReplyTo, cmd.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type sent struct {
+	topic string
+	reply Reply
+}
+
+type fakePublisher struct {
+	sent []sent
+}
+
+func (p *fakePublisher) Send(_ context.Context, topic, _ string, value interface{}) error {
+	p.sent = append(p.sent, sent{topic: topic, reply: value.(Reply)})
+	return nil
+}
+
+func newTestHandler(t *testing.T) (*Handler, *repo.Products, *service.CartService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Product{}, &domain.ProductImage{}, &domain.ProductVariant{}, &domain.VariantAttribute{}, &domain.ProductRevision{}, &domain.Reservation{}, &domain.Cart{}, &domain.CartItem{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+
+	products := repo.NewProducts(db)
+	carts := service.NewCartService(repo.NewCarts(db), products)
+	inventory := service.NewInventoryService(repo.NewInventory(db), time.Hour)
+	return NewHandler(inventory, carts), products, carts
+}
+
+func TestHandle_ReserveIsAllOrNothing(t *testing.T) {
+	h, products, _ := newTestHandler(t)
+
+	a := &domain.Product{UserID: 1, Name: "A", Price: 1, Stock: 2, Status: domain.StatusPublished}
+	b := &domain.Product{UserID: 2, Name: "B", Price: 1, Stock: 1, Status: domain.StatusPublished}
+	_ = products.Create(a)
+	_ = products.Create(b)
+
+	reply := h.Handle(Command{ID: "c1", SagaID: "s1", Type: TypeReserveStock, Reservations: []Reservation{
+		{Reference: "r-a", Items: []Item{{ProductID: a.ID, Quantity: 2}}},
+		{Reference: "r-b", Items: []Item{{ProductID: b.ID, Quantity: 2}}},
+	}})
+	if reply.OK || reply.Error != ErrOutOfStock || reply.SagaID != "s1" || reply.CommandID != "c1" {
+		t.Fatalf("reply = %+v, want out_of_stock", reply)
+	}
+	if got, _ := products.GetByID(a.ID); got.Stock != 2 {
+		t.Errorf("stock of A = %d, want 2 after rollback", got.Stock)
+	}
+
+	// откатанную резервацию нельзя переиспользовать
+	if reply := h.Handle(Command{Type: TypeReserveStock, Reservations: []Reservation{
+		{Reference: "r-a", Items: []Item{{ProductID: a.ID, Quantity: 1}}},
+	}}); reply.OK || reply.Error != ErrClosed {
+		t.Fatalf("reserve of a released ref reply = %+v, want reservation_closed", reply)
+	}
+
+	cmd := Command{ID: "c2", Type: TypeReserveStock, Reservations: []Reservation{
+		{Reference: "r-a2", Items: []Item{{ProductID: a.ID, Quantity: 1}}},
+	}}
+	for i := 0; i < 2; i++ {
+		if reply := h.Handle(cmd); !reply.OK {
+			t.Fatalf("reserve #%d reply = %+v", i+1, reply)
+		}
+	}
+	if got, _ := products.GetByID(a.ID); got.Stock != 1 {
+		t.Errorf("stock of A = %d, want 1 after a repeated reserve", got.Stock)
+	}
+
+	release := Command{Type: TypeReleaseStock, Reservations: []Reservation{{Reference: "r-a2"}, {Reference: "never"}}}
+	if reply := h.Handle(release); !reply.OK {
+		t.Fatalf("release reply = %+v", reply)
+	}
+	if got, _ := products.GetByID(a.ID); got.Stock != 2 {
+		t.Errorf("stock of A = %d, want 2 after release", got.Stock)
+	}
+}
+
+func TestHandle_ClearCartAndInvalid(t *testing.T) {
+	h, products, carts := newTestHandler(t)
+
+	p := &domain.Product{UserID: 1, Name: "A", Price: 1, Stock: 1, Status: domain.StatusPublished}
+	_ = products.Create(p)
+	if _, err := carts.AddItem(service.CartOwner{UserID: 7}, p.ID, 1); err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+
+	if reply := h.Handle(Command{Type: TypeClearCart, UserID: 7}); !reply.OK {
+		t.Fatalf("clear reply = %+v", reply)
+	}
+	if v, _ := carts.Get(service.CartOwner{UserID: 7}); len(v.Items) != 0 {
+		t.Errorf("cart not cleared: %+v", v.Items)
+	}
+
+	for _, cmd := range []Command{{Type: TypeClearCart}, {Type: "stock.teleport"}, {Type: TypeReserveStock}} {
+		if reply := h.Handle(cmd); reply.OK || reply.Error != ErrInvalid {
+			t.Errorf("%s reply = %+v, want invalid", cmd.Type, reply)
+		}
+	}
+}
+
+func TestConsumer_RepliesToReplyTopic(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	pub := &fakePublisher{}
+	c := &Consumer{handler: h, publisher: pub}
+
+	value, _ := json.Marshal(Command{ID: "c1", SagaID: "s1", Type: TypeClearCart, UserID: 3, ReplyTo: "order.saga.replies"})
+	if err := c.handleMessage(context.Background(), value); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if len(pub.sent) != 1 || pub.sent[0].topic != "order.saga.replies" || !pub.sent[0].reply.OK {
+		t.Fatalf("sent = %+v", pub.sent)
+	}
+
+	if err := c.handleMessage(context.Background(), []byte("{")); err == nil {
+		t.Errorf("handleMessage() of a bad payload error = nil")
+	}
+}
@@ -3,6 +3,9 @@ package internal
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +15,69 @@ type Config struct {
 	PGURL        string
 	JWTSecret    string
 	KafkaBrokers string
+	KafkaGroupID string
+	// InternalToken authenticates other services calling /internal routes.
+	InternalToken string
+	// AdminUserIDs may use the /admin routes, e.g. the moderation queue.
+	AdminUserIDs []uint
+
+	// Carts idle longer than their TTL are considered abandoned and removed
+	// by a background job running every CartCleanupInterval.
+	CartGuestTTL        time.Duration
+	CartUserTTL         time.Duration
+	CartCleanupInterval time.Duration
+
+	// Unpaid stock reservations are released after ReservationTTL, checked
+	// every ReservationSweepInterval.
+	ReservationTTL           time.Duration
+	ReservationSweepInterval time.Duration
+
+	// Deleted products can be restored by their owner for ProductRestoreGrace
+	// and are purged for good after ProductRetention, checked every
+	// ProductPurgeInterval.
+	ProductRestoreGrace  time.Duration
+	ProductRetention     time.Duration
+	ProductPurgeInterval time.Duration
+
+	// MediaStorage is "local" (files under MediaDir, served at MediaBaseURL)
+	// or "s3" for an S3-compatible bucket.
+	MediaStorage string
+	MediaDir     string
+	MediaBaseURL string
+	S3Endpoint   string
+	S3Region     string
+	S3Bucket     string
+	S3AccessKey  string
+	S3SecretKey  string
+	S3PublicURL  string
+
+	ImageMaxBytes      int
+	ImageMaxPixels     int
+	ImageMaxPerProduct int
+
+	// Product imports accept files up to ImportMaxBytes; queued ones are
+	// picked up every ImportPollInterval.
+	ImportMaxBytes     int
+	ImportPollInterval time.Duration
+
+	// The Merchant export feed links products as FeedBaseURL/products/<id>
+	// and prices them in FeedCurrency.
+	FeedTitle    string
+	FeedBaseURL  string
+	FeedCurrency string
+
+	// UserServiceURL is where seller profiles get display names from.
+	UserServiceURL string
+	UserTimeout    time.Duration
+
+	// OrderServiceURL is asked whether a buyer rating a seller has had an
+	// order from them delivered.
+	OrderServiceURL string
+	OrderTimeout    time.Duration
+
+	// Anonymous responses of the public catalog may be cached for
+	// CatalogCacheMaxAge.
+	CatalogCacheMaxAge time.Duration
 }
 
 func MustLoad() *Config {
@@ -22,6 +88,54 @@ func MustLoad() *Config {
 		PGURL:        mustEnv("PG_URL"),
 		JWTSecret:    mustEnv("JWT_SECRET"),
 		KafkaBrokers: getEnv("KAFKA_BROKERS", "kafka:9092"),
+		KafkaGroupID: getEnv("KAFKA_GROUP_ID", "product-service"),
+
+		InternalToken: getEnv("INTERNAL_TOKEN", ""),
+		AdminUserIDs:  getIDs("ADMIN_USER_IDS"),
+
+		CartGuestTTL:        getDuration("CART_GUEST_TTL", 30*24*time.Hour),
+		CartUserTTL:         getDuration("CART_USER_TTL", 90*24*time.Hour),
+		CartCleanupInterval: getDuration("CART_CLEANUP_INTERVAL", time.Hour),
+
+		ReservationTTL:           getDuration("RESERVATION_TTL", 15*time.Minute),
+		ReservationSweepInterval: getDuration("RESERVATION_SWEEP_INTERVAL", time.Minute),
+
+		ProductRestoreGrace:  getDuration("PRODUCT_RESTORE_GRACE", 30*24*time.Hour),
+		ProductRetention:     getDuration("PRODUCT_RETENTION", 90*24*time.Hour),
+		ProductPurgeInterval: getDuration("PRODUCT_PURGE_INTERVAL", time.Hour),
+
+		MediaStorage: getEnv("MEDIA_STORAGE", "local"),
+		MediaDir:     getEnv("MEDIA_DIR", "./media"),
+		MediaBaseURL: getEnv("MEDIA_BASE_URL", "http://localhost:8081/media"),
+		S3Endpoint:   getEnv("S3_ENDPOINT", ""),
+		S3Region:     getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:     getEnv("S3_BUCKET", ""),
+		S3AccessKey:  getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:  getEnv("S3_SECRET_KEY", ""),
+		S3PublicURL:  getEnv("S3_PUBLIC_URL", ""),
+
+		ImageMaxBytes:      getInt("IMAGE_MAX_BYTES", 5<<20),
+		ImageMaxPixels:     getInt("IMAGE_MAX_PIXELS", 40_000_000),
+		ImageMaxPerProduct: getInt("IMAGE_MAX_PER_PRODUCT", 10),
+
+		ImportMaxBytes:     getInt("IMPORT_MAX_BYTES", 10<<20),
+		ImportPollInterval: getDuration("IMPORT_POLL_INTERVAL", 5*time.Second),
+
+		FeedTitle:    getEnv("FEED_TITLE", "Go-Market"),
+		FeedBaseURL:  getEnv("FEED_BASE_URL", "http://localhost:8081"),
+		FeedCurrency: getEnv("FEED_CURRENCY", "KZT"),
+
+		UserServiceURL: getEnv("USER_SERVICE_URL", "http://user-service:8080"),
+		UserTimeout:    getDuration("USER_TIMEOUT", 2*time.Second),
+
+		OrderServiceURL: getEnv("ORDER_SERVICE_URL", "http://order-service:8083"),
+		OrderTimeout:    getDuration("ORDER_TIMEOUT", 2*time.Second),
+
+		CatalogCacheMaxAge: getDuration("CATALOG_CACHE_MAX_AGE", time.Minute),
+	}
+
+	if cfg.MediaStorage == "s3" && (cfg.S3Endpoint == "" || cfg.S3Bucket == "") {
+		log.Fatalf("MEDIA_STORAGE=s3 needs S3_ENDPOINT and S3_BUCKET")
 	}
 
 	return cfg
@@ -41,3 +155,34 @@ func getEnv(k, def string) string {
 	}
 	return def
 }
+
+func getDuration(k string, def time.Duration) time.Duration {
+	if v := os.Getenv(k); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+func getInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return def
+}
+
+// getIDs reads a comma-separated list of user ids, skipping bad entries.
+func getIDs(k string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(os.Getenv(k), ",") {
+		if n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64); err == nil && n > 0 {
+			ids = append(ids, uint(n))
+		} else if strings.TrimSpace(part) != "" {
+			log.Printf("config: %s: ignoring %q", k, part)
+		}
+	}
+	return ids
+}
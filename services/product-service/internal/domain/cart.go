@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Cart belongs either to a signed-in user or, for guests, is identified only
+// by its random Token. A guest cart is claimed or merged on login.
+type Cart struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	UserID    *uint      `gorm:"uniqueIndex"`
+	Token     string     `gorm:"size:64;not null;uniqueIndex"`
+	Items     []CartItem `gorm:"foreignKey:CartID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (c *Cart) IsGuest() bool {
+	return c.UserID == nil
+}
+
+// CartItem keeps a snapshot of the product name and price taken when the
+// item was added, so price changes can be shown to the buyer.
+type CartItem struct {
+	ID        uint    `gorm:"primaryKey;autoIncrement"`
+	CartID    uint    `gorm:"not null;uniqueIndex:idx_cart_items_cart_product"`
+	ProductID uint    `gorm:"not null;uniqueIndex:idx_cart_items_cart_product"`
+	Name      string  `gorm:"size:255;not null"`
+	UnitPrice float64 `gorm:"not null"`
+	Quantity  int     `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeBool   AttributeType = "bool"
+)
+
+// AttributeDef describes one attribute products of a category may carry.
+// Values, when set, lists the allowed values of a string attribute.
+type AttributeDef struct {
+	Name     string        `json:"name"`
+	Type     AttributeType `json:"type"`
+	Required bool          `json:"required,omitempty"`
+	Values   []string      `json:"values,omitempty"`
+}
+
+// Category groups products that share an attribute schema, e.g. laptops
+// with ram_gb and shoes with size.
+type Category struct {
+	Slug       string         `gorm:"primaryKey;size:64"`
+	Name       string         `gorm:"size:255;not null"`
+	Attributes []AttributeDef `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (c *Category) Attribute(name string) (AttributeDef, bool) {
+	for _, a := range c.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return AttributeDef{}, false
+}
+
+// Attributes are a product's specs, validated against its category. Values
+// are strings, float64 numbers or bools, as decoded from JSON.
+type Attributes map[string]any
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// ThumbnailSize is a thumbnail made for every uploaded image, no larger than
+// Max pixels on its longest side.
+type ThumbnailSize struct {
+	Name string
+	Max  int
+}
+
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", Max: 160},
+	{Name: "medium", Max: 480},
+	{Name: "large", Max: 1024},
+}
+
+// ProductImage is one picture of a product. The files themselves live in
+// object storage under Key: the original upload and one file per thumbnail
+// size. Images are shown in ascending Position.
+type ProductImage struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint   `gorm:"not null;index"`
+	Position    int    `gorm:"not null"`
+	Key         string `gorm:"size:255;not null"`
+	ContentType string `gorm:"size:64;not null"`
+	Size        int64  `gorm:"not null"`
+	Width       int    `gorm:"not null"`
+	Height      int    `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+func (i *ProductImage) OriginalKey() string {
+	return i.Key + "/original" + extension(i.ContentType)
+}
+
+// ThumbnailKey is where the thumbnail of the given size is stored. PNG and
+// GIF thumbnails are PNG to keep transparency, the rest are JPEG.
+func (i *ProductImage) ThumbnailKey(size string) string {
+	if i.ContentType == "image/png" || i.ContentType == "image/gif" {
+		return i.Key + "/" + size + ".png"
+	}
+	return i.Key + "/" + size + ".jpg"
+}
+
+// Keys lists every stored file of the image.
+func (i *ProductImage) Keys() []string {
+	keys := []string{i.OriginalKey()}
+	for _, s := range ThumbnailSizes {
+		keys = append(keys, i.ThumbnailKey(s.Name))
+	}
+	return keys
+}
+
+func extension(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".jpg"
+	}
+}
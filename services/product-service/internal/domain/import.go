@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+type ImportStatus string
+
+const (
+	ImportPending ImportStatus = "PENDING"
+	ImportRunning ImportStatus = "RUNNING"
+	ImportDone    ImportStatus = "DONE"
+	// ImportFailed means the file as a whole could not be read, e.g. a CSV
+	// without the required columns. Bad rows alone do not fail a job.
+	ImportFailed ImportStatus = "FAILED"
+)
+
+// Import file formats.
+const (
+	ImportCSV    = "csv"
+	ImportNDJSON = "ndjson"
+)
+
+// ImportJob is a seller's upload of products, processed in the background.
+// Rows are matched to the seller's products by SKU: existing ones are
+// updated, the rest created. A dry run only validates and counts.
+type ImportJob struct {
+	ID          uint         `gorm:"primaryKey;autoIncrement"`
+	SellerID    uint         `gorm:"not null;index"`
+	SellerEmail string       `gorm:"size:255"`
+	Format      string       `gorm:"size:16;not null"`
+	DryRun      bool         `gorm:"not null;default:false"`
+	Status      ImportStatus `gorm:"size:16;not null;default:'PENDING';index"`
+	// Payload is the uploaded file, dropped once the job has finished.
+	Payload []byte `gorm:"type:bytea"`
+	// Total is the number of rows in the file, known once it is parsed.
+	Total     int `gorm:"not null;default:0"`
+	Processed int `gorm:"not null;default:0"`
+	Created   int `gorm:"not null;default:0"`
+	Updated   int `gorm:"not null;default:0"`
+	Failed    int `gorm:"not null;default:0"`
+	// Error is why a FAILED job could not be processed at all.
+	Error      string           `gorm:"type:text"`
+	Errors     []ImportRowError `gorm:"type:jsonb;serializer:json"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// ImportRowError is why one row of an import was skipped. Row is the line
+// of the file the row starts on.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+func (j *ImportJob) Finished() bool {
+	return j.Status == ImportDone || j.Status == ImportFailed
+}
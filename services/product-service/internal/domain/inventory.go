@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInsufficientStock is returned when a reservation asks for more units
+// than a product has in stock.
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+)
+
+// Reservation holds Quantity units of a product for an order that is not paid
+// yet. The units are taken out of Product.Stock when reserved: committing
+// makes that final, releasing (or expiry) puts them back.
+//
+// Reference groups the rows of one reservation, one row per product.
+type Reservation struct {
+	ID        uint              `gorm:"primaryKey;autoIncrement"`
+	Reference string            `gorm:"size:64;not null;uniqueIndex:idx_reservations_ref_product"`
+	ProductID uint              `gorm:"not null;uniqueIndex:idx_reservations_ref_product"`
+	Quantity  int               `gorm:"not null"`
+	Status    ReservationStatus `gorm:"size:16;not null;index"`
+	ExpiresAt time.Time         `gorm:"not null;index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
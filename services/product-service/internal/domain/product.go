@@ -1,15 +1,104 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+type ProductStatus string
+
+const (
+	StatusDraft         ProductStatus = "DRAFT"
+	StatusPendingReview ProductStatus = "PENDING_REVIEW"
+	StatusPublished     ProductStatus = "PUBLISHED"
+	StatusRejected      ProductStatus = "REJECTED"
+	StatusArchived      ProductStatus = "ARCHIVED"
+)
+
+// ErrInvalidTransition is returned when a status change is not allowed by
+// the product lifecycle.
+var ErrInvalidTransition = errors.New("invalid product status transition")
+
+// transitions is the product lifecycle. Sellers submit drafts for review,
+// moderators publish or reject them, and sellers archive what they no
+// longer sell. Archived and rejected products go back through review.
+var transitions = map[ProductStatus][]ProductStatus{
+	StatusDraft:         {StatusPendingReview, StatusArchived},
+	StatusPendingReview: {StatusPublished, StatusRejected, StatusDraft},
+	StatusPublished:     {StatusArchived, StatusRejected},
+	StatusRejected:      {StatusPendingReview, StatusDraft, StatusArchived},
+	StatusArchived:      {StatusDraft},
+}
+
+func IsKnownStatus(s ProductStatus) bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+func CanTransition(from, to ProductStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Product struct {
-	ID          uint    `gorm:"primaryKey;autoIncrement"`
-	UserID      uint    `gorm:"not null"`
+	ID     uint `gorm:"primaryKey;autoIncrement"`
+	UserID uint `gorm:"not null;uniqueIndex:idx_products_user_sku"`
+	// SKU is the seller's own code for the product, unique per seller. It is
+	// optional and what imports match existing products by.
+	SKU         *string `gorm:"column:sku;size:64;uniqueIndex:idx_products_user_sku"`
 	Name        string  `gorm:"size:255;not null"`
 	Description string  `gorm:"type:text"`
 	Price       float64 `gorm:"not null"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// Category is the slug of the category whose schema Attributes follow.
+	// Empty for uncategorised products, which carry no attributes.
+	Category   string     `gorm:"size:64;not null;default:'';index"`
+	Attributes Attributes `gorm:"type:jsonb;serializer:json"`
+	// Stock is the number of units that can still be sold. Units held by
+	// active reservations are already subtracted.
+	Stock  int           `gorm:"not null;default:0;check:chk_products_stock,stock >= 0"`
+	Status ProductStatus `gorm:"size:32;not null;default:'DRAFT';index"`
+	// RejectionReason explains the last moderation rejection.
+	RejectionReason string `gorm:"type:text"`
+	// SellerEmail is where moderation decisions are sent, taken from the
+	// seller's token when the product is created or submitted.
+	SellerEmail string `gorm:"size:255"`
+	// Version goes up with every write that changes what a GET of the
+	// product returns, including stock moved by checkouts, images and
+	// variants, and is the product's ETag.
+	Version   int `gorm:"not null;default:1"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt marks a soft-deleted product. Such products are hidden from
+	// every query unless it is Unscoped, can be restored by their owner for
+	// a while and are purged for good later.
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// Images and Variants are loaded by the products repo, images in
+	// display order.
+	Images   []ProductImage   `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variants []ProductVariant `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+func (p *Product) OutOfStock() bool {
+	return p.Stock <= 0
+}
+
+func (p *Product) Published() bool {
+	return p.Status == StatusPublished
+}
+
+// Transition moves the product to status to, enforcing the lifecycle.
+func (p *Product) Transition(to ProductStatus) error {
+	if !CanTransition(p.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, p.Status, to)
+	}
+	p.Status = to
+	return nil
 }
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// What a revision records.
+const (
+	RevisionCreated       = "created"
+	RevisionUpdated       = "updated"
+	RevisionStatusChanged = "status_changed"
+	RevisionDeleted       = "deleted"
+	RevisionRestored      = "restored"
+	RevisionReverted      = "reverted"
+)
+
+// ProductSnapshot is the part of a product revisions keep track of. Stock
+// is left out: checkouts move it all the time and it has its own history
+// in reservations.
+type ProductSnapshot struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Price       float64       `json:"price"`
+	Category    string        `json:"category"`
+	Attributes  Attributes    `json:"attributes"`
+	Status      ProductStatus `json:"status"`
+}
+
+func (p *Product) Snapshot() ProductSnapshot {
+	attrs := p.Attributes
+	if attrs == nil {
+		attrs = Attributes{}
+	}
+	return ProductSnapshot{
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Category:    p.Category,
+		Attributes:  attrs,
+		Status:      p.Status,
+	}
+}
+
+// Change is the old and new value of one snapshot field.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Diff maps snapshot fields, by their JSON name, to how they changed.
+type Diff map[string]Change
+
+// DiffSnapshots compares two snapshots field by field. A nil from means the
+// product did not exist before, so every field counts as changed.
+func DiffSnapshots(from *ProductSnapshot, to ProductSnapshot) Diff {
+	after := snapshotFields(to)
+	before := map[string]any{}
+	if from != nil {
+		before = snapshotFields(*from)
+	}
+
+	d := Diff{}
+	for name, v := range after {
+		if old, ok := before[name]; !ok || !reflect.DeepEqual(old, v) {
+			d[name] = Change{From: before[name], To: v}
+		}
+	}
+	return d
+}
+
+func snapshotFields(s ProductSnapshot) map[string]any {
+	b, _ := json.Marshal(s)
+	var m map[string]any
+	_ = json.Unmarshal(b, &m)
+	return m
+}
+
+// ProductRevision is an immutable record of one change to a product: who
+// made it, when, what changed and what the product looked like after.
+type ProductRevision struct {
+	ID        uint `gorm:"primaryKey;autoIncrement"`
+	ProductID uint `gorm:"not null;index:idx_product_revisions_product,priority:1"`
+	// Version is the product's version after the change.
+	Version  int             `gorm:"not null"`
+	ActorID  uint            `gorm:"not null"`
+	Action   string          `gorm:"size:32;not null"`
+	Changes  Diff            `gorm:"type:jsonb;serializer:json"`
+	Snapshot ProductSnapshot `gorm:"type:jsonb;serializer:json"`
+	// RevertOf is the revision a revert went back to.
+	RevertOf  *uint
+	CreatedAt time.Time `gorm:"index:idx_product_revisions_product,priority:2"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Seller ratings are whole stars.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// SellerRating is one buyer's score for a seller. A buyer has a single
+// rating per seller and rating again replaces it.
+type SellerRating struct {
+	ID        uint `gorm:"primaryKey;autoIncrement"`
+	SellerID  uint `gorm:"not null;uniqueIndex:idx_seller_ratings_seller_buyer"`
+	BuyerID   uint `gorm:"not null;uniqueIndex:idx_seller_ratings_seller_buyer"`
+	Score     int  `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
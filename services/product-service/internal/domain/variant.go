@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDuplicateSKU is returned when a seller already uses a SKU on another
+// variant.
+var ErrDuplicateSKU = errors.New("duplicate sku")
+
+// ProductVariant is one sellable version of a product, e.g. "size M, red".
+// SKU is unique per seller, not globally. A nil Price means the variant
+// sells at the product's price.
+type ProductVariant struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	ProductID  uint   `gorm:"not null;index"`
+	SellerID   uint   `gorm:"not null;uniqueIndex:idx_product_variants_seller_sku"`
+	SKU        string `gorm:"column:sku;size:64;not null;uniqueIndex:idx_product_variants_seller_sku"`
+	Price      *float64
+	Stock      int                `gorm:"not null;default:0;check:chk_product_variants_stock,stock >= 0"`
+	Attributes []VariantAttribute `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// VariantAttribute is one name/value pair of a variant, such as
+// size=M. Names are unique within a variant.
+type VariantAttribute struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	VariantID uint   `gorm:"not null;uniqueIndex:idx_variant_attributes_variant_name"`
+	Name      string `gorm:"size:64;not null;uniqueIndex:idx_variant_attributes_variant_name;index:idx_variant_attributes_name_value"`
+	Value     string `gorm:"size:128;not null;index:idx_variant_attributes_name_value"`
+}
+
+func (v *ProductVariant) OutOfStock() bool {
+	return v.Stock <= 0
+}
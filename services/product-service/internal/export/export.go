@@ -0,0 +1,170 @@
+// Package export writes product catalogs as CSV, NDJSON, XLSX or a Google
+// Merchant XML feed. Writers stream: each product is written as it comes,
+// so an export never holds the whole catalog.
+package export
+
+import (
+	"GoProduct/internal/domain"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	CSV      = "csv"
+	NDJSON   = "ndjson"
+	XLSX     = "xlsx"
+	Merchant = "merchant"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Writer writes products one at a time. Close finishes the document; it
+// does not close the underlying io.Writer.
+type Writer interface {
+	Write(p *domain.Product) error
+	Close() error
+}
+
+// Feed describes the shop in a Merchant feed. Product links are
+// BaseURL/products/<id>.
+type Feed struct {
+	Title    string
+	BaseURL  string
+	Currency string
+	// ImageURL gives the public URL of a product image.
+	ImageURL func(img *domain.ProductImage) string
+}
+
+type format struct {
+	contentType string
+	ext         string
+}
+
+var formats = map[string]format{
+	CSV:      {"text/csv; charset=utf-8", "csv"},
+	NDJSON:   {"application/x-ndjson", "ndjson"},
+	XLSX:     {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	Merchant: {"application/xml; charset=utf-8", "xml"},
+}
+
+// ContentType and Ext describe a format's output; both are empty for an
+// unknown format.
+func ContentType(f string) string { return formats[f].contentType }
+func Ext(f string) string         { return formats[f].ext }
+
+// New returns a writer of the given format. feed is only used by Merchant.
+func New(f string, w io.Writer, feed Feed) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCSV(w)
+	case NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case XLSX:
+		return newXLSX(w)
+	case Merchant:
+		return newMerchant(w, feed)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Columns are the columns of CSV and XLSX exports.
+var Columns = []string{"id", "sku", "name", "description", "price", "stock", "category", "attributes", "seller_id", "updated_at"}
+
+// cell is one column value, kept typed so XLSX can store numbers as
+// numbers.
+type cell struct {
+	text   string
+	number bool
+}
+
+func cells(p *domain.Product) []cell {
+	sku := ""
+	if p.SKU != nil {
+		sku = *p.SKU
+	}
+	attrs := ""
+	if len(p.Attributes) > 0 {
+		b, _ := json.Marshal(p.Attributes)
+		attrs = string(b)
+	}
+	return []cell{
+		{strconv.FormatUint(uint64(p.ID), 10), true},
+		{sku, false},
+		{p.Name, false},
+		{p.Description, false},
+		{strconv.FormatFloat(p.Price, 'f', -1, 64), true},
+		{strconv.Itoa(p.Stock), true},
+		{p.Category, false},
+		{attrs, false},
+		{strconv.FormatUint(uint64(p.UserID), 10), true},
+		{p.UpdatedAt.UTC().Format(time.RFC3339), false},
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSV(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(Columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (w *csvWriter) Write(p *domain.Product) error {
+	row := cells(p)
+	record := make([]string, len(row))
+	for i, c := range row {
+		record[i] = c.text
+	}
+	return w.w.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// ndjsonProduct is one NDJSON line, named like the CSV columns.
+type ndjsonProduct struct {
+	ID          uint              `json:"id"`
+	SKU         string            `json:"sku,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	Stock       int               `json:"stock"`
+	Category    string            `json:"category"`
+	Attributes  domain.Attributes `json:"attributes,omitempty"`
+	SellerID    uint              `json:"seller_id"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(p *domain.Product) error {
+	line := ndjsonProduct{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Stock:       p.Stock,
+		Category:    p.Category,
+		Attributes:  p.Attributes,
+		SellerID:    p.UserID,
+		UpdatedAt:   p.UpdatedAt.UTC(),
+	}
+	if p.SKU != nil {
+		line.SKU = *p.SKU
+	}
+	return w.enc.Encode(line)
+}
+
+func (w *ndjsonWriter) Close() error { return nil }
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+)
+
+func testProducts() []domain.Product {
+	sku := "MUG-1"
+	updated := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []domain.Product{
+		{ID: 1, UserID: 7, SKU: &sku, Name: "Mug", Description: `big & "blue"`, Price: 7.5, Stock: 3, Category: "kitchen",
+			Attributes: domain.Attributes{"color": "blue"}, UpdatedAt: updated,
+			Images: []domain.ProductImage{{ID: 4, Key: "mug"}}},
+		{ID: 2, UserID: 7, Name: "Lamp, desk", Price: 20, UpdatedAt: updated},
+	}
+}
+
+func export(t *testing.T, format string, feed Feed) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := New(format, &buf, feed)
+	if err != nil {
+		t.Fatalf("New(%s) error = %v", format, err)
+	}
+	products := testProducts()
+	for i := range products {
+		if err := w.Write(&products[i]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(export(t, CSV, Feed{}))).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not CSV: %v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(Columns, ",") {
+		t.Fatalf("records = %v", records)
+	}
+	want := []string{"1", "MUG-1", "Mug", `big & "blue"`, "7.5", "3", "kitchen", `{"color":"blue"}`, "7", "2026-05-01T12:00:00Z"}
+	if strings.Join(records[1], "|") != strings.Join(want, "|") {
+		t.Errorf("row = %q, want %q", records[1], want)
+	}
+	if records[2][1] != "" || records[2][2] != "Lamp, desk" || records[2][7] != "" {
+		t.Errorf("row without sku = %q", records[2])
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(string(export(t, NDJSON, Feed{}))), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("line is not JSON: %s", lines[0])
+	}
+	if got["sku"] != "MUG-1" || got["price"] != 7.5 || got["seller_id"] != float64(7) || got["attributes"].(map[string]any)["color"] != "blue" {
+		t.Errorf("line = %v", got)
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	out := export(t, XLSX, Feed{})
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("output is not a zip: %v", err)
+	}
+
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		parts[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("sheet is not XML: %v", err)
+	}
+	if len(sheet.Rows) != 3 || sheet.Rows[0].Cells[2].Inline != "name" {
+		t.Fatalf("sheet = %+v", sheet)
+	}
+	mug := sheet.Rows[1].Cells
+	if mug[4].Type != "n" || mug[4].Value != "7.5" || mug[3].Inline != `big & "blue"` {
+		t.Errorf("mug row = %+v", mug)
+	}
+}
+
+func TestMerchant(t *testing.T) {
+	feed := Feed{
+		Title:    "Go-Market",
+		BaseURL:  "https://shop.example/",
+		Currency: "KZT",
+		ImageURL: func(img *domain.ProductImage) string { return "https://cdn.example/" + img.Key },
+	}
+	out := export(t, Merchant, feed)
+
+	var rss struct {
+		Title string `xml:"channel>title"`
+		Items []struct {
+			ID           string `xml:"http://base.google.com/ns/1.0 id"`
+			Link         string `xml:"http://base.google.com/ns/1.0 link"`
+			ImageLink    string `xml:"http://base.google.com/ns/1.0 image_link"`
+			Availability string `xml:"http://base.google.com/ns/1.0 availability"`
+			Price        string `xml:"http://base.google.com/ns/1.0 price"`
+			MPN          string `xml:"http://base.google.com/ns/1.0 mpn"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(out, &rss); err != nil {
+		t.Fatalf("feed is not XML: %v\n%s", err, out)
+	}
+	if rss.Title != "Go-Market" || len(rss.Items) != 2 {
+		t.Fatalf("feed = %+v", rss)
+	}
+	mug, lamp := rss.Items[0], rss.Items[1]
+	if mug.ID != "1" || mug.Link != "https://shop.example/products/1" || mug.ImageLink != "https://cdn.example/mug" ||
+		mug.Availability != "in_stock" || mug.Price != "7.50 KZT" || mug.MPN != "MUG-1" {
+		t.Errorf("mug item = %+v", mug)
+	}
+	if lamp.Availability != "out_of_stock" || lamp.ImageLink != "" {
+		t.Errorf("lamp item = %+v", lamp)
+	}
+}
+
+func TestNew_UnknownFormat(t *testing.T) {
+	if _, err := New("pdf", io.Discard, Feed{}); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("New(pdf) error = %v", err)
+	}
+	if ContentType("pdf") != "" || Ext(XLSX) != "xlsx" {
+		t.Errorf("format metadata is off")
+	}
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"GoProduct/internal/domain"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// merchantItem is one <item> of a Google Merchant RSS 2.0 feed. The g:
+// prefix is bound to the Google namespace on the <rss> element.
+type merchantItem struct {
+	XMLName      xml.Name `xml:"item"`
+	ID           string   `xml:"g:id"`
+	Title        string   `xml:"g:title"`
+	Description  string   `xml:"g:description"`
+	Link         string   `xml:"g:link"`
+	ImageLink    string   `xml:"g:image_link,omitempty"`
+	Availability string   `xml:"g:availability"`
+	Price        string   `xml:"g:price"`
+	Condition    string   `xml:"g:condition"`
+	MPN          string   `xml:"g:mpn,omitempty"`
+	ProductType  string   `xml:"g:product_type,omitempty"`
+}
+
+type merchantWriter struct {
+	w    io.Writer
+	enc  *xml.Encoder
+	feed Feed
+}
+
+func newMerchant(w io.Writer, feed Feed) (*merchantWriter, error) {
+	feed.BaseURL = strings.TrimRight(feed.BaseURL, "/")
+
+	var head strings.Builder
+	head.WriteString(xml.Header)
+	head.WriteString(`<rss version="2.0" xmlns:g="http://base.google.com/ns/1.0"><channel>`)
+	head.WriteString("<title>")
+	xml.EscapeText(&head, []byte(feed.Title))
+	head.WriteString("</title><link>")
+	xml.EscapeText(&head, []byte(feed.BaseURL))
+	head.WriteString("</link><description>")
+	xml.EscapeText(&head, []byte(feed.Title))
+	head.WriteString("</description>\n")
+	if _, err := io.WriteString(w, head.String()); err != nil {
+		return nil, err
+	}
+	return &merchantWriter{w: w, enc: xml.NewEncoder(w), feed: feed}, nil
+}
+
+func (w *merchantWriter) Write(p *domain.Product) error {
+	id := strconv.FormatUint(uint64(p.ID), 10)
+	item := merchantItem{
+		ID:           id,
+		Title:        p.Name,
+		Description:  p.Description,
+		Link:         w.feed.BaseURL + "/products/" + id,
+		Availability: "in_stock",
+		Price:        fmt.Sprintf("%.2f %s", p.Price, w.feed.Currency),
+		Condition:    "new",
+		ProductType:  p.Category,
+	}
+	if p.OutOfStock() {
+		item.Availability = "out_of_stock"
+	}
+	if p.SKU != nil {
+		item.MPN = *p.SKU
+	}
+	if len(p.Images) > 0 && w.feed.ImageURL != nil {
+		item.ImageLink = w.feed.ImageURL(&p.Images[0])
+	}
+	if err := w.enc.Encode(item); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+func (w *merchantWriter) Close() error {
+	if err := w.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "</channel></rss>\n")
+	return err
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/http/middleware"
+	"GoProduct/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bulkReq struct {
+	Atomic     bool        `json:"atomic"`
+	Operations []bulkOpReq `json:"operations" binding:"required,min=1,max=500,dive"`
+}
+
+type bulkOpReq struct {
+	ProductID uint     `json:"product_id" binding:"required"`
+	Op        string   `json:"op" binding:"required,oneof=price status delete"`
+	Version   int      `json:"version" binding:"min=0"`
+	Price     *float64 `json:"price"`
+	Percent   *float64 `json:"percent"`
+	Status    string   `json:"status"`
+}
+
+type bulkResp struct {
+	// Applied is false only when an atomic request was rolled back.
+	Applied bool             `json:"applied"`
+	Results []bulkResultResp `json:"results"`
+}
+
+type bulkResultResp struct {
+	ProductID uint   `json:"product_id"`
+	Op        string `json:"op"`
+	OK        bool   `json:"ok"`
+	// Version is the product's new ETag value; omitted when the operation
+	// failed or changed nothing.
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Bulk applies price, status and delete operations to the caller's own
+// products. With "atomic": true either all of them apply or, answered
+// with 409, none; otherwise each has its own result.
+func (h *ProductHandler) Bulk(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+
+	var req bulkReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	in := service.BulkInput{
+		UserID:     userID,
+		Email:      c.GetString(middleware.EmailKey),
+		Atomic:     req.Atomic,
+		Operations: make([]service.BulkOperation, 0, len(req.Operations)),
+	}
+	for _, op := range req.Operations {
+		in.Operations = append(in.Operations, service.BulkOperation{
+			ProductID: op.ProductID,
+			Op:        op.Op,
+			Version:   op.Version,
+			Price:     op.Price,
+			Percent:   op.Percent,
+			Status:    domain.ProductStatus(op.Status),
+		})
+	}
+
+	results, err := h.svc.Bulk(c.Request.Context(), in)
+	if err != nil {
+		if service.IsInvalidBulk(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	resp := bulkResp{Applied: true, Results: make([]bulkResultResp, 0, len(results))}
+	for _, r := range results {
+		item := bulkResultResp{ProductID: r.ProductID, Op: r.Op, OK: r.Err == nil, Version: r.Version}
+		if r.Err != nil {
+			item.Error = r.Err.Error()
+			if service.IsRolledBack(r.Err) {
+				resp.Applied = false
+			}
+		}
+		resp.Results = append(resp.Results, item)
+	}
+
+	code := http.StatusOK
+	if !resp.Applied {
+		code = http.StatusConflict
+	}
+	c.JSON(code, resp)
+}
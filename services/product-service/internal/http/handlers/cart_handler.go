@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"GoProduct/internal/http/middleware"
+	"GoProduct/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CartTokenHeader carries the guest cart token in both directions.
+const CartTokenHeader = "X-Cart-Token"
+
+type CartHandler struct {
+	svc *service.CartService
+}
+
+func NewCartHandler(svc *service.CartService) *CartHandler {
+	return &CartHandler{svc: svc}
+}
+
+type addCartItemReq struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"omitempty,min=1,max=99"`
+}
+
+type updateCartItemReq struct {
+	Quantity int `json:"quantity" binding:"required,min=1,max=99"`
+}
+
+type cartItemResp struct {
+	ProductID    uint    `json:"product_id"`
+	SellerID     uint    `json:"seller_id"`
+	Name         string  `json:"name"`
+	Quantity     int     `json:"quantity"`
+	UnitPrice    float64 `json:"unit_price"`
+	CurrentPrice float64 `json:"current_price"`
+	Available    bool    `json:"available"`
+	PriceChanged bool    `json:"price_changed"`
+	LineTotal    float64 `json:"line_total"`
+}
+
+type cartResp struct {
+	CartToken string         `json:"cart_token,omitempty"`
+	Items     []cartItemResp `json:"items"`
+	ItemCount int            `json:"item_count"`
+	Subtotal  float64        `json:"subtotal"`
+}
+
+func (h *CartHandler) Get(c *gin.Context) {
+	v, err := h.svc.Get(cartOwner(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	writeCart(c, http.StatusOK, v)
+}
+
+func (h *CartHandler) AddItem(c *gin.Context) {
+	var req addCartItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	v, err := h.svc.AddItem(cartOwner(c), req.ProductID, req.Quantity)
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+	writeCart(c, http.StatusOK, v)
+}
+
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	productID, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req updateCartItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	v, err := h.svc.UpdateItem(cartOwner(c), productID, req.Quantity)
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+	writeCart(c, http.StatusOK, v)
+}
+
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	productID, ok := productIDParam(c)
+	if !ok {
+		return
+	}
+
+	v, err := h.svc.RemoveItem(cartOwner(c), productID)
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+	writeCart(c, http.StatusOK, v)
+}
+
+func (h *CartHandler) Clear(c *gin.Context) {
+	v, err := h.svc.Clear(cartOwner(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	writeCart(c, http.StatusOK, v)
+}
+
+func cartOwner(c *gin.Context) service.CartOwner {
+	owner := service.CartOwner{Token: c.GetHeader(CartTokenHeader)}
+	if raw, exists := c.Get(middleware.UserIDKey); exists {
+		owner.UserID, _ = raw.(uint)
+	}
+	return owner
+}
+
+func productIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("product_id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func writeCartError(c *gin.Context, err error) {
+	switch {
+	case service.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+	case service.IsCartItemNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not in cart"})
+	case service.IsInvalidQuantity(err):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid quantity"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
+
+func writeCart(c *gin.Context, status int, v *service.CartView) {
+	resp := cartResp{
+		CartToken: v.Token,
+		Items:     make([]cartItemResp, 0, len(v.Items)),
+		ItemCount: v.ItemCount,
+		Subtotal:  v.Subtotal,
+	}
+	for _, it := range v.Items {
+		resp.Items = append(resp.Items, cartItemResp{
+			ProductID:    it.ProductID,
+			SellerID:     it.SellerID,
+			Name:         it.Name,
+			Quantity:     it.Quantity,
+			UnitPrice:    it.UnitPrice,
+			CurrentPrice: it.CurrentPrice,
+			Available:    it.Available,
+			PriceChanged: it.PriceChanged,
+			LineTotal:    it.LineTotal,
+		})
+	}
+
+	if v.Token != "" {
+		c.Header(CartTokenHeader, v.Token)
+	}
+	c.JSON(status, resp)
+}
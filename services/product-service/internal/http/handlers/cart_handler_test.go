@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/http/handlers"
+	"GoProduct/internal/http/middleware"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupCartServer(t *testing.T) (*gin.Engine, *domain.Product) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db := newTestDB(t)
+
+	products := repo.NewProducts(db)
+	p := &domain.Product{UserID: 1, Name: "Phone", Price: 100, Status: domain.StatusPublished}
+	if err := products.Create(p); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	h := handlers.NewCartHandler(service.NewCartService(repo.NewCarts(db), products))
+
+	r := gin.New()
+
+	// заглушка OptionalAuth: пользователь задаётся заголовком X-User-ID
+	optionalAuth := func(c *gin.Context) {
+		if raw := c.GetHeader("X-User-ID"); raw != "" {
+			id, _ := strconv.ParseUint(raw, 10, 64)
+			c.Set(middleware.UserIDKey, uint(id))
+		}
+		c.Next()
+	}
+
+	g := r.Group("/cart", optionalAuth)
+	{
+		g.GET("/items", h.Get)
+		g.POST("/items", h.AddItem)
+		g.DELETE("/items", h.Clear)
+		g.PATCH("/items/:product_id", h.UpdateItem)
+		g.DELETE("/items/:product_id", h.RemoveItem)
+	}
+	return r, p
+}
+
+type cartBody struct {
+	CartToken string `json:"cart_token"`
+	Items     []struct {
+		ProductID uint    `json:"product_id"`
+		Quantity  int     `json:"quantity"`
+		UnitPrice float64 `json:"unit_price"`
+		Available bool    `json:"available"`
+	} `json:"items"`
+	ItemCount int     `json:"item_count"`
+	Subtotal  float64 `json:"subtotal"`
+}
+
+func cartRequest(t *testing.T, r *gin.Engine, method, path, token, userID string, body any) (*httptest.ResponseRecorder, cartBody) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(handlers.CartTokenHeader, token)
+	}
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp cartBody
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestCartHandler_GuestFlowAndLogin(t *testing.T) {
+	r, p := setupCartServer(t)
+
+	w, cart := cartRequest(t, r, http.MethodPost, "/cart/items", "", "", gin.H{"product_id": p.ID, "quantity": 2})
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST status = %d, body = %s", w.Code, w.Body.String())
+	}
+	token := w.Header().Get(handlers.CartTokenHeader)
+	if token == "" || token != cart.CartToken {
+		t.Fatalf("guest token header = %q, body = %q", token, cart.CartToken)
+	}
+
+	path := "/cart/items/" + strconv.Itoa(int(p.ID))
+	w, cart = cartRequest(t, r, http.MethodPatch, path, token, "", gin.H{"quantity": 5})
+	if w.Code != http.StatusOK || cart.ItemCount != 5 || cart.Subtotal != 500 {
+		t.Fatalf("PATCH status = %d, cart = %+v", w.Code, cart)
+	}
+
+	// Login: the first authenticated request with the guest token claims it.
+	w, cart = cartRequest(t, r, http.MethodGet, "/cart/items", token, "42", nil)
+	if w.Code != http.StatusOK || cart.CartToken != "" || cart.ItemCount != 5 {
+		t.Fatalf("GET after login status = %d, cart = %+v", w.Code, cart)
+	}
+
+	w, cart = cartRequest(t, r, http.MethodDelete, path, "", "42", nil)
+	if w.Code != http.StatusOK || len(cart.Items) != 0 {
+		t.Fatalf("DELETE status = %d, cart = %+v", w.Code, cart)
+	}
+}
+
+func TestCartHandler_Errors(t *testing.T) {
+	r, p := setupCartServer(t)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		want   int
+	}{
+		{"missing product", http.MethodPost, "/cart/items", gin.H{"product_id": 999}, http.StatusNotFound},
+		{"bad quantity", http.MethodPost, "/cart/items", gin.H{"product_id": p.ID, "quantity": 500}, http.StatusBadRequest},
+		{"item not in cart", http.MethodPatch, "/cart/items/" + strconv.Itoa(int(p.ID)), gin.H{"quantity": 1}, http.StatusNotFound},
+		{"invalid id", http.MethodDelete, "/cart/items/abc", nil, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if w, _ := cartRequest(t, r, tc.method, tc.path, "", "1", tc.body); w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d; body = %s", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+
+	w, cart := cartRequest(t, r, http.MethodGet, "/cart/items", "", "", nil)
+	if w.Code != http.StatusOK || cart.Items == nil || len(cart.Items) != 0 {
+		t.Errorf("empty guest cart: status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	svc *service.CategoryService
+}
+
+func NewCategoryHandler(svc *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{svc: svc}
+}
+
+type saveCategoryReq struct {
+	Name       string                `json:"name" binding:"required,max=255"`
+	Attributes []domain.AttributeDef `json:"attributes"`
+}
+
+type categoryResp struct {
+	Slug       string                `json:"slug"`
+	Name       string                `json:"name"`
+	Attributes []domain.AttributeDef `json:"attributes"`
+}
+
+func (h *CategoryHandler) List(c *gin.Context) {
+	categories, err := h.svc.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	resp := make([]categoryResp, 0, len(categories))
+	for i := range categories {
+		resp = append(resp, toCategoryResp(&categories[i]))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *CategoryHandler) Get(c *gin.Context) {
+	cat, err := h.svc.Get(c.Param("slug"))
+	if err != nil {
+		if service.IsCategoryNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResp(cat))
+}
+
+// Save creates or replaces the category named by :slug. It is an internal
+// route: schemas are managed by the marketplace, not by sellers.
+func (h *CategoryHandler) Save(c *gin.Context) {
+	var req saveCategoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cat, err := h.svc.Save(service.SaveCategoryInput{Slug: c.Param("slug"), Name: req.Name, Attributes: req.Attributes})
+	if err != nil {
+		if service.IsInvalidCategory(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResp(cat))
+}
+
+func toCategoryResp(cat *domain.Category) categoryResp {
+	return categoryResp{Slug: cat.Slug, Name: cat.Name, Attributes: cat.Attributes}
+}
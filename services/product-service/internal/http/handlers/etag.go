@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats a product version as a strong ETag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch reads the product version a write is conditional on. Writes
+// without If-Match get 428, since blind overwrites are what it prevents;
+// anything but a single strong ETag or "*" cannot match and gets 412.
+// "*" matches any version and is returned as zero.
+func ifMatch(c *gin.Context) (int, bool) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header required"})
+		return 0, false
+	}
+	if raw == "*" {
+		return 0, true
+	}
+
+	unquoted, err := strconv.Unquote(raw)
+	if err == nil {
+		if v, err := strconv.Atoi(unquoted); err == nil && v > 0 {
+			return v, true
+		}
+	}
+	writePreconditionFailed(c)
+	return 0, false
+}
+
+// notModified answers 304 when the client's If-None-Match already names
+// the product's current version.
+func notModified(c *gin.Context, version int) bool {
+	tag := etag(version)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			c.Header("ETag", tag)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+func writePreconditionFailed(c *gin.Context) {
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "product has changed, reload it and try again"})
+}
+
+// writeProduct answers with a single product and its ETag.
+func (h *ProductHandler) writeProduct(c *gin.Context, code int, p *domain.Product) {
+	c.Header("ETag", etag(p.Version))
+	c.JSON(code, h.toProductResp(p))
+}
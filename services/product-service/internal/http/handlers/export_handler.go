@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/export"
+	"GoProduct/internal/service"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	svc  *service.ProductService
+	feed export.Feed
+}
+
+// NewExportHandler serves catalog exports; feed describes the shop in
+// Merchant feeds.
+func NewExportHandler(svc *service.ProductService, feed export.Feed) *ExportHandler {
+	return &ExportHandler{svc: svc, feed: feed}
+}
+
+// Export streams the published products matching the listing filters as
+// ?format=csv (the default), ndjson, xlsx or merchant. The response is
+// written batch by batch, so once it has started an error can only cut it
+// short.
+func (h *ExportHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", export.CSV)
+	if export.ContentType(format) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, ndjson, xlsx or merchant"})
+		return
+	}
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+
+	// заголовки уходят с первой пачкой: до неё ещё можно ответить ошибкой
+	var w export.Writer
+	start := func() error {
+		c.Header("Content-Type", export.ContentType(format))
+		c.Header("Content-Disposition", `attachment; filename="products.`+export.Ext(format)+`"`)
+		c.Status(http.StatusOK)
+		var err error
+		w, err = export.New(format, c.Writer, h.feed)
+		return err
+	}
+
+	err := h.svc.ExportProducts(filter, func(products []domain.Product) error {
+		if w == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for i := range products {
+			if err := w.Write(&products[i]); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil && w == nil {
+		err = start()
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if w == nil {
+		if service.IsInvalidFilter(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	log.Printf("product export (%s): %v", format, err)
+	c.Abort()
+}
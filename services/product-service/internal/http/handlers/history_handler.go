@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HistoryHandler serves a product's revisions: the full audit trail for its
+// owner and admins, reverts, and the public price history.
+type HistoryHandler struct {
+	svc      *service.ProductService
+	products *ProductHandler
+}
+
+func NewHistoryHandler(svc *service.ProductService, products *ProductHandler) *HistoryHandler {
+	return &HistoryHandler{svc: svc, products: products}
+}
+
+type revisionResp struct {
+	ID        uint        `json:"id"`
+	Version   int         `json:"version"`
+	ActorID   uint        `json:"actor_id"`
+	Action    string      `json:"action"`
+	Changes   domain.Diff `json:"changes"`
+	RevertOf  *uint       `json:"revert_of,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type revertReq struct {
+	RevisionID uint `json:"revision_id" binding:"required"`
+}
+
+type pricePointResp struct {
+	Price     float64   `json:"price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func (h *HistoryHandler) History(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	revs, err := h.svc.History(userID, id)
+	if err != nil {
+		writeHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRevisionResps(revs))
+}
+
+func (h *HistoryHandler) AdminHistory(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	revs, err := h.svc.AdminHistory(id)
+	if err != nil {
+		writeHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRevisionResps(revs))
+}
+
+// Revert rolls the product back to an earlier revision. Like any write it
+// needs If-Match.
+func (h *HistoryHandler) Revert(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var req revertReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.svc.Revert(service.RevertInput{ID: id, UserID: userID, Version: version, RevisionID: req.RevisionID})
+	if err != nil {
+		switch {
+		case service.IsVersionMismatch(err):
+			writePreconditionFailed(c)
+		case service.IsRevisionNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		case service.IsNotFound(err), service.IsForbidden(err):
+			writeHistoryError(c, err)
+		default:
+			// the category schema may have changed since the revision
+			writeAttributeError(c, err)
+		}
+		return
+	}
+	h.products.writeProduct(c, http.StatusOK, p)
+}
+
+func (h *HistoryHandler) PriceHistory(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	points, err := h.svc.PriceHistory(viewerID(c), id)
+	if err != nil {
+		writeHistoryError(c, err)
+		return
+	}
+	resp := make([]pricePointResp, 0, len(points))
+	for _, p := range points {
+		resp = append(resp, pricePointResp{Price: p.Price, ChangedAt: p.ChangedAt})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toRevisionResps(revs []domain.ProductRevision) []revisionResp {
+	resp := make([]revisionResp, 0, len(revs))
+	for _, r := range revs {
+		resp = append(resp, revisionResp{
+			ID:        r.ID,
+			Version:   r.Version,
+			ActorID:   r.ActorID,
+			Action:    r.Action,
+			Changes:   r.Changes,
+			RevertOf:  r.RevertOf,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return resp
+}
+
+func writeHistoryError(c *gin.Context, err error) {
+	switch {
+	case service.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case service.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/http/middleware"
+	"GoProduct/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImageFormField is the multipart field the image file is sent in.
+const ImageFormField = "image"
+
+type ImageHandler struct {
+	svc *service.ImageService
+}
+
+func NewImageHandler(svc *service.ImageService) *ImageHandler {
+	return &ImageHandler{svc: svc}
+}
+
+type imageResp struct {
+	ID          uint              `json:"id"`
+	Position    int               `json:"position"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+}
+
+type reorderImagesReq struct {
+	ImageIDs []uint `json:"image_ids" binding:"required"`
+}
+
+// Upload takes a multipart form with the file in the "image" field.
+func (h *ImageHandler) Upload(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	// запас на заголовки multipart сверх самого файла
+	maxBytes := h.svc.Limits().MaxBytes
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	fh, err := c.FormFile(ImageFormField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"image\" is required"})
+		return
+	}
+	if fh.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image too large"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read image"})
+		return
+	}
+	defer f.Close()
+
+	img, err := h.svc.Upload(c.Request.Context(), service.UploadImageInput{ProductID: productID, UserID: userID, File: f})
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toImageResp(h.svc, img))
+}
+
+func (h *ImageHandler) List(c *gin.Context) {
+	productID, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	images, err := h.svc.List(viewerID(c), productID)
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toImageResps(h.svc, images))
+}
+
+func (h *ImageHandler) Reorder(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req reorderImagesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := h.svc.Reorder(userID, productID, req.ImageIDs)
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toImageResps(h.svc, images))
+}
+
+func (h *ImageHandler) Delete(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := idParam(c)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseUint(c.Param("image_id"), 10, 64)
+	if err != nil || imageID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid image id"})
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), userID, productID, uint(imageID)); err != nil {
+		writeImageError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeImageError(c *gin.Context, err error) {
+	switch {
+	case service.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case service.IsImageNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "image not found"})
+	case service.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+	case service.IsImageTooLarge(err):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image too large"})
+	case service.IsUnsupportedImage(err):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "only JPEG, PNG and GIF images are accepted"})
+	case service.IsTooManyImages(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "image limit reached"})
+	case service.IsInvalidImageOrder(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids must list every image of the product once"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
+
+func toImageResp(svc *service.ImageService, img *domain.ProductImage) imageResp {
+	urls := svc.URLs(img)
+	return imageResp{
+		ID:          img.ID,
+		Position:    img.Position,
+		ContentType: img.ContentType,
+		Size:        img.Size,
+		Width:       img.Width,
+		Height:      img.Height,
+		URL:         urls.Original,
+		Thumbnails:  urls.Thumbnails,
+	}
+}
+
+func toImageResps(svc *service.ImageService, images []domain.ProductImage) []imageResp {
+	resp := make([]imageResp, 0, len(images))
+	for i := range images {
+		resp = append(resp, toImageResp(svc, &images[i]))
+	}
+	return resp
+}
+
+func requestUserID(c *gin.Context) (uint, bool) {
+	raw, exists := c.Get(middleware.UserIDKey)
+	userID, ok := raw.(uint)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found in context"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// viewerID is the authenticated user on routes where signing in is
+// optional, or zero for anonymous visitors.
+func viewerID(c *gin.Context) uint {
+	raw, _ := c.Get(middleware.UserIDKey)
+	id, _ := raw.(uint)
+	return id
+}
+
+func idParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/http/middleware"
+	"GoProduct/internal/service"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportFormField is the multipart field the import file is sent in.
+const ImportFormField = "file"
+
+type ImportHandler struct {
+	svc      *service.ImportService
+	maxBytes int64
+}
+
+// NewImportHandler serves imports of files up to maxBytes.
+func NewImportHandler(svc *service.ImportService, maxBytes int64) *ImportHandler {
+	return &ImportHandler{svc: svc, maxBytes: maxBytes}
+}
+
+type importJobResp struct {
+	ID         uint       `json:"id"`
+	Status     string     `json:"status"`
+	Format     string     `json:"format"`
+	DryRun     bool       `json:"dry_run"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Created    int        `json:"created"`
+	Updated    int        `json:"updated"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Errors lists the skipped rows. ErrorReport is the same as a CSV
+	// download.
+	Errors      []domain.ImportRowError `json:"errors"`
+	ErrorReport string                  `json:"error_report,omitempty"`
+}
+
+// Start takes a multipart form with the file in the "file" field. The
+// format is ?format=csv|ndjson or, without it, the file's extension.
+// ?dry_run=true validates the rows without saving anything.
+func (h *ImportHandler) Start(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+		return
+	}
+
+	// запас на заголовки multipart сверх самого файла
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+1<<20)
+	fh, err := c.FormFile(ImportFormField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"file\" is required"})
+		return
+	}
+	if fh.Size > h.maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = importFormat(fh.Filename)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file"})
+		return
+	}
+
+	job, err := h.svc.Start(service.ImportInput{
+		SellerID:    userID,
+		SellerEmail: c.GetString(middleware.EmailKey),
+		Format:      format,
+		DryRun:      dryRun,
+		Data:        data,
+	})
+	if err != nil {
+		if service.IsInvalidImport(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.Header("Location", importPath(job.ID))
+	c.JSON(http.StatusAccepted, toImportJobResp(job))
+}
+
+// Get reports a job's progress and the rows skipped so far.
+func (h *ImportHandler) Get(c *gin.Context) {
+	job, ok := h.job(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toImportJobResp(job))
+}
+
+// Errors downloads the skipped rows as CSV.
+func (h *ImportHandler) Errors(c *gin.Context) {
+	job, ok := h.job(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-errors.csv"`, job.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"row", "sku", "error"})
+	for _, e := range job.Errors {
+		_ = w.Write([]string{strconv.Itoa(e.Row), e.SKU, e.Error})
+	}
+	w.Flush()
+}
+
+func (h *ImportHandler) job(c *gin.Context) (*domain.ImportJob, bool) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return nil, false
+	}
+	id, err := strconv.ParseUint(c.Param("job_id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return nil, false
+	}
+
+	job, err := h.svc.Get(userID, uint(id))
+	if err != nil {
+		switch {
+		case service.IsImportNotFound(err), service.IsForbidden(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return nil, false
+	}
+	return job, true
+}
+
+// importFormat guesses the format from a file name.
+func importFormat(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return domain.ImportCSV
+	case ".ndjson", ".jsonl":
+		return domain.ImportNDJSON
+	}
+	return ""
+}
+
+func importPath(id uint) string {
+	return "/products/import/" + strconv.FormatUint(uint64(id), 10)
+}
+
+func toImportJobResp(job *domain.ImportJob) importJobResp {
+	resp := importJobResp{
+		ID:         job.ID,
+		Status:     string(job.Status),
+		Format:     job.Format,
+		DryRun:     job.DryRun,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Created:    job.Created,
+		Updated:    job.Updated,
+		Failed:     job.Failed,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+		Errors:     job.Errors,
+	}
+	if resp.Errors == nil {
+		resp.Errors = []domain.ImportRowError{}
+	}
+	if job.Failed > 0 {
+		resp.ErrorReport = importPath(job.ID) + "/errors"
+	}
+	return resp
+}
@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"GoProduct/internal/http/handlers"
+	"GoProduct/internal/http/middleware"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupImportServer(t *testing.T) (*gin.Engine, *service.ImportService) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db := newTestDB(t)
+
+	products := service.NewProductService(repo.NewProducts(db), repo.NewCategories(db), nil)
+	imports := service.NewImportService(products, repo.NewImports(db))
+	h := handlers.NewProductHandler(products, nil)
+	importH := handlers.NewImportHandler(imports, 1<<10)
+
+	r := gin.New()
+
+	// заглушка AuthRequired: пользователь задаётся заголовком X-User-ID
+	auth := func(c *gin.Context) {
+		id, _ := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64)
+		c.Set(middleware.UserIDKey, uint(id))
+		c.Next()
+	}
+
+	g := r.Group("/products", auth)
+	{
+		g.GET("/:id", h.Get)
+		g.POST("/import", importH.Start)
+		g.GET("/import/:job_id", importH.Get)
+		g.GET("/import/:job_id/errors", importH.Errors)
+	}
+	return r, imports
+}
+
+func TestImportHandler(t *testing.T) {
+	r, imports := setupImportServer(t)
+
+	upload := func(query, filename, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, _ := mw.CreateFormFile(handlers.ImportFormField, filename)
+		_, _ = fw.Write([]byte(content))
+		_ = mw.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/products/import"+query, &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("X-User-ID", "1")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path, user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-User-ID", user)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := upload("", "items.xml", "<items/>"); w.Code != http.StatusBadRequest {
+		t.Errorf("xml upload: status = %d, want 400", w.Code)
+	}
+	if w := upload("", "big.csv", strings.Repeat("x", 2<<10)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized upload: status = %d, want 413", w.Code)
+	}
+	if w := upload("?dry_run=maybe", "items.csv", "sku,name,price\n"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad dry_run: status = %d, want 400", w.Code)
+	}
+
+	w := upload("?format=ndjson", "export.txt", `{"sku":"A","name":"Alpha","price":10}`+"\n"+`{"sku":"","name":"No sku","price":1}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("upload: %d %s", w.Code, w.Body.String())
+	}
+	var job map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &job)
+	location := w.Header().Get("Location")
+	if job["status"] != "PENDING" || location != "/products/import/"+strconv.Itoa(int(job["id"].(float64))) {
+		t.Fatalf("queued job = %v, Location %q", job, location)
+	}
+
+	if _, err := imports.ProcessPending(context.Background()); err != nil {
+		t.Fatalf("ProcessPending() error = %v", err)
+	}
+
+	w = get(location, "1")
+	_ = json.Unmarshal(w.Body.Bytes(), &job)
+	if w.Code != http.StatusOK || job["status"] != "DONE" || job["created"] != float64(1) || job["failed"] != float64(1) || job["error_report"] != location+"/errors" {
+		t.Fatalf("finished job: %d %v", w.Code, job)
+	}
+	if w := get(location, "2"); w.Code != http.StatusNotFound {
+		t.Errorf("job of another seller: status = %d, want 404", w.Code)
+	}
+
+	w = get(location+"/errors", "1")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("error report: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if got := w.Body.String(); got != "row,sku,error\n2,,sku is required\n" {
+		t.Errorf("error report = %q", got)
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"GoProduct/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryHandler serves stock reservations to other services. Routes are
+// internal and not meant for end users.
+type InventoryHandler struct {
+	svc *service.InventoryService
+}
+
+func NewInventoryHandler(svc *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{svc: svc}
+}
+
+type reserveItemDTO struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
+}
+
+type reserveReq struct {
+	Reference string           `json:"reference" binding:"required,max=64"`
+	Items     []reserveItemDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+type reservationResp struct {
+	Reference string           `json:"reference"`
+	Status    string           `json:"status"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	Items     []reserveItemDTO `json:"items"`
+}
+
+func (h *InventoryHandler) Reserve(c *gin.Context) {
+	var req reserveReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]service.ReserveItem, 0, len(req.Items))
+	for _, it := range req.Items {
+		items = append(items, service.ReserveItem{ProductID: it.ProductID, Quantity: it.Quantity})
+	}
+
+	v, err := h.svc.Reserve(req.Reference, items)
+	if err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toReservationResp(v))
+}
+
+func (h *InventoryHandler) Get(c *gin.Context) {
+	v, err := h.svc.Get(c.Param("ref"))
+	if err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toReservationResp(v))
+}
+
+func (h *InventoryHandler) Commit(c *gin.Context) {
+	v, err := h.svc.Commit(c.Param("ref"))
+	if err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toReservationResp(v))
+}
+
+func (h *InventoryHandler) Release(c *gin.Context) {
+	v, err := h.svc.Release(c.Param("ref"))
+	if err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toReservationResp(v))
+}
+
+func writeInventoryError(c *gin.Context, err error) {
+	switch {
+	case service.IsInvalidReservation(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation"})
+	case service.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+	case service.IsReservationNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+	case service.IsInsufficientStock(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "out of stock"})
+	case service.IsReservationClosed(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "reservation already closed"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
+
+func toReservationResp(v *service.ReservationView) reservationResp {
+	resp := reservationResp{
+		Reference: v.Reference,
+		Status:    string(v.Status),
+		ExpiresAt: v.ExpiresAt,
+		Items:     make([]reserveItemDTO, 0, len(v.Items)),
+	}
+	for _, it := range v.Items {
+		resp.Items = append(resp.Items, reserveItemDTO{ProductID: it.ProductID, Quantity: it.Quantity})
+	}
+	return resp
+}
@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/http/handlers"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupInventoryServer(t *testing.T) (*gin.Engine, *domain.Product) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	db := newTestDB(t)
+
+	p := &domain.Product{UserID: 1, Name: "Phone", Price: 100, Stock: 2, Status: domain.StatusPublished}
+	if err := repo.NewProducts(db).Create(p); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	h := handlers.NewInventoryHandler(service.NewInventoryService(repo.NewInventory(db), time.Hour))
+	products := handlers.NewProductHandler(service.NewProductService(repo.NewProducts(db), repo.NewCategories(db), nil), nil)
+
+	r := gin.New()
+	r.GET("/products/:id", products.Get)
+	g := r.Group("/internal")
+	{
+		g.POST("/reservations", h.Reserve)
+		g.GET("/reservations/:ref", h.Get)
+		g.POST("/reservations/:ref/commit", h.Commit)
+		g.POST("/reservations/:ref/release", h.Release)
+	}
+	return r, p
+}
+
+func TestInventoryHandler_Flow(t *testing.T) {
+	r, p := setupInventoryServer(t)
+
+	do := func(method, path string, body any) (*httptest.ResponseRecorder, map[string]any) {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		var resp map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	items := []map[string]any{{"product_id": p.ID, "quantity": 2}}
+
+	w, resp := do(http.MethodPost, "/internal/reservations", map[string]any{"reference": "order-1", "items": items})
+	if w.Code != http.StatusCreated || resp["status"] != "active" {
+		t.Fatalf("reserve: %d %v", w.Code, resp)
+	}
+
+	w, _ = do(http.MethodPost, "/internal/reservations", map[string]any{"reference": "order-2", "items": items})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("reserve over stock: status = %d, want 409", w.Code)
+	}
+
+	w, _ = do(http.MethodPost, "/internal/reservations", map[string]any{"reference": "order-3", "items": []map[string]any{{"product_id": p.ID, "quantity": 0}}})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("zero quantity: status = %d, want 400", w.Code)
+	}
+
+	w, resp = do(http.MethodPost, "/internal/reservations/order-1/release", nil)
+	if w.Code != http.StatusOK || resp["status"] != "released" {
+		t.Fatalf("release: %d %v", w.Code, resp)
+	}
+
+	w, _ = do(http.MethodPost, "/internal/reservations/order-1/commit", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("commit after release: status = %d, want 409", w.Code)
+	}
+
+	w, _ = do(http.MethodGet, "/internal/reservations/missing", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("get missing: status = %d, want 404", w.Code)
+	}
+}
+
+func TestInventoryHandler_ReserveChangesETag(t *testing.T) {
+	r, p := setupInventoryServer(t)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/products/"+strconv.Itoa(int(p.ID)), nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tag := get("").Header().Get("ETag")
+	if w := get(tag); w.Code != http.StatusNotModified {
+		t.Fatalf("unchanged product: status = %d, want 304", w.Code)
+	}
+
+	b, _ := json.Marshal(map[string]any{"reference": "order-1", "items": []map[string]any{{"product_id": p.ID, "quantity": 2}}})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/internal/reservations", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("reserve: %d %s", w.Code, w.Body.String())
+	}
+
+	// закэшированный ответ с прежним остатком больше не годится
+	w = get(tag)
+	var got map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == tag || got["stock"] != float64(0) || got["out_of_stock"] != true {
+		t.Errorf("after reserve: %d, ETag %s (was %s), body %v", w.Code, w.Header().Get("ETag"), tag, got)
+	}
+}
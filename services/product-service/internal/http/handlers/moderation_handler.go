@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"GoProduct/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAdminLimit = 50
+	maxAdminLimit     = 200
+)
+
+// ModerationHandler serves the admin review queue. It reuses the product
+// handler's responses so moderators see what buyers would.
+type ModerationHandler struct {
+	svc      *service.ProductService
+	products *ProductHandler
+}
+
+func NewModerationHandler(svc *service.ProductService, products *ProductHandler) *ModerationHandler {
+	return &ModerationHandler{svc: svc, products: products}
+}
+
+type rejectReq struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+func (h *ModerationHandler) Queue(c *gin.Context) {
+	limit, ok := limitQuery(c)
+	if !ok {
+		return
+	}
+
+	products, err := h.svc.ModerationQueue(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	c.JSON(http.StatusOK, h.products.toProductResps(products))
+}
+
+func (h *ModerationHandler) Approve(c *gin.Context) {
+	adminID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.svc.Approve(c.Request.Context(), adminID, id)
+	if err != nil {
+		writeStatusError(c, err)
+		return
+	}
+	h.products.writeProduct(c, http.StatusOK, p)
+}
+
+func (h *ModerationHandler) Reject(c *gin.Context) {
+	adminID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req rejectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.svc.Reject(c.Request.Context(), adminID, id, req.Reason)
+	if err != nil {
+		writeStatusError(c, err)
+		return
+	}
+	h.products.writeProduct(c, http.StatusOK, p)
+}
+
+// limitQuery reads the ?limit= of the admin listings.
+func limitQuery(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultAdminLimit, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > maxAdminLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return 0, false
+	}
+	return n, true
+}
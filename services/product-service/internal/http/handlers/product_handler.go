@@ -1,40 +1,78 @@
 package handlers
 
 import (
+	"GoProduct/internal/domain"
 	"GoProduct/internal/http/middleware"
+	"GoProduct/internal/patch"
 	"GoProduct/internal/service"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type ProductHandler struct {
-	svc *service.ProductService
+	svc    *service.ProductService
+	images *service.ImageService
 }
 
-func NewProductHandler(svc *service.ProductService) *ProductHandler {
-	return &ProductHandler{svc: svc}
+func NewProductHandler(svc *service.ProductService, images *service.ImageService) *ProductHandler {
+	return &ProductHandler{svc: svc, images: images}
 }
 
 type createProductReq struct {
-	Name        string  `json:"name" binding:"required,max=255"`
-	Description string  `json:"description" binding:"max=1000"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
+	SKU         string         `json:"sku" binding:"max=64"`
+	Name        string         `json:"name" binding:"required,max=255"`
+	Description string         `json:"description" binding:"max=1000"`
+	Price       float64        `json:"price" binding:"required,gt=0"`
+	Stock       int            `json:"stock" binding:"min=0"`
+	Category    string         `json:"category" binding:"max=64"`
+	Attributes  map[string]any `json:"attributes"`
 }
 
 type productResp struct {
-	ID          uint    `json:"id"`
-	UserID      uint    `json:"user_id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          uint              `json:"id"`
+	UserID      uint              `json:"user_id"`
+	SKU         *string           `json:"sku,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	Stock       int               `json:"stock"`
+	Category    string            `json:"category"`
+	Attributes  domain.Attributes `json:"attributes"`
+	OutOfStock  bool              `json:"out_of_stock"`
+	Status      string            `json:"status"`
+	Version     int               `json:"version"`
+	// RejectionReason is set while the product is REJECTED.
+	RejectionReason string `json:"rejection_reason,omitempty"`
+	// DeletedAt is only set in the admin listing of deleted products.
+	DeletedAt *time.Time    `json:"deleted_at,omitempty"`
+	Images    []imageResp   `json:"images"`
+	Variants  []variantResp `json:"variants"`
+}
+
+type changeStatusReq struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type adjustStockReq struct {
+	Delta int `json:"delta" binding:"required"`
 }
 
 type updateProductReq struct {
-	Name        string  `json:"name" binding:"required,max=255"`
-	Description string  `json:"description" binding:"max=1000"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
+	Name        string         `json:"name" binding:"required,max=255"`
+	Description string         `json:"description" binding:"max=1000"`
+	Price       float64        `json:"price" binding:"required,gt=0"`
+	Category    string         `json:"category" binding:"max=64"`
+	Attributes  map[string]any `json:"attributes"`
 }
 
 func (h *ProductHandler) Create(c *gin.Context) {
@@ -58,45 +96,70 @@ func (h *ProductHandler) Create(c *gin.Context) {
 
 	input := service.CreateProductInput{
 		UserID:      userID,
+		SellerEmail: c.GetString(middleware.EmailKey),
+		SKU:         req.SKU,
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
+		Stock:       req.Stock,
+		Category:    req.Category,
+		Attributes:  req.Attributes,
 	}
 
 	p, err := h.svc.CreateProduct(input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		writeAttributeError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, productResp{
-		ID:          p.ID,
-		UserID:      p.UserID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-	})
+	h.writeProduct(c, http.StatusCreated, p)
 }
 
 func (h *ProductHandler) List(c *gin.Context) {
-	products, err := h.svc.ListProducts()
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+
+	products, err := h.svc.ListProducts(filter)
 	if err != nil {
+		if service.IsInvalidFilter(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
 		return
 	}
 
-	resp := make([]productResp, 0, len(products))
-	for _, p := range products {
-		resp = append(resp, productResp{
-			ID:          p.ID,
-			UserID:      p.UserID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		})
+	c.JSON(http.StatusOK, h.toProductResps(products))
+}
+
+// Mine lists the caller's own products in every status, including drafts
+// and those waiting for review; ?status= narrows it to one.
+func (h *ProductHandler) Mine(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+
+	products, err := h.svc.ListOwnProducts(userID, domain.ProductStatus(c.Query("status")), filter)
+	if err != nil {
+		switch {
+		case service.IsInvalidStatus(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown status"})
+		case service.IsInvalidFilter(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	c.JSON(http.StatusOK, h.toProductResps(products))
 }
 
 func (h *ProductHandler) Get(c *gin.Context) {
@@ -107,7 +170,7 @@ func (h *ProductHandler) Get(c *gin.Context) {
 		return
 	}
 
-	p, err := h.svc.GetProduct(uint(id))
+	p, err := h.svc.GetVisibleProduct(viewerID(c), uint(id))
 	if err != nil {
 		if service.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
@@ -117,13 +180,41 @@ func (h *ProductHandler) Get(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, productResp{
-		ID:          p.ID,
-		UserID:      p.UserID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
+	if notModified(c, p.Version) {
+		return
+	}
+	h.writeProduct(c, http.StatusOK, p)
+}
+
+// ChangeStatus is the seller's lifecycle endpoint: submit for review
+// (PENDING_REVIEW), withdraw (DRAFT) or archive (ARCHIVED).
+func (h *ProductHandler) ChangeStatus(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req changeStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.svc.ChangeStatus(service.ChangeStatusInput{
+		ID:     id,
+		UserID: userID,
+		Email:  c.GetString(middleware.EmailKey),
+		Status: domain.ProductStatus(req.Status),
 	})
+	if err != nil {
+		writeStatusError(c, err)
+		return
+	}
+	h.writeProduct(c, http.StatusOK, p)
 }
 
 func (h *ProductHandler) Update(c *gin.Context) {
@@ -134,6 +225,15 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	var req updateProductReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -142,12 +242,67 @@ func (h *ProductHandler) Update(c *gin.Context) {
 
 	input := service.UpdateProductInput{
 		ID:          uint(id),
+		ActorID:     userID,
+		Version:     version,
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
+		Category:    req.Category,
+		Attributes:  req.Attributes,
 	}
 
 	p, err := h.svc.UpdateProduct(input)
+	if err != nil {
+		switch {
+		case service.IsNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case service.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+		case service.IsVersionMismatch(err):
+			writePreconditionFailed(c)
+		default:
+			writeAttributeError(c, err)
+		}
+		return
+	}
+
+	h.writeProduct(c, http.StatusOK, p)
+}
+
+// Patch applies a JSON Merge Patch, or a JSON Patch when sent as
+// application/json-patch+json, to the product's editable fields. The result
+// must pass the same checks as a full update.
+func (h *ProductHandler) Patch(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var apply func(doc, patch []byte) ([]byte, error)
+	switch c.ContentType() {
+	case patch.MergePatchType, binding.MIMEJSON:
+		apply = patch.Merge
+	case patch.JSONPatchType:
+		apply = patch.Apply
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "send " + patch.MergePatchType + " or " + patch.JSONPatchType})
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read body"})
+		return
+	}
+
+	p, err := h.svc.GetProduct(id)
 	if err != nil {
 		if service.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
@@ -156,14 +311,85 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
 		return
 	}
+	if p.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+		return
+	}
+	if version != 0 && version != p.Version {
+		writePreconditionFailed(c)
+		return
+	}
 
-	c.JSON(http.StatusOK, productResp{
-		ID:          p.ID,
-		UserID:      p.UserID,
+	req, err := patchProduct(p, body, apply)
+	if err != nil {
+		if errors.Is(err, patch.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// патч наложен на версию p, её и требуем при записи
+	p, err = h.svc.UpdateProduct(service.UpdateProductInput{
+		ID:          id,
+		ActorID:     userID,
+		Version:     p.Version,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Category:    req.Category,
+		Attributes:  req.Attributes,
+	})
+	if err != nil {
+		switch {
+		case service.IsNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case service.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+		case service.IsVersionMismatch(err):
+			writePreconditionFailed(c)
+		default:
+			writeAttributeError(c, err)
+		}
+		return
+	}
+	h.writeProduct(c, http.StatusOK, p)
+}
+
+// patchProduct applies body to the editable fields of p and validates the
+// result like a PUT body. Fields outside the editable set are rejected.
+func patchProduct(p *domain.Product, body []byte, apply func(doc, patch []byte) ([]byte, error)) (*updateProductReq, error) {
+	attrs := map[string]any(p.Attributes)
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
+	doc, err := json.Marshal(updateProductReq{
 		Name:        p.Name,
 		Description: p.Description,
 		Price:       p.Price,
+		Category:    p.Category,
+		Attributes:  attrs,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := apply(doc, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var req updateProductReq
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return nil, fmt.Errorf("patched product: %w", err)
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
 }
 
 func (h *ProductHandler) Delete(c *gin.Context) {
@@ -174,14 +400,189 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.DeleteProduct(uint(id)); err != nil {
-		if service.IsNotFound(err) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	// удаление мягкое: картинки остаются до очистки корзины
+	if err := h.svc.DeleteProduct(userID, uint(id), version); err != nil {
+		switch {
+		case service.IsNotFound(err):
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-			return
+		case service.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+		case service.IsVersionMismatch(err):
+			writePreconditionFailed(c)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req adjustStockReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.svc.AdjustStock(userID, id, req.Delta)
+	if err != nil {
+		switch {
+		case service.IsNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case service.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+		case service.IsInsufficientStock(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "stock cannot go below zero"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return
+	}
+
+	h.writeProduct(c, http.StatusOK, p)
+}
+
+func (h *ProductHandler) toProductResp(p *domain.Product) productResp {
+	resp := productResp{
+		ID:          p.ID,
+		UserID:      p.UserID,
+		SKU:         p.SKU,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Stock:       p.Stock,
+		Category:    p.Category,
+		Attributes:  p.Attributes,
+		OutOfStock:  p.OutOfStock(),
+		Status:      string(p.Status),
+		Version:     p.Version,
+		Images:      toImageResps(h.images, p.Images),
+		Variants:    toVariantResps(p.Variants),
+	}
+	if p.Status == domain.StatusRejected {
+		resp.RejectionReason = p.RejectionReason
+	}
+	if p.DeletedAt.Valid {
+		resp.DeletedAt = &p.DeletedAt.Time
+	}
+	return resp
+}
+
+func (h *ProductHandler) toProductResps(products []domain.Product) []productResp {
+	resp := make([]productResp, 0, len(products))
+	for i := range products {
+		resp = append(resp, h.toProductResp(&products[i]))
+	}
+	return resp
+}
+
+// listFilter reads the filters of the product listing, which the export
+// takes too.
+func listFilter(c *gin.Context) (service.ProductFilter, bool) {
+	var filter service.ProductFilter
+	if raw := c.Query("in_stock"); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid in_stock"})
+			return filter, false
+		}
+		filter.InStock = &inStock
+	}
+	filter.SKU = c.Query("sku")
+	filter.Category = c.Query("category")
+	attrs, err := parseAttrFilters(c.Request.URL.RawQuery)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	filter.Attrs = attrs
+	for key, values := range c.Request.URL.Query() {
+		if name, ok := strings.CutPrefix(key, VariantFilterPrefix); ok && name != "" && len(values) > 0 {
+			if filter.VariantAttrs == nil {
+				filter.VariantAttrs = make(map[string]string)
+			}
+			filter.VariantAttrs[name] = values[0]
+		}
+	}
+	return filter, true
+}
+
+// writeAttributeError answers a failed create or update whose error is
+// not specific to the endpoint.
+func writeAttributeError(c *gin.Context, err error) {
+	switch {
+	case service.IsCategoryNotFound(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown category"})
+	case service.IsInvalidAttributes(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.IsDuplicateSKU(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "sku already used by another of your products"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
+
+// parseAttrFilters reads attr.<name><op><value> terms from a raw query
+// string, e.g. attr.color=red&attr.ram_gb>=16. The query is parsed by hand
+// because url.ParseQuery would split "ram_gb>=16" into the key "ram_gb>"
+// and the value "16".
+func parseAttrFilters(rawQuery string) ([]service.AttrFilter, error) {
+	var filters []service.AttrFilter
+	for _, term := range strings.Split(rawQuery, "&") {
+		term, err := url.QueryUnescape(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
+		rest, ok := strings.CutPrefix(term, AttrFilterPrefix)
+		if !ok {
+			continue
+		}
+
+		i := strings.IndexAny(rest, "<>=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid attribute filter %q", term)
+		}
+		op := rest[i : i+1]
+		if len(rest) > i+1 && rest[i+1] == '=' && op != "=" {
+			op += "="
+		}
+		filters = append(filters, service.AttrFilter{Name: rest[:i], Op: op, Value: rest[i+len(op):]})
+	}
+	return filters, nil
+}
+
+func writeStatusError(c *gin.Context, err error) {
+	switch {
+	case service.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case service.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "status change not allowed"})
+	case service.IsInvalidStatus(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown status"})
+	case service.IsInvalidReason(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a rejection needs a reason"})
+	case service.IsInvalidTransition(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "status change not allowed from the current status"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
@@ -2,45 +2,88 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"GoProduct/internal/clients"
 	"GoProduct/internal/domain"
+	"GoProduct/internal/export"
 	"GoProduct/internal/http/handlers"
 	"GoProduct/internal/http/middleware"
 	"GoProduct/internal/repo"
 	"GoProduct/internal/service"
+	"GoProduct/internal/storage"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
+// fakeUsers подменяет user-service.
+type fakeUsers map[uint]*clients.User
+
+func (f fakeUsers) Get(_ context.Context, id uint) (*clients.User, error) {
+	if u, ok := f[id]; ok {
+		return u, nil
+	}
+	return nil, clients.ErrUserNotFound
+}
+
+// fakeOrders подменяет order-service: по заголовку Authorization отдаёт
+// продавцов, от которых покупателю доставлены заказы.
+type fakeOrders map[string][]uint
+
+func (f fakeOrders) DeliveredFrom(_ context.Context, authorization string, sellerID uint) (bool, error) {
+	for _, id := range f[authorization] {
+		if id == sellerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func setupTestServer(t *testing.T) *gin.Engine {
 	t.Helper()
 
 	gin.SetMode(gin.TestMode)
 
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	if err := db.AutoMigrate(&domain.Product{}); err != nil {
-		t.Fatalf("auto-migrate: %v", err)
-	}
+	db := newTestDB(t)
 
 	productsRepo := repo.NewProducts(db)
-	svc := service.NewProductService(productsRepo)
-	h := handlers.NewProductHandler(svc)
+	svc := service.NewProductService(productsRepo, repo.NewCategories(db), nil)
+	images := service.NewImageService(productsRepo, repo.NewImages(db), storage.NewLocal(t.TempDir(), "/media"), service.ImageLimits{
+		MaxBytes:      64 << 10,
+		MaxPixels:     1 << 20,
+		MaxPerProduct: 3,
+	})
+	h := handlers.NewProductHandler(svc, images)
+	imageH := handlers.NewImageHandler(images)
+	categoryH := handlers.NewCategoryHandler(service.NewCategoryService(repo.NewCategories(db)))
+	variantH := handlers.NewVariantHandler(service.NewVariantService(productsRepo, repo.NewVariants(db)))
+	moderationH := handlers.NewModerationHandler(svc, h)
+	trashH := handlers.NewTrashHandler(service.NewTrashService(productsRepo, time.Hour, images.DeleteFiles), h)
+	historyH := handlers.NewHistoryHandler(svc, h)
+	exportH := handlers.NewExportHandler(svc, export.Feed{Title: "Test", BaseURL: "http://shop.test", Currency: "KZT"})
+	sellerH := handlers.NewSellerHandler(service.NewSellerService(productsRepo, repo.NewRatings(db), fakeUsers{
+		1: {ID: 1, DisplayName: "Aigerim S.", MemberSince: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}, fakeOrders{"Bearer buyer-3": {1}}), h)
 
 	r := gin.New()
 
-	// middleware-заглушка: просто кладёт user_id = 1
+	// middleware-заглушка: кладёт user_id из X-User-ID, по умолчанию 1
 	authBypass := func(c *gin.Context) {
-		c.Set(middleware.UserIDKey, uint(1))
+		userID := uint(1)
+		if id, err := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 64); err == nil {
+			userID = uint(id)
+		}
+		c.Set(middleware.UserIDKey, userID)
 		c.Next()
 	}
 
@@ -49,14 +92,88 @@ func setupTestServer(t *testing.T) *gin.Engine {
 	{
 		g.POST("/", h.Create)
 		g.GET("/", h.List)
+		g.GET("/export", exportH.Export)
+		g.POST("/bulk", h.Bulk)
 		g.GET("/:id", h.Get)
 		g.PUT("/:id", h.Update)
+		g.PATCH("/:id", h.Patch)
 		g.DELETE("/:id", h.Delete)
+		g.POST("/:id/restore", trashH.Restore)
+		g.GET("/:id/history", historyH.History)
+		g.GET("/:id/price-history", historyH.PriceHistory)
+		g.POST("/:id/revert", historyH.Revert)
+		g.POST("/:id/stock", h.AdjustStock)
+		g.POST("/:id/status", h.ChangeStatus)
+		g.GET("/:id/images", imageH.List)
+		g.POST("/:id/images", imageH.Upload)
+		g.PUT("/:id/images/order", imageH.Reorder)
+		g.DELETE("/:id/images/:image_id", imageH.Delete)
+		g.POST("/:id/variants", variantH.Create)
+		g.PUT("/:id/variants/:variant_id", variantH.Update)
+		g.DELETE("/:id/variants/:variant_id", variantH.Delete)
+	}
+
+	// публичный каталог без авторизации
+	catalog := r.Group("/catalog", middleware.PublicCache(time.Minute))
+	{
+		catalog.GET("/products/", h.List)
+		catalog.GET("/products/:id", h.Get)
+		catalog.GET("/products/:id/images", imageH.List)
+		catalog.GET("/products/:id/variants", variantH.List)
+		catalog.GET("/products/:id/price-history", historyH.PriceHistory)
+		catalog.GET("/sellers/:id", sellerH.Profile)
+		catalog.GET("/sellers/:id/products", sellerH.Products)
+	}
+
+	r.GET("/me/products", authBypass, h.Mine)
+	r.PUT("/sellers/:id/rating", authBypass, sellerH.Rate)
+
+	r.PUT("/internal/categories/:slug", categoryH.Save)
+	r.GET("/admin/products/deleted", trashH.Deleted)
+	r.GET("/admin/products/:id/history", historyH.AdminHistory)
+
+	admin := r.Group("/admin/moderation")
+	admin.Use(authBypass)
+	{
+		admin.GET("", moderationH.Queue)
+		admin.POST("/:id/approve", moderationH.Approve)
+		admin.POST("/:id/reject", moderationH.Reject)
 	}
 
 	return r
 }
 
+// createPublished создаёт товар и проводит его через модерацию, чтобы он
+// попал в общий список. Возвращает ответ на создание.
+func createPublished(t *testing.T, r *gin.Engine, body map[string]any) map[string]any {
+	t.Helper()
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/products/", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create product failed: %d %s", w.Code, w.Body.String())
+	}
+	var created map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	id := strconv.Itoa(int(created["id"].(float64)))
+
+	if w := do(http.MethodPost, "/products/"+id+"/status", map[string]any{"status": "PENDING_REVIEW"}); w.Code != http.StatusOK {
+		t.Fatalf("submit for review: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/admin/moderation/"+id+"/approve", nil); w.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", w.Code, w.Body.String())
+	}
+	return created
+}
+
 func TestProductHandler_CreateAndGet(t *testing.T) {
 	r := setupTestServer(t)
 
@@ -108,23 +225,21 @@ func TestProductHandler_List(t *testing.T) {
 
 	// создаём 2 продукта
 	for i := 0; i < 2; i++ {
-		body := map[string]interface{}{
+		createPublished(t, r, map[string]any{
 			"name":  "P" + strconv.Itoa(i),
 			"price": float64(i+1) * 10,
-		}
-		b, _ := json.Marshal(body)
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest(http.MethodPost, "/products/", bytes.NewReader(b))
-		req.Header.Set("Content-Type", "application/json")
-		r.ServeHTTP(w, req)
-		if w.Code != http.StatusCreated {
-			t.Fatalf("create product failed: %d %s", w.Code, w.Body.String())
-		}
+		})
 	}
+	// черновик в списке не показывается
+	b, _ := json.Marshal(map[string]any{"name": "Draft", "price": 5})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/products/", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
 
 	// проверяем список
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest(http.MethodGet, "/products/", nil)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/products/", nil)
 
 	r.ServeHTTP(w, req)
 
@@ -154,6 +269,7 @@ func TestProductHandler_Update_NotFound(t *testing.T) {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodPut, "/products/9999", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
 
 	r.ServeHTTP(w, req)
 
@@ -195,3 +311,824 @@ func TestProductHandler_Get_InvalidID(t *testing.T) {
 		t.Fatalf("GET /products/:id status = %d, want %d; body = %s", w.Code, http.StatusBadRequest, w.Body.String())
 	}
 }
+
+func TestProductHandler_StockAndFilter(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	created := createPublished(t, r, map[string]any{"name": "In", "price": 10, "stock": 3})
+	if created["stock"] != float64(3) || created["out_of_stock"] != false {
+		t.Fatalf("create response = %v", created)
+	}
+	createPublished(t, r, map[string]any{"name": "Out", "price": 10})
+
+	if w := do(http.MethodPost, "/products/", map[string]any{"name": "Bad", "price": 10, "stock": -1}); w.Code != http.StatusBadRequest {
+		t.Errorf("negative stock: status = %d, want 400", w.Code)
+	}
+
+	w := do(http.MethodGet, "/products/?in_stock=false", nil)
+	var list []map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0]["name"] != "Out" || list[0]["out_of_stock"] != true {
+		t.Fatalf("in_stock=false = %v", list)
+	}
+	if w := do(http.MethodGet, "/products/?in_stock=maybe", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid in_stock: status = %d, want 400", w.Code)
+	}
+
+	id := strconv.Itoa(int(created["id"].(float64)))
+	if w := do(http.MethodPost, "/products/"+id+"/stock", map[string]any{"delta": -4}); w.Code != http.StatusConflict {
+		t.Errorf("delta below zero: status = %d, want 409", w.Code)
+	}
+	w = do(http.MethodPost, "/products/"+id+"/stock", map[string]any{"delta": -3})
+	var adjusted map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &adjusted)
+	if w.Code != http.StatusOK || adjusted["out_of_stock"] != true {
+		t.Errorf("adjust: %d %v", w.Code, adjusted)
+	}
+}
+
+func uploadImage(r *gin.Engine, productID string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	// имя и тип части выдуманы, сервер смотрит на байты
+	part, _ := mw.CreateFormFile(handlers.ImageFormField, "photo.jpg")
+	_, _ = part.Write(data)
+	_ = mw.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/products/"+productID+"/images", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestProductHandler_Images(t *testing.T) {
+	r := setupTestServer(t)
+
+	b, _ := json.Marshal(map[string]any{"name": "Pictured", "price": 5})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/products/", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	var created map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	id := strconv.Itoa(int(created["id"].(float64)))
+	if imgs, ok := created["images"].([]any); !ok || len(imgs) != 0 {
+		t.Fatalf("new product images = %v, want []", created["images"])
+	}
+
+	var pic bytes.Buffer
+	_ = png.Encode(&pic, image.NewRGBA(image.Rect(0, 0, 300, 200)))
+
+	ids := make([]float64, 0, 2)
+	for i := 0; i < 2; i++ {
+		w := uploadImage(r, id, pic.Bytes())
+		if w.Code != http.StatusCreated {
+			t.Fatalf("upload: %d %s", w.Code, w.Body.String())
+		}
+		var img map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &img)
+		thumbs, _ := img["thumbnails"].(map[string]any)
+		if img["content_type"] != "image/png" || len(thumbs) != len(domain.ThumbnailSizes) {
+			t.Fatalf("upload response = %v", img)
+		}
+		ids = append(ids, img["id"].(float64))
+	}
+
+	if w := uploadImage(r, id, []byte("just text")); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text upload: status = %d, want 415", w.Code)
+	}
+	if w := uploadImage(r, id, bytes.Repeat([]byte{0}, 70<<10)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("big upload: status = %d, want 413", w.Code)
+	}
+	if w := uploadImage(r, "999", pic.Bytes()); w.Code != http.StatusNotFound {
+		t.Errorf("upload to missing product: status = %d, want 404", w.Code)
+	}
+
+	b, _ = json.Marshal(map[string]any{"image_ids": []float64{ids[1], ids[0]}})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPut, "/products/"+id+"/images/order", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reorder: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/products/"+id, nil)
+	r.ServeHTTP(w, req)
+	var got struct {
+		Images []struct {
+			ID  float64 `json:"id"`
+			URL string  `json:"url"`
+		} `json:"images"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if len(got.Images) != 2 || got.Images[0].ID != ids[1] || got.Images[0].URL == "" {
+		t.Fatalf("product images = %+v", got.Images)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/products/"+id+"/images/"+strconv.Itoa(int(ids[0])), nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("delete image: status = %d, want 204", w.Code)
+	}
+}
+
+func TestProductHandler_Variants(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	created := createPublished(t, r, map[string]any{"name": "Tee", "price": 10})
+	id := strconv.Itoa(int(created["id"].(float64)))
+	createPublished(t, r, map[string]any{"name": "No variants", "price": 10})
+
+	w := do(http.MethodPost, "/products/"+id+"/variants", map[string]any{
+		"sku": "TEE-S", "attributes": map[string]string{"size": "S"}, "price": 12.5, "stock": 4,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create variant: %d %s", w.Code, w.Body.String())
+	}
+	var v map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &v)
+	if v["sku"] != "TEE-S" || v["price"] != 12.5 || v["attributes"].(map[string]any)["size"] != "S" {
+		t.Fatalf("variant = %v", v)
+	}
+	if w := do(http.MethodPost, "/products/"+id+"/variants", map[string]any{"sku": "TEE-S"}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate sku: status = %d, want 409", w.Code)
+	}
+	if w := do(http.MethodPost, "/products/"+id+"/variants", map[string]any{"sku": "TEE-M", "stock": -1}); w.Code != http.StatusBadRequest {
+		t.Errorf("negative stock: status = %d, want 400", w.Code)
+	}
+
+	vid := strconv.Itoa(int(v["id"].(float64)))
+	if w := do(http.MethodPut, "/products/"+id+"/variants/"+vid, map[string]any{"sku": "TEE-S", "attributes": map[string]string{"size": "S"}}); w.Code != http.StatusOK {
+		t.Errorf("update variant: %d %s", w.Code, w.Body.String())
+	}
+
+	var list []map[string]any
+	_ = json.Unmarshal(do(http.MethodGet, "/products/?variant.size=S", nil).Body.Bytes(), &list)
+	if len(list) != 1 || list[0]["name"] != "Tee" || len(list[0]["variants"].([]any)) != 1 {
+		t.Fatalf("variant.size=S = %v", list)
+	}
+	// после обновления сток варианта 0
+	_ = json.Unmarshal(do(http.MethodGet, "/products/?variant.size=S&in_stock=true", nil).Body.Bytes(), &list)
+	if len(list) != 0 {
+		t.Errorf("in-stock size S = %v, want none", list)
+	}
+
+	if w := do(http.MethodDelete, "/products/"+id+"/variants/"+vid, nil); w.Code != http.StatusNoContent {
+		t.Errorf("delete variant: status = %d, want 204", w.Code)
+	}
+}
+
+func TestProductHandler_AttributeFilters(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPut, "/internal/categories/laptops", map[string]any{
+		"name": "Laptops",
+		"attributes": []map[string]any{
+			{"name": "ram_gb", "type": "number", "required": true},
+			{"name": "color", "type": "string"},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("save category: %d %s", w.Code, w.Body.String())
+	}
+
+	for _, ram := range []int{8, 16, 32} {
+		createPublished(t, r, map[string]any{
+			"name": "Laptop " + strconv.Itoa(ram), "price": 100, "category": "laptops",
+			"attributes": map[string]any{"ram_gb": ram, "color": "red"},
+		})
+	}
+	w = do(http.MethodPost, "/products/", map[string]any{"name": "Bad", "price": 1, "category": "laptops", "attributes": map[string]any{"color": "red"}})
+	if w.Code != http.StatusBadRequest || !bytes.Contains(w.Body.Bytes(), []byte("ram_gb is required")) {
+		t.Errorf("missing required attribute: %d %s", w.Code, w.Body.String())
+	}
+
+	var list []map[string]any
+	_ = json.Unmarshal(do(http.MethodGet, "/products/?attr.color=red&attr.ram_gb>=16", nil).Body.Bytes(), &list)
+	if len(list) != 2 {
+		t.Fatalf("attr.ram_gb>=16 = %v, want 2 products", list)
+	}
+	if attrs, _ := list[0]["attributes"].(map[string]any); attrs["color"] != "red" {
+		t.Errorf("attributes in response = %v", list[0]["attributes"])
+	}
+	// экранированные операторы тоже понимаем
+	_ = json.Unmarshal(do(http.MethodGet, "/products/?attr.ram_gb%3C16", nil).Body.Bytes(), &list)
+	if len(list) != 1 {
+		t.Errorf("attr.ram_gb<16 = %v, want 1 product", list)
+	}
+	if w := do(http.MethodGet, "/products/?attr.ram_gb>=many", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("non-numeric range: status = %d, want 400", w.Code)
+	}
+}
+
+func TestProductHandler_Moderation(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var created map[string]any
+	_ = json.Unmarshal(do(http.MethodPost, "/products/", map[string]any{"name": "Phone", "price": 10}).Body.Bytes(), &created)
+	if created["status"] != "DRAFT" {
+		t.Fatalf("new product status = %v, want DRAFT", created["status"])
+	}
+	id := strconv.Itoa(int(created["id"].(float64)))
+
+	if w := do(http.MethodPost, "/products/"+id+"/status", map[string]any{"status": "PUBLISHED"}); w.Code != http.StatusForbidden {
+		t.Errorf("seller publish: status = %d, want 403", w.Code)
+	}
+	if w := do(http.MethodPost, "/products/"+id+"/status", map[string]any{"status": "SOLD"}); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown status: status = %d, want 400", w.Code)
+	}
+	if w := do(http.MethodPost, "/admin/moderation/"+id+"/approve", nil); w.Code != http.StatusConflict {
+		t.Errorf("approve draft: status = %d, want 409", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/products/"+id+"/status", map[string]any{"status": "PENDING_REVIEW"}); w.Code != http.StatusOK {
+		t.Fatalf("submit: %d %s", w.Code, w.Body.String())
+	}
+
+	var queue []map[string]any
+	_ = json.Unmarshal(do(http.MethodGet, "/admin/moderation?limit=10", nil).Body.Bytes(), &queue)
+	if len(queue) != 1 || queue[0]["name"] != "Phone" {
+		t.Fatalf("queue = %v", queue)
+	}
+	if w := do(http.MethodGet, "/admin/moderation?limit=0", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status = %d, want 400", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/admin/moderation/"+id+"/reject", map[string]any{}); w.Code != http.StatusBadRequest {
+		t.Errorf("reject without reason: status = %d, want 400", w.Code)
+	}
+	w := do(http.MethodPost, "/admin/moderation/"+id+"/reject", map[string]any{"reason": "blurry photos"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("reject: %d %s", w.Code, w.Body.String())
+	}
+	var rejected map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &rejected)
+	if rejected["status"] != "REJECTED" || rejected["rejection_reason"] != "blurry photos" {
+		t.Errorf("rejected = %v", rejected)
+	}
+
+	if w := do(http.MethodPost, "/admin/moderation/9999/approve", nil); w.Code != http.StatusNotFound {
+		t.Errorf("approve missing: status = %d, want 404", w.Code)
+	}
+}
+
+func TestProductHandler_DeleteAndRestore(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	created := createPublished(t, r, map[string]any{"name": "Phone", "price": 10})
+	id := strconv.Itoa(int(created["id"].(float64)))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/products/"+id, nil)
+	req.Header.Set("If-Match", "*")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/products/"+id, nil); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: status = %d, want 404", w.Code)
+	}
+
+	var deleted []map[string]any
+	_ = json.Unmarshal(do(http.MethodGet, "/admin/products/deleted", nil).Body.Bytes(), &deleted)
+	if len(deleted) != 1 || deleted[0]["deleted_at"] == nil {
+		t.Fatalf("deleted listing = %v", deleted)
+	}
+
+	w = do(http.MethodPost, "/products/"+id+"/restore", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore: %d %s", w.Code, w.Body.String())
+	}
+	var list []map[string]any
+	_ = json.Unmarshal(do(http.MethodGet, "/products/", nil).Body.Bytes(), &list)
+	if len(list) != 1 || list[0]["status"] != "PUBLISHED" {
+		t.Errorf("list after restore = %v", list)
+	}
+	if w := do(http.MethodPost, "/products/"+id+"/restore", nil); w.Code != http.StatusNotFound {
+		t.Errorf("second restore: status = %d, want 404", w.Code)
+	}
+}
+
+func TestProductHandler_IfMatch(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path, ifMatch string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	created := createPublished(t, r, map[string]any{"name": "Phone", "price": 10})
+	path := "/products/" + strconv.Itoa(int(created["id"].(float64)))
+
+	tag := do(http.MethodGet, path, "", nil).Header().Get("ETag")
+	if tag == "" {
+		t.Fatalf("GET has no ETag")
+	}
+
+	update := map[string]any{"name": "Phone 2", "price": 12}
+	if w := do(http.MethodPut, path, "", update); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("PUT without If-Match: status = %d, want 428", w.Code)
+	}
+	if w := do(http.MethodPut, path, `"999"`, update); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with a wrong version: status = %d, want 412", w.Code)
+	}
+	if w := do(http.MethodPut, path, "W/"+tag, update); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with a weak ETag: status = %d, want 412", w.Code)
+	}
+
+	w := do(http.MethodPut, path, tag, update)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: %d %s", w.Code, w.Body.String())
+	}
+	newTag := w.Header().Get("ETag")
+	if newTag == "" || newTag == tag {
+		t.Fatalf("ETag after update = %q, was %q", newTag, tag)
+	}
+
+	// вторая вкладка со старой версией не перетрёт изменения
+	if w := do(http.MethodPut, path, tag, map[string]any{"name": "Stale", "price": 1}); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with the old ETag: status = %d, want 412", w.Code)
+	}
+	if w := do(http.MethodDelete, path, tag, nil); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with the old ETag: status = %d, want 412", w.Code)
+	}
+	if w := do(http.MethodDelete, path, newTag, nil); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status = %d, want 204", w.Code)
+	}
+}
+
+func TestProductHandler_Patch(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path, contentType, ifMatch, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", contentType)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	categories, _ := json.Marshal(map[string]any{
+		"name":       "Laptops",
+		"attributes": []map[string]any{{"name": "ram_gb", "type": "number"}, {"name": "color", "type": "string"}},
+	})
+	if w := do(http.MethodPut, "/internal/categories/laptops", "application/json", "", string(categories)); w.Code != http.StatusOK {
+		t.Fatalf("save category: %d %s", w.Code, w.Body.String())
+	}
+
+	created := createPublished(t, r, map[string]any{
+		"name": "Laptop", "description": "fast", "price": 100, "category": "laptops",
+		"attributes": map[string]any{"ram_gb": 16, "color": "red"},
+	})
+	path := "/products/" + strconv.Itoa(int(created["id"].(float64)))
+	tag := do(http.MethodGet, path, "", "", "").Header().Get("ETag")
+
+	const mergeType = "application/merge-patch+json"
+	if w := do(http.MethodPatch, path, mergeType, "", `{"price":90}`); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("PATCH without If-Match: status = %d, want 428", w.Code)
+	}
+	if w := do(http.MethodPatch, path, "text/plain", tag, `price=90`); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("PATCH as text/plain: status = %d, want 415", w.Code)
+	}
+
+	w := do(http.MethodPatch, path, mergeType, tag, `{"price":90,"attributes":{"color":null}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("merge patch: %d %s", w.Code, w.Body.String())
+	}
+	var got map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	attrs := got["attributes"].(map[string]any)
+	if got["price"] != float64(90) || got["name"] != "Laptop" || got["description"] != "fast" || attrs["ram_gb"] != float64(16) || attrs["color"] != nil {
+		t.Fatalf("patched product = %v", got)
+	}
+	tag = w.Header().Get("ETag")
+
+	// итог патча проверяется как при создании
+	for _, body := range []string{`{"price":0}`, `{"name":null}`, `{"stock":100}`, `{"attributes":{"weight":2}}`} {
+		if w := do(http.MethodPatch, path, mergeType, tag, body); w.Code != http.StatusBadRequest {
+			t.Errorf("merge patch %s: status = %d, want 400", body, w.Code)
+		}
+	}
+
+	const jsonPatchType = "application/json-patch+json"
+	w = do(http.MethodPatch, path, jsonPatchType, tag, `[{"op":"test","path":"/price","value":90},{"op":"replace","path":"/name","value":"Laptop Pro"}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("json patch: %d %s", w.Code, w.Body.String())
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got["name"] != "Laptop Pro" || got["price"] != float64(90) {
+		t.Fatalf("json-patched product = %v", got)
+	}
+	tag = w.Header().Get("ETag")
+
+	if w := do(http.MethodPatch, path, jsonPatchType, tag, `[{"op":"test","path":"/price","value":1}]`); w.Code != http.StatusConflict {
+		t.Errorf("failed test op: status = %d, want 409", w.Code)
+	}
+	if w := do(http.MethodPatch, path, jsonPatchType, tag, `{"op":"replace"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed json patch: status = %d, want 400", w.Code)
+	}
+
+	// пустой патч ничего не пишет и версию не меняет
+	w = do(http.MethodPatch, path, mergeType, tag, `{}`)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != tag {
+		t.Errorf("empty patch: %d, ETag %q, want unchanged %q", w.Code, w.Header().Get("ETag"), tag)
+	}
+}
+
+func TestProductHandler_HistoryAndRevert(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path, ifMatch string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	created := createPublished(t, r, map[string]any{"name": "Lamp", "price": 30})
+	path := "/products/" + strconv.Itoa(int(created["id"].(float64)))
+
+	w := do(http.MethodPut, path, "*", map[string]any{"name": "Lamp", "price": 25})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	tag := w.Header().Get("ETag")
+
+	w = do(http.MethodGet, path+"/history", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("history: %d %s", w.Code, w.Body.String())
+	}
+	var revs []map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &revs)
+	if len(revs) != 4 || revs[0]["action"] != "created" || revs[3]["action"] != "updated" || revs[3]["actor_id"] != float64(1) {
+		t.Fatalf("history = %v", revs)
+	}
+	price := revs[3]["changes"].(map[string]any)["price"].(map[string]any)
+	if price["from"] != float64(30) || price["to"] != float64(25) {
+		t.Errorf("price change = %v", price)
+	}
+
+	if w := do(http.MethodGet, "/admin/products/"+strconv.Itoa(int(created["id"].(float64)))+"/history", "", nil); w.Code != http.StatusOK {
+		t.Errorf("admin history: %d %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodGet, path+"/price-history", "", nil)
+	var points []map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &points)
+	if w.Code != http.StatusOK || len(points) != 2 || points[1]["price"] != float64(25) || points[1]["changed_at"] == nil {
+		t.Fatalf("price history: %d %v", w.Code, points)
+	}
+
+	first := revs[0]["id"]
+	if w := do(http.MethodPost, path+"/revert", "", map[string]any{"revision_id": first}); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("revert without If-Match: status = %d, want 428", w.Code)
+	}
+	if w := do(http.MethodPost, path+"/revert", tag, map[string]any{"revision_id": 999}); w.Code != http.StatusNotFound {
+		t.Errorf("revert to an unknown revision: status = %d, want 404", w.Code)
+	}
+	w = do(http.MethodPost, path+"/revert", tag, map[string]any{"revision_id": first})
+	if w.Code != http.StatusOK {
+		t.Fatalf("revert: %d %s", w.Code, w.Body.String())
+	}
+	var got map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got["price"] != float64(30) || w.Header().Get("ETag") == tag {
+		t.Errorf("reverted product = %v, ETag %q", got, w.Header().Get("ETag"))
+	}
+	if w := do(http.MethodPost, path+"/revert", tag, map[string]any{"revision_id": first}); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("revert with a stale ETag: status = %d, want 412", w.Code)
+	}
+}
+
+func TestProductHandler_Export(t *testing.T) {
+	r := setupTestServer(t)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	createPublished(t, r, map[string]any{"name": "Mug", "price": 5, "stock": 2, "sku": "MUG-1"})
+	createPublished(t, r, map[string]any{"name": "Lamp", "price": 20})
+	// черновики в выгрузку не попадают
+	b, _ := json.Marshal(map[string]any{"name": "Draft", "price": 1})
+	req, _ := http.NewRequest(http.MethodPost, "/products/", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if w := get("/products/export"); w.Code != http.StatusOK || strings.Count(w.Body.String(), "\n") != 3 {
+		t.Fatalf("export: %d %q", w.Code, w.Body.String())
+	}
+
+	w := get("/products/export?in_stock=true")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), "products.csv") {
+		t.Fatalf("csv export: %d %v", w.Code, w.Header())
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "1,MUG-1,Mug,") {
+		t.Errorf("csv export = %q", w.Body.String())
+	}
+
+	w = get("/products/export?format=merchant")
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), "<item>") != 2 || !strings.Contains(w.Body.String(), "<g:link>http://shop.test/products/2</g:link>") {
+		t.Errorf("merchant export: %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/products/export?format=xlsx"); w.Code != http.StatusOK || !bytes.HasPrefix(w.Body.Bytes(), []byte("PK")) {
+		t.Errorf("xlsx export: %d", w.Code)
+	}
+
+	if w := get("/products/export?format=pdf"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: status = %d, want 400", w.Code)
+	}
+	if w := get("/products/export?attr.ram_gb>=lots"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid filter: status = %d, want 400", w.Code)
+	}
+}
+
+func TestProductHandler_Bulk(t *testing.T) {
+	r := setupTestServer(t)
+
+	bulk := func(body any) (int, map[string]any) {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/products/bulk", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		var resp map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	createPublished(t, r, map[string]any{"name": "Mug", "price": 10})
+	createPublished(t, r, map[string]any{"name": "Lamp", "price": 20})
+
+	code, resp := bulk(map[string]any{"operations": []map[string]any{
+		{"product_id": 1, "op": "price", "percent": 15},
+		{"product_id": 42, "op": "delete"},
+	}})
+	results, _ := resp["results"].([]any)
+	if code != http.StatusOK || resp["applied"] != true || len(results) != 2 {
+		t.Fatalf("bulk: %d %v", code, resp)
+	}
+	first, second := results[0].(map[string]any), results[1].(map[string]any)
+	if first["ok"] != true || first["version"] == nil || second["ok"] != false || second["error"] != "product_not_found" {
+		t.Errorf("results = %v", results)
+	}
+
+	// атомарный запрос откатывается целиком
+	code, resp = bulk(map[string]any{"atomic": true, "operations": []map[string]any{
+		{"product_id": 2, "op": "delete"},
+		{"product_id": 1, "op": "status", "status": "ARCHIVED", "version": 1},
+	}})
+	results, _ = resp["results"].([]any)
+	if code != http.StatusConflict || resp["applied"] != false || results[0].(map[string]any)["error"] != "rolled_back" {
+		t.Fatalf("atomic bulk: %d %v", code, resp)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/products/2", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("rolled back delete applied: %d", w.Code)
+	}
+
+	if code, _ := bulk(map[string]any{"operations": []map[string]any{{"product_id": 1, "op": "price"}}}); code != http.StatusBadRequest {
+		t.Errorf("price without value: status = %d, want 400", code)
+	}
+	if code, _ := bulk(map[string]any{"operations": []map[string]any{}}); code != http.StatusBadRequest {
+		t.Errorf("no operations: status = %d, want 400", code)
+	}
+}
+
+func TestProductHandler_PublicCatalog(t *testing.T) {
+	r := setupTestServer(t)
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	createPublished(t, r, map[string]any{"name": "Mug", "price": 5})
+	b, _ := json.Marshal(map[string]any{"name": "Draft", "price": 1})
+	req, _ := http.NewRequest(http.MethodPost, "/products/", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := get("/catalog/products/1", "")
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "public, max-age=60" || w.Header().Get("Vary") != "Authorization" {
+		t.Fatalf("public get: %d %v", w.Code, w.Header())
+	}
+	if w := get("/catalog/products/1", w.Header().Get("ETag")); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("If-None-Match: status = %d, want 304", w.Code)
+	}
+
+	// черновик анонимам не виден, в том числе через картинки и варианты
+	for _, path := range []string{"/catalog/products/2", "/catalog/products/2/images", "/catalog/products/2/variants", "/catalog/products/2/price-history"} {
+		if w := get(path, ""); w.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, w.Code)
+		}
+	}
+	// а владельцу виден
+	if w := get("/products/2/images", ""); w.Code != http.StatusOK {
+		t.Errorf("owner images of draft: status = %d", w.Code)
+	}
+
+	var list []map[string]any
+	w = get("/catalog/products/", "")
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 1 || list[0]["name"] != "Mug" {
+		t.Errorf("public list: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProductHandler_SellerStorefront(t *testing.T) {
+	r := setupTestServer(t)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	list := func(path string) []map[string]any {
+		t.Helper()
+		w := do(http.MethodGet, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", path, w.Code, w.Body.String())
+		}
+		var items []map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &items)
+		return items
+	}
+
+	createPublished(t, r, map[string]any{"name": "Mug", "price": 5})
+	do(http.MethodPost, "/products/", map[string]any{"name": "Draft", "price": 1})
+
+	if items := list("/catalog/sellers/1/products"); len(items) != 1 || items[0]["name"] != "Mug" {
+		t.Errorf("storefront = %v", items)
+	}
+	if items := list("/catalog/sellers/2/products"); len(items) != 0 {
+		t.Errorf("other seller's storefront = %v", items)
+	}
+
+	// свои товары видны во всех статусах
+	if items := list("/me/products"); len(items) != 2 {
+		t.Errorf("my products = %v", items)
+	}
+	if items := list("/me/products?status=DRAFT"); len(items) != 1 || items[0]["name"] != "Draft" {
+		t.Errorf("my drafts = %v", items)
+	}
+	if w := do(http.MethodGet, "/me/products?status=SOLD", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown status: %d, want 400", w.Code)
+	}
+
+	w := do(http.MethodGet, "/catalog/sellers/1", nil)
+	var profile map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	if w.Code != http.StatusOK || profile["display_name"] != "Aigerim S." || profile["product_count"] != float64(1) ||
+		profile["rating"].(map[string]any)["count"] != float64(0) {
+		t.Errorf("profile: %d %v", w.Code, profile)
+	}
+	if w := do(http.MethodGet, "/catalog/sellers/2", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown seller: %d, want 404", w.Code)
+	}
+
+	// оценить продавца может только покупатель с доставленным заказом
+	if w := doAs(r, 4, http.MethodPut, "/sellers/1/rating", map[string]any{"score": 5},
+		map[string]string{"Authorization": "Bearer buyer-4"}); w.Code != http.StatusForbidden {
+		t.Errorf("rating without a delivered order: %d, want 403", w.Code)
+	}
+	if w := do(http.MethodPut, "/sellers/1/rating", map[string]any{"score": 5}); w.Code != http.StatusForbidden {
+		t.Errorf("rating yourself: %d, want 403", w.Code)
+	}
+	buyer := map[string]string{"Authorization": "Bearer buyer-3"}
+	if w := doAs(r, 3, http.MethodPut, "/sellers/1/rating", map[string]any{"score": 6}, buyer); w.Code != http.StatusBadRequest {
+		t.Errorf("score 6: %d, want 400", w.Code)
+	}
+	w = doAs(r, 3, http.MethodPut, "/sellers/1/rating", map[string]any{"score": 4}, buyer)
+	var rating map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &rating)
+	if w.Code != http.StatusOK || rating["average"] != float64(4) || rating["count"] != float64(1) {
+		t.Errorf("rate: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// doAs выполняет запрос от имени другого пользователя.
+func doAs(r *gin.Engine, userID uint, method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	b, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", strconv.FormatUint(uint64(userID), 10))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestProductHandler_ForeignProducts(t *testing.T) {
+	r := setupTestServer(t)
+
+	created := createPublished(t, r, map[string]any{"name": "Mug", "price": 5, "stock": 3})
+	id := strconv.Itoa(int(created["id"].(float64)))
+
+	if w := doAs(r, 2, http.MethodPost, "/products/"+id+"/stock", map[string]any{"delta": -3}, nil); w.Code != http.StatusForbidden {
+		t.Errorf("foreign stock adjustment: %d %s, want 403", w.Code, w.Body.String())
+	}
+
+	// If-Match: * не должен открывать чужой товар
+	anyVersion := map[string]string{"If-Match": "*"}
+	writes := []struct {
+		method string
+		body   any
+	}{
+		{http.MethodPatch, map[string]any{"price": 1}},
+		{http.MethodPut, map[string]any{"name": "Mine", "price": 1}},
+		{http.MethodDelete, nil},
+	}
+	for _, wr := range writes {
+		if w := doAs(r, 2, wr.method, "/products/"+id, wr.body, anyVersion); w.Code != http.StatusForbidden {
+			t.Errorf("foreign %s: %d %s, want 403", wr.method, w.Code, w.Body.String())
+		}
+	}
+
+	w := doAs(r, 1, http.MethodGet, "/products/"+id, nil, nil)
+	var got map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got["stock"] != float64(3) || got["name"] != "Mug" || got["price"] != float64(5) {
+		t.Errorf("product after foreign writes = %v", got)
+	}
+}
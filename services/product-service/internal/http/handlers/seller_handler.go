@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"GoProduct/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SellerHandler serves seller storefronts: the profile, the published
+// products and buyers' ratings.
+type SellerHandler struct {
+	svc      *service.SellerService
+	products *ProductHandler
+}
+
+func NewSellerHandler(svc *service.SellerService, products *ProductHandler) *SellerHandler {
+	return &SellerHandler{svc: svc, products: products}
+}
+
+type sellerRatingResp struct {
+	Average float64 `json:"average"`
+	Count   int64   `json:"count"`
+}
+
+type sellerProfileResp struct {
+	SellerID     uint             `json:"seller_id"`
+	DisplayName  string           `json:"display_name,omitempty"`
+	MemberSince  *time.Time       `json:"member_since,omitempty"`
+	Rating       sellerRatingResp `json:"rating"`
+	ProductCount int64            `json:"product_count"`
+}
+
+type rateSellerReq struct {
+	Score int `json:"score" binding:"required"`
+}
+
+func (h *SellerHandler) Profile(c *gin.Context) {
+	sellerID, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.svc.Profile(c.Request.Context(), sellerID)
+	if err != nil {
+		writeSellerError(c, err)
+		return
+	}
+
+	resp := sellerProfileResp{
+		SellerID:     p.SellerID,
+		DisplayName:  p.DisplayName,
+		Rating:       sellerRatingResp{Average: p.Rating.Average, Count: p.Rating.Count},
+		ProductCount: p.ProductCount,
+	}
+	if !p.MemberSince.IsZero() {
+		resp.MemberSince = &p.MemberSince
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Products lists a seller's published products; it takes the same filters
+// as the catalog listing.
+func (h *SellerHandler) Products(c *gin.Context) {
+	sellerID, ok := idParam(c)
+	if !ok {
+		return
+	}
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	filter.SellerID = sellerID
+
+	products, err := h.products.svc.ListProducts(filter)
+	if err != nil {
+		if service.IsInvalidFilter(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	c.JSON(http.StatusOK, h.products.toProductResps(products))
+}
+
+// Rate sets the caller's score for a seller, from 1 to 5 stars. Only buyers
+// who received an order from the seller may rate them.
+func (h *SellerHandler) Rate(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	sellerID, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req rateSellerReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rating, err := h.svc.Rate(c.Request.Context(), service.RateSellerInput{
+		SellerID:      sellerID,
+		BuyerID:       userID,
+		Authorization: c.GetHeader("Authorization"),
+		Score:         req.Score,
+	})
+	if err != nil {
+		writeSellerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sellerRatingResp{Average: rating.Average, Count: rating.Count})
+}
+
+func writeSellerError(c *gin.Context, err error) {
+	switch {
+	case service.IsSellerNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "seller not found"})
+	case service.IsInvalidRating(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "sellers cannot rate themselves"})
+	case service.IsNotDelivered(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only buyers with a delivered order from this seller can rate them"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
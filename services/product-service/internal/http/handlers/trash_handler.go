@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"GoProduct/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashHandler serves soft-deleted products: restore for their owners and
+// a listing for admins.
+type TrashHandler struct {
+	svc      *service.TrashService
+	products *ProductHandler
+}
+
+func NewTrashHandler(svc *service.TrashService, products *ProductHandler) *TrashHandler {
+	return &TrashHandler{svc: svc, products: products}
+}
+
+func (h *TrashHandler) Restore(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.svc.Restore(userID, id)
+	if err != nil {
+		switch {
+		case service.IsNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case service.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+		case service.IsRestoreExpired(err):
+			c.JSON(http.StatusGone, gin.H{"error": "product was deleted too long ago to restore"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}
+		return
+	}
+	h.products.writeProduct(c, http.StatusOK, p)
+}
+
+func (h *TrashHandler) Deleted(c *gin.Context) {
+	limit, ok := limitQuery(c)
+	if !ok {
+		return
+	}
+
+	products, err := h.svc.ListDeleted(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+	c.JSON(http.StatusOK, h.products.toProductResps(products))
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VariantFilterPrefix marks GET /products query parameters that filter by
+// variant attributes, e.g. ?variant.size=M&variant.color=red.
+const VariantFilterPrefix = "variant."
+
+// AttrFilterPrefix marks GET /products query terms that filter by product
+// attributes, e.g. ?attr.color=red&attr.ram_gb>=16.
+const AttrFilterPrefix = "attr."
+
+type VariantHandler struct {
+	svc *service.VariantService
+}
+
+func NewVariantHandler(svc *service.VariantService) *VariantHandler {
+	return &VariantHandler{svc: svc}
+}
+
+type variantReq struct {
+	SKU        string            `json:"sku" binding:"required,max=64"`
+	Attributes map[string]string `json:"attributes"`
+	Price      *float64          `json:"price" binding:"omitempty,gt=0"`
+	Stock      int               `json:"stock" binding:"min=0"`
+}
+
+// variantResp.Price is null when the variant sells at the product's price.
+type variantResp struct {
+	ID         uint              `json:"id"`
+	SKU        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+	Price      *float64          `json:"price"`
+	Stock      int               `json:"stock"`
+	OutOfStock bool              `json:"out_of_stock"`
+}
+
+func (h *VariantHandler) List(c *gin.Context) {
+	productID, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	variants, err := h.svc.List(viewerID(c), productID)
+	if err != nil {
+		writeVariantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toVariantResps(variants))
+}
+
+func (h *VariantHandler) Create(c *gin.Context) {
+	in, ok := bindVariant(c)
+	if !ok {
+		return
+	}
+
+	v, err := h.svc.Create(in)
+	if err != nil {
+		writeVariantError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toVariantResp(v))
+}
+
+func (h *VariantHandler) Update(c *gin.Context) {
+	in, ok := bindVariant(c)
+	if !ok {
+		return
+	}
+	if in.VariantID, ok = variantIDParam(c); !ok {
+		return
+	}
+
+	v, err := h.svc.Update(in)
+	if err != nil {
+		writeVariantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toVariantResp(v))
+}
+
+func (h *VariantHandler) Delete(c *gin.Context) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := idParam(c)
+	if !ok {
+		return
+	}
+	variantID, ok := variantIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(userID, productID, variantID); err != nil {
+		writeVariantError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func bindVariant(c *gin.Context) (service.VariantInput, bool) {
+	userID, ok := requestUserID(c)
+	if !ok {
+		return service.VariantInput{}, false
+	}
+	productID, ok := idParam(c)
+	if !ok {
+		return service.VariantInput{}, false
+	}
+
+	var req variantReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return service.VariantInput{}, false
+	}
+
+	return service.VariantInput{
+		ProductID:  productID,
+		UserID:     userID,
+		SKU:        req.SKU,
+		Attributes: req.Attributes,
+		Price:      req.Price,
+		Stock:      req.Stock,
+	}, true
+}
+
+func variantIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func writeVariantError(c *gin.Context, err error) {
+	switch {
+	case service.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case service.IsVariantNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "variant not found"})
+	case service.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not your product"})
+	case service.IsDuplicateSKU(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "sku already used by another of your variants"})
+	case service.IsInvalidVariant(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+	}
+}
+
+func toVariantResp(v *domain.ProductVariant) variantResp {
+	attrs := make(map[string]string, len(v.Attributes))
+	for _, a := range v.Attributes {
+		attrs[a.Name] = a.Value
+	}
+	return variantResp{
+		ID:         v.ID,
+		SKU:        v.SKU,
+		Attributes: attrs,
+		Price:      v.Price,
+		Stock:      v.Stock,
+		OutOfStock: v.OutOfStock(),
+	}
+}
+
+func toVariantResps(variants []domain.ProductVariant) []variantResp {
+	resp := make([]variantResp, 0, len(variants))
+	for i := range variants {
+		resp = append(resp, toVariantResp(&variants[i]))
+	}
+	return resp
+}
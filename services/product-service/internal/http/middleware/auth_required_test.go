@@ -123,3 +123,134 @@ func TestAuthRequired_InvalidToken(t *testing.T) {
 		t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusUnauthorized, w.Body.String())
 	}
 }
+
+func TestOptionalAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := "test-secret"
+	verifier := jwtutil.NewVerifier(secret)
+
+	r := gin.New()
+	r.GET("/cart", mw.OptionalAuth(verifier), func(c *gin.Context) {
+		uid, _ := c.Get(mw.UserIDKey)
+		c.JSON(http.StatusOK, gin.H{"user_id": uid})
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"anonymous", "", http.StatusOK},
+		{"valid token", "Bearer " + makeToken(secret, 5, "u@example.com", time.Now().Add(time.Hour)), http.StatusOK},
+		{"invalid token", "Bearer garbage", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/cart", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d; body = %s", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+}
+
+func TestInternalOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name       string
+		configured string
+		header     string
+		want       int
+	}{
+		{"valid token", "s3cret", "s3cret", http.StatusOK},
+		{"wrong token", "s3cret", "guess", http.StatusUnauthorized},
+		{"missing token", "s3cret", "", http.StatusUnauthorized},
+		{"not configured", "", "", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		r := gin.New()
+		r.GET("/internal", mw.InternalOnly(tc.configured), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/internal", nil)
+		if tc.header != "" {
+			req.Header.Set(mw.InternalTokenHeader, tc.header)
+		}
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := "test-secret"
+	verifier := jwtutil.NewVerifier(secret)
+
+	r := gin.New()
+	r.GET("/admin", mw.AuthRequired(verifier), mw.RequireAdmin([]uint{7}), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"email": c.GetString(mw.EmailKey)})
+	})
+
+	cases := []struct {
+		name   string
+		userID uint
+		want   int
+	}{
+		{"admin", 7, http.StatusOK},
+		{"regular user", 8, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+makeToken(secret, tc.userID, "admin@example.com", time.Now().Add(time.Hour)))
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
+
+func TestPublicCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := "test-secret"
+	verifier := jwtutil.NewVerifier(secret)
+
+	r := gin.New()
+	r.GET("/products", mw.OptionalAuth(verifier), mw.PublicCache(5*time.Minute), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"anonymous", "", "public, max-age=300"},
+		{"signed in", "Bearer " + makeToken(secret, 5, "u@example.com", time.Now().Add(time.Hour)), "private, no-cache"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/products", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		r.ServeHTTP(w, req)
+		if got := w.Header().Get("Cache-Control"); got != tc.want || w.Header().Get("Vary") != "Authorization" {
+			t.Errorf("%s: Cache-Control = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -2,15 +2,22 @@ package middleware
 
 import (
 	jwtutil "GoProduct/pkg/jwt"
+	"crypto/subtle"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 const UserIDKey = "user_id"
+const EmailKey = "email"
 const Status = "status"
 
+// InternalTokenHeader carries the shared secret of service-to-service calls.
+const InternalTokenHeader = "X-Internal-Token"
+
 func AuthRequired(verifier *jwtutil.Verifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -33,11 +40,42 @@ func AuthRequired(verifier *jwtutil.Verifier) gin.HandlerFunc {
 		}
 
 		c.Set(UserIDKey, claims.UserID)
+		c.Set(EmailKey, claims.Email)
 		c.Set(Status, claims.Status)
 		c.Next()
 	}
 }
 
+// OptionalAuth authenticates the request when an Authorization header is
+// present and lets anonymous requests through. A malformed or invalid token
+// is still rejected rather than silently treated as a guest.
+func OptionalAuth(verifier *jwtutil.Verifier) gin.HandlerFunc {
+	required := AuthRequired(verifier)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
+// PublicCache lets browsers and CDNs cache the public catalog for maxAge.
+// A signed-in seller also sees their own unpublished products there, so
+// responses to requests with a token are only cached privately.
+func PublicCache(maxAge time.Duration) gin.HandlerFunc {
+	public := "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	return func(c *gin.Context) {
+		c.Header("Vary", "Authorization")
+		if _, ok := c.Get(UserIDKey); ok {
+			c.Header("Cache-Control", "private, no-cache")
+		} else {
+			c.Header("Cache-Control", public)
+		}
+		c.Next()
+	}
+}
+
 func RequireActive() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		status, _ := c.Get(Status)
@@ -48,3 +86,35 @@ func RequireActive() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireAdmin admits only the configured admin users. It must run after
+// AuthRequired.
+func RequireAdmin(adminIDs []uint) gin.HandlerFunc {
+	admins := make(map[uint]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+	return func(c *gin.Context) {
+		userID, _ := c.Get(UserIDKey)
+		id, ok := userID.(uint)
+		if !ok || !admins[id] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin only"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// InternalOnly admits requests carrying the shared internal token. With an
+// empty token every request is rejected, so internal routes stay closed
+// until a token is configured.
+func InternalOnly(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got := c.GetHeader(InternalTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid internal token"})
+			return
+		}
+		c.Next()
+	}
+}
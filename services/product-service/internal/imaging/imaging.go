@@ -0,0 +1,90 @@
+// Package imaging makes thumbnails with the standard library only.
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Fit scales img down so that neither side exceeds max, keeping the aspect
+// ratio. Images that already fit are returned as they are.
+func Fit(img image.Image, max int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= max && h <= max {
+		return img
+	}
+	if w >= h {
+		h = maxInt(1, h*max/w)
+		w = max
+	} else {
+		w = maxInt(1, w*max/h)
+		h = max
+	}
+	return resize(img, w, h)
+}
+
+// resize averages every source pixel that falls into a destination pixel
+// (a box filter), which is good enough for downscaling photos.
+func resize(img image.Image, w, h int) image.Image {
+	src := toRGBA(img)
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, maxInt((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, maxInt((x+1)*sw/w, x*sw/w+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				off := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[off])
+					g += uint32(src.Pix[off+1])
+					b += uint32(src.Pix[off+2])
+					a += uint32(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// Encode writes img as contentType. Formats that may carry transparency
+// (PNG, GIF) become PNG; everything else becomes JPEG. It returns the
+// content type actually written.
+func Encode(w io.Writer, img image.Image, contentType string) (string, error) {
+	switch contentType {
+	case "image/png", "image/gif":
+		return "image/png", png.Encode(w, img)
+	default:
+		return "image/jpeg", jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,44 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFit_KeepsAspectRatio(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.RGBA{R: 200, A: 255})
+		}
+	}
+
+	got := Fit(src, 100)
+	if b := got.Bounds(); b.Dx() != 100 || b.Dy() != 25 {
+		t.Fatalf("Fit() size = %v, want 100x25", b.Size())
+	}
+	if r, _, _, a := got.At(50, 10).RGBA(); r>>8 != 200 || a>>8 != 255 {
+		t.Errorf("pixel = %d/%d, want the source colour", r>>8, a>>8)
+	}
+
+	// маленькие картинки не увеличиваем
+	if small := Fit(src, 1000); small != image.Image(src) {
+		t.Errorf("Fit() upscaled a small image")
+	}
+}
+
+func TestEncode_PicksFormat(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for in, want := range map[string]string{"image/png": "image/png", "image/gif": "image/png", "image/jpeg": "image/jpeg"} {
+		var buf bytes.Buffer
+		ct, err := Encode(&buf, img, in)
+		if err != nil || ct != want {
+			t.Errorf("Encode(%s) = %s, %v; want %s", in, ct, err, want)
+		}
+		if _, format, err := image.Decode(&buf); err != nil || "image/"+format != want {
+			t.Errorf("Encode(%s) wrote %s, %v", in, format, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("result is not JSON: %s", got)
+	}
+	_ = json.Unmarshal([]byte(want), &w)
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// Примеры из приложения A RFC 7396.
+func TestMerge_RFCExamples(t *testing.T) {
+	cases := []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		got, err := Merge([]byte(tc.doc), []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("Merge(%s, %s) error = %v", tc.doc, tc.patch, err)
+		}
+		assertJSON(t, got, tc.want)
+	}
+
+	if _, err := Merge([]byte(`{}`), []byte(`{`)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("malformed patch error = %v, want ErrInvalid", err)
+	}
+}
+
+// Примеры из приложения A RFC 6902.
+func TestApply_RFCExamples(t *testing.T) {
+	cases := []struct{ doc, patch, want string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"foo":1}`, `[{"op":"copy","from":"/foo","path":"/bar"}]`, `{"foo":1,"bar":1}`},
+	}
+	for _, tc := range cases {
+		got, err := Apply([]byte(tc.doc), []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("Apply(%s, %s) error = %v", tc.doc, tc.patch, err)
+		}
+		assertJSON(t, got, tc.want)
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	cases := []struct {
+		doc, patch string
+		want       error
+	}{
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ErrConflict},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ErrConflict},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/nope"}]`, ErrConflict},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/nope","value":1}]`, ErrConflict},
+		{`{"foo":["a"]}`, `[{"op":"add","path":"/foo/01","value":1}]`, ErrConflict},
+		{`{"foo":"bar"}`, `[{"op":"jump","path":"/foo"}]`, ErrInvalid},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"foo","value":1}]`, ErrInvalid},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/x"}]`, ErrInvalid},
+		{`{"foo":"bar"}`, `{"op":"add"}`, ErrInvalid},
+	}
+	for _, tc := range cases {
+		if _, err := Apply([]byte(tc.doc), []byte(tc.patch)); !errors.Is(err, tc.want) {
+			t.Errorf("Apply(%s, %s) error = %v, want %v", tc.doc, tc.patch, err, tc.want)
+		}
+	}
+
+	// патч применяется целиком или никак
+	doc := []byte(`{"a":1}`)
+	if _, err := Apply(doc, []byte(`[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":2}]`)); err == nil {
+		t.Fatalf("Apply() error = nil, want test failure")
+	}
+	assertJSON(t, doc, `{"a":1}`)
+}
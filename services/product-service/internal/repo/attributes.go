@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// AttrFilter compares one product attribute with Value. Op is one of =, >,
+// >=, < and <=; the ordering operators only match numeric attributes. Name
+// must already be validated as a plain identifier.
+type AttrFilter struct {
+	Name  string
+	Op    string
+	Value string
+}
+
+var attrOps = map[string]bool{"=": true, ">": true, ">=": true, "<": true, "<=": true}
+
+// whereAttr adds f to q. On Postgres it is written with the jsonb operators
+// served by the GIN index on products.attributes (@> for equality, @@ with
+// a jsonpath predicate for ranges); other dialects, i.e. SQLite in tests,
+// fall back to json_extract.
+func whereAttr(q *gorm.DB, f AttrFilter) *gorm.DB {
+	if !attrOps[f.Op] {
+		return q.Where("1 = 0")
+	}
+	num, numErr := strconv.ParseFloat(f.Value, 64)
+	isNum := numErr == nil
+	b, boolErr := strconv.ParseBool(f.Value)
+	isBool := boolErr == nil && (f.Value == "true" || f.Value == "false")
+
+	if q.Dialector.Name() == "postgres" {
+		if f.Op != "=" {
+			if !isNum {
+				return q.Where("1 = 0")
+			}
+			path := "$." + f.Name + " " + f.Op + " " + strconv.FormatFloat(num, 'f', -1, 64)
+			return q.Where("attributes @@ ?::jsonpath", path)
+		}
+
+		candidates := []any{f.Value}
+		if isNum {
+			candidates = append(candidates, num)
+		}
+		if isBool {
+			candidates = append(candidates, b)
+		}
+		cond := q.Session(&gorm.Session{NewDB: true})
+		for i, v := range candidates {
+			doc, _ := json.Marshal(map[string]any{f.Name: v})
+			if i == 0 {
+				cond = cond.Where("attributes @> ?::jsonb", string(doc))
+			} else {
+				cond = cond.Or("attributes @> ?::jsonb", string(doc))
+			}
+		}
+		return q.Where(cond)
+	}
+
+	path := "$." + f.Name
+	if f.Op != "=" {
+		if !isNum {
+			return q.Where("1 = 0")
+		}
+		return q.Where("json_type(attributes, ?) IN ('integer', 'real') AND json_extract(attributes, ?) "+f.Op+" ?", path, path, num)
+	}
+
+	cond := q.Session(&gorm.Session{NewDB: true}).
+		Where("json_type(attributes, ?) = 'text' AND json_extract(attributes, ?) = ?", path, path, f.Value)
+	if isNum {
+		cond = cond.Or("json_type(attributes, ?) IN ('integer', 'real') AND json_extract(attributes, ?) = ?", path, path, num)
+	}
+	if isBool {
+		cond = cond.Or("json_type(attributes, ?) = ?", path, f.Value)
+	}
+	return q.Where(cond)
+}
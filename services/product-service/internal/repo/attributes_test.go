@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"strings"
+	"testing"
+
+	"GoProduct/internal/domain"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestWhereAttr_PostgresUsesIndexedOperators(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	cases := []struct {
+		filter AttrFilter
+		want   string
+	}{
+		{AttrFilter{Name: "color", Op: "=", Value: "red"}, `attributes @> '{"color":"red"}'::jsonb`},
+		{AttrFilter{Name: "ram_gb", Op: "=", Value: "16"}, `OR attributes @> '{"ram_gb":16}'::jsonb`},
+		{AttrFilter{Name: "ram_gb", Op: ">=", Value: "16"}, `attributes @@ '$.ram_gb >= 16'::jsonpath`},
+	}
+	for _, tc := range cases {
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return whereAttr(tx.Model(&domain.Product{}), tc.filter).Find(&[]domain.Product{})
+		})
+		if !strings.Contains(sql, tc.want) {
+			t.Errorf("%+v:\n%s\nwant it to contain %s", tc.filter, sql, tc.want)
+		}
+	}
+}
+
+func TestProducts_ListByAttributes(t *testing.T) {
+	db := newTestDB(t)
+	r := NewProducts(db)
+
+	for _, p := range []domain.Product{
+		{UserID: 1, Name: "Small", Price: 1, Category: "laptops", Attributes: domain.Attributes{"ram_gb": 8.0, "color": "red"}},
+		{UserID: 1, Name: "Big", Price: 1, Category: "laptops", Attributes: domain.Attributes{"ram_gb": 32.0, "color": "red", "touch": true}},
+		{UserID: 1, Name: "Text ram", Price: 1, Category: "laptops", Attributes: domain.Attributes{"ram_gb": "lots"}},
+		{UserID: 1, Name: "Shoe", Price: 1, Category: "shoes", Attributes: domain.Attributes{"color": "red"}},
+	} {
+		if err := r.Create(&p); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		filter ProductFilter
+		want   int
+	}{
+		{"string", ProductFilter{Attrs: []AttrFilter{{Name: "color", Op: "=", Value: "red"}}}, 3},
+		{"category", ProductFilter{Category: "laptops", Attrs: []AttrFilter{{Name: "color", Op: "=", Value: "red"}}}, 2},
+		// строковое значение не попадает в числовой диапазон
+		{"range", ProductFilter{Attrs: []AttrFilter{{Name: "ram_gb", Op: ">=", Value: "16"}}}, 1},
+		{"number equality", ProductFilter{Attrs: []AttrFilter{{Name: "ram_gb", Op: "=", Value: "8"}}}, 1},
+		{"bool", ProductFilter{Attrs: []AttrFilter{{Name: "touch", Op: "=", Value: "true"}}}, 1},
+		{"bad operator", ProductFilter{Attrs: []AttrFilter{{Name: "ram_gb", Op: "; DROP", Value: "1"}}}, 0},
+	}
+	for _, tc := range cases {
+		got, err := r.List(tc.filter)
+		if err != nil {
+			t.Fatalf("%s: List() error = %v", tc.name, err)
+		}
+		if len(got) != tc.want {
+			t.Errorf("%s: got %d products, want %d", tc.name, len(got), tc.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Carts struct {
+	db *gorm.DB
+}
+
+func NewCarts(db *gorm.DB) *Carts {
+	return &Carts{db: db}
+}
+
+func (r *Carts) Create(c *domain.Cart) error {
+	return r.db.Create(c).Error
+}
+
+func (r *Carts) GetByUser(userID uint) (*domain.Cart, error) {
+	return r.first(r.db.Where("user_id = ?", userID))
+}
+
+func (r *Carts) GetByToken(token string) (*domain.Cart, error) {
+	return r.first(r.db.Where("token = ?", token))
+}
+
+func (r *Carts) first(q *gorm.DB) (*domain.Cart, error) {
+	var c domain.Cart
+	err := q.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("cart_items.id")
+	}).First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Claim attaches a guest cart to a user who has no cart yet.
+func (r *Carts) Claim(cartID, userID uint) error {
+	return r.db.Model(&domain.Cart{}).
+		Where("id = ? AND user_id IS NULL", cartID).
+		Updates(map[string]any{"user_id": userID, "updated_at": time.Now()}).Error
+}
+
+// SaveItems inserts or replaces items by (cart_id, product_id) and bumps the
+// cart's updated_at so active carts are not treated as abandoned.
+func (r *Carts) SaveItems(cartID uint, items ...domain.CartItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveItems(tx, cartID, items)
+	})
+}
+
+func saveItems(tx *gorm.DB, cartID uint, items []domain.CartItem) error {
+	if len(items) > 0 {
+		for i := range items {
+			items[i].CartID = cartID
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "cart_id"}, {Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "unit_price", "quantity", "updated_at"}),
+		}).Create(&items).Error
+		if err != nil {
+			return err
+		}
+	}
+	return touch(tx, cartID)
+}
+
+func (r *Carts) DeleteItem(cartID, productID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&domain.CartItem{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return touch(tx, cartID)
+	})
+}
+
+func (r *Carts) ClearItems(cartID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id = ?", cartID).Delete(&domain.CartItem{}).Error; err != nil {
+			return err
+		}
+		return touch(tx, cartID)
+	})
+}
+
+// Merge writes the merged items into dst and deletes the guest cart src in
+// one transaction.
+func (r *Carts) Merge(srcID, dstID uint, items []domain.CartItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveItems(tx, dstID, items); err != nil {
+			return err
+		}
+		if err := tx.Where("cart_id = ?", srcID).Delete(&domain.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Cart{}, srcID).Error
+	})
+}
+
+// DeleteAbandoned removes guest carts untouched since guestBefore and user
+// carts untouched since userBefore. It returns the number of carts removed.
+func (r *Carts) DeleteAbandoned(guestBefore, userBefore time.Time) (int64, error) {
+	var n int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ids := tx.Model(&domain.Cart{}).Select("id").Where(
+			"(user_id IS NULL AND updated_at < ?) OR (user_id IS NOT NULL AND updated_at < ?)",
+			guestBefore, userBefore,
+		)
+		if err := tx.Where("cart_id IN (?)", ids).Delete(&domain.CartItem{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("id IN (?)", ids).Delete(&domain.Cart{})
+		n = res.RowsAffected
+		return res.Error
+	})
+	return n, err
+}
+
+func touch(tx *gorm.DB, cartID uint) error {
+	return tx.Model(&domain.Cart{}).Where("id = ?", cartID).Update("updated_at", time.Now()).Error
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+)
+
+func TestCarts_SaveItemsUpsertsByProduct(t *testing.T) {
+	r := NewCarts(newTestDB(t))
+
+	cart := &domain.Cart{Token: "guest-token"}
+	if err := r.Create(cart); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := r.SaveItems(cart.ID, domain.CartItem{ProductID: 1, Name: "A", UnitPrice: 10, Quantity: 1}); err != nil {
+		t.Fatalf("SaveItems() error = %v", err)
+	}
+	if err := r.SaveItems(cart.ID, domain.CartItem{ProductID: 1, Name: "A", UnitPrice: 12, Quantity: 3}); err != nil {
+		t.Fatalf("SaveItems() error = %v", err)
+	}
+
+	got, err := r.GetByToken("guest-token")
+	if err != nil {
+		t.Fatalf("GetByToken() error = %v", err)
+	}
+	if len(got.Items) != 1 || got.Items[0].Quantity != 3 || got.Items[0].UnitPrice != 12 {
+		t.Fatalf("items = %+v, want one item with quantity 3 at 12", got.Items)
+	}
+
+	if err := r.DeleteItem(cart.ID, 1); err != nil {
+		t.Fatalf("DeleteItem() error = %v", err)
+	}
+	if err := r.DeleteItem(cart.ID, 1); err == nil {
+		t.Errorf("DeleteItem() of a missing item error = nil")
+	}
+}
+
+func TestCarts_DeleteAbandoned(t *testing.T) {
+	db := newTestDB(t)
+	r := NewCarts(db)
+
+	userID := uint(7)
+	old := time.Now().Add(-48 * time.Hour)
+	carts := []*domain.Cart{
+		{Token: "old-guest"},
+		{Token: "new-guest"},
+		{Token: "old-user", UserID: &userID},
+	}
+	for _, c := range carts {
+		if err := r.Create(c); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		_ = r.SaveItems(c.ID, domain.CartItem{ProductID: 1, Name: "A", UnitPrice: 1, Quantity: 1})
+	}
+	for _, c := range []*domain.Cart{carts[0], carts[2]} {
+		db.Model(&domain.Cart{}).Where("id = ?", c.ID).Update("updated_at", old)
+	}
+
+	// Guests expire after a day, users after a week: only the old guest goes.
+	n, err := r.DeleteAbandoned(time.Now().Add(-24*time.Hour), time.Now().Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteAbandoned() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("DeleteAbandoned() = %d, want 1", n)
+	}
+	if _, err := r.GetByToken("old-guest"); err == nil {
+		t.Errorf("old guest cart still exists")
+	}
+
+	var items int64
+	db.Model(&domain.CartItem{}).Count(&items)
+	if items != 2 {
+		t.Errorf("cart items left = %d, want 2", items)
+	}
+}
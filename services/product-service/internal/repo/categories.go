@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Categories struct {
+	db *gorm.DB
+}
+
+func NewCategories(db *gorm.DB) *Categories {
+	return &Categories{db: db}
+}
+
+// Save creates the category or replaces its name and schema.
+func (r *Categories) Save(c *domain.Category) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "attributes", "updated_at"}),
+	}).Create(c).Error
+}
+
+func (r *Categories) Get(slug string) (*domain.Category, error) {
+	var c domain.Category
+	if err := r.db.Where("slug = ?", slug).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *Categories) List() ([]domain.Category, error) {
+	var categories []domain.Category
+	if err := r.db.Order("slug").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
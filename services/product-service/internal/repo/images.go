@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type Images struct {
+	db *gorm.DB
+}
+
+func NewImages(db *gorm.DB) *Images {
+	return &Images{db: db}
+}
+
+// Create appends img after the product's other images.
+func (r *Images) Create(img *domain.ProductImage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var last *int
+		if err := tx.Model(&domain.ProductImage{}).
+			Where("product_id = ?", img.ProductID).
+			Select("MAX(position)").Scan(&last).Error; err != nil {
+			return err
+		}
+		img.Position = 0
+		if last != nil {
+			img.Position = *last + 1
+		}
+		if err := tx.Create(img).Error; err != nil {
+			return err
+		}
+		return bumpVersion(tx, img.ProductID)
+	})
+}
+
+func (r *Images) Count(productID uint) (int64, error) {
+	var n int64
+	err := r.db.Model(&domain.ProductImage{}).Where("product_id = ?", productID).Count(&n).Error
+	return n, err
+}
+
+// ListByProduct returns the product's images in display order.
+func (r *Images) ListByProduct(productID uint) ([]domain.ProductImage, error) {
+	var images []domain.ProductImage
+	if err := r.db.Where("product_id = ?", productID).Scopes(imageOrder).Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (r *Images) Get(productID, id uint) (*domain.ProductImage, error) {
+	var img domain.ProductImage
+	if err := r.db.Where("product_id = ?", productID).First(&img, id).Error; err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+func (r *Images) Delete(productID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("product_id = ?", productID).Delete(&domain.ProductImage{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return bumpVersion(tx, productID)
+	})
+}
+
+// Reorder gives the images positions in the order of ids. The caller makes
+// sure ids are exactly the product's images.
+func (r *Images) Reorder(productID uint, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for pos, id := range ids {
+			if err := tx.Model(&domain.ProductImage{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("position", pos).Error; err != nil {
+				return err
+			}
+		}
+		return bumpVersion(tx, productID)
+	})
+}
+
+func imageOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"testing"
+
+	"GoProduct/internal/domain"
+)
+
+func TestImages_PositionsAndReorder(t *testing.T) {
+	db := newTestDB(t)
+	r := NewImages(db)
+
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		img := &domain.ProductImage{ProductID: 1, Key: "k", ContentType: "image/png"}
+		if err := r.Create(img); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if img.Position != i {
+			t.Errorf("image %d position = %d, want %d", i, img.Position, i)
+		}
+		ids = append(ids, img.ID)
+	}
+	// картинка другого товара не влияет на позиции
+	other := &domain.ProductImage{ProductID: 2, Key: "k", ContentType: "image/png"}
+	_ = r.Create(other)
+	if other.Position != 0 {
+		t.Errorf("other product position = %d, want 0", other.Position)
+	}
+
+	if err := r.Reorder(1, []uint{ids[2], ids[0], ids[1]}); err != nil {
+		t.Fatalf("Reorder() error = %v", err)
+	}
+	got, _ := r.ListByProduct(1)
+	if len(got) != 3 || got[0].ID != ids[2] || got[1].ID != ids[0] || got[2].ID != ids[1] {
+		t.Errorf("ListByProduct() = %+v", got)
+	}
+
+	if err := r.Delete(2, ids[0]); err == nil {
+		t.Errorf("Delete() through another product succeeded")
+	}
+}
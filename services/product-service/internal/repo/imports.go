@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Imports struct {
+	db *gorm.DB
+}
+
+func NewImports(db *gorm.DB) *Imports {
+	return &Imports{db: db}
+}
+
+func (r *Imports) Create(job *domain.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+// Get returns a job without its payload.
+func (r *Imports) Get(id uint) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	if err := r.db.Omit("payload").First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim marks the oldest job waiting to be processed as RUNNING and
+// returns it with its payload, or gorm.ErrRecordNotFound when there is
+// none. A RUNNING job whose progress has not moved since staleBefore is
+// taken over too: the instance running it is presumed gone.
+func (r *Imports) Claim(staleBefore time.Time) (*domain.ImportJob, error) {
+	claimable := func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? OR (status = ? AND updated_at < ?)", domain.ImportPending, domain.ImportRunning, staleBefore)
+	}
+	for {
+		var job domain.ImportJob
+		if err := r.db.Scopes(claimable).Order("id").First(&job).Error; err != nil {
+			return nil, err
+		}
+
+		// другой экземпляр мог забрать задачу между выборкой и обновлением
+		now := time.Now()
+		res := r.db.Model(&domain.ImportJob{}).Scopes(claimable).
+			Where("id = ?", job.ID).
+			Updates(map[string]any{"status": domain.ImportRunning, "updated_at": now})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			job.Status = domain.ImportRunning
+			job.UpdatedAt = now
+			return &job, nil
+		}
+	}
+}
+
+// SaveProgress stores the job's counters, errors and status, and drops the
+// payload of a finished job.
+func (r *Imports) SaveProgress(job *domain.ImportJob) error {
+	job.UpdatedAt = time.Now()
+	columns := []string{"status", "total", "processed", "created", "updated", "failed", "error", "errors", "updated_at", "finished_at"}
+	if job.Finished() {
+		job.Payload = nil
+		columns = append(columns, "payload")
+	}
+	return r.db.Model(job).Select(columns).Updates(job).Error
+}
@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+	"gorm.io/gorm"
+)
+
+func TestImports_ClaimOnceAndTakeOverStale(t *testing.T) {
+	db := newTestDB(t)
+	r := NewImports(db)
+
+	job := &domain.ImportJob{SellerID: 1, Format: domain.ImportCSV, Status: domain.ImportPending, Payload: []byte("sku,name,price\n")}
+	if err := r.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	claimed, err := r.Claim(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.ID != job.ID || claimed.Status != domain.ImportRunning || string(claimed.Payload) != "sku,name,price\n" {
+		t.Fatalf("claimed = %+v", claimed)
+	}
+	// задача уже в работе и не зависла
+	if _, err := r.Claim(time.Now().Add(-time.Minute)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("second Claim() error = %v, want not found", err)
+	}
+	// а зависшую забирают снова
+	if _, err := r.Claim(time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Claim() of a stale job error = %v", err)
+	}
+
+	claimed.Status = domain.ImportDone
+	now := time.Now()
+	claimed.FinishedAt = &now
+	if err := r.SaveProgress(claimed); err != nil {
+		t.Fatalf("SaveProgress() error = %v", err)
+	}
+	var stored domain.ImportJob
+	db.First(&stored, job.ID)
+	if stored.Payload != nil {
+		t.Errorf("payload kept after the job finished: %q", stored.Payload)
+	}
+	if _, err := r.Claim(time.Now().Add(time.Hour)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Claim() of a finished job error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Inventory moves stock between products and reservations. Every change is
+// a conditional UPDATE, so concurrent checkouts cannot oversell and a
+// reservation is committed or released at most once.
+type Inventory struct {
+	db *gorm.DB
+}
+
+func NewInventory(db *gorm.DB) *Inventory {
+	return &Inventory{db: db}
+}
+
+// Reserve takes the quantities of rows out of stock and records them under
+// ref, all or nothing. Calling it again with a ref that already exists
+// changes nothing and returns the stored rows, so retries are safe.
+func (r *Inventory) Reserve(ref string, rows []domain.Reservation, expiresAt time.Time) ([]domain.Reservation, error) {
+	var out []domain.Reservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := byReference(tx, ref)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			out = existing
+			return nil
+		}
+
+		for i := range rows {
+			res := tx.Model(&domain.Product{}).
+				Where("id = ? AND stock >= ?", rows[i].ProductID, rows[i].Quantity).
+				Updates(map[string]any{
+					"stock":      gorm.Expr("stock - ?", rows[i].Quantity),
+					"version":    gorm.Expr("version + 1"),
+					"updated_at": time.Now(),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return missingOrShort(tx, rows[i].ProductID)
+			}
+
+			rows[i].Reference = ref
+			rows[i].Status = domain.ReservationActive
+			rows[i].ExpiresAt = expiresAt
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+		out = rows
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Inventory) Get(ref string) ([]domain.Reservation, error) {
+	rows, err := byReference(r.db, ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return rows, nil
+}
+
+// Commit marks the active rows of ref as sold unless they expired before
+// now. It returns how many rows changed; zero means the reservation was
+// already committed, released, expired or never existed.
+func (r *Inventory) Commit(ref string, now time.Time) (int64, error) {
+	res := r.db.Model(&domain.Reservation{}).
+		Where("reference = ? AND status = ? AND expires_at >= ?", ref, domain.ReservationActive, now).
+		Updates(map[string]any{"status": domain.ReservationCommitted, "updated_at": time.Now()})
+	return res.RowsAffected, res.Error
+}
+
+// Release returns the active rows of ref to stock and reports how many rows
+// were released.
+func (r *Inventory) Release(ref string) (int64, error) {
+	rows, err := byReference(r.db, ref)
+	if err != nil {
+		return 0, err
+	}
+	return r.release(rows)
+}
+
+// ReleaseExpired releases every active row that expired before now.
+func (r *Inventory) ReleaseExpired(now time.Time) (int64, error) {
+	var rows []domain.Reservation
+	err := r.db.Where("status = ? AND expires_at < ?", domain.ReservationActive, now).
+		Order("id").Find(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+	return r.release(rows)
+}
+
+func (r *Inventory) release(rows []domain.Reservation) (int64, error) {
+	var released int64
+	for _, row := range rows {
+		if row.Status != domain.ReservationActive {
+			continue
+		}
+		won := false
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			// Flipping the status first means only one caller wins the row
+			// and gives the units back.
+			res := tx.Model(&domain.Reservation{}).
+				Where("id = ? AND status = ?", row.ID, domain.ReservationActive).
+				Updates(map[string]any{"status": domain.ReservationReleased, "updated_at": time.Now()})
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			won = true
+			// Unscoped: units of a soft-deleted product still go back, so
+			// it is whole again if restored.
+			return tx.Unscoped().Model(&domain.Product{}).Where("id = ?", row.ProductID).
+				Updates(map[string]any{
+					"stock":      gorm.Expr("stock + ?", row.Quantity),
+					"version":    gorm.Expr("version + 1"),
+					"updated_at": time.Now(),
+				}).Error
+		})
+		if err != nil {
+			return released, err
+		}
+		if won {
+			released++
+		}
+	}
+	return released, nil
+}
+
+func byReference(db *gorm.DB, ref string) ([]domain.Reservation, error) {
+	var rows []domain.Reservation
+	if err := db.Where("reference = ?", ref).Order("product_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
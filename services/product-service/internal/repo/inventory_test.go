@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+)
+
+func stockOf(t *testing.T, r *Products, id uint) int {
+	t.Helper()
+	p, err := r.GetByID(id)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	return p.Stock
+}
+
+func TestInventory_ReserveIsAllOrNothing(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProducts(db)
+	inv := NewInventory(db)
+
+	a := &domain.Product{UserID: 1, Name: "A", Price: 1, Stock: 5}
+	b := &domain.Product{UserID: 1, Name: "B", Price: 1, Stock: 1}
+	_ = products.Create(a)
+	_ = products.Create(b)
+
+	_, err := inv.Reserve("r1", []domain.Reservation{
+		{ProductID: a.ID, Quantity: 2},
+		{ProductID: b.ID, Quantity: 2},
+	}, time.Now().Add(time.Hour))
+	if !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("Reserve() error = %v, want ErrInsufficientStock", err)
+	}
+	if got := stockOf(t, products, a.ID); got != 5 {
+		t.Errorf("stock of A after failed reserve = %d, want 5", got)
+	}
+
+	rows, err := inv.Reserve("r2", []domain.Reservation{
+		{ProductID: a.ID, Quantity: 2},
+		{ProductID: b.ID, Quantity: 1},
+	}, time.Now().Add(time.Hour))
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("Reserve() = %v, %v", rows, err)
+	}
+	if stockOf(t, products, a.ID) != 3 || stockOf(t, products, b.ID) != 0 {
+		t.Errorf("stock not reserved")
+	}
+
+	// Повтор с тем же reference ничего не списывает повторно.
+	if _, err := inv.Reserve("r2", []domain.Reservation{{ProductID: a.ID, Quantity: 2}}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("repeat Reserve() error = %v", err)
+	}
+	if got := stockOf(t, products, a.ID); got != 3 {
+		t.Errorf("stock after repeated reserve = %d, want 3", got)
+	}
+}
+
+func TestInventory_CommitAndReleaseOnce(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProducts(db)
+	inv := NewInventory(db)
+
+	p := &domain.Product{UserID: 1, Name: "A", Price: 1, Stock: 5}
+	_ = products.Create(p)
+
+	now := time.Now()
+	_, _ = inv.Reserve("paid", []domain.Reservation{{ProductID: p.ID, Quantity: 2}}, now.Add(time.Hour))
+	_, _ = inv.Reserve("cancelled", []domain.Reservation{{ProductID: p.ID, Quantity: 1}}, now.Add(time.Hour))
+
+	if n, err := inv.Commit("paid", now); err != nil || n != 1 {
+		t.Fatalf("Commit() = %d, %v; want 1", n, err)
+	}
+	if n, _ := inv.Release("paid"); n != 0 {
+		t.Errorf("Release() of committed reservation released %d rows", n)
+	}
+
+	if n, err := inv.Release("cancelled"); err != nil || n != 1 {
+		t.Fatalf("Release() = %d, %v; want 1", n, err)
+	}
+	if n, _ := inv.Release("cancelled"); n != 0 {
+		t.Errorf("second Release() released %d rows", n)
+	}
+
+	if got := stockOf(t, products, p.ID); got != 3 {
+		t.Errorf("stock = %d, want 3", got)
+	}
+}
+
+func TestInventory_Expiry(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProducts(db)
+	inv := NewInventory(db)
+
+	p := &domain.Product{UserID: 1, Name: "A", Price: 1, Stock: 5}
+	_ = products.Create(p)
+
+	now := time.Now()
+	_, _ = inv.Reserve("old", []domain.Reservation{{ProductID: p.ID, Quantity: 2}}, now.Add(-time.Minute))
+	_, _ = inv.Reserve("fresh", []domain.Reservation{{ProductID: p.ID, Quantity: 1}}, now.Add(time.Hour))
+
+	if n, _ := inv.Commit("old", now); n != 0 {
+		t.Errorf("Commit() of expired reservation changed %d rows", n)
+	}
+
+	n, err := inv.ReleaseExpired(now)
+	if err != nil || n != 1 {
+		t.Fatalf("ReleaseExpired() = %d, %v; want 1", n, err)
+	}
+	if got := stockOf(t, products, p.ID); got != 4 {
+		t.Errorf("stock = %d, want 4", got)
+	}
+}
+
+func TestInventory_ReleaseToDeletedProduct(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProducts(db)
+	r := NewInventory(db)
+
+	p := &domain.Product{UserID: 1, Name: "A", Price: 1, Stock: 3}
+	_ = products.Create(p)
+	if _, err := r.Reserve("ref", []domain.Reservation{{ProductID: p.ID, Quantity: 2}}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	_ = products.Delete(p)
+
+	if n, err := r.Release("ref"); err != nil || n != 1 {
+		t.Fatalf("Release() = %d, %v", n, err)
+	}
+	// после восстановления товар снова целый
+	_ = products.Restore(p.ID, time.Now().Add(-time.Hour))
+	if got := stockOf(t, products, p.ID); got != 3 {
+		t.Errorf("stock = %d, want 3", got)
+	}
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"GoProduct/internal/domain"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,30 +15,363 @@ func NewProducts(db *gorm.DB) *Products {
 	return &Products{db: db}
 }
 
+// Create stores p. It returns domain.ErrDuplicateSKU if the seller already
+// has a product, deleted or not, with p's SKU.
 func (r *Products) Create(p *domain.Product) error {
-	return r.db.Create(p).Error
+	if p.Version == 0 {
+		p.Version = 1
+	}
+	if p.SKU == nil {
+		return r.db.Create(p).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Unscoped().Model(&domain.Product{}).
+			Where("user_id = ? AND sku = ?", p.UserID, *p.SKU).
+			Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return domain.ErrDuplicateSKU
+		}
+		return tx.Create(p).Error
+	})
+}
+
+// GetBySKU returns the seller's product with that SKU, including a
+// soft-deleted one, which still holds the SKU.
+func (r *Products) GetBySKU(sellerID uint, sku string) (*domain.Product, error) {
+	var p domain.Product
+	if err := r.db.Unscoped().Where("user_id = ? AND sku = ?", sellerID, sku).First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
 func (r *Products) GetByID(id uint) (*domain.Product, error) {
 	var p domain.Product
-	if err := r.db.First(&p, id).Error; err != nil {
+	if err := r.db.Scopes(withDetails).First(&p, id).Error; err != nil {
 		return nil, err
 	}
 	return &p, nil
 }
 
-func (r *Products) List() ([]domain.Product, error) {
+// ProductFilter narrows List. A nil InStock matches every product.
+//
+// SKU and VariantAttrs match products that have a variant with that SKU
+// and all of those attributes. When either is set, InStock applies to the
+// matching variant rather than to the product, so "size M in stock" means
+// a size M variant is in stock.
+//
+// Attrs must all match the product's own attributes.
+type ProductFilter struct {
+	InStock      *bool
+	SKU          string
+	VariantAttrs map[string]string
+	Category     string
+	Attrs        []AttrFilter
+	// Status, when set, keeps only products in that status.
+	Status domain.ProductStatus
+	// SellerID, when set, keeps only that seller's products.
+	SellerID uint
+}
+
+func (f ProductFilter) byVariant() bool {
+	return f.SKU != "" || len(f.VariantAttrs) > 0
+}
+
+func (r *Products) List(f ProductFilter) ([]domain.Product, error) {
 	var products []domain.Product
-	if err := r.db.Find(&products).Error; err != nil {
+	if err := r.filtered(f).Scopes(withDetails).Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-func (r *Products) Update(p *domain.Product) error {
-	return r.db.Save(p).Error
+// Count returns how many products match f.
+func (r *Products) Count(f ProductFilter) (int64, error) {
+	var n int64
+	err := r.filtered(f).Count(&n).Error
+	return n, err
+}
+
+// Each calls fn with the products matching f in batches of up to size,
+// in id order, with their images. Batches are read with a keyset cursor
+// on id, so only one batch is held in memory. Rows inserted meanwhile get
+// higher ids and are picked up ahead of the cursor; rows behind it are not
+// read again, so later changes to them are missed. An error from fn stops
+// the walk and is returned.
+func (r *Products) Each(f ProductFilter, size int, fn func([]domain.Product) error) error {
+	var last uint
+	for {
+		var batch []domain.Product
+		if err := r.filtered(f).Preload("Images", imageOrder).
+			Where("id > ?", last).Order("id").Limit(size).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < size {
+			return nil
+		}
+		last = batch[len(batch)-1].ID
+	}
+}
+
+// filtered selects the products matching f.
+func (r *Products) filtered(f ProductFilter) *gorm.DB {
+	q := r.db.Model(&domain.Product{})
+	if f.byVariant() {
+		q = q.Where("id IN (?)", r.matchingVariants(f))
+	} else if f.InStock != nil {
+		if *f.InStock {
+			q = q.Where("stock > 0")
+		} else {
+			q = q.Where("stock <= 0")
+		}
+	}
+	if f.Category != "" {
+		q = q.Where("category = ?", f.Category)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.SellerID != 0 {
+		q = q.Where("user_id = ?", f.SellerID)
+	}
+	for _, a := range f.Attrs {
+		q = whereAttr(q, a)
+	}
+	return q
+}
+
+// matchingVariants selects the product ids of variants matching f.
+func (r *Products) matchingVariants(f ProductFilter) *gorm.DB {
+	sub := r.db.Model(&domain.ProductVariant{}).Select("product_id")
+	if f.SKU != "" {
+		sub = sub.Where("sku = ?", f.SKU)
+	}
+	for name, value := range f.VariantAttrs {
+		sub = sub.Where("EXISTS (SELECT 1 FROM variant_attributes a WHERE a.variant_id = product_variants.id AND a.name = ? AND a.value = ?)", name, value)
+	}
+	if f.InStock != nil {
+		if *f.InStock {
+			sub = sub.Where("stock > 0")
+		} else {
+			sub = sub.Where("stock <= 0")
+		}
+	}
+	return sub
+}
+
+// withDetails loads what a product response shows besides the row itself.
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Images", imageOrder).
+		Preload("Variants", variantOrder).
+		Preload("Variants.Attributes", attributeOrder)
+}
+
+// editableColumns are the product columns Update may write.
+var editableColumns = []string{"name", "description", "price", "category", "attributes"}
+
+// Update saves the given columns of p, all editable columns when none are
+// given, if the row is still at p.Version and bumps the version; otherwise
+// it returns gorm.ErrRecordNotFound. The stock only changes through
+// AdjustStock and reservations so concurrent checkouts are not overwritten,
+// and the status only through SetStatus. Images and variants have their own
+// repos and are left alone.
+func (r *Products) Update(p *domain.Product, columns ...string) error {
+	if len(columns) == 0 {
+		columns = editableColumns
+	}
+	columns = append(columns[:len(columns):len(columns)], "version", "updated_at")
+	from := p.Version
+	p.Version++
+	p.UpdatedAt = time.Now()
+	res := r.db.Model(p).
+		Where("version = ?", from).
+		Select(columns).
+		Updates(p)
+	if res.Error != nil {
+		p.Version = from
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		p.Version = from
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetStatus stores p's status, rejection reason and seller email if the
+// product is still in status from. Otherwise it returns
+// gorm.ErrRecordNotFound.
+func (r *Products) SetStatus(p *domain.Product, from domain.ProductStatus) error {
+	p.UpdatedAt = time.Now()
+	res := r.db.Model(&domain.Product{}).
+		Where("id = ? AND status = ?", p.ID, from).
+		Updates(map[string]any{
+			"status":           p.Status,
+			"rejection_reason": p.RejectionReason,
+			"seller_email":     p.SellerEmail,
+			"version":          gorm.Expr("version + 1"),
+			"updated_at":       p.UpdatedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	p.Version++
+	return nil
 }
 
-func (r *Products) Delete(id uint) error {
-	return r.db.Delete(&domain.Product{}, id).Error
+// ListForModeration returns products pending review, longest waiting first.
+func (r *Products) ListForModeration(limit int) ([]domain.Product, error) {
+	var products []domain.Product
+	if err := r.db.Scopes(withDetails).
+		Where("status = ?", domain.StatusPendingReview).
+		Order("updated_at, id").Limit(limit).
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// AdjustStock adds delta (possibly negative) to a product's stock. It
+// returns domain.ErrInsufficientStock instead of going below zero.
+func (r *Products) AdjustStock(id uint, delta int) error {
+	res := r.db.Model(&domain.Product{}).
+		Where("id = ? AND stock + ? >= 0", id, delta).
+		Updates(map[string]any{
+			"stock":      gorm.Expr("stock + ?", delta),
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return missingOrShort(r.db, id)
+	}
+	return nil
+}
+
+// bumpVersion bumps the version of a product whose images or variants changed,
+// so its ETag no longer matches. Unscoped, like stock, so a soft-deleted
+// product comes back with a fresh one.
+func bumpVersion(tx *gorm.DB, productID uint) error {
+	return tx.Unscoped().Model(&domain.Product{}).Where("id = ?", productID).
+		Updates(map[string]any{"version": gorm.Expr("version + 1"), "updated_at": time.Now()}).Error
+}
+
+// missingOrShort explains why a conditional stock update matched no rows.
+func missingOrShort(db *gorm.DB, id uint) error {
+	var n int64
+	if err := db.Model(&domain.Product{}).Where("id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return domain.ErrInsufficientStock
+}
+
+// Delete soft-deletes p if the row is still at p.Version; otherwise it
+// returns gorm.ErrRecordNotFound. The row stays, with deleted_at set, until
+// Purge removes it.
+func (r *Products) Delete(p *domain.Product) error {
+	res := r.db.Where("version = ?", p.Version).Delete(&domain.Product{}, p.ID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetDeleted returns a soft-deleted product.
+func (r *Products) GetDeleted(id uint) (*domain.Product, error) {
+	var p domain.Product
+	if err := r.db.Unscoped().Scopes(withDetails).
+		Where("deleted_at IS NOT NULL").
+		First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListDeleted returns soft-deleted products, most recently deleted first.
+func (r *Products) ListDeleted(limit int) ([]domain.Product, error) {
+	var products []domain.Product
+	if err := r.db.Unscoped().Scopes(withDetails).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id").Limit(limit).
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// Restore undeletes a product deleted at or after deletedAfter. Otherwise
+// it returns gorm.ErrRecordNotFound.
+func (r *Products) Restore(id uint, deletedAfter time.Time) error {
+	res := r.db.Unscoped().Model(&domain.Product{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND deleted_at >= ?", id, deletedAfter).
+		Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1"), "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge permanently removes up to limit products soft-deleted before
+// before and returns them with their images, whose files the caller still
+// has to delete. Images, variants and reservations go with the row.
+func (r *Products) Purge(before time.Time, limit int) ([]domain.Product, error) {
+	var products []domain.Product
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Preload("Images").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("deleted_at, id").Limit(limit).
+			Find(&products).Error; err != nil {
+			return err
+		}
+		if len(products) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(products))
+		for _, p := range products {
+			ids = append(ids, p.ID)
+		}
+		return tx.Unscoped().Delete(&domain.Product{}, ids).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetByIDs returns the products that still exist among ids, in no
+// particular order.
+func (r *Products) GetByIDs(ids []uint) ([]domain.Product, error) {
+	var products []domain.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
 }
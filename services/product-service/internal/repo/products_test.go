@@ -1,26 +1,14 @@
 package repo
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"GoProduct/internal/domain"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-func newTestDB(t *testing.T) *gorm.DB {
-	t.Helper()
-
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	if err := db.AutoMigrate(&domain.Product{}); err != nil {
-		t.Fatalf("auto-migrate: %v", err)
-	}
-	return db
-}
-
 func TestProducts_CreateAndGetByID(t *testing.T) {
 	db := newTestDB(t)
 	// без префикса repo, мы уже в этом пакете
@@ -55,7 +43,7 @@ func TestProducts_List(t *testing.T) {
 	_ = r.Create(&domain.Product{UserID: 1, Name: "A", Price: 1})
 	_ = r.Create(&domain.Product{UserID: 1, Name: "B", Price: 2})
 
-	list, err := r.List()
+	list, err := r.List(ProductFilter{})
 	if err != nil {
 		t.Fatalf("List() error = %v", err)
 	}
@@ -88,7 +76,7 @@ func TestProducts_UpdateAndDelete(t *testing.T) {
 		t.Errorf("Update() did not persist changes: got %+v", got)
 	}
 
-	if err := r.Delete(p.ID); err != nil {
+	if err := r.Delete(p); err != nil {
 		t.Fatalf("Delete() error = %v", err)
 	}
 
@@ -96,3 +84,200 @@ func TestProducts_UpdateAndDelete(t *testing.T) {
 		t.Fatalf("expected error after Delete, got nil")
 	}
 }
+
+func TestProducts_SoftDeleteRestoreAndPurge(t *testing.T) {
+	db := newTestDB(t)
+	r := NewProducts(db)
+	images := NewImages(db)
+
+	kept := &domain.Product{UserID: 1, Name: "Kept", Price: 1}
+	old := &domain.Product{UserID: 1, Name: "Old", Price: 1}
+	_ = r.Create(kept)
+	_ = r.Create(old)
+	_ = images.Create(&domain.ProductImage{ProductID: old.ID, Key: "k", ContentType: "image/png"})
+
+	_ = r.Delete(kept)
+	_ = r.Delete(old)
+	// старый товар удалён давно
+	longAgo := time.Now().Add(-48 * time.Hour)
+	db.Unscoped().Model(&domain.Product{}).Where("id = ?", old.ID).Update("deleted_at", longAgo)
+
+	if list, _ := r.List(ProductFilter{}); len(list) != 0 {
+		t.Fatalf("List() shows deleted products: %+v", list)
+	}
+	deleted, err := r.ListDeleted(10)
+	if err != nil || len(deleted) != 2 || deleted[0].ID != kept.ID {
+		t.Fatalf("ListDeleted() = %+v, %v; want newest first", deleted, err)
+	}
+
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	if err := r.Restore(old.ID, dayAgo); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Restore() past the grace period error = %v", err)
+	}
+
+	purged, err := r.Purge(dayAgo, 10)
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if len(purged) != 1 || purged[0].ID != old.ID || len(purged[0].Images) != 1 {
+		t.Fatalf("Purge() = %+v, want the old product with its image", purged)
+	}
+	if _, err := r.GetDeleted(old.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("purged product still there: %v", err)
+	}
+
+	if err := r.Restore(kept.ID, dayAgo); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, err := r.GetByID(kept.ID); err != nil {
+		t.Errorf("restored product not found: %v", err)
+	}
+	if err := r.Restore(kept.ID, dayAgo); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("second Restore() error = %v", err)
+	}
+}
+
+func TestProducts_StockFilterAndAdjust(t *testing.T) {
+	db := newTestDB(t)
+	r := NewProducts(db)
+
+	p := &domain.Product{UserID: 1, Name: "A", Price: 1, Stock: 2}
+	_ = r.Create(p)
+	_ = r.Create(&domain.Product{UserID: 1, Name: "B", Price: 2})
+
+	inStock := true
+	list, err := r.List(ProductFilter{InStock: &inStock})
+	if err != nil || len(list) != 1 || list[0].ID != p.ID {
+		t.Fatalf("List(in stock) = %+v, %v; want only A", list, err)
+	}
+
+	if err := r.AdjustStock(p.ID, -3); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("AdjustStock(-3) error = %v, want ErrInsufficientStock", err)
+	}
+	if err := r.AdjustStock(999, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("AdjustStock(missing) error = %v, want ErrRecordNotFound", err)
+	}
+	if err := r.AdjustStock(p.ID, 5); err != nil {
+		t.Fatalf("AdjustStock(5) error = %v", err)
+	}
+
+	// AdjustStock поднял версию, старая копия уже не сохранится
+	p.Name = "A2"
+	p.Stock = 0
+	if err := r.Update(p); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Update() with a stale version error = %v, want ErrRecordNotFound", err)
+	}
+
+	// Update не должен перетирать остаток, изменённый параллельно.
+	fresh, _ := r.GetByID(p.ID)
+	p.Version = fresh.Version
+	if err := r.Update(p); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ := r.GetByID(p.ID)
+	if got.Name != "A2" || got.Stock != 7 {
+		t.Errorf("after Update: name %q stock %d, want A2 and 7", got.Name, got.Stock)
+	}
+}
+
+func TestProducts_EachWalksInBatches(t *testing.T) {
+	db := newTestDB(t)
+	r := NewProducts(db)
+
+	for i := 0; i < 5; i++ {
+		_ = r.Create(&domain.Product{UserID: 1, Name: "P", Price: 1, Stock: i % 2, Status: domain.StatusPublished})
+	}
+	_ = r.Create(&domain.Product{UserID: 1, Name: "Draft", Price: 1, Stock: 1})
+
+	var sizes []int
+	var ids []uint
+	err := r.Each(ProductFilter{Status: domain.StatusPublished}, 2, func(batch []domain.Product) error {
+		sizes = append(sizes, len(batch))
+		for _, p := range batch {
+			ids = append(ids, p.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each() error = %v", err)
+	}
+	if len(sizes) != 3 || sizes[2] != 1 || len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+		t.Errorf("batches = %v, ids = %v", sizes, ids)
+	}
+
+	inStock := true
+	stop := errors.New("stop")
+	calls := 0
+	err = r.Each(ProductFilter{InStock: &inStock}, 1, func([]domain.Product) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Each() with a failing fn = %v after %d calls", err, calls)
+	}
+}
+
+func TestProducts_VersionFollowsEveryChange(t *testing.T) {
+	db := newTestDB(t)
+	products := NewProducts(db)
+	images := NewImages(db)
+	variants := NewVariants(db)
+	inv := NewInventory(db)
+
+	p := &domain.Product{UserID: 1, Name: "Phone", Price: 100, Stock: 3}
+	if err := products.Create(p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// каждая запись, меняющая ответ GET, должна менять и ETag
+	last := p.Version
+	bumped := func(what string) {
+		t.Helper()
+		got, err := products.GetByID(p.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.Version <= last {
+			t.Errorf("%s: version = %d, want above %d", what, got.Version, last)
+		}
+		last = got.Version
+	}
+
+	if _, err := inv.Reserve("r1", []domain.Reservation{{ProductID: p.ID, Quantity: 1}}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	bumped("reserve")
+	if _, err := inv.Release("r1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	bumped("release")
+
+	img := &domain.ProductImage{ProductID: p.ID, Key: "k", ContentType: "image/png"}
+	if err := images.Create(img); err != nil {
+		t.Fatalf("image Create() error = %v", err)
+	}
+	bumped("image upload")
+	if err := images.Reorder(p.ID, []uint{img.ID}); err != nil {
+		t.Fatalf("Reorder() error = %v", err)
+	}
+	bumped("image reorder")
+	if err := images.Delete(p.ID, img.ID); err != nil {
+		t.Fatalf("image Delete() error = %v", err)
+	}
+	bumped("image delete")
+
+	v := &domain.ProductVariant{ProductID: p.ID, SellerID: 1, SKU: "PH-1"}
+	if err := variants.Create(v); err != nil {
+		t.Fatalf("variant Create() error = %v", err)
+	}
+	bumped("variant create")
+	v.Stock = 5
+	if err := variants.Update(v); err != nil {
+		t.Fatalf("variant Update() error = %v", err)
+	}
+	bumped("variant update")
+	if err := variants.Delete(p.ID, v.ID); err != nil {
+		t.Fatalf("variant Delete() error = %v", err)
+	}
+	bumped("variant delete")
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Ratings struct {
+	db *gorm.DB
+}
+
+func NewRatings(db *gorm.DB) *Ratings {
+	return &Ratings{db: db}
+}
+
+// Save stores a buyer's rating of a seller, replacing their previous one.
+func (r *Ratings) Save(rating *domain.SellerRating) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "seller_id"}, {Name: "buyer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"score", "updated_at"}),
+	}).Create(rating).Error
+}
+
+// Summary returns the average score of a seller and how many buyers rated
+// them. A seller nobody rated has zero for both.
+func (r *Ratings) Summary(sellerID uint) (float64, int64, error) {
+	var row struct {
+		Average float64
+		Count   int64
+	}
+	err := r.db.Model(&domain.SellerRating{}).
+		Select("COALESCE(AVG(score), 0) AS average, COUNT(*) AS count").
+		Where("seller_id = ?", sellerID).
+		Scan(&row).Error
+	return row.Average, row.Count, err
+}
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// InTx runs fn with a Products bound to one transaction, so a product
+// change and its revision are stored together or not at all.
+func (r *Products) InTx(fn func(tx *Products) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Products{db: tx})
+	})
+}
+
+// AddRevision stores a revision. Revisions are never updated or deleted,
+// only purged together with their product.
+func (r *Products) AddRevision(rev *domain.ProductRevision) error {
+	return r.db.Create(rev).Error
+}
+
+// ListRevisions returns a product's revisions, oldest first.
+func (r *Products) ListRevisions(productID uint) ([]domain.ProductRevision, error) {
+	var revs []domain.ProductRevision
+	if err := r.db.Where("product_id = ?", productID).Order("id").Find(&revs).Error; err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+func (r *Products) GetRevision(productID, id uint) (*domain.ProductRevision, error) {
+	var rev domain.ProductRevision
+	if err := r.db.Where("product_id = ?", productID).First(&rev, id).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// GetWithDeleted returns a product whether or not it is soft-deleted.
+func (r *Products) GetWithDeleted(id uint) (*domain.Product, error) {
+	var p domain.Product
+	if err := r.db.Unscoped().First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// LastRevision returns the product's most recent revision.
+func (r *Products) LastRevision(productID uint) (*domain.ProductRevision, error) {
+	var rev domain.ProductRevision
+	if err := r.db.Where("product_id = ?", productID).Order("id DESC").First(&rev).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"GoProduct/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Variants struct {
+	db *gorm.DB
+}
+
+func NewVariants(db *gorm.DB) *Variants {
+	return &Variants{db: db}
+}
+
+// Create stores v with its attributes. It returns domain.ErrDuplicateSKU if
+// the seller already has a variant with that SKU.
+func (r *Variants) Create(v *domain.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := skuFree(tx, v); err != nil {
+			return err
+		}
+		if err := tx.Create(v).Error; err != nil {
+			return err
+		}
+		return bumpVersion(tx, v.ProductID)
+	})
+}
+
+// Update saves v and replaces its attributes with v.Attributes.
+func (r *Variants) Update(v *domain.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := skuFree(tx, v); err != nil {
+			return err
+		}
+		v.UpdatedAt = time.Now()
+		if err := tx.Omit(clause.Associations).Save(v).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("variant_id = ?", v.ID).Delete(&domain.VariantAttribute{}).Error; err != nil {
+			return err
+		}
+		for i := range v.Attributes {
+			v.Attributes[i].ID = 0
+			v.Attributes[i].VariantID = v.ID
+		}
+		if len(v.Attributes) > 0 {
+			if err := tx.Create(&v.Attributes).Error; err != nil {
+				return err
+			}
+		}
+		return bumpVersion(tx, v.ProductID)
+	})
+}
+
+func (r *Variants) Get(productID, id uint) (*domain.ProductVariant, error) {
+	var v domain.ProductVariant
+	if err := r.db.Preload("Attributes", attributeOrder).
+		Where("product_id = ?", productID).First(&v, id).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *Variants) ListByProduct(productID uint) ([]domain.ProductVariant, error) {
+	var variants []domain.ProductVariant
+	if err := r.db.Preload("Attributes", attributeOrder).
+		Where("product_id = ?", productID).Scopes(variantOrder).Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (r *Variants) Delete(productID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("product_id = ?", productID).Delete(&domain.ProductVariant{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("variant_id = ?", id).Delete(&domain.VariantAttribute{}).Error; err != nil {
+			return err
+		}
+		return bumpVersion(tx, productID)
+	})
+}
+
+// skuFree checks the unique (seller, SKU) index up front so callers get a
+// domain error instead of a driver-specific one.
+func skuFree(tx *gorm.DB, v *domain.ProductVariant) error {
+	var n int64
+	if err := tx.Model(&domain.ProductVariant{}).
+		Where("seller_id = ? AND sku = ? AND id <> ?", v.SellerID, v.SKU, v.ID).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return domain.ErrDuplicateSKU
+	}
+	return nil
+}
+
+func variantOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+func attributeOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("name")
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// MaxBulkOperations caps the operations of one bulk request.
+const MaxBulkOperations = 500
+
+// TopicProductChanged announces each change a bulk request makes.
+const TopicProductChanged = "product.changed"
+
+// Bulk operation kinds.
+const (
+	BulkPrice  = "price"
+	BulkStatus = "status"
+	BulkDelete = "delete"
+)
+
+var (
+	errInvalidBulk = errors.New("invalid_bulk_operation")
+	// errRolledBack marks the operations of an atomic request undone
+	// because another one failed.
+	errRolledBack = errors.New("rolled_back")
+)
+
+func IsInvalidBulk(err error) bool { return errors.Is(err, errInvalidBulk) }
+func IsRolledBack(err error) bool  { return errors.Is(err, errRolledBack) }
+
+// BulkOperation changes one product. A price operation sets either Price
+// or, relative to the current price, Percent; a status operation moves
+// the product to Status like ChangeStatus. A non-zero Version must match
+// the product's.
+type BulkOperation struct {
+	ProductID uint
+	Op        string
+	Version   int
+	Price     *float64
+	Percent   *float64
+	Status    domain.ProductStatus
+}
+
+// BulkInput is a seller's list of operations on their own products. An
+// atomic request applies all of them or, if any fails, none; otherwise
+// each one stands on its own.
+type BulkInput struct {
+	UserID     uint
+	Email      string
+	Atomic     bool
+	Operations []BulkOperation
+}
+
+// BulkResult is the outcome of one operation, in request order. Version
+// is the product's version afterwards; Err is nil on success.
+type BulkResult struct {
+	ProductID uint
+	Op        string
+	Version   int
+	Err       error
+}
+
+// ProductChangedEvent is published on TopicProductChanged.
+type ProductChangedEvent struct {
+	ProductID uint        `json:"product_id"`
+	SellerID  uint        `json:"seller_id"`
+	Version   int         `json:"version"`
+	Action    string      `json:"action"`
+	Changes   domain.Diff `json:"changes"`
+}
+
+// Bulk applies a seller's operations and publishes one event per product
+// change once it is committed. The error is only for invalid requests and
+// failures of the database itself; what went wrong with single operations
+// is in their results. An atomic request that failed has every result
+// either failed or rolled back.
+func (s *ProductService) Bulk(ctx context.Context, in BulkInput) ([]BulkResult, error) {
+	if len(in.Operations) == 0 || len(in.Operations) > MaxBulkOperations {
+		return nil, fmt.Errorf("%w: between 1 and %d operations are allowed", errInvalidBulk, MaxBulkOperations)
+	}
+	for i, op := range in.Operations {
+		if err := checkBulkOperation(op); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	results := make([]BulkResult, len(in.Operations))
+	revs := make([]*domain.ProductRevision, len(in.Operations))
+	for i, op := range in.Operations {
+		results[i] = BulkResult{ProductID: op.ProductID, Op: op.Op}
+	}
+
+	if !in.Atomic {
+		for i, op := range in.Operations {
+			var rev *domain.ProductRevision
+			err := s.products.InTx(func(tx *repo.Products) error {
+				var err error
+				rev, err = s.applyBulk(tx, in, op)
+				return err
+			})
+			if err != nil && !isOperationError(err) {
+				// предыдущие операции уже закоммичены, их события не теряем
+				s.publishChanges(ctx, in.UserID, revs[:i])
+				return nil, err
+			}
+			results[i].Err = err
+			revs[i] = rev
+			if rev != nil {
+				results[i].Version = rev.Version
+			}
+		}
+		s.publishChanges(ctx, in.UserID, revs)
+		return results, nil
+	}
+
+	failed := -1
+	err := s.products.InTx(func(tx *repo.Products) error {
+		for i, op := range in.Operations {
+			rev, err := s.applyBulk(tx, in, op)
+			if err != nil {
+				failed = i
+				return err
+			}
+			revs[i] = rev
+			if rev != nil {
+				results[i].Version = rev.Version
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if failed < 0 || !isOperationError(err) {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Version = 0
+			results[i].Err = errRolledBack
+		}
+		results[failed].Err = err
+		return results, nil
+	}
+	s.publishChanges(ctx, in.UserID, revs)
+	return results, nil
+}
+
+func checkBulkOperation(op BulkOperation) error {
+	if op.ProductID == 0 {
+		return fmt.Errorf("%w: product_id is required", errInvalidBulk)
+	}
+	switch op.Op {
+	case BulkPrice:
+		if (op.Price == nil) == (op.Percent == nil) {
+			return fmt.Errorf("%w: price needs exactly one of price and percent", errInvalidBulk)
+		}
+		if op.Price != nil && *op.Price <= 0 {
+			return fmt.Errorf("%w: price must be greater than zero", errInvalidBulk)
+		}
+		if op.Percent != nil && *op.Percent <= -100 {
+			return fmt.Errorf("%w: percent must be greater than -100", errInvalidBulk)
+		}
+	case BulkStatus:
+		if err := checkSellerStatus(op.Status); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidBulk, err)
+		}
+	case BulkDelete:
+	default:
+		return fmt.Errorf("%w: unknown op %q", errInvalidBulk, op.Op)
+	}
+	return nil
+}
+
+// applyBulk applies one operation within tx and returns the revision it
+// recorded, or nil when it changed nothing.
+func (s *ProductService) applyBulk(tx *repo.Products, in BulkInput, op BulkOperation) (*domain.ProductRevision, error) {
+	p, err := tx.GetByID(op.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	if p.UserID != in.UserID {
+		return nil, errForbidden
+	}
+	if op.Version != 0 && op.Version != p.Version {
+		return nil, errVersionMismatch
+	}
+
+	switch op.Op {
+	case BulkPrice:
+		price := 0.0
+		if op.Price != nil {
+			price = *op.Price
+		} else {
+			price = math.Round(p.Price*(100+*op.Percent)) / 100
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("%w: price would drop to zero", errInvalidBulk)
+		}
+		if price == p.Price {
+			return nil, nil
+		}
+		before := p.Snapshot()
+		p.Price = price
+		if err := tx.Update(p, "price"); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errVersionMismatch
+			}
+			return nil, err
+		}
+		err = record(tx, in.UserID, domain.RevisionUpdated, &before, p, nil)
+	case BulkStatus:
+		if p.Status == op.Status {
+			return nil, nil
+		}
+		prepareSellerStatus(p, op.Status, in.Email)
+		err = setStatus(tx, in.UserID, p, op.Status)
+	case BulkDelete:
+		err = deleteProduct(tx, in.UserID, p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tx.LastRevision(p.ID)
+}
+
+// isOperationError tells the errors of a single operation from failures
+// of the whole request.
+func isOperationError(err error) bool {
+	for _, target := range []error{errNotFound, errForbidden, errVersionMismatch, errInvalidTransition, errInvalidBulk} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ProductService) publishChanges(ctx context.Context, sellerID uint, revs []*domain.ProductRevision) {
+	if s.publisher == nil {
+		return
+	}
+	for _, rev := range revs {
+		if rev == nil {
+			continue
+		}
+		event := ProductChangedEvent{
+			ProductID: rev.ProductID,
+			SellerID:  sellerID,
+			Version:   rev.Version,
+			Action:    rev.Action,
+			Changes:   rev.Changes,
+		}
+		if err := s.publisher.Send(ctx, TopicProductChanged, strconv.FormatUint(uint64(rev.ProductID), 10), event); err != nil {
+			log.Printf("product %d: publish %s: %v", rev.ProductID, TopicProductChanged, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+
+	"gorm.io/gorm"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestBulk_PerItemResults(t *testing.T) {
+	svc, pub := newTestModeration(t)
+	ctx := context.Background()
+
+	mug, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Mug", Price: 19.99})
+	lamp, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Lamp", Price: 40})
+	foreign, _ := svc.CreateProduct(service.CreateProductInput{UserID: 2, Name: "Chair", Price: 70})
+
+	results, err := svc.Bulk(ctx, service.BulkInput{UserID: 1, Operations: []service.BulkOperation{
+		{ProductID: mug.ID, Op: service.BulkPrice, Percent: ptr(10)},
+		{ProductID: lamp.ID, Op: service.BulkStatus, Status: domain.StatusPendingReview},
+		{ProductID: foreign.ID, Op: service.BulkDelete},
+		{ProductID: 999, Op: service.BulkDelete},
+		{ProductID: lamp.ID, Op: service.BulkPrice, Price: ptr(45), Version: lamp.Version},
+	}})
+	if err != nil {
+		t.Fatalf("Bulk() error = %v", err)
+	}
+
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("own products failed: %+v", results)
+	}
+	if !service.IsForbidden(results[2].Err) || !service.IsNotFound(results[3].Err) {
+		t.Errorf("foreign/missing results = %+v, %+v", results[2], results[3])
+	}
+	// версия уже сдвинута предыдущей операцией
+	if !service.IsVersionMismatch(results[4].Err) {
+		t.Errorf("stale version result = %+v", results[4])
+	}
+
+	got, _ := svc.GetProduct(mug.ID)
+	if got.Price != 21.99 || results[0].Version != got.Version {
+		t.Errorf("mug after +10%% = %v (v%d), result version %d", got.Price, got.Version, results[0].Version)
+	}
+	got, _ = svc.GetProduct(lamp.ID)
+	if got.Status != domain.StatusPendingReview || got.Price != 40 {
+		t.Errorf("lamp = %s, %v", got.Status, got.Price)
+	}
+	if _, err := svc.GetProduct(foreign.ID); err != nil {
+		t.Errorf("foreign product deleted: %v", err)
+	}
+
+	if len(pub.changed) != 2 {
+		t.Fatalf("events = %+v, want one per change", pub.changed)
+	}
+	if ev := pub.changed[0]; ev.ProductID != mug.ID || ev.SellerID != 1 || ev.Action != domain.RevisionUpdated || !hasChange(ev.Changes, "price") {
+		t.Errorf("price event = %+v", ev)
+	}
+	if ev := pub.changed[1]; ev.ProductID != lamp.ID || !hasChange(ev.Changes, "status") {
+		t.Errorf("status event = %+v", ev)
+	}
+}
+
+func TestBulk_PublishesCommittedBeforeFailure(t *testing.T) {
+	db := newTestDB(t)
+	pub := &fakePublisher{}
+	svc := service.NewProductService(repo.NewProducts(db), repo.NewCategories(db), pub)
+
+	mug, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Mug", Price: 20})
+	lamp, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Lamp", Price: 40})
+
+	// вторая запись в БД падает
+	updates := 0
+	_ = db.Callback().Update().Before("gorm:update").Register("test:fail", func(tx *gorm.DB) {
+		if updates++; updates == 2 {
+			_ = tx.AddError(errors.New("db down"))
+		}
+	})
+
+	_, err := svc.Bulk(context.Background(), service.BulkInput{UserID: 1, Operations: []service.BulkOperation{
+		{ProductID: mug.ID, Op: service.BulkPrice, Price: ptr(25)},
+		{ProductID: lamp.ID, Op: service.BulkPrice, Price: ptr(45)},
+	}})
+	if err == nil {
+		t.Fatal("Bulk() error = nil, want the db error")
+	}
+	if got, _ := svc.GetProduct(mug.ID); got.Price != 25 {
+		t.Fatalf("mug price = %v, want the first change committed", got.Price)
+	}
+	if len(pub.changed) != 1 || pub.changed[0].ProductID != mug.ID {
+		t.Errorf("events = %+v, want one for the committed change", pub.changed)
+	}
+}
+
+func TestBulk_AtomicRollsBack(t *testing.T) {
+	svc, pub := newTestModeration(t)
+	ctx := context.Background()
+
+	mug, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Mug", Price: 20})
+	lamp, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Lamp", Price: 40})
+
+	results, err := svc.Bulk(ctx, service.BulkInput{UserID: 1, Atomic: true, Operations: []service.BulkOperation{
+		{ProductID: mug.ID, Op: service.BulkPrice, Price: ptr(25)},
+		{ProductID: lamp.ID, Op: service.BulkDelete, Version: lamp.Version + 1},
+	}})
+	if err != nil {
+		t.Fatalf("Bulk() error = %v", err)
+	}
+	if !service.IsRolledBack(results[0].Err) || results[0].Version != 0 || !service.IsVersionMismatch(results[1].Err) {
+		t.Fatalf("results = %+v", results)
+	}
+	if got, _ := svc.GetProduct(mug.ID); got.Price != 20 {
+		t.Errorf("mug price = %v, want the change rolled back", got.Price)
+	}
+	if history, _ := svc.History(1, mug.ID); len(history) != 1 {
+		t.Errorf("history = %d revisions, want only the creation", len(history))
+	}
+	if len(pub.changed) != 0 {
+		t.Errorf("events for a rolled back request: %+v", pub.changed)
+	}
+
+	results, err = svc.Bulk(ctx, service.BulkInput{UserID: 1, Atomic: true, Operations: []service.BulkOperation{
+		{ProductID: mug.ID, Op: service.BulkPrice, Percent: ptr(-50)},
+		{ProductID: lamp.ID, Op: service.BulkDelete},
+	}})
+	if err != nil || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("Bulk() = %+v, %v", results, err)
+	}
+	if got, _ := svc.GetProduct(mug.ID); got.Price != 10 {
+		t.Errorf("mug price = %v, want 10", got.Price)
+	}
+	if _, err := svc.GetProduct(lamp.ID); !service.IsNotFound(err) {
+		t.Errorf("lamp not deleted: %v", err)
+	}
+	if len(pub.changed) != 2 || pub.changed[1].Action != domain.RevisionDeleted {
+		t.Errorf("events = %+v", pub.changed)
+	}
+}
+
+func TestBulk_RejectsInvalidRequests(t *testing.T) {
+	svc := newTestProductService(t)
+
+	cases := map[string][]service.BulkOperation{
+		"empty":          nil,
+		"unknown op":     {{ProductID: 1, Op: "rename"}},
+		"price and pct":  {{ProductID: 1, Op: service.BulkPrice, Price: ptr(5), Percent: ptr(5)}},
+		"minus 100%":     {{ProductID: 1, Op: service.BulkPrice, Percent: ptr(-100)}},
+		"self-publish":   {{ProductID: 1, Op: service.BulkStatus, Status: domain.StatusPublished}},
+		"missing target": {{Op: service.BulkDelete}},
+	}
+	for name, ops := range cases {
+		if _, err := svc.Bulk(context.Background(), service.BulkInput{UserID: 1, Operations: ops}); !service.IsInvalidBulk(err) {
+			t.Errorf("%s: err = %v, want invalid bulk", name, err)
+		}
+	}
+}
+
+func hasChange(d domain.Diff, field string) bool {
+	_, ok := d[field]
+	return ok
+}
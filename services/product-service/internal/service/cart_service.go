@@ -0,0 +1,388 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxItemQuantity caps the quantity of a single product in a cart.
+const MaxItemQuantity = 99
+
+var (
+	errCartItemNotFound = errors.New("cart_item_not_found")
+	errInvalidQuantity  = errors.New("invalid_quantity")
+)
+
+func IsCartItemNotFound(err error) bool { return errors.Is(err, errCartItemNotFound) }
+func IsInvalidQuantity(err error) bool  { return errors.Is(err, errInvalidQuantity) }
+
+// CartOwner identifies whose cart a request works with: the signed-in user,
+// a guest cart token, or both right after login.
+type CartOwner struct {
+	UserID uint
+	Token  string
+}
+
+type CartLine struct {
+	ProductID uint
+	SellerID  uint
+	Name      string
+	Quantity  int
+	// UnitPrice is the price when the item was added; CurrentPrice is the
+	// product's price now. Unavailable lines refer to deleted products.
+	UnitPrice    float64
+	CurrentPrice float64
+	Available    bool
+	PriceChanged bool
+	LineTotal    float64
+}
+
+type CartView struct {
+	// Token is set for guest carts only; guests send it back to keep
+	// working with the same cart.
+	Token     string
+	Items     []CartLine
+	ItemCount int
+	Subtotal  float64
+	UpdatedAt time.Time
+}
+
+type CartService struct {
+	carts    *repo.Carts
+	products *repo.Products
+}
+
+func NewCartService(carts *repo.Carts, products *repo.Products) *CartService {
+	return &CartService{carts: carts, products: products}
+}
+
+func (s *CartService) Get(owner CartOwner) (*CartView, error) {
+	cart, err := s.resolve(owner, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(cart)
+}
+
+// AddItem puts quantity units of a product into the cart, adding to what is
+// already there, and refreshes the price snapshot.
+func (s *CartService) AddItem(owner CartOwner, productID uint, quantity int) (*CartView, error) {
+	if quantity < 1 {
+		return nil, errInvalidQuantity
+	}
+
+	p, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.resolve(owner, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing := findItem(cart, productID); existing != nil {
+		quantity += existing.Quantity
+	}
+	if quantity > MaxItemQuantity {
+		return nil, errInvalidQuantity
+	}
+
+	if err := s.carts.SaveItems(cart.ID, snapshot(p, quantity)); err != nil {
+		return nil, err
+	}
+	return s.reload(cart)
+}
+
+// UpdateItem sets the quantity of a product already in the cart.
+func (s *CartService) UpdateItem(owner CartOwner, productID uint, quantity int) (*CartView, error) {
+	if quantity < 1 || quantity > MaxItemQuantity {
+		return nil, errInvalidQuantity
+	}
+
+	cart, err := s.resolve(owner, false)
+	if err != nil {
+		return nil, err
+	}
+	item := findItem(cart, productID)
+	if item == nil {
+		return nil, errCartItemNotFound
+	}
+
+	p, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.carts.SaveItems(cart.ID, snapshot(p, quantity)); err != nil {
+		return nil, err
+	}
+	return s.reload(cart)
+}
+
+func (s *CartService) RemoveItem(owner CartOwner, productID uint) (*CartView, error) {
+	cart, err := s.resolve(owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, errCartItemNotFound
+	}
+
+	if err := s.carts.DeleteItem(cart.ID, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errCartItemNotFound
+		}
+		return nil, err
+	}
+	return s.reload(cart)
+}
+
+func (s *CartService) Clear(owner CartOwner) (*CartView, error) {
+	cart, err := s.resolve(owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return s.view(nil)
+	}
+
+	if err := s.carts.ClearItems(cart.ID); err != nil {
+		return nil, err
+	}
+	return s.reload(cart)
+}
+
+// Cleanup removes guest carts idle for guestTTL and user carts idle for
+// userTTL.
+func (s *CartService) Cleanup(now time.Time, guestTTL, userTTL time.Duration) (int64, error) {
+	return s.carts.DeleteAbandoned(now.Add(-guestTTL), now.Add(-userTTL))
+}
+
+// RunCleanup calls Cleanup every interval until ctx is cancelled.
+func (s *CartService) RunCleanup(ctx context.Context, interval, guestTTL, userTTL time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Cleanup(time.Now(), guestTTL, userTTL)
+			if err != nil {
+				log.Printf("cart cleanup: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("cart cleanup: removed %d abandoned carts", n)
+			}
+		}
+	}
+}
+
+// resolve finds the cart for owner, creating one when create is set. A
+// signed-in user who still holds a guest token gets the guest cart merged
+// into their own, which is how guest carts survive login.
+func (s *CartService) resolve(owner CartOwner, create bool) (*domain.Cart, error) {
+	guest, err := s.guestCart(owner.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner.UserID == 0 {
+		if guest == nil && create {
+			return s.create(nil)
+		}
+		return guest, nil
+	}
+
+	cart, err := s.carts.GetByUser(owner.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		cart, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case guest != nil && cart == nil:
+		if err := s.carts.Claim(guest.ID, owner.UserID); err != nil {
+			return nil, err
+		}
+		return s.carts.GetByUser(owner.UserID)
+	case guest != nil:
+		if err := s.carts.Merge(guest.ID, cart.ID, mergeItems(cart.Items, guest.Items)); err != nil {
+			return nil, err
+		}
+		return s.carts.GetByUser(owner.UserID)
+	case cart == nil && create:
+		userID := owner.UserID
+		return s.create(&userID)
+	}
+	return cart, nil
+}
+
+func (s *CartService) guestCart(token string) (*domain.Cart, error) {
+	if token == "" {
+		return nil, nil
+	}
+	cart, err := s.carts.GetByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !cart.IsGuest() {
+		return nil, nil
+	}
+	return cart, nil
+}
+
+func (s *CartService) create(userID *uint) (*domain.Cart, error) {
+	cart := &domain.Cart{UserID: userID, Token: newCartToken()}
+	if err := s.carts.Create(cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (s *CartService) reload(cart *domain.Cart) (*CartView, error) {
+	fresh, err := s.carts.GetByToken(cart.Token)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(fresh)
+}
+
+// product returns a product that can be put in a cart, i.e. a published
+// one.
+func (s *CartService) product(id uint) (*domain.Product, error) {
+	p, err := s.products.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	if !p.Published() {
+		return nil, errNotFound
+	}
+	return p, nil
+}
+
+// view joins the cart with current product data. Lines whose product has
+// been deleted or is no longer published stay visible as unavailable and
+// are left out of the subtotal.
+func (s *CartService) view(cart *domain.Cart) (*CartView, error) {
+	v := &CartView{Items: []CartLine{}}
+	if cart == nil {
+		return v, nil
+	}
+	if cart.IsGuest() {
+		v.Token = cart.Token
+	}
+	v.UpdatedAt = cart.UpdatedAt
+
+	ids := make([]uint, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		ids = append(ids, it.ProductID)
+	}
+	products, err := s.products.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]domain.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	for _, it := range cart.Items {
+		line := CartLine{
+			ProductID: it.ProductID,
+			Name:      it.Name,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+		}
+		if p, ok := byID[it.ProductID]; ok && p.Published() {
+			line.Available = true
+			line.SellerID = p.UserID
+			line.Name = p.Name
+			line.CurrentPrice = p.Price
+			line.PriceChanged = p.Price != it.UnitPrice
+			line.LineTotal = p.Price * float64(it.Quantity)
+
+			v.ItemCount += it.Quantity
+			v.Subtotal += line.LineTotal
+		}
+		v.Items = append(v.Items, line)
+	}
+	return v, nil
+}
+
+// mergeItems combines a user's cart with a guest cart. Quantities of the
+// same product add up, capped at MaxItemQuantity, and the newer price
+// snapshot wins.
+func mergeItems(user, guest []domain.CartItem) []domain.CartItem {
+	byProduct := make(map[uint]domain.CartItem, len(user))
+	for _, it := range user {
+		byProduct[it.ProductID] = it
+	}
+
+	merged := make([]domain.CartItem, 0, len(guest))
+	for _, g := range guest {
+		out := domain.CartItem{
+			ProductID: g.ProductID,
+			Name:      g.Name,
+			UnitPrice: g.UnitPrice,
+			Quantity:  g.Quantity,
+			UpdatedAt: time.Now(),
+		}
+		if u, ok := byProduct[g.ProductID]; ok {
+			out.Quantity += u.Quantity
+			if u.UpdatedAt.After(g.UpdatedAt) {
+				out.Name, out.UnitPrice = u.Name, u.UnitPrice
+			}
+		}
+		out.Quantity = min(out.Quantity, MaxItemQuantity)
+		merged = append(merged, out)
+	}
+	return merged
+}
+
+func findItem(cart *domain.Cart, productID uint) *domain.CartItem {
+	if cart == nil {
+		return nil
+	}
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			return &cart.Items[i]
+		}
+	}
+	return nil
+}
+
+func snapshot(p *domain.Product, quantity int) domain.CartItem {
+	return domain.CartItem{
+		ProductID: p.ID,
+		Name:      p.Name,
+		UnitPrice: p.Price,
+		Quantity:  quantity,
+		UpdatedAt: time.Now(),
+	}
+}
+
+func newCartToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
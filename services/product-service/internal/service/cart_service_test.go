@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+)
+
+func newTestCartService(t *testing.T) (*service.CartService, *service.ProductService) {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	products := repo.NewProducts(db)
+	return service.NewCartService(repo.NewCarts(db), products), service.NewProductService(products, repo.NewCategories(db), nil)
+}
+
+func mustCreateProduct(t *testing.T, svc *service.ProductService, name string, price float64) *domain.Product {
+	t.Helper()
+
+	p, err := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: name, Price: price})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	mustPublish(t, svc, p)
+	return p
+}
+
+func TestCartService_GuestAddAndUpdate(t *testing.T) {
+	carts, products := newTestCartService(t)
+	p := mustCreateProduct(t, products, "Phone", 100)
+
+	v, err := carts.AddItem(service.CartOwner{}, p.ID, 2)
+	if err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	if v.Token == "" {
+		t.Fatalf("guest cart has no token")
+	}
+	guest := service.CartOwner{Token: v.Token}
+
+	v, err = carts.AddItem(guest, p.ID, 1)
+	if err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	if len(v.Items) != 1 || v.Items[0].Quantity != 3 || v.Subtotal != 300 {
+		t.Fatalf("cart = %+v, want 3 x Phone = 300", v)
+	}
+
+	if _, err := carts.AddItem(guest, p.ID, service.MaxItemQuantity); !service.IsInvalidQuantity(err) {
+		t.Errorf("AddItem() over the limit error = %v, want invalid quantity", err)
+	}
+	if _, err := carts.AddItem(guest, 999, 1); !service.IsNotFound(err) {
+		t.Errorf("AddItem() of missing product error = %v, want not found", err)
+	}
+
+	v, err = carts.UpdateItem(guest, p.ID, 1)
+	if err != nil {
+		t.Fatalf("UpdateItem() error = %v", err)
+	}
+	if v.ItemCount != 1 {
+		t.Errorf("ItemCount = %d, want 1", v.ItemCount)
+	}
+
+	if _, err := carts.RemoveItem(guest, p.ID); err != nil {
+		t.Fatalf("RemoveItem() error = %v", err)
+	}
+	if _, err := carts.UpdateItem(guest, p.ID, 1); !service.IsCartItemNotFound(err) {
+		t.Errorf("UpdateItem() of removed item error = %v, want not found", err)
+	}
+}
+
+func TestCartService_PriceChangesAndDeletedProducts(t *testing.T) {
+	carts, products := newTestCartService(t)
+	phone := mustCreateProduct(t, products, "Phone", 100)
+	phoneCase := mustCreateProduct(t, products, "Case", 10)
+
+	owner := service.CartOwner{UserID: 5}
+	_, _ = carts.AddItem(owner, phone.ID, 1)
+	_, _ = carts.AddItem(owner, phoneCase.ID, 2)
+
+	if _, err := products.UpdateProduct(service.UpdateProductInput{ID: phone.ID, ActorID: phone.UserID, Name: "Phone", Price: 80}); err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if err := products.DeleteProduct(phoneCase.UserID, phoneCase.ID, 0); err != nil {
+		t.Fatalf("DeleteProduct() error = %v", err)
+	}
+
+	v, err := carts.Get(owner)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if v.Token != "" {
+		t.Errorf("user cart exposes token %q", v.Token)
+	}
+	if len(v.Items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(v.Items))
+	}
+	phoneLine, caseLine := v.Items[0], v.Items[1]
+	if !phoneLine.PriceChanged || phoneLine.UnitPrice != 100 || phoneLine.CurrentPrice != 80 {
+		t.Errorf("phone line = %+v, want price change 100 -> 80", phoneLine)
+	}
+	if caseLine.Available {
+		t.Errorf("deleted product still available: %+v", caseLine)
+	}
+	if v.Subtotal != 80 || v.ItemCount != 1 {
+		t.Errorf("subtotal = %v, count = %d; want 80 and 1", v.Subtotal, v.ItemCount)
+	}
+}
+
+func TestCartService_GuestCartMergedOnLogin(t *testing.T) {
+	carts, products := newTestCartService(t)
+	phone := mustCreateProduct(t, products, "Phone", 100)
+	phoneCase := mustCreateProduct(t, products, "Case", 10)
+
+	user := service.CartOwner{UserID: 5}
+	_, _ = carts.AddItem(user, phone.ID, 1)
+
+	v, _ := carts.AddItem(service.CartOwner{}, phone.ID, 2)
+	token := v.Token
+	_, _ = carts.AddItem(service.CartOwner{Token: token}, phoneCase.ID, 1)
+
+	// First request after login still carries the guest token.
+	v, err := carts.Get(service.CartOwner{UserID: 5, Token: token})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(v.Items) != 2 || v.ItemCount != 4 {
+		t.Fatalf("merged cart = %+v, want 3 phones and 1 case", v.Items)
+	}
+
+	// The guest cart is gone after merging.
+	if v, _ := carts.Get(service.CartOwner{Token: token}); len(v.Items) != 0 {
+		t.Errorf("guest cart still has %d items", len(v.Items))
+	}
+}
+
+func TestCartService_GuestCartClaimedByNewUser(t *testing.T) {
+	carts, products := newTestCartService(t)
+	p := mustCreateProduct(t, products, "Phone", 100)
+
+	v, _ := carts.AddItem(service.CartOwner{}, p.ID, 1)
+
+	v, err := carts.Get(service.CartOwner{UserID: 9, Token: v.Token})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if v.Token != "" || len(v.Items) != 1 {
+		t.Errorf("claimed cart = %+v, want one item and no guest token", v)
+	}
+}
+
+func TestCartService_Cleanup(t *testing.T) {
+	carts, products := newTestCartService(t)
+	p := mustCreateProduct(t, products, "Phone", 100)
+
+	_, _ = carts.AddItem(service.CartOwner{}, p.ID, 1)
+	_, _ = carts.AddItem(service.CartOwner{UserID: 1}, p.ID, 1)
+
+	n, err := carts.Cleanup(time.Now().Add(48*time.Hour), 24*time.Hour, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Cleanup() removed %d carts, want only the guest cart", n)
+	}
+}
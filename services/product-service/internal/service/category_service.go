@@ -0,0 +1,156 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errCategoryNotFound  = errors.New("category_not_found")
+	errInvalidCategory   = errors.New("invalid_category")
+	errInvalidAttributes = errors.New("invalid_attributes")
+)
+
+func IsCategoryNotFound(err error) bool  { return errors.Is(err, errCategoryNotFound) }
+func IsInvalidCategory(err error) bool   { return errors.Is(err, errInvalidCategory) }
+func IsInvalidAttributes(err error) bool { return errors.Is(err, errInvalidAttributes) }
+
+// attributeName is what attribute names and category slugs look like. It
+// keeps names safe to splice into JSON paths.
+var attributeName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+type SaveCategoryInput struct {
+	Slug       string
+	Name       string
+	Attributes []domain.AttributeDef
+}
+
+type CategoryService struct {
+	categories *repo.Categories
+}
+
+func NewCategoryService(categories *repo.Categories) *CategoryService {
+	return &CategoryService{categories: categories}
+}
+
+// Save creates or replaces a category. Products already in the category
+// are not revalidated against the new schema.
+func (s *CategoryService) Save(in SaveCategoryInput) (*domain.Category, error) {
+	if !attributeName.MatchString(in.Slug) || in.Name == "" {
+		return nil, errInvalidCategory
+	}
+	seen := make(map[string]bool, len(in.Attributes))
+	for _, a := range in.Attributes {
+		if !attributeName.MatchString(a.Name) || seen[a.Name] {
+			return nil, fmt.Errorf("%w: bad or repeated attribute name %q", errInvalidCategory, a.Name)
+		}
+		seen[a.Name] = true
+		switch a.Type {
+		case domain.AttributeString:
+		case domain.AttributeNumber, domain.AttributeBool:
+			if len(a.Values) > 0 {
+				return nil, fmt.Errorf("%w: only string attributes take values, %s is %s", errInvalidCategory, a.Name, a.Type)
+			}
+		default:
+			return nil, fmt.Errorf("%w: attribute %s has unknown type %q", errInvalidCategory, a.Name, a.Type)
+		}
+	}
+
+	c := &domain.Category{Slug: in.Slug, Name: in.Name, Attributes: in.Attributes}
+	if c.Attributes == nil {
+		c.Attributes = []domain.AttributeDef{}
+	}
+	if err := s.categories.Save(c); err != nil {
+		return nil, err
+	}
+	return s.Get(in.Slug)
+}
+
+func (s *CategoryService) Get(slug string) (*domain.Category, error) {
+	c, err := s.categories.Get(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errCategoryNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *CategoryService) List() ([]domain.Category, error) {
+	return s.categories.List()
+}
+
+// checkAttributes validates attrs against the schema of category and
+// returns them normalised: numbers as float64, never nil.
+func checkAttributes(categories *repo.Categories, category string, attrs map[string]any) (domain.Attributes, error) {
+	out := domain.Attributes{}
+	if category == "" {
+		if len(attrs) > 0 {
+			return nil, fmt.Errorf("%w: attributes need a category", errInvalidAttributes)
+		}
+		return out, nil
+	}
+
+	c, err := categories.Get(category)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errCategoryNotFound
+		}
+		return nil, err
+	}
+
+	for name, v := range attrs {
+		def, ok := c.Attribute(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not an attribute of %s", errInvalidAttributes, name, c.Slug)
+		}
+		val, ok := attributeValue(def, v)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a %s", errInvalidAttributes, name, def.Type)
+		}
+		if len(def.Values) > 0 && !contains(def.Values, val.(string)) {
+			return nil, fmt.Errorf("%w: %s must be one of %v", errInvalidAttributes, name, def.Values)
+		}
+		out[name] = val
+	}
+	for _, def := range c.Attributes {
+		if _, ok := out[def.Name]; def.Required && !ok {
+			return nil, fmt.Errorf("%w: %s is required", errInvalidAttributes, def.Name)
+		}
+	}
+	return out, nil
+}
+
+func attributeValue(def domain.AttributeDef, v any) (any, bool) {
+	switch def.Type {
+	case domain.AttributeString:
+		s, ok := v.(string)
+		return s, ok && s != ""
+	case domain.AttributeBool:
+		b, ok := v.(bool)
+		return b, ok
+	case domain.AttributeNumber:
+		switch n := v.(type) {
+		case float64:
+			return n, true
+		case int:
+			return float64(n), true
+		}
+	}
+	return nil, false
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
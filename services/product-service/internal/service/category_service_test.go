@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+)
+
+func newCategoryEnv(t *testing.T) (*service.CategoryService, *service.ProductService) {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	categories := repo.NewCategories(db)
+	return service.NewCategoryService(categories), service.NewProductService(repo.NewProducts(db), categories, nil)
+}
+
+var laptops = service.SaveCategoryInput{
+	Slug: "laptops",
+	Name: "Laptops",
+	Attributes: []domain.AttributeDef{
+		{Name: "ram_gb", Type: domain.AttributeNumber, Required: true},
+		{Name: "color", Type: domain.AttributeString, Values: []string{"black", "silver"}},
+		{Name: "touch", Type: domain.AttributeBool},
+	},
+}
+
+func TestCategory_SaveValidatesSchema(t *testing.T) {
+	categories, _ := newCategoryEnv(t)
+
+	bad := []service.SaveCategoryInput{
+		{Slug: "Laptops", Name: "Laptops"},
+		{Slug: "laptops", Name: "Laptops", Attributes: []domain.AttributeDef{{Name: "ram", Type: "float"}}},
+		{Slug: "laptops", Name: "Laptops", Attributes: []domain.AttributeDef{{Name: "ram", Type: domain.AttributeNumber, Values: []string{"8"}}}},
+		{Slug: "laptops", Name: "Laptops", Attributes: []domain.AttributeDef{{Name: "ram", Type: domain.AttributeNumber}, {Name: "ram", Type: domain.AttributeNumber}}},
+	}
+	for _, in := range bad {
+		if _, err := categories.Save(in); !service.IsInvalidCategory(err) {
+			t.Errorf("Save(%+v) error = %v, want invalid", in, err)
+		}
+	}
+
+	if _, err := categories.Save(laptops); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	// повторное сохранение заменяет схему
+	renamed := laptops
+	renamed.Name = "Notebooks"
+	c, err := categories.Save(renamed)
+	if err != nil || c.Name != "Notebooks" || len(c.Attributes) != 3 {
+		t.Fatalf("Save() again = %+v, %v", c, err)
+	}
+}
+
+func TestCreateProduct_ValidatesAttributes(t *testing.T) {
+	categories, products := newCategoryEnv(t)
+	if _, err := categories.Save(laptops); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	create := func(category string, attrs map[string]any) (*domain.Product, error) {
+		return products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Laptop", Price: 100, Category: category, Attributes: attrs})
+	}
+
+	if _, err := create("phones", nil); !service.IsCategoryNotFound(err) {
+		t.Errorf("unknown category error = %v", err)
+	}
+	invalid := []map[string]any{
+		{"color": "black"},               // нет обязательного ram_gb
+		{"ram_gb": "16"},                 // строка вместо числа
+		{"ram_gb": 16.0, "color": "red"}, // значение не из списка
+		{"ram_gb": 16.0, "weight": 2.0},  // атрибута нет в схеме
+		{"ram_gb": 16.0, "touch": "yes"},
+	}
+	for _, attrs := range invalid {
+		if _, err := create("laptops", attrs); !service.IsInvalidAttributes(err) {
+			t.Errorf("attributes %v error = %v, want invalid", attrs, err)
+		}
+	}
+	if _, err := create("", map[string]any{"ram_gb": 16.0}); !service.IsInvalidAttributes(err) {
+		t.Errorf("attributes without category error = %v, want invalid", err)
+	}
+
+	p, err := create("laptops", map[string]any{"ram_gb": 16.0, "color": "silver", "touch": true})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	mustPublish(t, products, p)
+	got, _ := products.GetProduct(p.ID)
+	if got.Category != "laptops" || got.Attributes["ram_gb"] != 16.0 || got.Attributes["touch"] != true {
+		t.Errorf("stored product = %+v", got)
+	}
+
+	list, err := products.ListProducts(service.ProductFilter{Attrs: []service.AttrFilter{{Name: "ram_gb", Op: ">", Value: "8"}}})
+	if err != nil || len(list) != 1 {
+		t.Errorf("ram_gb>8 = %d products, %v", len(list), err)
+	}
+	if _, err := products.ListProducts(service.ProductFilter{Attrs: []service.AttrFilter{{Name: "ram_gb", Op: ">=", Value: "lots"}}}); !service.IsInvalidFilter(err) {
+		t.Errorf("non-numeric range error = %v, want invalid filter", err)
+	}
+}
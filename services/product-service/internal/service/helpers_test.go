@@ -0,0 +1,37 @@
+package service_test
+
+import (
+	"testing"
+
+	"GoProduct/internal/domain"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestDB открывает sqlite в памяти со всеми таблицами сервиса, чтобы
+// тестам не приходилось помнить, какие модели нужны их зависимостям.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&domain.Product{},
+		&domain.ProductImage{},
+		&domain.ProductVariant{},
+		&domain.VariantAttribute{},
+		&domain.ProductRevision{},
+		&domain.Category{},
+		&domain.Cart{},
+		&domain.CartItem{},
+		&domain.Reservation{},
+		&domain.ImportJob{},
+		&domain.SellerRating{},
+	); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	return db
+}
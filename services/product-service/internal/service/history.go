@@ -0,0 +1,128 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var errRevisionNotFound = errors.New("revision_not_found")
+
+func IsRevisionNotFound(err error) bool { return errors.Is(err, errRevisionNotFound) }
+
+// RevertInput rolls a product's editable fields back to what they were
+// after revision RevisionID. Version works as in UpdateProductInput.
+type RevertInput struct {
+	ID         uint
+	UserID     uint
+	Version    int
+	RevisionID uint
+}
+
+// PricePoint is a product's price from ChangedAt until the next point.
+type PricePoint struct {
+	Price     float64
+	ChangedAt time.Time
+}
+
+// record stores the revision for a change to p, which already holds the
+// new state. It runs in the change's transaction.
+func record(tx *repo.Products, actorID uint, action string, before *domain.ProductSnapshot, p *domain.Product, revertOf *uint) error {
+	after := p.Snapshot()
+	return tx.AddRevision(&domain.ProductRevision{
+		ProductID: p.ID,
+		Version:   p.Version,
+		ActorID:   actorID,
+		Action:    action,
+		Changes:   domain.DiffSnapshots(before, after),
+		Snapshot:  after,
+		RevertOf:  revertOf,
+	})
+}
+
+// History lists a product's revisions, oldest first, deleted products
+// included. Only the owner may read it.
+func (s *ProductService) History(userID, id uint) ([]domain.ProductRevision, error) {
+	p, err := s.products.GetWithDeleted(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, errForbidden
+	}
+	return s.products.ListRevisions(id)
+}
+
+// AdminHistory is History without the owner check.
+func (s *ProductService) AdminHistory(id uint) ([]domain.ProductRevision, error) {
+	if _, err := s.products.GetWithDeleted(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	return s.products.ListRevisions(id)
+}
+
+// Revert restores the name, description, price, category and attributes
+// a product had after an earlier revision. Status is left alone: it has
+// its own lifecycle. The revert is a revision of its own, so it can be
+// reverted too.
+func (s *ProductService) Revert(in RevertInput) (*domain.Product, error) {
+	p, err := s.GetProduct(in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != in.UserID {
+		return nil, errForbidden
+	}
+	if in.Version != 0 && in.Version != p.Version {
+		return nil, errVersionMismatch
+	}
+
+	rev, err := s.products.GetRevision(p.ID, in.RevisionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errRevisionNotFound
+		}
+		return nil, err
+	}
+
+	snap := rev.Snapshot
+	return s.update(p, UpdateProductInput{
+		ID:          p.ID,
+		ActorID:     in.UserID,
+		Name:        snap.Name,
+		Description: snap.Description,
+		Price:       snap.Price,
+		Category:    snap.Category,
+		Attributes:  snap.Attributes,
+	}, domain.RevisionReverted, &rev.ID)
+}
+
+// PriceHistory lists the prices a product the viewer may see has had,
+// oldest first.
+func (s *ProductService) PriceHistory(viewerID, id uint) ([]PricePoint, error) {
+	if _, err := s.GetVisibleProduct(viewerID, id); err != nil {
+		return nil, err
+	}
+	revs, err := s.products.ListRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []PricePoint{}
+	for _, rev := range revs {
+		if _, changed := rev.Changes["price"]; !changed {
+			continue
+		}
+		points = append(points, PricePoint{Price: rev.Snapshot.Price, ChangedAt: rev.CreatedAt})
+	}
+	return points, nil
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/service"
+)
+
+func TestHistory_RecordsEveryChange(t *testing.T) {
+	svc := newTestProductService(t)
+
+	p, err := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Phone", Price: 100})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	mustPublish(t, svc, p)
+	p, _ = svc.GetProduct(p.ID)
+
+	update := func(name string, price float64) *domain.Product {
+		t.Helper()
+		got, err := svc.UpdateProduct(service.UpdateProductInput{ID: p.ID, ActorID: 1, Name: name, Price: price})
+		if err != nil {
+			t.Fatalf("UpdateProduct() error = %v", err)
+		}
+		return got
+	}
+	update("Phone", 90)
+	update("Phone X", 90)
+	// обновление без изменений ревизию не пишет
+	update("Phone X", 90)
+
+	revs, err := svc.History(1, p.ID)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	wantActions := []string{
+		domain.RevisionCreated,
+		domain.RevisionStatusChanged, // на проверку
+		domain.RevisionStatusChanged, // одобрен модератором
+		domain.RevisionUpdated,
+		domain.RevisionUpdated,
+	}
+	if len(revs) != len(wantActions) {
+		t.Fatalf("got %d revisions, want %d: %+v", len(revs), len(wantActions), revs)
+	}
+	for i, want := range wantActions {
+		if revs[i].Action != want {
+			t.Errorf("revision %d action = %s, want %s", i, revs[i].Action, want)
+		}
+	}
+	if revs[2].ActorID != adminID || revs[3].ActorID != 1 {
+		t.Errorf("actors = %d, %d", revs[2].ActorID, revs[3].ActorID)
+	}
+	if c := revs[3].Changes["price"]; len(revs[3].Changes) != 1 || c.From != 100.0 || c.To != 90.0 {
+		t.Errorf("price change = %+v", revs[3].Changes)
+	}
+	if revs[4].Version <= revs[3].Version {
+		t.Errorf("versions %d, %d not increasing", revs[3].Version, revs[4].Version)
+	}
+
+	if _, err := svc.History(2, p.ID); !service.IsForbidden(err) {
+		t.Errorf("History() by a stranger error = %v, want forbidden", err)
+	}
+
+	// цена менялась дважды: при создании и при первом обновлении
+	points, err := svc.PriceHistory(2, p.ID)
+	if err != nil {
+		t.Fatalf("PriceHistory() error = %v", err)
+	}
+	if len(points) != 2 || points[0].Price != 100 || points[1].Price != 90 {
+		t.Errorf("price history = %+v", points)
+	}
+
+	if err := svc.DeleteProduct(1, p.ID, 0); err != nil {
+		t.Fatalf("DeleteProduct() error = %v", err)
+	}
+	revs, err = svc.History(1, p.ID)
+	if err != nil {
+		t.Fatalf("History() of a deleted product error = %v", err)
+	}
+	if last := revs[len(revs)-1]; last.Action != domain.RevisionDeleted || len(last.Changes) != 0 {
+		t.Errorf("last revision = %+v, want deleted", last)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	svc := newTestProductService(t)
+
+	p, err := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Phone", Description: "old", Price: 100})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	p, err = svc.UpdateProduct(service.UpdateProductInput{ID: p.ID, ActorID: 1, Name: "Phone X", Price: 80})
+	if err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	revs, _ := svc.History(1, p.ID)
+	created := revs[0]
+
+	if _, err := svc.Revert(service.RevertInput{ID: p.ID, UserID: 2, RevisionID: created.ID}); !service.IsForbidden(err) {
+		t.Errorf("Revert() by a stranger error = %v, want forbidden", err)
+	}
+	if _, err := svc.Revert(service.RevertInput{ID: p.ID, UserID: 1, RevisionID: 999}); !service.IsRevisionNotFound(err) {
+		t.Errorf("Revert() to an unknown revision error = %v", err)
+	}
+	if _, err := svc.Revert(service.RevertInput{ID: p.ID, UserID: 1, Version: p.Version - 1, RevisionID: created.ID}); !service.IsVersionMismatch(err) {
+		t.Errorf("stale Revert() error = %v, want version mismatch", err)
+	}
+
+	reverted, err := svc.Revert(service.RevertInput{ID: p.ID, UserID: 1, Version: p.Version, RevisionID: created.ID})
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if reverted.Name != "Phone" || reverted.Description != "old" || reverted.Price != 100 || reverted.Version != p.Version+1 {
+		t.Fatalf("reverted = %+v", reverted)
+	}
+
+	revs, _ = svc.History(1, p.ID)
+	last := revs[len(revs)-1]
+	if last.Action != domain.RevisionReverted || last.RevertOf == nil || *last.RevertOf != created.ID {
+		t.Errorf("revert revision = %+v", last)
+	}
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/imaging"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/storage"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errForbidden         = errors.New("forbidden")
+	errImageNotFound     = errors.New("image_not_found")
+	errImageTooLarge     = errors.New("image_too_large")
+	errUnsupportedImage  = errors.New("unsupported_image")
+	errTooManyImages     = errors.New("too_many_images")
+	errInvalidImageOrder = errors.New("invalid_image_order")
+)
+
+func IsForbidden(err error) bool         { return errors.Is(err, errForbidden) }
+func IsImageNotFound(err error) bool     { return errors.Is(err, errImageNotFound) }
+func IsImageTooLarge(err error) bool     { return errors.Is(err, errImageTooLarge) }
+func IsUnsupportedImage(err error) bool  { return errors.Is(err, errUnsupportedImage) }
+func IsTooManyImages(err error) bool     { return errors.Is(err, errTooManyImages) }
+func IsInvalidImageOrder(err error) bool { return errors.Is(err, errInvalidImageOrder) }
+
+// ImageLimits bound what sellers may upload. MaxPixels (width × height)
+// stops small files that decode into huge bitmaps.
+type ImageLimits struct {
+	MaxBytes      int64
+	MaxPixels     int
+	MaxPerProduct int
+}
+
+// imageTypes are the sniffed content types accepted for upload.
+var imageTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true}
+
+type UploadImageInput struct {
+	ProductID uint
+	UserID    uint
+	File      io.Reader
+}
+
+// ImageURLs are the public addresses of an image and its thumbnails,
+// keyed by domain.ThumbnailSizes names.
+type ImageURLs struct {
+	Original   string
+	Thumbnails map[string]string
+}
+
+type ImageService struct {
+	products *repo.Products
+	images   *repo.Images
+	store    storage.Storage
+	limits   ImageLimits
+}
+
+func NewImageService(products *repo.Products, images *repo.Images, store storage.Storage, limits ImageLimits) *ImageService {
+	return &ImageService{products: products, images: images, store: store, limits: limits}
+}
+
+func (s *ImageService) Limits() ImageLimits { return s.limits }
+
+// Upload checks the file, stores it with its thumbnails and appends it to
+// the product's images. Only the product's seller may upload.
+func (s *ImageService) Upload(ctx context.Context, in UploadImageInput) (*domain.ProductImage, error) {
+	if _, err := s.ownProduct(in.UserID, in.ProductID); err != nil {
+		return nil, err
+	}
+	n, err := s.images.Count(in.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if int(n) >= s.limits.MaxPerProduct {
+		return nil, errTooManyImages
+	}
+
+	data, err := io.ReadAll(io.LimitReader(in.File, s.limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.limits.MaxBytes {
+		return nil, errImageTooLarge
+	}
+
+	// верим содержимому, а не заголовку или расширению от клиента
+	contentType := http.DetectContentType(data)
+	if !imageTypes[contentType] {
+		return nil, errUnsupportedImage
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > s.limits.MaxPixels {
+		return nil, errImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errUnsupportedImage
+	}
+
+	img := &domain.ProductImage{
+		ProductID:   in.ProductID,
+		Key:         fmt.Sprintf("products/%d/%s", in.ProductID, newImageToken()),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}
+	if err := s.storeFiles(ctx, img, data, src); err != nil {
+		s.deleteFiles(ctx, img)
+		return nil, err
+	}
+	if err := s.images.Create(img); err != nil {
+		s.deleteFiles(ctx, img)
+		return nil, err
+	}
+	return img, nil
+}
+
+func (s *ImageService) storeFiles(ctx context.Context, img *domain.ProductImage, data []byte, src image.Image) error {
+	if err := s.store.Put(ctx, img.OriginalKey(), bytes.NewReader(data), int64(len(data)), img.ContentType); err != nil {
+		return err
+	}
+	for _, size := range domain.ThumbnailSizes {
+		var buf bytes.Buffer
+		contentType, err := imaging.Encode(&buf, imaging.Fit(src, size.Max), img.ContentType)
+		if err != nil {
+			return err
+		}
+		if err := s.store.Put(ctx, img.ThumbnailKey(size.Name), &buf, int64(buf.Len()), contentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns a product's images if viewerID may see the product:
+// unpublished products exist only for their seller.
+func (s *ImageService) List(viewerID, productID uint) ([]domain.ProductImage, error) {
+	p, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Published() && p.UserID != viewerID {
+		return nil, errNotFound
+	}
+	return s.images.ListByProduct(productID)
+}
+
+// Delete removes an image and its files. Remaining images keep their
+// relative order.
+func (s *ImageService) Delete(ctx context.Context, userID, productID, imageID uint) error {
+	if _, err := s.ownProduct(userID, productID); err != nil {
+		return err
+	}
+	img, err := s.images.Get(productID, imageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errImageNotFound
+		}
+		return err
+	}
+	if err := s.images.Delete(productID, imageID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errImageNotFound
+		}
+		return err
+	}
+	s.deleteFiles(ctx, img)
+	return nil
+}
+
+// Reorder puts the product's images in the order of ids, which must list
+// every image exactly once.
+func (s *ImageService) Reorder(userID, productID uint, ids []uint) ([]domain.ProductImage, error) {
+	if _, err := s.ownProduct(userID, productID); err != nil {
+		return nil, err
+	}
+	current, err := s.images.ListByProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) != len(current) {
+		return nil, errInvalidImageOrder
+	}
+	want := make(map[uint]bool, len(current))
+	for _, img := range current {
+		want[img.ID] = true
+	}
+	for _, id := range ids {
+		if !want[id] {
+			return nil, errInvalidImageOrder
+		}
+		delete(want, id)
+	}
+
+	if err := s.images.Reorder(productID, ids); err != nil {
+		return nil, err
+	}
+	return s.images.ListByProduct(productID)
+}
+
+// DeleteFiles removes the stored files of images whose rows are already
+// gone, e.g. after the product was purged.
+func (s *ImageService) DeleteFiles(ctx context.Context, images []domain.ProductImage) {
+	for i := range images {
+		s.deleteFiles(ctx, &images[i])
+	}
+}
+
+func (s *ImageService) URLs(img *domain.ProductImage) ImageURLs {
+	urls := ImageURLs{
+		Original:   s.store.URL(img.OriginalKey()),
+		Thumbnails: make(map[string]string, len(domain.ThumbnailSizes)),
+	}
+	for _, size := range domain.ThumbnailSizes {
+		urls.Thumbnails[size.Name] = s.store.URL(img.ThumbnailKey(size.Name))
+	}
+	return urls
+}
+
+// deleteFiles is best effort: a leftover file is only wasted space.
+func (s *ImageService) deleteFiles(ctx context.Context, img *domain.ProductImage) {
+	for _, key := range img.Keys() {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Printf("images: delete %s: %v", key, err)
+		}
+	}
+}
+
+func (s *ImageService) product(id uint) (*domain.Product, error) {
+	p, err := s.products.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *ImageService) ownProduct(userID, productID uint) (*domain.Product, error) {
+	p, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, errForbidden
+	}
+	return p, nil
+}
+
+func newImageToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
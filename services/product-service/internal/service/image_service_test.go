@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+	"GoProduct/internal/storage"
+)
+
+type imageEnv struct {
+	svc     *service.ImageService
+	dir     string
+	product *domain.Product
+}
+
+func newImageEnv(t *testing.T) *imageEnv {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	products := repo.NewProducts(db)
+	p := &domain.Product{UserID: 7, Name: "Lamp", Price: 10}
+	if err := products.Create(p); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	dir := t.TempDir()
+	svc := service.NewImageService(products, repo.NewImages(db), storage.NewLocal(dir, "http://cdn.test/media"), service.ImageLimits{
+		MaxBytes:      64 << 10,
+		MaxPixels:     1000 * 1000,
+		MaxPerProduct: 2,
+	})
+	return &imageEnv{svc: svc, dir: dir, product: p}
+}
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.RGBA{G: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func (e *imageEnv) upload(userID uint, data []byte) (*domain.ProductImage, error) {
+	return e.svc.Upload(context.Background(), service.UploadImageInput{
+		ProductID: e.product.ID,
+		UserID:    userID,
+		File:      bytes.NewReader(data),
+	})
+}
+
+func TestImageUpload_StoresOriginalAndThumbnails(t *testing.T) {
+	env := newImageEnv(t)
+
+	img, err := env.upload(7, pngBytes(t, 800, 400))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if img.ContentType != "image/png" || img.Width != 800 || img.Height != 400 || img.Position != 0 {
+		t.Fatalf("image = %+v", img)
+	}
+
+	for _, size := range domain.ThumbnailSizes {
+		f, err := os.Open(filepath.Join(env.dir, filepath.FromSlash(img.ThumbnailKey(size.Name))))
+		if err != nil {
+			t.Fatalf("thumbnail %s: %v", size.Name, err)
+		}
+		cfg, _, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("decode thumbnail %s: %v", size.Name, err)
+		}
+		// большая миниатюра не растягивает картинку 800px
+		wantW := min(size.Max, 800)
+		if cfg.Width != wantW || cfg.Height != wantW/2 {
+			t.Errorf("thumbnail %s = %dx%d, want %dx%d", size.Name, cfg.Width, cfg.Height, wantW, wantW/2)
+		}
+	}
+
+	urls := env.svc.URLs(img)
+	if !strings.HasPrefix(urls.Original, "http://cdn.test/media/products/") || !strings.HasSuffix(urls.Thumbnails["small"], "/small.png") {
+		t.Errorf("urls = %+v", urls)
+	}
+}
+
+func TestImageUpload_Rejects(t *testing.T) {
+	env := newImageEnv(t)
+
+	if _, err := env.upload(8, pngBytes(t, 10, 10)); !service.IsForbidden(err) {
+		t.Errorf("upload by a stranger error = %v, want forbidden", err)
+	}
+	// расширение и заголовок не важны, смотрим на содержимое
+	if _, err := env.upload(7, []byte("<html><body>not an image</body></html>")); !service.IsUnsupportedImage(err) {
+		t.Errorf("html upload error = %v, want unsupported", err)
+	}
+	if _, err := env.upload(7, bytes.Repeat([]byte{0xff}, 65<<10)); !service.IsImageTooLarge(err) {
+		t.Errorf("oversized upload error = %v, want too large", err)
+	}
+	// маленький файл, но огромная картинка после распаковки
+	if _, err := env.upload(7, pngBytes(t, 2000, 1000)); !service.IsImageTooLarge(err) {
+		t.Errorf("too many pixels error = %v, want too large", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := env.upload(7, pngBytes(t, 10, 10)); err != nil {
+			t.Fatalf("Upload() #%d error = %v", i+1, err)
+		}
+	}
+	if _, err := env.upload(7, pngBytes(t, 10, 10)); !service.IsTooManyImages(err) {
+		t.Errorf("third upload error = %v, want too many images", err)
+	}
+}
+
+func TestImageReorderAndDelete(t *testing.T) {
+	env := newImageEnv(t)
+	ctx := context.Background()
+
+	first, _ := env.upload(7, pngBytes(t, 10, 10))
+	second, _ := env.upload(7, pngBytes(t, 20, 20))
+
+	if _, err := env.svc.Reorder(7, env.product.ID, []uint{second.ID}); !service.IsInvalidImageOrder(err) {
+		t.Errorf("partial order error = %v, want invalid", err)
+	}
+	if _, err := env.svc.Reorder(7, env.product.ID, []uint{second.ID, second.ID}); !service.IsInvalidImageOrder(err) {
+		t.Errorf("duplicate order error = %v, want invalid", err)
+	}
+	images, err := env.svc.Reorder(7, env.product.ID, []uint{second.ID, first.ID})
+	if err != nil {
+		t.Fatalf("Reorder() error = %v", err)
+	}
+	if images[0].ID != second.ID || images[1].ID != first.ID {
+		t.Errorf("order = %d, %d; want %d, %d", images[0].ID, images[1].ID, second.ID, first.ID)
+	}
+
+	if err := env.svc.Delete(ctx, 8, env.product.ID, first.ID); !service.IsForbidden(err) {
+		t.Errorf("delete by a stranger error = %v, want forbidden", err)
+	}
+	if err := env.svc.Delete(ctx, 7, env.product.ID, second.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(env.dir, filepath.FromSlash(second.OriginalKey()))); !os.IsNotExist(err) {
+		t.Errorf("original file still there: %v", err)
+	}
+	if err := env.svc.Delete(ctx, 7, env.product.ID, second.ID); !service.IsImageNotFound(err) {
+		t.Errorf("second Delete() error = %v, want not found", err)
+	}
+
+	images, _ = env.svc.List(7, env.product.ID)
+	if len(images) != 1 || images[0].ID != first.ID {
+		t.Errorf("images after delete = %+v", images)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ImportColumns are the CSV columns an import understands, and the keys
+// of an NDJSON line. sku, name and price are required; attributes holds a
+// JSON object in CSV.
+var ImportColumns = []string{"sku", "name", "description", "price", "stock", "category", "attributes"}
+
+var requiredImportColumns = []string{"sku", "name", "price"}
+
+// importRow is one parsed row. Err is set when the row could not be read,
+// which skips it without failing the job.
+type importRow struct {
+	Line        int            `json:"-"`
+	SKU         string         `json:"sku"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Price       float64        `json:"price"`
+	Stock       int            `json:"stock"`
+	Category    string         `json:"category"`
+	Attributes  map[string]any `json:"attributes"`
+	Err         error          `json:"-"`
+}
+
+// parseImport reads every row of the file. An error means the file as a
+// whole is unusable.
+func parseImport(format string, data []byte) ([]importRow, error) {
+	switch format {
+	case domain.ImportCSV:
+		return parseCSV(data)
+	case domain.ImportNDJSON:
+		return parseNDJSON(data)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func parseCSV(data []byte) ([]importRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !knownImportColumn(name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		index[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			// кавычки сломаны: дальше строки не разобрать
+			return nil, err
+		}
+
+		line, _ := r.FieldPos(0)
+		row := importRow{Line: line}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row.SKU = field("sku")
+		row.Name = field("name")
+		row.Description = field("description")
+		row.Category = field("category")
+		row.Err = parseCSVNumbers(&row, field("price"), field("stock"), field("attributes"))
+		rows = append(rows, row)
+	}
+}
+
+func parseCSVNumbers(row *importRow, price, stock, attrs string) error {
+	var err error
+	if row.Price, err = strconv.ParseFloat(price, 64); err != nil {
+		return fmt.Errorf("price %q is not a number", price)
+	}
+	if stock != "" {
+		if row.Stock, err = strconv.Atoi(stock); err != nil {
+			return fmt.Errorf("stock %q is not a whole number", stock)
+		}
+	}
+	if attrs != "" {
+		if err := json.Unmarshal([]byte(attrs), &row.Attributes); err != nil {
+			return errors.New("attributes must be a JSON object")
+		}
+	}
+	return nil
+}
+
+func parseNDJSON(data []byte) ([]importRow, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64<<10), len(data)+1)
+
+	var rows []importRow
+	for line := 1; sc.Scan(); line++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		row := importRow{Line: line}
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&row); err != nil {
+			row = importRow{Line: line, Err: fmt.Errorf("invalid JSON: %v", err)}
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func knownImportColumn(name string) bool {
+	for _, c := range ImportColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks what the create endpoint's binding checks.
+func (row *importRow) validate() error {
+	row.SKU = strings.TrimSpace(row.SKU)
+	switch {
+	case row.SKU == "":
+		return errors.New("sku is required")
+	case len(row.SKU) > 64:
+		return errors.New("sku is longer than 64 characters")
+	case strings.TrimSpace(row.Name) == "":
+		return errors.New("name is required")
+	case len(row.Name) > 255:
+		return errors.New("name is longer than 255 characters")
+	case len(row.Description) > 1000:
+		return errors.New("description is longer than 1000 characters")
+	case row.Price <= 0:
+		return errors.New("price must be greater than zero")
+	case row.Stock < 0:
+		return errors.New("stock cannot be negative")
+	case len(row.Category) > 64:
+		return errors.New("category is longer than 64 characters")
+	}
+	return nil
+}
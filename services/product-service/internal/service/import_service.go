@@ -0,0 +1,253 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxImportRows caps the rows of a single import file.
+const MaxImportRows = 10000
+
+const (
+	// importProgressEvery is how often, in rows, a running job stores its
+	// progress.
+	importProgressEvery = 50
+	// importStaleAfter is how long a RUNNING job may go without progress
+	// before another instance takes it over.
+	importStaleAfter = 5 * time.Minute
+)
+
+var (
+	errInvalidImport  = errors.New("invalid_import")
+	errImportNotFound = errors.New("import_not_found")
+)
+
+func IsInvalidImport(err error) bool  { return errors.Is(err, errInvalidImport) }
+func IsImportNotFound(err error) bool { return errors.Is(err, errImportNotFound) }
+
+// ImportInput is an uploaded file to import for a seller.
+type ImportInput struct {
+	SellerID    uint
+	SellerEmail string
+	Format      string
+	DryRun      bool
+	Data        []byte
+}
+
+// ImportService runs sellers' product imports. Uploads are stored as jobs
+// and processed in the background by RunImports, so a large file does not
+// hold the request open.
+type ImportService struct {
+	products *ProductService
+	jobs     *repo.Imports
+}
+
+func NewImportService(products *ProductService, jobs *repo.Imports) *ImportService {
+	return &ImportService{products: products, jobs: jobs}
+}
+
+// Start queues an import. The file itself is only parsed once the job
+// runs; problems with it are reported on the job.
+func (s *ImportService) Start(in ImportInput) (*domain.ImportJob, error) {
+	if in.Format != domain.ImportCSV && in.Format != domain.ImportNDJSON {
+		return nil, fmt.Errorf("%w: format must be csv or ndjson", errInvalidImport)
+	}
+	if len(in.Data) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", errInvalidImport)
+	}
+
+	job := &domain.ImportJob{
+		SellerID:    in.SellerID,
+		SellerEmail: in.SellerEmail,
+		Format:      in.Format,
+		DryRun:      in.DryRun,
+		Status:      domain.ImportPending,
+		Payload:     in.Data,
+	}
+	if err := s.jobs.Create(job); err != nil {
+		return nil, err
+	}
+	job.Payload = nil
+	return job, nil
+}
+
+// Get returns one of the seller's import jobs.
+func (s *ImportService) Get(sellerID, id uint) (*domain.ImportJob, error) {
+	job, err := s.jobs.Get(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errImportNotFound
+		}
+		return nil, err
+	}
+	if job.SellerID != sellerID {
+		return nil, errForbidden
+	}
+	return job, nil
+}
+
+// ProcessPending runs queued jobs one after another until none is left or
+// ctx is cancelled, and reports how many it ran.
+func (s *ImportService) ProcessPending(ctx context.Context) (int, error) {
+	n := 0
+	for ctx.Err() == nil {
+		job, err := s.jobs.Claim(time.Now().Add(-importStaleAfter))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := s.process(ctx, job); err != nil {
+			return n, fmt.Errorf("import %d: %w", job.ID, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// RunImports calls ProcessPending every interval until ctx is cancelled.
+func (s *ImportService) RunImports(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ProcessPending(ctx)
+			if err != nil {
+				log.Printf("product import: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("product import: ran %d jobs", n)
+			}
+		}
+	}
+}
+
+// process imports the rows of a claimed job. A job taken over from
+// another instance resumes after the rows that instance reported done.
+func (s *ImportService) process(ctx context.Context, job *domain.ImportJob) error {
+	rows, err := parseImport(job.Format, job.Payload)
+	if err == nil && len(rows) > MaxImportRows {
+		err = fmt.Errorf("file has %d rows, at most %d are allowed", len(rows), MaxImportRows)
+	}
+	if err != nil {
+		job.Status = domain.ImportFailed
+		job.Error = err.Error()
+		return s.finish(job)
+	}
+
+	job.Total = len(rows)
+	for _, row := range rows[min(job.Processed, len(rows)):] {
+		if ctx.Err() != nil {
+			// остаётся RUNNING, после importStaleAfter задачу подхватят
+			return s.jobs.SaveProgress(job)
+		}
+
+		created, err := s.importRow(job, row)
+		switch {
+		case err != nil:
+			job.Failed++
+			job.Errors = append(job.Errors, domain.ImportRowError{Row: row.Line, SKU: strings.TrimSpace(row.SKU), Error: err.Error()})
+		case created:
+			job.Created++
+		default:
+			job.Updated++
+		}
+		job.Processed++
+
+		if job.Processed%importProgressEvery == 0 {
+			if err := s.jobs.SaveProgress(job); err != nil {
+				return err
+			}
+		}
+	}
+
+	job.Status = domain.ImportDone
+	return s.finish(job)
+}
+
+func (s *ImportService) finish(job *domain.ImportJob) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	return s.jobs.SaveProgress(job)
+}
+
+// importRow creates or updates the seller's product with the row's SKU.
+// Errors are the row's own and end up in the job's report; in a dry run
+// nothing is written. Stock is set to the row's value.
+func (s *ImportService) importRow(job *domain.ImportJob, row importRow) (created bool, err error) {
+	if row.Err != nil {
+		return false, row.Err
+	}
+	if err := row.validate(); err != nil {
+		return false, err
+	}
+	if _, err := checkAttributes(s.products.categories, row.Category, row.Attributes); err != nil {
+		if IsCategoryNotFound(err) {
+			return false, fmt.Errorf("unknown category %q", row.Category)
+		}
+		return false, err
+	}
+
+	existing, err := s.products.products.GetBySKU(job.SellerID, row.SKU)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		existing = nil
+	case err != nil:
+		return false, err
+	case existing.DeletedAt.Valid:
+		return false, errors.New("sku belongs to a deleted product, restore it first")
+	}
+
+	if job.DryRun {
+		return existing == nil, nil
+	}
+
+	if existing == nil {
+		_, err := s.products.CreateProduct(CreateProductInput{
+			UserID:      job.SellerID,
+			SellerEmail: job.SellerEmail,
+			SKU:         row.SKU,
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			Stock:       row.Stock,
+			Category:    row.Category,
+			Attributes:  row.Attributes,
+		})
+		return true, err
+	}
+
+	p, err := s.products.UpdateProduct(UpdateProductInput{
+		ID:          existing.ID,
+		ActorID:     job.SellerID,
+		Version:     existing.Version,
+		Name:        row.Name,
+		Description: row.Description,
+		Price:       row.Price,
+		Category:    row.Category,
+		Attributes:  row.Attributes,
+	})
+	if err != nil {
+		return false, err
+	}
+	if delta := row.Stock - p.Stock; delta != 0 {
+		if _, err := s.products.AdjustStock(p.UserID, p.ID, delta); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+)
+
+type importEnv struct {
+	products *service.ProductService
+	imports  *service.ImportService
+}
+
+func newImportEnv(t *testing.T) *importEnv {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	products := service.NewProductService(repo.NewProducts(db), repo.NewCategories(db), nil)
+	return &importEnv{products: products, imports: service.NewImportService(products, repo.NewImports(db))}
+}
+
+// run ставит импорт в очередь и сразу его выполняет.
+func (e *importEnv) run(t *testing.T, in service.ImportInput) *domain.ImportJob {
+	t.Helper()
+	job, err := e.imports.Start(in)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if job.Status != domain.ImportPending {
+		t.Fatalf("new job status = %s", job.Status)
+	}
+	if _, err := e.imports.ProcessPending(context.Background()); err != nil {
+		t.Fatalf("ProcessPending() error = %v", err)
+	}
+	job, err = e.imports.Get(in.SellerID, job.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return job
+}
+
+func TestImport_CSVUpsertsBySKU(t *testing.T) {
+	env := newImportEnv(t)
+
+	existing, err := env.products.CreateProduct(service.CreateProductInput{UserID: 1, SKU: "MUG-1", Name: "Mug", Price: 5, Stock: 3})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	if _, err := env.products.CreateProduct(service.CreateProductInput{UserID: 1, SKU: "MUG-1", Name: "Mug", Price: 5}); !service.IsDuplicateSKU(err) {
+		t.Fatalf("duplicate sku error = %v", err)
+	}
+	// тот же SKU у другого продавца не мешает
+	if _, err := env.products.CreateProduct(service.CreateProductInput{UserID: 2, SKU: "LAMP-1", Name: "Lamp", Price: 5}); err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+
+	csv := strings.Join([]string{
+		"sku,name,price,stock,description",
+		"MUG-1,Big mug,7.5,10,now bigger",
+		"LAMP-1,Lamp,20,2,",
+		",No sku,1,1,",
+		"BAD-1,Bad price,free,1,",
+		`"QUOTED-1","Plate, white",3,0,"multi`,
+		`line"`,
+	}, "\n")
+	job := env.run(t, service.ImportInput{SellerID: 1, Format: domain.ImportCSV, Data: []byte(csv)})
+
+	if job.Status != domain.ImportDone || job.Total != 5 || job.Processed != 5 || job.Created != 2 || job.Updated != 1 || job.Failed != 2 {
+		t.Fatalf("job = %+v", job)
+	}
+	if len(job.Errors) != 2 || job.Errors[0].Row != 4 || job.Errors[1].Row != 5 || job.Errors[1].SKU != "BAD-1" {
+		t.Errorf("errors = %+v", job.Errors)
+	}
+
+	mug, _ := env.products.GetProduct(existing.ID)
+	if mug.Name != "Big mug" || mug.Price != 7.5 || mug.Stock != 10 || mug.Description != "now bigger" {
+		t.Errorf("updated mug = %+v", mug)
+	}
+	revs, _ := env.products.History(1, mug.ID)
+	if last := revs[len(revs)-1]; last.Action != domain.RevisionUpdated || last.ActorID != 1 {
+		t.Errorf("import revision = %+v", last)
+	}
+
+	// повторный импорт того же файла ничего не создаёт
+	again := env.run(t, service.ImportInput{SellerID: 1, Format: domain.ImportCSV, Data: []byte(csv)})
+	if again.Created != 0 || again.Updated != 3 {
+		t.Errorf("re-import = %+v", again)
+	}
+}
+
+func TestImport_NDJSONDryRun(t *testing.T) {
+	env := newImportEnv(t)
+
+	ndjson := strings.Join([]string{
+		`{"sku":"A","name":"Alpha","price":10,"stock":1}`,
+		``,
+		`{"sku":"B","name":"Beta","price":10,"category":"laptops"}`,
+		`{"sku":"C","name":"Gamma","price":10,"colour":"red"}`,
+		`not json`,
+	}, "\n")
+
+	dry := env.run(t, service.ImportInput{SellerID: 1, Format: domain.ImportNDJSON, DryRun: true, Data: []byte(ndjson)})
+	if dry.Status != domain.ImportDone || dry.Total != 4 || dry.Created != 1 || dry.Failed != 3 {
+		t.Fatalf("dry run = %+v", dry)
+	}
+	wantRows := []int{3, 4, 5}
+	for i, e := range dry.Errors {
+		if e.Row != wantRows[i] {
+			t.Errorf("error %d row = %d, want %d", i, e.Row, wantRows[i])
+		}
+	}
+	if _, err := env.products.GetProduct(1); !service.IsNotFound(err) {
+		t.Errorf("dry run created a product: %v", err)
+	}
+
+	real := env.run(t, service.ImportInput{SellerID: 1, Format: domain.ImportNDJSON, Data: []byte(ndjson)})
+	if real.Created != 1 || real.Failed != 3 {
+		t.Errorf("import = %+v", real)
+	}
+}
+
+func TestImport_FailedFileAndOwnership(t *testing.T) {
+	env := newImportEnv(t)
+
+	if _, err := env.imports.Start(service.ImportInput{SellerID: 1, Format: "xml", Data: []byte("<a/>")}); !service.IsInvalidImport(err) {
+		t.Errorf("xml import error = %v, want invalid", err)
+	}
+	if _, err := env.imports.Start(service.ImportInput{SellerID: 1, Format: domain.ImportCSV}); !service.IsInvalidImport(err) {
+		t.Errorf("empty import error = %v, want invalid", err)
+	}
+
+	job := env.run(t, service.ImportInput{SellerID: 1, Format: domain.ImportCSV, Data: []byte("name,price\nMug,5\n")})
+	if job.Status != domain.ImportFailed || !strings.Contains(job.Error, `"sku"`) || job.FinishedAt == nil {
+		t.Errorf("job without sku column = %+v", job)
+	}
+
+	if _, err := env.imports.Get(2, job.ID); !service.IsForbidden(err) {
+		t.Errorf("Get() by another seller error = %v, want forbidden", err)
+	}
+	if _, err := env.imports.Get(1, 999); !service.IsImportNotFound(err) {
+		t.Errorf("Get() of a missing job error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errReservationNotFound = errors.New("reservation_not_found")
+	errReservationClosed   = errors.New("reservation_closed")
+	errInvalidReservation  = errors.New("invalid_reservation")
+)
+
+func IsReservationNotFound(err error) bool { return errors.Is(err, errReservationNotFound) }
+func IsReservationClosed(err error) bool   { return errors.Is(err, errReservationClosed) }
+func IsInvalidReservation(err error) bool  { return errors.Is(err, errInvalidReservation) }
+
+type ReserveItem struct {
+	ProductID uint
+	Quantity  int
+}
+
+type ReservationView struct {
+	Reference string
+	Status    domain.ReservationStatus
+	ExpiresAt time.Time
+	Items     []ReserveItem
+}
+
+type InventoryService struct {
+	inventory *repo.Inventory
+	ttl       time.Duration
+}
+
+// NewInventoryService creates reservations that expire after ttl unless
+// committed.
+func NewInventoryService(inventory *repo.Inventory, ttl time.Duration) *InventoryService {
+	return &InventoryService{inventory: inventory, ttl: ttl}
+}
+
+// Reserve holds stock for items under ref, all or nothing. Repeating a
+// reserve with the same ref returns the existing reservation, unless it has
+// been released: a released ref cannot be reserved again.
+func (s *InventoryService) Reserve(ref string, items []ReserveItem) (*ReservationView, error) {
+	if ref == "" || len(items) == 0 {
+		return nil, errInvalidReservation
+	}
+
+	// Same product twice is folded into one row, and rows are locked in
+	// product order so concurrent reservations cannot deadlock.
+	byProduct := make(map[uint]int, len(items))
+	for _, it := range items {
+		if it.ProductID == 0 || it.Quantity < 1 {
+			return nil, errInvalidReservation
+		}
+		byProduct[it.ProductID] += it.Quantity
+	}
+	rows := make([]domain.Reservation, 0, len(byProduct))
+	for id, q := range byProduct {
+		rows = append(rows, domain.Reservation{ProductID: id, Quantity: q})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ProductID < rows[j].ProductID })
+
+	stored, err := s.inventory.Reserve(ref, rows, time.Now().Add(s.ttl))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, errNotFound
+		case errors.Is(err, domain.ErrInsufficientStock):
+			return nil, errInsufficientStock
+		}
+		return nil, err
+	}
+	v := reservationView(stored)
+	if v.Status == domain.ReservationReleased {
+		return nil, errReservationClosed
+	}
+	return v, nil
+}
+
+func (s *InventoryService) Get(ref string) (*ReservationView, error) {
+	rows, err := s.inventory.Get(ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errReservationNotFound
+		}
+		return nil, err
+	}
+	return reservationView(rows), nil
+}
+
+// Commit makes a reservation final once the order is paid. Committing twice
+// is fine; committing a released or expired reservation is not.
+func (s *InventoryService) Commit(ref string) (*ReservationView, error) {
+	if _, err := s.inventory.Commit(ref, time.Now()); err != nil {
+		return nil, err
+	}
+	v, err := s.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != domain.ReservationCommitted {
+		return nil, errReservationClosed
+	}
+	return v, nil
+}
+
+// Release gives reserved units back to stock. Releasing twice is fine;
+// releasing a committed reservation is not.
+func (s *InventoryService) Release(ref string) (*ReservationView, error) {
+	if _, err := s.inventory.Release(ref); err != nil {
+		return nil, err
+	}
+	v, err := s.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != domain.ReservationReleased {
+		return nil, errReservationClosed
+	}
+	return v, nil
+}
+
+// ReleaseExpired returns stock held by reservations that expired before now.
+func (s *InventoryService) ReleaseExpired(now time.Time) (int64, error) {
+	return s.inventory.ReleaseExpired(now)
+}
+
+// RunExpiry calls ReleaseExpired every interval until ctx is cancelled.
+func (s *InventoryService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ReleaseExpired(time.Now())
+			if err != nil {
+				log.Printf("reservation expiry: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("reservation expiry: released %d reservations", n)
+			}
+		}
+	}
+}
+
+// reservationView summarises the rows of one reservation. They share their
+// status except mid-release, where any active row keeps the whole
+// reservation active.
+func reservationView(rows []domain.Reservation) *ReservationView {
+	v := &ReservationView{Items: make([]ReserveItem, 0, len(rows))}
+	for _, r := range rows {
+		v.Reference = r.Reference
+		v.ExpiresAt = r.ExpiresAt
+		if v.Status == "" || r.Status == domain.ReservationActive {
+			v.Status = r.Status
+		}
+		v.Items = append(v.Items, ReserveItem{ProductID: r.ProductID, Quantity: r.Quantity})
+	}
+	return v
+}
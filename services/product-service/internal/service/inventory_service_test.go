@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+)
+
+func newTestInventoryService(t *testing.T, ttl time.Duration) (*service.InventoryService, *service.ProductService) {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	return service.NewInventoryService(repo.NewInventory(db), ttl), service.NewProductService(repo.NewProducts(db), repo.NewCategories(db), nil)
+}
+
+func TestInventoryService_ReserveCommitRelease(t *testing.T) {
+	inv, products := newTestInventoryService(t, time.Hour)
+
+	p, _ := products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "A", Price: 1, Stock: 3})
+
+	// одна и та же позиция дважды складывается в одну строку
+	v, err := inv.Reserve("order-1", []service.ReserveItem{
+		{ProductID: p.ID, Quantity: 1},
+		{ProductID: p.ID, Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if v.Status != domain.ReservationActive || len(v.Items) != 1 || v.Items[0].Quantity != 2 {
+		t.Fatalf("Reserve() = %+v", v)
+	}
+
+	if _, err := inv.Reserve("order-2", []service.ReserveItem{{ProductID: p.ID, Quantity: 2}}); !service.IsInsufficientStock(err) {
+		t.Fatalf("Reserve() over stock error = %v, want insufficient stock", err)
+	}
+	if _, err := inv.Reserve("order-3", []service.ReserveItem{{ProductID: 999, Quantity: 1}}); !service.IsNotFound(err) {
+		t.Fatalf("Reserve() of missing product error = %v, want not found", err)
+	}
+
+	if _, err := inv.Commit("order-1"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if _, err := inv.Commit("order-1"); err != nil {
+		t.Fatalf("repeated Commit() error = %v", err)
+	}
+	if _, err := inv.Release("order-1"); !service.IsReservationClosed(err) {
+		t.Fatalf("Release() after commit error = %v, want closed", err)
+	}
+	if _, err := inv.Release("nope"); !service.IsReservationNotFound(err) {
+		t.Fatalf("Release() of unknown ref error = %v, want not found", err)
+	}
+
+	got, _ := products.GetProduct(p.ID)
+	if got.Stock != 1 || got.OutOfStock() {
+		t.Errorf("stock = %d, want 1", got.Stock)
+	}
+}
+
+func TestInventoryService_ExpiredCannotBeCommitted(t *testing.T) {
+	inv, products := newTestInventoryService(t, -time.Minute)
+
+	p, _ := products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "A", Price: 1, Stock: 1})
+	if _, err := inv.Reserve("order-1", []service.ReserveItem{{ProductID: p.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+
+	got, _ := products.GetProduct(p.ID)
+	if !got.OutOfStock() {
+		t.Fatalf("product should be out of stock while reserved")
+	}
+
+	if _, err := inv.Commit("order-1"); !service.IsReservationClosed(err) {
+		t.Fatalf("Commit() of expired reservation error = %v, want closed", err)
+	}
+	if n, err := inv.ReleaseExpired(time.Now()); err != nil || n != 1 {
+		t.Fatalf("ReleaseExpired() = %d, %v; want 1", n, err)
+	}
+
+	got, _ = products.GetProduct(p.ID)
+	if got.Stock != 1 {
+		t.Errorf("stock after expiry = %d, want 1", got.Stock)
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	svc := newTestProductService(t)
+
+	p, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "A", Price: 1})
+	if !p.OutOfStock() {
+		t.Fatalf("new product without stock should be out of stock")
+	}
+
+	got, err := svc.AdjustStock(1, p.ID, 4)
+	if err != nil || got.Stock != 4 {
+		t.Fatalf("AdjustStock(4) = %+v, %v", got, err)
+	}
+	if _, err := svc.AdjustStock(1, p.ID, -5); !service.IsInsufficientStock(err) {
+		t.Fatalf("AdjustStock(-5) error = %v, want insufficient stock", err)
+	}
+	if _, err := svc.AdjustStock(1, 999, 1); !service.IsNotFound(err) {
+		t.Fatalf("AdjustStock(missing) error = %v, want not found", err)
+	}
+	// чужой склад трогать нельзя
+	if _, err := svc.AdjustStock(2, p.ID, -4); !service.IsForbidden(err) {
+		t.Fatalf("AdjustStock() by another seller error = %v, want forbidden", err)
+	}
+	if got, _ := svc.GetProduct(p.ID); got.Stock != 4 {
+		t.Errorf("stock = %d after a foreign adjustment, want 4", got.Stock)
+	}
+}
+
+func TestInventoryService_ReleasedRefCannotBeReused(t *testing.T) {
+	inv, products := newTestInventoryService(t, time.Hour)
+
+	p, _ := products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "A", Price: 1, Stock: 3})
+	items := []service.ReserveItem{{ProductID: p.ID, Quantity: 1}}
+
+	if _, err := inv.Reserve("order-1", items); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if _, err := inv.Release("order-1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := inv.Reserve("order-1", items); !service.IsReservationClosed(err) {
+		t.Fatalf("Reserve() of a released ref error = %v, want closed", err)
+	}
+}
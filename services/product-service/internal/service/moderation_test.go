@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+)
+
+type sentEvent struct {
+	topic string
+	event service.ProductEvent
+}
+
+type fakePublisher struct {
+	sent    []sentEvent
+	changed []service.ProductChangedEvent
+}
+
+func (p *fakePublisher) Send(_ context.Context, topic, _ string, value interface{}) error {
+	switch ev := value.(type) {
+	case service.ProductChangedEvent:
+		p.changed = append(p.changed, ev)
+	default:
+		p.sent = append(p.sent, sentEvent{topic: topic, event: ev.(service.ProductEvent)})
+	}
+	return nil
+}
+
+func newTestModeration(t *testing.T) (*service.ProductService, *fakePublisher) {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	pub := &fakePublisher{}
+	return service.NewProductService(repo.NewProducts(db), repo.NewCategories(db), pub), pub
+}
+
+func TestProductLifecycle_ApproveAndArchive(t *testing.T) {
+	svc, pub := newTestModeration(t)
+	ctx := context.Background()
+
+	p, err := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Phone", Price: 100})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	if p.Status != domain.StatusDraft {
+		t.Fatalf("new product status = %s, want DRAFT", p.Status)
+	}
+
+	// черновик виден только владельцу
+	if _, err := svc.GetVisibleProduct(2, p.ID); !service.IsNotFound(err) {
+		t.Fatalf("draft visible to other users: %v", err)
+	}
+	if _, err := svc.GetVisibleProduct(1, p.ID); err != nil {
+		t.Fatalf("draft hidden from owner: %v", err)
+	}
+
+	// продавец не может опубликовать сам себя
+	_, err = svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 1, Status: domain.StatusPublished})
+	if !service.IsForbidden(err) {
+		t.Fatalf("seller publish err = %v, want forbidden", err)
+	}
+	// и не может трогать чужие товары
+	_, err = svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 2, Status: domain.StatusPendingReview})
+	if !service.IsForbidden(err) {
+		t.Fatalf("foreign submit err = %v, want forbidden", err)
+	}
+	// одобрить можно только то, что на проверке
+	if _, err := svc.Approve(ctx, adminID, p.ID); !service.IsInvalidTransition(err) {
+		t.Fatalf("approve draft err = %v, want invalid transition", err)
+	}
+
+	_, err = svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 1, Email: "seller@example.com", Status: domain.StatusPendingReview})
+	if err != nil {
+		t.Fatalf("submit error = %v", err)
+	}
+	queue, err := svc.ModerationQueue(10)
+	if err != nil || len(queue) != 1 || queue[0].ID != p.ID {
+		t.Fatalf("ModerationQueue() = %v, %v; want the submitted product", queue, err)
+	}
+
+	approved, err := svc.Approve(ctx, adminID, p.ID)
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if approved.Status != domain.StatusPublished {
+		t.Fatalf("approved status = %s", approved.Status)
+	}
+	if _, err := svc.GetVisibleProduct(2, p.ID); err != nil {
+		t.Fatalf("published product hidden: %v", err)
+	}
+
+	if len(pub.sent) != 1 || pub.sent[0].topic != service.TopicProductApproved {
+		t.Fatalf("events = %+v, want one product.approved", pub.sent)
+	}
+	if ev := pub.sent[0].event; ev.SellerID != 1 || ev.SellerEmail != "seller@example.com" || ev.Name != "Phone" {
+		t.Errorf("approved event = %+v", ev)
+	}
+
+	archived, err := svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 1, Status: domain.StatusArchived})
+	if err != nil || archived.Status != domain.StatusArchived {
+		t.Fatalf("archive = %v, %v", archived, err)
+	}
+	// из архива — только обратно в черновик
+	_, err = svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 1, Status: domain.StatusPendingReview})
+	if !service.IsInvalidTransition(err) {
+		t.Fatalf("archived -> pending err = %v, want invalid transition", err)
+	}
+}
+
+func TestProductLifecycle_Reject(t *testing.T) {
+	svc, pub := newTestModeration(t)
+	ctx := context.Background()
+
+	p, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Replica", Price: 10})
+	_, _ = svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 1, Status: domain.StatusPendingReview})
+
+	if _, err := svc.Reject(ctx, adminID, p.ID, "   "); !service.IsInvalidReason(err) {
+		t.Fatalf("empty reason err = %v, want invalid reason", err)
+	}
+
+	rejected, err := svc.Reject(ctx, adminID, p.ID, "counterfeit goods")
+	if err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if rejected.Status != domain.StatusRejected || rejected.RejectionReason != "counterfeit goods" {
+		t.Fatalf("rejected = %+v", rejected)
+	}
+	if len(pub.sent) != 1 || pub.sent[0].topic != service.TopicProductRejected || pub.sent[0].event.Reason != "counterfeit goods" {
+		t.Fatalf("events = %+v, want one product.rejected with reason", pub.sent)
+	}
+
+	// повторная отправка очищает причину отказа
+	resubmitted, err := svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 1, Status: domain.StatusPendingReview})
+	if err != nil {
+		t.Fatalf("resubmit error = %v", err)
+	}
+	if resubmitted.RejectionReason != "" {
+		t.Errorf("rejection reason kept after resubmit: %q", resubmitted.RejectionReason)
+	}
+
+	if _, err := svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: 1, Status: "SOLD"}); !service.IsInvalidStatus(err) {
+		t.Fatalf("unknown status err = %v", err)
+	}
+}
@@ -3,46 +3,157 @@ package service
 import (
 	"GoProduct/internal/domain"
 	"GoProduct/internal/repo"
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
-var errNotFound = errors.New("product_not_found")
+// MaxRejectionReason caps the length of a moderator's rejection reason.
+const MaxRejectionReason = 1000
 
-func IsNotFound(err error) bool { return errors.Is(err, errNotFound) }
+const (
+	TopicProductApproved = "product.approved"
+	TopicProductRejected = "product.rejected"
+)
+
+var (
+	errNotFound          = errors.New("product_not_found")
+	errInsufficientStock = errors.New("insufficient_stock")
+	errInvalidFilter     = errors.New("invalid_filter")
+	errInvalidTransition = errors.New("invalid_status_transition")
+	errInvalidStatus     = errors.New("invalid_status")
+	errInvalidReason     = errors.New("invalid_reason")
+	errVersionMismatch   = errors.New("version_mismatch")
+)
+
+func IsNotFound(err error) bool          { return errors.Is(err, errNotFound) }
+func IsInsufficientStock(err error) bool { return errors.Is(err, errInsufficientStock) }
+func IsInvalidFilter(err error) bool     { return errors.Is(err, errInvalidFilter) }
+func IsInvalidTransition(err error) bool { return errors.Is(err, errInvalidTransition) }
+func IsInvalidStatus(err error) bool     { return errors.Is(err, errInvalidStatus) }
+func IsInvalidReason(err error) bool     { return errors.Is(err, errInvalidReason) }
+func IsVersionMismatch(err error) bool   { return errors.Is(err, errVersionMismatch) }
+
+// Publisher sends product events to Kafka.
+type Publisher interface {
+	Send(ctx context.Context, topic string, key string, value interface{}) error
+}
+
+// ProductEvent announces a moderation decision to the seller.
+type ProductEvent struct {
+	ProductID   uint   `json:"product_id"`
+	SellerID    uint   `json:"seller_id"`
+	SellerEmail string `json:"seller_email"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+}
 
+// CreateProductInput describes a new product. SKU is optional.
 type CreateProductInput struct {
 	UserID      uint
+	SellerEmail string
+	SKU         string
 	Name        string
 	Description string
 	Price       float64
+	Stock       int
+	Category    string
+	Attributes  map[string]any
 }
 
+// UpdateProductInput replaces a product's editable fields. Version is the
+// version the client edited; zero skips the check. ActorID must own the
+// product and is recorded in its history.
 type UpdateProductInput struct {
 	ID          uint
+	ActorID     uint
+	Version     int
 	Name        string
 	Description string
 	Price       float64
+	Category    string
+	Attributes  map[string]any
+}
+
+// AttrFilter compares a product attribute with Value using Op: one of =,
+// >, >=, < and <=. The ordering operators need a numeric Value.
+type AttrFilter struct {
+	Name  string
+	Op    string
+	Value string
+}
+
+// ProductFilter narrows ListProducts. A nil InStock lists every product.
+// SKU and VariantAttrs select products by their variants; see
+// repo.ProductFilter for how they combine with InStock.
+type ProductFilter struct {
+	InStock      *bool
+	SKU          string
+	VariantAttrs map[string]string
+	Category     string
+	Attrs        []AttrFilter
+	// SellerID, when set, lists only that seller's products.
+	SellerID uint
+}
+
+// ChangeStatusInput is a seller moving their own product through the
+// lifecycle. Publishing and rejecting are left to moderators.
+type ChangeStatusInput struct {
+	ID     uint
+	UserID uint
+	Email  string
+	Status domain.ProductStatus
 }
 
 type ProductService struct {
-	products *repo.Products
+	products   *repo.Products
+	categories *repo.Categories
+	publisher  Publisher
 }
 
-func NewProductService(products *repo.Products) *ProductService {
-	return &ProductService{products: products}
+// NewProductService wires the service. A nil publisher skips events.
+func NewProductService(products *repo.Products, categories *repo.Categories, publisher Publisher) *ProductService {
+	return &ProductService{products: products, categories: categories, publisher: publisher}
 }
 
 func (s *ProductService) CreateProduct(in CreateProductInput) (*domain.Product, error) {
+	attrs, err := checkAttributes(s.categories, in.Category, in.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	p := &domain.Product{
 		UserID:      in.UserID,
+		SellerEmail: in.SellerEmail,
+		Status:      domain.StatusDraft,
 		Name:        in.Name,
 		Description: in.Description,
 		Price:       in.Price,
+		Stock:       in.Stock,
+		Category:    in.Category,
+		Attributes:  attrs,
+	}
+	if sku := strings.TrimSpace(in.SKU); sku != "" {
+		p.SKU = &sku
 	}
 
-	if err := s.products.Create(p); err != nil {
+	err = s.products.InTx(func(tx *repo.Products) error {
+		if err := tx.Create(p); err != nil {
+			return err
+		}
+		return record(tx, in.UserID, domain.RevisionCreated, nil, p, nil)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrDuplicateSKU) {
+			return nil, errDuplicateSKU
+		}
 		return nil, err
 	}
 
@@ -60,10 +171,95 @@ func (s *ProductService) GetProduct(id uint) (*domain.Product, error) {
 	return p, nil
 }
 
-func (s *ProductService) ListProducts() ([]domain.Product, error) {
-	return s.products.List()
+// GetVisibleProduct returns a product the viewer may see: any published
+// product, or one of the viewer's own in any status.
+func (s *ProductService) GetVisibleProduct(viewerID, id uint) (*domain.Product, error) {
+	p, err := s.GetProduct(id)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Published() && p.UserID != viewerID {
+		return nil, errNotFound
+	}
+	return p, nil
 }
 
+// exportBatch is how many products ExportProducts reads at a time.
+const exportBatch = 500
+
+// ListProducts lists published products only.
+func (s *ProductService) ListProducts(f ProductFilter) ([]domain.Product, error) {
+	rf, err := listFilter(f)
+	if err != nil {
+		return nil, err
+	}
+	return s.products.List(rf)
+}
+
+// ListOwnProducts lists a seller's own products in any status, or only in
+// status when it is set.
+func (s *ProductService) ListOwnProducts(userID uint, status domain.ProductStatus, f ProductFilter) ([]domain.Product, error) {
+	if status != "" && !domain.IsKnownStatus(status) {
+		return nil, errInvalidStatus
+	}
+	rf, err := listFilter(f)
+	if err != nil {
+		return nil, err
+	}
+	rf.SellerID = userID
+	rf.Status = status
+	return s.products.List(rf)
+}
+
+// ExportProducts walks the published products matching f in batches, see
+// repo.Products.Each, so exports of the whole catalog stay small in
+// memory. An error from fn stops the export.
+func (s *ProductService) ExportProducts(f ProductFilter, fn func([]domain.Product) error) error {
+	rf, err := listFilter(f)
+	if err != nil {
+		return err
+	}
+	return s.products.Each(rf, exportBatch, fn)
+}
+
+// listFilter checks f and turns it into the repo filter of a public
+// listing.
+func listFilter(f ProductFilter) (repo.ProductFilter, error) {
+	variantAttrs := make(map[string]string, len(f.VariantAttrs))
+	for name, value := range f.VariantAttrs {
+		variantAttrs[strings.ToLower(name)] = value
+	}
+
+	attrs := make([]repo.AttrFilter, 0, len(f.Attrs))
+	for _, a := range f.Attrs {
+		if !attributeName.MatchString(a.Name) {
+			return repo.ProductFilter{}, fmt.Errorf("%w: bad attribute name %q", errInvalidFilter, a.Name)
+		}
+		switch a.Op {
+		case "=":
+		case ">", ">=", "<", "<=":
+			if _, err := strconv.ParseFloat(a.Value, 64); err != nil {
+				return repo.ProductFilter{}, fmt.Errorf("%w: %s%s needs a number", errInvalidFilter, a.Name, a.Op)
+			}
+		default:
+			return repo.ProductFilter{}, fmt.Errorf("%w: unknown operator %q", errInvalidFilter, a.Op)
+		}
+		attrs = append(attrs, repo.AttrFilter{Name: a.Name, Op: a.Op, Value: a.Value})
+	}
+
+	return repo.ProductFilter{
+		InStock:      f.InStock,
+		SKU:          f.SKU,
+		VariantAttrs: variantAttrs,
+		Category:     f.Category,
+		Attrs:        attrs,
+		Status:       domain.StatusPublished,
+		SellerID:     f.SellerID,
+	}, nil
+}
+
+// UpdateProduct replaces the editable fields of a product owned by
+// in.ActorID.
 func (s *ProductService) UpdateProduct(in UpdateProductInput) (*domain.Product, error) {
 	p, err := s.products.GetByID(in.ID)
 	if err != nil {
@@ -72,26 +268,258 @@ func (s *ProductService) UpdateProduct(in UpdateProductInput) (*domain.Product,
 		}
 		return nil, err
 	}
+	if p.UserID != in.ActorID {
+		return nil, errForbidden
+	}
+	if in.Version != 0 && in.Version != p.Version {
+		return nil, errVersionMismatch
+	}
+
+	return s.update(p, in, domain.RevisionUpdated, nil)
+}
 
+// update writes in to p and records the change as action. revertOf is the
+// revision a revert goes back to.
+func (s *ProductService) update(p *domain.Product, in UpdateProductInput, action string, revertOf *uint) (*domain.Product, error) {
+	attrs, err := checkAttributes(s.categories, in.Category, in.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := changedColumns(p, in, attrs)
+	if len(changed) == 0 {
+		return p, nil
+	}
+
+	before := p.Snapshot()
 	p.Name = in.Name
 	p.Description = in.Description
 	p.Price = in.Price
+	p.Category = in.Category
+	p.Attributes = attrs
 
-	if err := s.products.Update(p); err != nil {
+	err = s.products.InTx(func(tx *repo.Products) error {
+		if err := tx.Update(p, changed...); err != nil {
+			return err
+		}
+		return record(tx, in.ActorID, action, &before, p, revertOf)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// changed or deleted since we read it
+			return nil, errVersionMismatch
+		}
 		return nil, err
 	}
 
 	return p, nil
 }
 
-func (s *ProductService) DeleteProduct(id uint) error {
-	_, err := s.products.GetByID(id)
+// changedColumns lists the columns an update actually changes, so only
+// those are written and an update that changes nothing keeps the version.
+func changedColumns(p *domain.Product, in UpdateProductInput, attrs domain.Attributes) []string {
+	var cols []string
+	if p.Name != in.Name {
+		cols = append(cols, "name")
+	}
+	if p.Description != in.Description {
+		cols = append(cols, "description")
+	}
+	if p.Price != in.Price {
+		cols = append(cols, "price")
+	}
+	if p.Category != in.Category {
+		cols = append(cols, "category")
+	}
+	if (len(p.Attributes) > 0 || len(attrs) > 0) && !reflect.DeepEqual(p.Attributes, attrs) {
+		cols = append(cols, "attributes")
+	}
+	return cols
+}
+
+// DeleteProduct soft-deletes a product on behalf of actorID, who must own
+// it. A non-zero version must match the product's current version.
+func (s *ProductService) DeleteProduct(actorID, id uint, version int) error {
+	p, err := s.products.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errNotFound
 		}
 		return err
 	}
+	if p.UserID != actorID {
+		return errForbidden
+	}
+	if version != 0 && version != p.Version {
+		return errVersionMismatch
+	}
+
+	return s.products.InTx(func(tx *repo.Products) error {
+		return deleteProduct(tx, actorID, p)
+	})
+}
+
+// deleteProduct soft-deletes p within tx if it is still at p.Version.
+func deleteProduct(tx *repo.Products, actorID uint, p *domain.Product) error {
+	before := p.Snapshot()
+	if err := tx.Delete(p); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errVersionMismatch
+		}
+		return err
+	}
+	return record(tx, actorID, domain.RevisionDeleted, &before, p, nil)
+}
+
+// AdjustStock adds delta units to a seller's own product's stock, e.g.
+// after a delivery (positive) or a write-off (negative). Stock never goes
+// below zero.
+func (s *ProductService) AdjustStock(userID, id uint, delta int) (*domain.Product, error) {
+	p, err := s.GetProduct(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, errForbidden
+	}
+	if err := s.products.AdjustStock(id, delta); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, errNotFound
+		case errors.Is(err, domain.ErrInsufficientStock):
+			return nil, errInsufficientStock
+		}
+		return nil, err
+	}
+	return s.GetProduct(id)
+}
+
+// ChangeStatus applies a seller's status change: submitting for review,
+// withdrawing, archiving or reviving an archived product as a draft.
+func (s *ProductService) ChangeStatus(in ChangeStatusInput) (*domain.Product, error) {
+	if err := checkSellerStatus(in.Status); err != nil {
+		return nil, err
+	}
+
+	p, err := s.GetProduct(in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != in.UserID {
+		return nil, errForbidden
+	}
+
+	prepareSellerStatus(p, in.Status, in.Email)
+	if err := s.transition(in.UserID, p, in.Status); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkSellerStatus rejects statuses sellers may not set themselves.
+func checkSellerStatus(status domain.ProductStatus) error {
+	if !domain.IsKnownStatus(status) {
+		return errInvalidStatus
+	}
+	if status == domain.StatusPublished || status == domain.StatusRejected {
+		return errForbidden
+	}
+	return nil
+}
+
+// prepareSellerStatus clears the last rejection when the seller submits
+// the product again, and keeps the email decisions will go to current.
+func prepareSellerStatus(p *domain.Product, status domain.ProductStatus, email string) {
+	if status == domain.StatusPendingReview {
+		p.RejectionReason = ""
+		if email != "" {
+			p.SellerEmail = email
+		}
+	}
+}
+
+// ModerationQueue lists products waiting for review, oldest first.
+func (s *ProductService) ModerationQueue(limit int) ([]domain.Product, error) {
+	return s.products.ListForModeration(limit)
+}
+
+// Approve publishes a product waiting for review and tells the seller.
+// adminID is recorded in the product's history.
+func (s *ProductService) Approve(ctx context.Context, adminID, id uint) (*domain.Product, error) {
+	p, err := s.GetProduct(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != domain.StatusPendingReview {
+		return nil, errInvalidTransition
+	}
 
-	return s.products.Delete(id)
+	p.RejectionReason = ""
+	if err := s.transition(adminID, p, domain.StatusPublished); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, TopicProductApproved, p)
+	return p, nil
+}
+
+// Reject sends a product back to its seller with a reason. Published
+// products can be rejected too, which takes them down.
+func (s *ProductService) Reject(ctx context.Context, adminID, id uint, reason string) (*domain.Product, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > MaxRejectionReason {
+		return nil, errInvalidReason
+	}
+	p, err := s.GetProduct(id)
+	if err != nil {
+		return nil, err
+	}
+
+	p.RejectionReason = reason
+	if err := s.transition(adminID, p, domain.StatusRejected); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, TopicProductRejected, p)
+	return p, nil
+}
+
+// transition moves p to status to on behalf of actorID.
+func (s *ProductService) transition(actorID uint, p *domain.Product, to domain.ProductStatus) error {
+	return s.products.InTx(func(tx *repo.Products) error {
+		return setStatus(tx, actorID, p, to)
+	})
+}
+
+// setStatus moves p to status to within tx. The write is conditional on
+// the status p was read with, so a concurrent change makes it fail rather
+// than being overwritten.
+func setStatus(tx *repo.Products, actorID uint, p *domain.Product, to domain.ProductStatus) error {
+	from := p.Status
+	before := p.Snapshot()
+	if err := p.Transition(to); err != nil {
+		return errInvalidTransition
+	}
+	if err := tx.SetStatus(p, from); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errInvalidTransition
+		}
+		return err
+	}
+	return record(tx, actorID, domain.RevisionStatusChanged, &before, p, nil)
+}
+
+func (s *ProductService) publish(ctx context.Context, topic string, p *domain.Product) {
+	if s.publisher == nil {
+		return
+	}
+	event := ProductEvent{
+		ProductID:   p.ID,
+		SellerID:    p.UserID,
+		SellerEmail: p.SellerEmail,
+		Name:        p.Name,
+		Status:      string(p.Status),
+		Reason:      p.RejectionReason,
+	}
+	if err := s.publisher.Send(ctx, topic, strconv.FormatUint(uint64(p.ID), 10), event); err != nil {
+		log.Printf("product %d: publish %s: %v", p.ID, topic, err)
+	}
 }
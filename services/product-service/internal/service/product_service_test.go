@@ -1,31 +1,37 @@
 package service_test
 
 import (
+	"context"
 	"testing"
 
 	"GoProduct/internal/domain"
 	"GoProduct/internal/repo"
 	"GoProduct/internal/service"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 func newTestProductService(t *testing.T) *service.ProductService {
 	t.Helper()
 
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-
-	// создаём таблицу products
-	if err := db.AutoMigrate(&domain.Product{}); err != nil {
-		t.Fatalf("auto-migrate: %v", err)
-	}
+	db := newTestDB(t)
 
 	productsRepo := repo.NewProducts(db)
-	return service.NewProductService(productsRepo)
+	return service.NewProductService(productsRepo, repo.NewCategories(db), nil)
+}
+
+// adminID — модератор в тестах.
+const adminID = 99
+
+// mustPublish проводит товар через модерацию: на проверку и одобрение.
+func mustPublish(t *testing.T, svc *service.ProductService, p *domain.Product) {
+	t.Helper()
+
+	if _, err := svc.ChangeStatus(service.ChangeStatusInput{ID: p.ID, UserID: p.UserID, Status: domain.StatusPendingReview}); err != nil {
+		t.Fatalf("ChangeStatus(PENDING_REVIEW) error = %v", err)
+	}
+	if _, err := svc.Approve(context.Background(), adminID, p.ID); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	p.Status = domain.StatusPublished
 }
 
 func TestCreateAndGetProduct(t *testing.T) {
@@ -63,14 +69,17 @@ func TestCreateAndGetProduct(t *testing.T) {
 func TestListProducts(t *testing.T) {
 	svc := newTestProductService(t)
 
-	_, _ = svc.CreateProduct(service.CreateProductInput{
-		UserID: 1, Name: "P1", Price: 1,
-	})
-	_, _ = svc.CreateProduct(service.CreateProductInput{
-		UserID: 1, Name: "P2", Price: 2,
-	})
+	for _, name := range []string{"P1", "P2"} {
+		p, err := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: name, Price: 1})
+		if err != nil {
+			t.Fatalf("CreateProduct() error = %v", err)
+		}
+		mustPublish(t, svc, p)
+	}
+	// черновики в общий список не попадают
+	_, _ = svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Draft", Price: 3})
 
-	list, err := svc.ListProducts()
+	list, err := svc.ListProducts(service.ProductFilter{})
 	if err != nil {
 		t.Fatalf("ListProducts() error = %v", err)
 	}
@@ -94,6 +103,7 @@ func TestUpdateProduct(t *testing.T) {
 
 	updated, err := svc.UpdateProduct(service.UpdateProductInput{
 		ID:          created.ID,
+		ActorID:     1,
 		Name:        "New",
 		Description: "new desc",
 		Price:       10,
@@ -105,6 +115,40 @@ func TestUpdateProduct(t *testing.T) {
 	if updated.Name != "New" || updated.Price != 10 {
 		t.Errorf("UpdateProduct(): got %+v", updated)
 	}
+
+	// чужой товар не правится и не удаляется
+	if _, err := svc.UpdateProduct(service.UpdateProductInput{ID: created.ID, ActorID: 2, Name: "Mine", Price: 1}); !service.IsForbidden(err) {
+		t.Errorf("foreign UpdateProduct() error = %v, want forbidden", err)
+	}
+	if err := svc.DeleteProduct(2, created.ID, 0); !service.IsForbidden(err) {
+		t.Errorf("foreign DeleteProduct() error = %v, want forbidden", err)
+	}
+}
+
+func TestUpdateProduct_VersionMismatch(t *testing.T) {
+	svc := newTestProductService(t)
+
+	created, _ := svc.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Old", Price: 5})
+
+	updated, err := svc.UpdateProduct(service.UpdateProductInput{ID: created.ID, ActorID: 1, Version: created.Version, Name: "New", Price: 6})
+	if err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+	if updated.Version != created.Version+1 {
+		t.Errorf("Version = %d, want %d", updated.Version, created.Version+1)
+	}
+
+	// вторая правка по старой версии
+	_, err = svc.UpdateProduct(service.UpdateProductInput{ID: created.ID, ActorID: 1, Version: created.Version, Name: "Other", Price: 7})
+	if !service.IsVersionMismatch(err) {
+		t.Fatalf("stale UpdateProduct() error = %v, want version mismatch", err)
+	}
+	if err := svc.DeleteProduct(created.UserID, created.ID, created.Version); !service.IsVersionMismatch(err) {
+		t.Fatalf("stale DeleteProduct() error = %v, want version mismatch", err)
+	}
+	if err := svc.DeleteProduct(created.UserID, created.ID, updated.Version); err != nil {
+		t.Fatalf("DeleteProduct() error = %v", err)
+	}
 }
 
 func TestDeleteProduct(t *testing.T) {
@@ -119,7 +163,7 @@ func TestDeleteProduct(t *testing.T) {
 		t.Fatalf("CreateProduct() error = %v", err)
 	}
 
-	if err := svc.DeleteProduct(created.ID); err != nil {
+	if err := svc.DeleteProduct(created.UserID, created.ID, 0); err != nil {
 		t.Fatalf("DeleteProduct() error = %v", err)
 	}
 
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"GoProduct/internal/clients"
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+var (
+	errSellerNotFound = errors.New("seller_not_found")
+	errInvalidRating  = errors.New("invalid_rating")
+	errNotDelivered   = errors.New("no_delivered_order")
+)
+
+func IsSellerNotFound(err error) bool { return errors.Is(err, errSellerNotFound) }
+func IsInvalidRating(err error) bool  { return errors.Is(err, errInvalidRating) }
+func IsNotDelivered(err error) bool   { return errors.Is(err, errNotDelivered) }
+
+// UserDirectory looks up public user profiles, see clients.Users.
+type UserDirectory interface {
+	Get(ctx context.Context, id uint) (*clients.User, error)
+}
+
+// OrderHistory tells whether a buyer has received an order from a seller,
+// see clients.Orders.
+type OrderHistory interface {
+	DeliveredFrom(ctx context.Context, authorization string, sellerID uint) (bool, error)
+}
+
+// SellerRating sums up the scores buyers gave a seller. Average is rounded
+// to one decimal and zero while nobody has rated them.
+type SellerRating struct {
+	Average float64
+	Count   int64
+}
+
+// SellerProfile is a seller's storefront header: who they are according to
+// user-service and how they do here.
+type SellerProfile struct {
+	SellerID     uint
+	DisplayName  string
+	MemberSince  time.Time
+	Rating       SellerRating
+	ProductCount int64
+}
+
+// RateSellerInput is a buyer's score for a seller. Authorization is the
+// buyer's own header, used to look up their orders.
+type RateSellerInput struct {
+	SellerID      uint
+	BuyerID       uint
+	Authorization string
+	Score         int
+}
+
+type SellerService struct {
+	products *repo.Products
+	ratings  *repo.Ratings
+	users    UserDirectory
+	orders   OrderHistory
+}
+
+func NewSellerService(products *repo.Products, ratings *repo.Ratings, users UserDirectory, orders OrderHistory) *SellerService {
+	return &SellerService{products: products, ratings: ratings, users: users, orders: orders}
+}
+
+// Profile builds a seller's public profile. When user-service cannot be
+// reached the profile of a seller with published products is still served,
+// just without a name.
+func (s *SellerService) Profile(ctx context.Context, sellerID uint) (*SellerProfile, error) {
+	count, err := s.publishedCount(sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &SellerProfile{SellerID: sellerID, ProductCount: count}
+	u, err := s.users.Get(ctx, sellerID)
+	switch {
+	case errors.Is(err, clients.ErrUserNotFound):
+		return nil, errSellerNotFound
+	case err != nil && count == 0:
+		return nil, err
+	case err != nil:
+		log.Printf("seller %d: %v", sellerID, err)
+	default:
+		p.DisplayName = u.DisplayName
+		p.MemberSince = u.MemberSince
+	}
+
+	p.Rating, err = s.rating(sellerID)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Rate records a buyer's score for a seller, replacing their earlier one,
+// and returns the seller's new rating. Only buyers with a delivered order
+// from the seller, according to order-service, may rate them.
+func (s *SellerService) Rate(ctx context.Context, in RateSellerInput) (SellerRating, error) {
+	if in.Score < domain.MinRating || in.Score > domain.MaxRating {
+		return SellerRating{}, fmt.Errorf("%w: score must be from %d to %d", errInvalidRating, domain.MinRating, domain.MaxRating)
+	}
+	if in.BuyerID == in.SellerID {
+		return SellerRating{}, errForbidden
+	}
+	delivered, err := s.orders.DeliveredFrom(ctx, in.Authorization, in.SellerID)
+	if err != nil {
+		return SellerRating{}, err
+	}
+	if !delivered {
+		return SellerRating{}, errNotDelivered
+	}
+
+	if err := s.ratings.Save(&domain.SellerRating{SellerID: in.SellerID, BuyerID: in.BuyerID, Score: in.Score}); err != nil {
+		return SellerRating{}, err
+	}
+	return s.rating(in.SellerID)
+}
+
+func (s *SellerService) publishedCount(sellerID uint) (int64, error) {
+	return s.products.Count(repo.ProductFilter{SellerID: sellerID, Status: domain.StatusPublished})
+}
+
+func (s *SellerService) rating(sellerID uint) (SellerRating, error) {
+	avg, n, err := s.ratings.Summary(sellerID)
+	if err != nil {
+		return SellerRating{}, err
+	}
+	return SellerRating{Average: math.Round(avg*10) / 10, Count: n}, nil
+}
@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"GoProduct/internal/clients"
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+)
+
+// fakeUsers подменяет user-service; down имитирует его недоступность.
+type fakeUsers struct {
+	users map[uint]*clients.User
+	down  bool
+}
+
+func (f *fakeUsers) Get(_ context.Context, id uint) (*clients.User, error) {
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	if u, ok := f.users[id]; ok {
+		return u, nil
+	}
+	return nil, clients.ErrUserNotFound
+}
+
+// fakeOrders подменяет order-service: по заголовку Authorization отдаёт
+// продавцов, от которых покупателю доставлены заказы.
+type fakeOrders map[string][]uint
+
+func (f fakeOrders) DeliveredFrom(_ context.Context, authorization string, sellerID uint) (bool, error) {
+	if authorization == "" {
+		return false, clients.ErrUnauthorized
+	}
+	for _, id := range f[authorization] {
+		if id == sellerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type sellerEnv struct {
+	sellers  *service.SellerService
+	products *service.ProductService
+	users    *fakeUsers
+}
+
+func newSellerEnv(t *testing.T) *sellerEnv {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	products := repo.NewProducts(db)
+	users := &fakeUsers{users: map[uint]*clients.User{
+		1: {ID: 1, DisplayName: "Aigerim S.", MemberSince: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		2: {ID: 2, DisplayName: "Daniyar K."},
+	}}
+	return &sellerEnv{
+		sellers: service.NewSellerService(products, repo.NewRatings(db), users, fakeOrders{
+			"Bearer buyer-3": {1},
+			"Bearer buyer-4": {1},
+			"Bearer buyer-5": {1},
+			"Bearer buyer-6": {2},
+		}),
+		products: service.NewProductService(products, repo.NewCategories(db), nil),
+		users:    users,
+	}
+}
+
+func TestSeller_ProfileAndRating(t *testing.T) {
+	env := newSellerEnv(t)
+	ctx := context.Background()
+
+	p, _ := env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Mug", Price: 5})
+	mustPublish(t, env.products, p)
+	_, _ = env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Draft", Price: 1})
+
+	if _, err := env.sellers.Rate(ctx, service.RateSellerInput{SellerID: 1, BuyerID: 1, Authorization: "Bearer buyer-1", Score: 5}); !service.IsForbidden(err) {
+		t.Errorf("rating yourself: %v", err)
+	}
+	if _, err := env.sellers.Rate(ctx, service.RateSellerInput{SellerID: 1, BuyerID: 3, Authorization: "Bearer buyer-3", Score: 0}); !service.IsInvalidRating(err) {
+		t.Errorf("score 0: %v", err)
+	}
+	// заказ доставлен от другого продавца — оценить нельзя
+	if _, err := env.sellers.Rate(ctx, service.RateSellerInput{SellerID: 1, BuyerID: 6, Authorization: "Bearer buyer-6", Score: 1}); !service.IsNotDelivered(err) {
+		t.Errorf("rating without a delivered order: %v", err)
+	}
+	if _, err := env.sellers.Rate(ctx, service.RateSellerInput{SellerID: 1, BuyerID: 6, Score: 1}); !errors.Is(err, clients.ErrUnauthorized) {
+		t.Errorf("rating without authorization: %v", err)
+	}
+
+	for _, in := range []service.RateSellerInput{
+		{SellerID: 1, BuyerID: 3, Authorization: "Bearer buyer-3", Score: 2},
+		{SellerID: 1, BuyerID: 4, Authorization: "Bearer buyer-4", Score: 4},
+		{SellerID: 1, BuyerID: 5, Authorization: "Bearer buyer-5", Score: 5},
+		// повторная оценка заменяет прежнюю
+		{SellerID: 1, BuyerID: 3, Authorization: "Bearer buyer-3", Score: 5},
+	} {
+		if _, err := env.sellers.Rate(ctx, in); err != nil {
+			t.Fatalf("Rate(%+v) error = %v", in, err)
+		}
+	}
+
+	profile, err := env.sellers.Profile(ctx, 1)
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+	if profile.DisplayName != "Aigerim S." || profile.ProductCount != 1 || profile.MemberSince.Year() != 2026 {
+		t.Errorf("profile = %+v", profile)
+	}
+	if profile.Rating.Average != 4.7 || profile.Rating.Count != 3 {
+		t.Errorf("rating = %+v, want 4.7 from 3 buyers", profile.Rating)
+	}
+
+	if p, err := env.sellers.Profile(ctx, 2); err != nil || p.ProductCount != 0 || p.Rating.Count != 0 {
+		t.Errorf("seller without products = %+v, %v", p, err)
+	}
+	if _, err := env.sellers.Profile(ctx, 9); !service.IsSellerNotFound(err) {
+		t.Errorf("unknown user: %v", err)
+	}
+
+	// user-service лежит: витрина отдаётся без имени
+	env.users.down = true
+	if p, err := env.sellers.Profile(ctx, 1); err != nil || p.DisplayName != "" || p.ProductCount != 1 {
+		t.Errorf("profile with user-service down = %+v, %v", p, err)
+	}
+	if _, err := env.sellers.Profile(ctx, 2); err == nil {
+		t.Errorf("profile of a seller without products with user-service down: no error")
+	}
+}
+
+func TestListOwnProducts(t *testing.T) {
+	env := newSellerEnv(t)
+
+	p, _ := env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Mug", Price: 5})
+	mustPublish(t, env.products, p)
+	_, _ = env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Draft", Price: 1})
+	_, _ = env.products.CreateProduct(service.CreateProductInput{UserID: 2, Name: "Foreign", Price: 1})
+
+	own, err := env.products.ListOwnProducts(1, "", service.ProductFilter{})
+	if err != nil || len(own) != 2 {
+		t.Fatalf("ListOwnProducts() = %d, %v; want both of seller 1", len(own), err)
+	}
+	drafts, _ := env.products.ListOwnProducts(1, domain.StatusDraft, service.ProductFilter{})
+	if len(drafts) != 1 || drafts[0].Name != "Draft" {
+		t.Errorf("drafts = %+v", drafts)
+	}
+	if _, err := env.products.ListOwnProducts(1, "SOLD", service.ProductFilter{}); !service.IsInvalidStatus(err) {
+		t.Errorf("unknown status: %v", err)
+	}
+
+	public, _ := env.products.ListProducts(service.ProductFilter{SellerID: 1})
+	if len(public) != 1 || public[0].Name != "Mug" {
+		t.Errorf("storefront = %+v", public)
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// purgeBatch is how many products one Purge round removes at most.
+const purgeBatch = 100
+
+var errRestoreExpired = errors.New("restore_expired")
+
+func IsRestoreExpired(err error) bool { return errors.Is(err, errRestoreExpired) }
+
+// FileDeleter removes the stored files of images whose rows are gone, see
+// ImageService.DeleteFiles.
+type FileDeleter func(ctx context.Context, images []domain.ProductImage)
+
+// TrashService handles soft-deleted products: owners restore them within
+// the grace period, admins look through them and a background job purges
+// them for good.
+type TrashService struct {
+	products *repo.Products
+	grace    time.Duration
+	files    FileDeleter
+}
+
+func NewTrashService(products *repo.Products, grace time.Duration, files FileDeleter) *TrashService {
+	return &TrashService{products: products, grace: grace, files: files}
+}
+
+// Restore undeletes one of the user's products deleted less than the grace
+// period ago.
+func (s *TrashService) Restore(userID, id uint) (*domain.Product, error) {
+	p, err := s.products.GetDeleted(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, errForbidden
+	}
+
+	since := time.Now().Add(-s.grace)
+	if p.DeletedAt.Time.Before(since) {
+		return nil, errRestoreExpired
+	}
+	err = s.products.InTx(func(tx *repo.Products) error {
+		if err := tx.Restore(id, since); err != nil {
+			return err
+		}
+		p.Version++
+		before := p.Snapshot()
+		return record(tx, userID, domain.RevisionRestored, &before, p, nil)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+
+	p, err = s.products.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListDeleted lists soft-deleted products, most recently deleted first.
+func (s *TrashService) ListDeleted(limit int) ([]domain.Product, error) {
+	return s.products.ListDeleted(limit)
+}
+
+// Purge permanently removes products deleted before before, with their
+// image files, and reports how many were removed.
+func (s *TrashService) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for {
+		products, err := s.products.Purge(before, purgeBatch)
+		if err != nil {
+			return purged, err
+		}
+		for i := range products {
+			if s.files != nil {
+				s.files(ctx, products[i].Images)
+			}
+		}
+		purged += len(products)
+		if len(products) < purgeBatch || ctx.Err() != nil {
+			return purged, nil
+		}
+	}
+}
+
+// RunPurge calls Purge every interval until ctx is cancelled, removing
+// products deleted more than retention ago.
+func (s *TrashService) RunPurge(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Purge(ctx, time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("product purge: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("product purge: removed %d deleted products", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+
+	"gorm.io/gorm"
+)
+
+type trashEnv struct {
+	db       *gorm.DB
+	products *service.ProductService
+	trash    *service.TrashService
+	// removed собирает ключи картинок, чьи файлы удалены
+	removed []string
+}
+
+func newTrashEnv(t *testing.T) *trashEnv {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	env := &trashEnv{db: db}
+	productsRepo := repo.NewProducts(db)
+	env.products = service.NewProductService(productsRepo, repo.NewCategories(db), nil)
+	env.trash = service.NewTrashService(productsRepo, 24*time.Hour, func(_ context.Context, images []domain.ProductImage) {
+		for _, img := range images {
+			env.removed = append(env.removed, img.Key)
+		}
+	})
+	return env
+}
+
+// deletedAgo удаляет товар и сдвигает время удаления в прошлое.
+func (e *trashEnv) deletedAgo(t *testing.T, p *domain.Product, ago time.Duration) {
+	t.Helper()
+	if err := e.products.DeleteProduct(p.UserID, p.ID, 0); err != nil {
+		t.Fatalf("DeleteProduct() error = %v", err)
+	}
+	e.db.Unscoped().Model(&domain.Product{}).Where("id = ?", p.ID).Update("deleted_at", time.Now().Add(-ago))
+}
+
+func TestTrash_Restore(t *testing.T) {
+	env := newTrashEnv(t)
+
+	p, _ := env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Phone", Price: 10})
+	env.deletedAgo(t, p, time.Hour)
+
+	if _, err := env.products.GetProduct(p.ID); !service.IsNotFound(err) {
+		t.Fatalf("deleted product still found: %v", err)
+	}
+	if _, err := env.trash.Restore(2, p.ID); !service.IsForbidden(err) {
+		t.Errorf("restore by another user error = %v, want forbidden", err)
+	}
+
+	restored, err := env.trash.Restore(1, p.ID)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restored.ID != p.ID || restored.DeletedAt.Valid {
+		t.Errorf("restored = %+v", restored)
+	}
+	revs, _ := env.products.History(1, p.ID)
+	if last := revs[len(revs)-1]; last.Action != domain.RevisionRestored || last.Version != restored.Version {
+		t.Errorf("last revision = %+v, want restored at version %d", last, restored.Version)
+	}
+	if _, err := env.trash.Restore(1, p.ID); !service.IsNotFound(err) {
+		t.Errorf("restore of a live product error = %v, want not found", err)
+	}
+
+	expired, _ := env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Old", Price: 10})
+	env.deletedAgo(t, expired, 48*time.Hour)
+	if _, err := env.trash.Restore(1, expired.ID); !service.IsRestoreExpired(err) {
+		t.Errorf("restore after the grace period error = %v, want expired", err)
+	}
+}
+
+func TestTrash_PurgeRemovesFiles(t *testing.T) {
+	env := newTrashEnv(t)
+
+	old, _ := env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Old", Price: 10})
+	recent, _ := env.products.CreateProduct(service.CreateProductInput{UserID: 1, Name: "Recent", Price: 10})
+	env.db.Create(&domain.ProductImage{ProductID: old.ID, Key: "old-image", ContentType: "image/png"})
+	env.deletedAgo(t, old, 100*24*time.Hour)
+	env.deletedAgo(t, recent, time.Hour)
+
+	n, err := env.trash.Purge(context.Background(), time.Now().Add(-90*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Purge() = %d, want 1", n)
+	}
+	if len(env.removed) != 1 || env.removed[0] != "old-image" {
+		t.Errorf("removed files = %v", env.removed)
+	}
+
+	deleted, _ := env.trash.ListDeleted(10)
+	if len(deleted) != 1 || deleted[0].ID != recent.ID {
+		t.Errorf("ListDeleted() = %+v, want only the recent product", deleted)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"errors"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxVariantAttributes caps the attributes of a single variant.
+const MaxVariantAttributes = 10
+
+var (
+	errVariantNotFound = errors.New("variant_not_found")
+	errDuplicateSKU    = errors.New("duplicate_sku")
+	errInvalidVariant  = errors.New("invalid_variant")
+)
+
+func IsVariantNotFound(err error) bool { return errors.Is(err, errVariantNotFound) }
+func IsDuplicateSKU(err error) bool    { return errors.Is(err, errDuplicateSKU) }
+func IsInvalidVariant(err error) bool  { return errors.Is(err, errInvalidVariant) }
+
+// VariantInput creates or, with VariantID set, replaces a variant.
+// Attribute names are case-insensitive and stored in lower case.
+type VariantInput struct {
+	ProductID  uint
+	VariantID  uint
+	UserID     uint
+	SKU        string
+	Attributes map[string]string
+	Price      *float64
+	Stock      int
+}
+
+type VariantService struct {
+	products *repo.Products
+	variants *repo.Variants
+}
+
+func NewVariantService(products *repo.Products, variants *repo.Variants) *VariantService {
+	return &VariantService{products: products, variants: variants}
+}
+
+// List returns a product's variants if viewerID may see the product:
+// unpublished products exist only for their seller.
+func (s *VariantService) List(viewerID, productID uint) ([]domain.ProductVariant, error) {
+	p, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Published() && p.UserID != viewerID {
+		return nil, errNotFound
+	}
+	return s.variants.ListByProduct(productID)
+}
+
+func (s *VariantService) Create(in VariantInput) (*domain.ProductVariant, error) {
+	p, err := s.ownProduct(in.UserID, in.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	v := &domain.ProductVariant{ProductID: p.ID, SellerID: p.UserID}
+	if err := fillVariant(v, in); err != nil {
+		return nil, err
+	}
+
+	if err := s.variants.Create(v); err != nil {
+		if errors.Is(err, domain.ErrDuplicateSKU) {
+			return nil, errDuplicateSKU
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *VariantService) Update(in VariantInput) (*domain.ProductVariant, error) {
+	if _, err := s.ownProduct(in.UserID, in.ProductID); err != nil {
+		return nil, err
+	}
+	v, err := s.variants.Get(in.ProductID, in.VariantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errVariantNotFound
+		}
+		return nil, err
+	}
+	if err := fillVariant(v, in); err != nil {
+		return nil, err
+	}
+
+	if err := s.variants.Update(v); err != nil {
+		if errors.Is(err, domain.ErrDuplicateSKU) {
+			return nil, errDuplicateSKU
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *VariantService) Delete(userID, productID, variantID uint) error {
+	if _, err := s.ownProduct(userID, productID); err != nil {
+		return err
+	}
+	if err := s.variants.Delete(productID, variantID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errVariantNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func fillVariant(v *domain.ProductVariant, in VariantInput) error {
+	sku := strings.TrimSpace(in.SKU)
+	if sku == "" || in.Stock < 0 || (in.Price != nil && *in.Price <= 0) || len(in.Attributes) > MaxVariantAttributes {
+		return errInvalidVariant
+	}
+	attrs := make([]domain.VariantAttribute, 0, len(in.Attributes))
+	seen := make(map[string]bool, len(in.Attributes))
+	for name, value := range in.Attributes {
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		if name == "" || value == "" || len(name) > 64 || len(value) > 128 || seen[name] {
+			return errInvalidVariant
+		}
+		seen[name] = true
+		attrs = append(attrs, domain.VariantAttribute{Name: name, Value: value})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+
+	v.SKU = sku
+	v.Price = in.Price
+	v.Stock = in.Stock
+	v.Attributes = attrs
+	return nil
+}
+
+func (s *VariantService) product(id uint) (*domain.Product, error) {
+	p, err := s.products.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *VariantService) ownProduct(userID, productID uint) (*domain.Product, error) {
+	p, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, errForbidden
+	}
+	return p, nil
+}
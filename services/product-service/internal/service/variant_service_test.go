@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"testing"
+
+	"GoProduct/internal/domain"
+	"GoProduct/internal/repo"
+	"GoProduct/internal/service"
+)
+
+type variantEnv struct {
+	products *service.ProductService
+	variants *service.VariantService
+}
+
+func newVariantEnv(t *testing.T) *variantEnv {
+	t.Helper()
+
+	db := newTestDB(t)
+
+	productsRepo := repo.NewProducts(db)
+	return &variantEnv{
+		products: service.NewProductService(productsRepo, repo.NewCategories(db), nil),
+		variants: service.NewVariantService(productsRepo, repo.NewVariants(db)),
+	}
+}
+
+func (e *variantEnv) product(t *testing.T, sellerID uint, name string) *domain.Product {
+	t.Helper()
+	p, err := e.products.CreateProduct(service.CreateProductInput{UserID: sellerID, Name: name, Price: 20})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	mustPublish(t, e.products, p)
+	return p
+}
+
+func (e *variantEnv) variant(t *testing.T, p *domain.Product, sku string, stock int, attrs map[string]string) *domain.ProductVariant {
+	t.Helper()
+	v, err := e.variants.Create(service.VariantInput{ProductID: p.ID, UserID: p.UserID, SKU: sku, Stock: stock, Attributes: attrs})
+	if err != nil {
+		t.Fatalf("Create(%s) error = %v", sku, err)
+	}
+	return v
+}
+
+func TestVariants_CRUDAndSKUPerSeller(t *testing.T) {
+	env := newVariantEnv(t)
+	shirt := env.product(t, 1, "Shirt")
+	mug := env.product(t, 1, "Mug")
+	other := env.product(t, 2, "Other shirt")
+
+	price := 25.0
+	v, err := env.variants.Create(service.VariantInput{
+		ProductID:  shirt.ID,
+		UserID:     1,
+		SKU:        " SH-M-RED ",
+		Attributes: map[string]string{"Size": "M", "color": "red"},
+		Price:      &price,
+		Stock:      3,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if v.SKU != "SH-M-RED" || len(v.Attributes) != 2 || v.Attributes[1].Name != "size" || *v.Price != 25 {
+		t.Fatalf("variant = %+v", v)
+	}
+
+	// тот же SKU у того же продавца запрещён даже на другом товаре
+	if _, err := env.variants.Create(service.VariantInput{ProductID: mug.ID, UserID: 1, SKU: "SH-M-RED"}); !service.IsDuplicateSKU(err) {
+		t.Errorf("duplicate sku error = %v, want duplicate", err)
+	}
+	// а у другого продавца можно
+	env.variant(t, other, "SH-M-RED", 1, nil)
+
+	if _, err := env.variants.Create(service.VariantInput{ProductID: shirt.ID, UserID: 2, SKU: "X"}); !service.IsForbidden(err) {
+		t.Errorf("create by a stranger error = %v, want forbidden", err)
+	}
+	if _, err := env.variants.Create(service.VariantInput{ProductID: shirt.ID, UserID: 1, SKU: "Y", Attributes: map[string]string{"size": ""}}); !service.IsInvalidVariant(err) {
+		t.Errorf("empty attribute error = %v, want invalid", err)
+	}
+
+	updated, err := env.variants.Update(service.VariantInput{
+		ProductID:  shirt.ID,
+		VariantID:  v.ID,
+		UserID:     1,
+		SKU:        "SH-L-RED",
+		Attributes: map[string]string{"size": "L"},
+		Stock:      0,
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Price != nil || len(updated.Attributes) != 1 || updated.Attributes[0].Value != "L" {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	p, _ := env.products.GetProduct(shirt.ID)
+	if len(p.Variants) != 1 || p.Variants[0].SKU != "SH-L-RED" || len(p.Variants[0].Attributes) != 1 {
+		t.Fatalf("product variants = %+v", p.Variants)
+	}
+
+	if err := env.variants.Delete(1, shirt.ID, v.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := env.variants.Delete(1, shirt.ID, v.ID); !service.IsVariantNotFound(err) {
+		t.Errorf("second Delete() error = %v, want not found", err)
+	}
+}
+
+func TestListProducts_FiltersByVariant(t *testing.T) {
+	env := newVariantEnv(t)
+	shirt := env.product(t, 1, "Shirt")
+	dress := env.product(t, 1, "Dress")
+	env.product(t, 1, "Plain")
+
+	env.variant(t, shirt, "SH-M", 0, map[string]string{"size": "M", "color": "red"})
+	env.variant(t, shirt, "SH-L", 2, map[string]string{"size": "L", "color": "red"})
+	env.variant(t, dress, "DR-M", 1, map[string]string{"size": "M", "color": "blue"})
+
+	inStock := true
+	cases := []struct {
+		name   string
+		filter service.ProductFilter
+		want   []string
+	}{
+		{"size", service.ProductFilter{VariantAttrs: map[string]string{"size": "M"}}, []string{"Shirt", "Dress"}},
+		{"both attributes on one variant", service.ProductFilter{VariantAttrs: map[string]string{"Size": "M", "color": "red"}}, []string{"Shirt"}},
+		{"attributes of different variants", service.ProductFilter{VariantAttrs: map[string]string{"size": "M", "color": "green"}}, nil},
+		// размер M у рубашки закончился
+		{"size in stock", service.ProductFilter{InStock: &inStock, VariantAttrs: map[string]string{"size": "M"}}, []string{"Dress"}},
+		{"sku", service.ProductFilter{SKU: "SH-L"}, []string{"Shirt"}},
+	}
+	for _, tc := range cases {
+		got, err := env.products.ListProducts(tc.filter)
+		if err != nil {
+			t.Fatalf("%s: ListProducts() error = %v", tc.name, err)
+		}
+		names := map[string]bool{}
+		for _, p := range got {
+			names[p.Name] = true
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %d products, want %v", tc.name, len(got), tc.want)
+			continue
+		}
+		for _, n := range tc.want {
+			if !names[n] {
+				t.Errorf("%s: missing %s", tc.name, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package storage keeps uploaded files such as product images. Objects are
+// addressed by slash-separated keys, e.g. "products/7/ab12/original.jpg".
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any previous
+	// object with that key.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL is the public address clients download the object from.
+	URL(key string) string
+}
+
+// validKey rejects keys that could escape the storage root.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
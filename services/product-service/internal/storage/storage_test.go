@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocal_PutDeleteURL(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocal(dir, "http://localhost:8081/media/")
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "products/1/a/original.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "products", "1", "a", "original.png"))
+	if err != nil || string(got) != "png" {
+		t.Fatalf("stored file = %q, %v", got, err)
+	}
+	if url := s.URL("products/1/a/original.png"); url != "http://localhost:8081/media/products/1/a/original.png" {
+		t.Errorf("URL() = %s", url)
+	}
+
+	if err := s.Delete(ctx, "products/1/a/original.png"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	// повторное удаление не ошибка
+	if err := s.Delete(ctx, "products/1/a/original.png"); err != nil {
+		t.Errorf("Delete() of a missing file error = %v", err)
+	}
+
+	for _, key := range []string{"", "/etc/passwd", "../x", "a/../../x", "a//b"} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, ""); err != ErrInvalidKey {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+// Пример get-vanilla из тестового набора AWS Signature Version 4.
+func TestSign_AWSTestSuite(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	sign(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// fakeBucket is a local stand-in for an S3-compatible server. It checks
+// the signature the same way S3 does before touching its objects.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	check := r.Clone(context.Background())
+	check.Header.Del("Authorization")
+	check.Header.Del("X-Amz-Content-Sha256")
+	check.URL.Host = r.Host
+	date, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	sign(check, body, "minio", "minio-secret", "us-east-1", "s3", date)
+	if r.Header.Get("Authorization") != check.Header.Get("Authorization") || r.Header.Get("X-Amz-Content-Sha256") != hashHex(body) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		b.objects[r.URL.Path] = body
+		b.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodDelete:
+		delete(b.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3_PutDeleteAgainstStandIn(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string][]byte{}, types: map[string]string{}}
+	srv := httptest.NewServer(bucket)
+	defer srv.Close()
+
+	s := NewS3(S3Config{Endpoint: srv.URL, Bucket: "media", AccessKey: "minio", SecretKey: "minio-secret"})
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "products/1/a/small.jpg", bytes.NewReader([]byte("jpeg")), 4, "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := string(bucket.objects["/media/products/1/a/small.jpg"]); got != "jpeg" {
+		t.Fatalf("stored object = %q", got)
+	}
+	if got := bucket.types["/media/products/1/a/small.jpg"]; got != "image/jpeg" {
+		t.Errorf("content type = %q", got)
+	}
+	if url := s.URL("products/1/a/small.jpg"); url != srv.URL+"/media/products/1/a/small.jpg" {
+		t.Errorf("URL() = %s", url)
+	}
+
+	if err := s.Delete(ctx, "products/1/a/small.jpg"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(bucket.objects) != 0 {
+		t.Errorf("objects after Delete() = %v", bucket.objects)
+	}
+
+	bad := NewS3(S3Config{Endpoint: srv.URL, Bucket: "media", AccessKey: "minio", SecretKey: "wrong"})
+	if err := bad.Put(ctx, "products/1/a/small.jpg", strings.NewReader("x"), 1, ""); err == nil {
+		t.Errorf("Put() with a wrong secret succeeded")
+	}
+}
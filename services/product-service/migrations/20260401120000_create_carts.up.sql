@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS carts (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER UNIQUE,
+    token      VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_carts_updated_at ON carts(updated_at);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    id         SERIAL PRIMARY KEY,
+    cart_id    INTEGER          NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    product_id INTEGER          NOT NULL,
+    name       VARCHAR(255)     NOT NULL,
+    unit_price DOUBLE PRECISION NOT NULL,
+    quantity   INTEGER          NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_cart_product ON cart_items(cart_id, product_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reservations;
+
+DROP INDEX IF EXISTS idx_products_stock;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_stock;
+ALTER TABLE products DROP COLUMN IF EXISTS stock;
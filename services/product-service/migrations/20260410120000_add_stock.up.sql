@@ -0,0 +1,19 @@
+ALTER TABLE products ADD COLUMN stock INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD CONSTRAINT chk_products_stock CHECK (stock >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_products_stock ON products(stock);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id         SERIAL PRIMARY KEY,
+    reference  VARCHAR(64) NOT NULL,
+    product_id INTEGER     NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity   INTEGER     NOT NULL CHECK (quantity > 0),
+    status     VARCHAR(16) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_ref_product ON reservations(reference, product_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_status ON reservations(status);
+CREATE INDEX IF NOT EXISTS idx_reservations_expires_at ON reservations(expires_at);
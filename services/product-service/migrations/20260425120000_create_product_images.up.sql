@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id           SERIAL PRIMARY KEY,
+    product_id   INTEGER      NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    position     INTEGER      NOT NULL,
+    key          VARCHAR(255) NOT NULL,
+    content_type VARCHAR(64)  NOT NULL,
+    size         BIGINT       NOT NULL,
+    width        INTEGER      NOT NULL,
+    height       INTEGER      NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images(product_id, position);
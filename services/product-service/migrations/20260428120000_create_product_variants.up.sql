@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id         SERIAL PRIMARY KEY,
+    product_id INTEGER          NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    seller_id  INTEGER          NOT NULL,
+    sku        VARCHAR(64)      NOT NULL,
+    price      DOUBLE PRECISION,
+    stock      INTEGER          NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_product_variants_stock CHECK (stock >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_seller_sku ON product_variants(seller_id, sku);
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants(product_id);
+
+CREATE TABLE IF NOT EXISTS variant_attributes (
+    id         SERIAL PRIMARY KEY,
+    variant_id INTEGER      NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    name       VARCHAR(64)  NOT NULL,
+    value      VARCHAR(128) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_variant_attributes_variant_name ON variant_attributes(variant_id, name);
+CREATE INDEX IF NOT EXISTS idx_variant_attributes_name_value ON variant_attributes(name, value);
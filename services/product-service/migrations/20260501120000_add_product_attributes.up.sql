@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS categories (
+    slug       VARCHAR(64)  PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    attributes JSONB        NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE products ADD COLUMN category VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_products_category ON products(category);
+-- jsonb_path_ops serves both @> (equality filters) and @@ (range filters)
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops);
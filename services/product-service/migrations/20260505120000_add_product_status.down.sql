@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_products_status;
+ALTER TABLE products DROP COLUMN IF EXISTS seller_email;
+ALTER TABLE products DROP COLUMN IF EXISTS rejection_reason;
+ALTER TABLE products DROP COLUMN IF EXISTS status;
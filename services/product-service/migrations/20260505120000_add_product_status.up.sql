@@ -0,0 +1,7 @@
+-- products that already exist were live, so they start out published
+ALTER TABLE products ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'PUBLISHED';
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 'DRAFT';
+ALTER TABLE products ADD COLUMN rejection_reason TEXT;
+ALTER TABLE products ADD COLUMN seller_email VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_products_status ON products(status);
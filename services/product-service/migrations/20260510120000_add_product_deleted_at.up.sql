@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products(deleted_at);
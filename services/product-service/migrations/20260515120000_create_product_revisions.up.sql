@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id         SERIAL PRIMARY KEY,
+    product_id INTEGER     NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    version    INTEGER     NOT NULL,
+    actor_id   INTEGER     NOT NULL,
+    action     VARCHAR(32) NOT NULL,
+    changes    JSONB,
+    snapshot   JSONB,
+    revert_of  INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_revisions_product ON product_revisions(product_id, created_at);
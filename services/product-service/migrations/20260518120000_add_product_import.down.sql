@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS import_jobs;
+
+DROP INDEX IF EXISTS idx_products_user_sku;
+
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
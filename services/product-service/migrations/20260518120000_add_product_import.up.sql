@@ -0,0 +1,26 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_user_sku ON products(user_id, sku);
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id           SERIAL PRIMARY KEY,
+    seller_id    INTEGER      NOT NULL,
+    seller_email VARCHAR(255),
+    format       VARCHAR(16)  NOT NULL,
+    dry_run      BOOLEAN      NOT NULL DEFAULT FALSE,
+    status       VARCHAR(16)  NOT NULL DEFAULT 'PENDING',
+    payload      BYTEA,
+    total        INTEGER      NOT NULL DEFAULT 0,
+    processed    INTEGER      NOT NULL DEFAULT 0,
+    created      INTEGER      NOT NULL DEFAULT 0,
+    updated      INTEGER      NOT NULL DEFAULT 0,
+    failed       INTEGER      NOT NULL DEFAULT 0,
+    error        TEXT,
+    errors       JSONB,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_seller_id ON import_jobs(seller_id);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs(status);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS seller_ratings (
+    id         SERIAL PRIMARY KEY,
+    seller_id  INTEGER     NOT NULL,
+    buyer_id   INTEGER     NOT NULL,
+    score      SMALLINT    NOT NULL CHECK (score BETWEEN 1 AND 5),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seller_ratings_seller_buyer ON seller_ratings(seller_id, buyer_id);
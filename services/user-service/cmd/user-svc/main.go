@@ -39,11 +39,13 @@ func main() {
 	usersRepo := repo.NewUsers(db)
 	authSvc := service.NewAuthService(usersRepo, signer, producer, cfg.BaseURL)
 	authH := handlers.NewAuthHandler(authSvc)
+	userH := handlers.NewUserHandler(service.NewUserService(usersRepo))
 
 	r := gin.Default()
 	r.POST("/auth/register", authH.Register)
 	r.POST("/auth/login", authH.Login)
 	r.GET("/auth/verify", authH.VerifyEmail)
+	r.GET("/users/:id", userH.Get)
 
 	srv := &http.Server{
 		Addr:         cfg.HTTPAddr,
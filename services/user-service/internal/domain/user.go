@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type UserStatus string
@@ -19,6 +21,18 @@ type User struct {
 	PasswordHash      string     `gorm:"size:255;not null"`
 	Status            UserStatus `gorm:"type:text;not null;default:PENDING"`
 	VerificationToken string     `gorm:"size:64"`
+	Locale            string     `gorm:"size:8;not null;default:ru"`
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 }
+
+// DisplayName is how the user is shown to others: the first name and the
+// initial of the surname, e.g. "Aigerim S.".
+func (u *User) DisplayName() string {
+	name := strings.TrimSpace(u.Name)
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(u.Surname))
+	if r == utf8.RuneError {
+		return name
+	}
+	return name + " " + string(r) + "."
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "testing"
+
+func TestUser_DisplayName(t *testing.T) {
+	cases := []struct {
+		name, surname, want string
+	}{
+		{"Aigerim", "Sadykova", "Aigerim S."},
+		{" Aigerim ", "  sadykova", "Aigerim s."},
+		// фамилия не обязательна
+		{"Aigerim", "", "Aigerim"},
+		{"Aigerim", "   ", "Aigerim"},
+		// инициал берётся целой руной, а не первым байтом
+		{"Айгерим", "Садыкова", "Айгерим С."},
+		{"Daniyar", "Ömirbek", "Daniyar Ö."},
+	}
+	for _, tc := range cases {
+		u := &User{Name: tc.name, Surname: tc.surname}
+		if got := u.DisplayName(); got != tc.want {
+			t.Errorf("DisplayName(%q, %q) = %q, want %q", tc.name, tc.surname, got, tc.want)
+		}
+	}
+}
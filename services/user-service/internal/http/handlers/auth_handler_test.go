@@ -8,11 +8,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"GoMarket/internal/domain"
-	"GoMarket/internal/service"
+	"GoUser/internal/domain"
+	"GoUser/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -72,7 +71,7 @@ func setupTestRouter() (*gin.Engine, *MockAuthService) {
 			return
 		}
 		c.JSON(http.StatusCreated, registerResp{
-			UserID: out.UserID.String(),
+			UserID: out.UserID,
 			Status: string(out.Status),
 		})
 	})
@@ -99,7 +98,7 @@ func setupTestRouter() (*gin.Engine, *MockAuthService) {
 		}
 		c.JSON(http.StatusOK, loginResp{
 			AccessToken: out.AccessToken,
-			UserID:      out.UserID.String(),
+			UserID:      out.UserID,
 			Email:       out.Email,
 		})
 	})
@@ -125,7 +124,7 @@ func TestAuthHandler_Register(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockAuthService) {
-				userID := uuid.New()
+				userID := uint(1)
 				m.On("Register", service.RegisterInput{
 					Name:     "John",
 					Surname:  "Doe",
@@ -481,12 +480,12 @@ func BenchmarkAuthHandler_Register(b *testing.B) {
 			return
 		}
 		c.JSON(http.StatusCreated, registerResp{
-			UserID: out.UserID.String(),
+			UserID: out.UserID,
 			Status: string(out.Status),
 		})
 	})
 
-	userID := uuid.New()
+	userID := uint(1)
 	mockSvc.On("Register", mock.AnythingOfType("service.RegisterInput")).Return(
 		service.RegisterOutput{
 			UserID: userID,
@@ -537,12 +536,12 @@ func BenchmarkAuthHandler_Login(b *testing.B) {
 		}
 		c.JSON(http.StatusOK, loginResp{
 			AccessToken: out.AccessToken,
-			UserID:      out.UserID.String(),
+			UserID:      out.UserID,
 			Email:       out.Email,
 		})
 	})
 
-	userID := uuid.New()
+	userID := uint(1)
 	mockSvc.On("Login", mock.AnythingOfType("service.LoginInput")).Return(
 		service.LoginOutput{
 			AccessToken: "mock-jwt-token",
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"GoUser/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	users *service.UserService
+}
+
+func NewUserHandler(users *service.UserService) *UserHandler {
+	return &UserHandler{users: users}
+}
+
+type publicProfileResp struct {
+	UserID      uint      `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	MemberSince time.Time `json:"member_since"`
+}
+
+// Get returns a user's public profile. It carries nothing private, so it
+// needs no authentication; product-service reads it for seller profiles.
+func (h *UserHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	p, err := h.users.PublicProfile(uint(id))
+	if err != nil {
+		if service.IsUserNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, publicProfileResp{UserID: p.UserID, DisplayName: p.DisplayName, MemberSince: p.MemberSince})
+}
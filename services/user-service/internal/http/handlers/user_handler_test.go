@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"GoUser/internal/domain"
+	"GoUser/internal/http/handlers"
+	"GoUser/internal/repo"
+	"GoUser/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestUserHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.User{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	active := &domain.User{Name: "Айгерим", Surname: "Садыкова", Email: "a@example.com", PasswordHash: "x", Status: domain.StatusActive}
+	pending := &domain.User{Name: "Daniyar", Email: "d@example.com", PasswordHash: "x", Status: domain.StatusPending}
+	for _, u := range []*domain.User{active, pending} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	r := gin.New()
+	r.GET("/users/:id", handlers.NewUserHandler(service.NewUserService(repo.NewUsers(db))).Get)
+
+	get := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/users/"+id, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(strconv.Itoa(int(active.ID)))
+	var got map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != http.StatusOK || got["display_name"] != "Айгерим С." || got["user_id"] != float64(active.ID) {
+		t.Errorf("active user: %d %s", w.Code, w.Body.String())
+	}
+	// в публичном профиле нет ничего личного
+	if _, ok := got["email"]; ok {
+		t.Errorf("profile leaks email: %v", got)
+	}
+
+	for id, want := range map[string]int{
+		strconv.Itoa(int(pending.ID)): http.StatusNotFound,
+		"999":                         http.StatusNotFound,
+		"abc":                         http.StatusBadRequest,
+		"0":                           http.StatusBadRequest,
+		"-1":                          http.StatusBadRequest,
+	} {
+		if w := get(id); w.Code != want {
+			t.Errorf("GET /users/%s: %d %s, want %d", id, w.Code, w.Body.String(), want)
+		}
+	}
+}
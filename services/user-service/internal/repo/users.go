@@ -18,10 +18,15 @@ func (u *Users) Create(user *domain.User) error {
 	return u.db.Create(user).Error
 }
 
+// IsUniqueEmail reports a duplicate email, either as the raw Postgres error
+// or translated by gorm when the connection has TranslateError set.
 func IsUniqueEmail(err error) bool {
 	if err == nil {
 		return false
 	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
 
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
@@ -40,6 +45,14 @@ func (u *Users) ByEmail(email string) (*domain.User, error) {
 	return &user, nil
 }
 
+func (u *Users) ByID(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := u.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (u *Users) ByVerificationToken(token string) (*domain.User, error) {
 	var user domain.User
 	if err := u.db.Where("verification_token = ?", token).First(&user).Error; err != nil {
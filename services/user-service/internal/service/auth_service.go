@@ -17,6 +17,7 @@ import (
 
 type RegisterInput struct {
 	Name, Surname, Email, Password string
+	Locale                         string
 }
 
 type LoginInput struct {
@@ -40,6 +41,7 @@ type UserRegisteredEvent struct {
 	Name    string `json:"name"`
 	Token   string `json:"token"`
 	BaseURL string `json:"base_url"`
+	Locale  string `json:"locale"`
 }
 
 type AuthService struct {
@@ -49,6 +51,8 @@ type AuthService struct {
 	baseURL  string
 }
 
+const defaultLocale = "ru"
+
 var (
 	errEmailTaken         = errors.New("email_taken")
 	errInvalidCredentials = errors.New("invalid_credentials")
@@ -61,6 +65,8 @@ func IsInvalidCredentials(err error) bool { return errors.Is(err, errInvalidCred
 func IsInvalidToken(err error) bool       { return errors.Is(err, errInvalidToken) }
 func IsAlreadyActive(err error) bool      { return errors.Is(err, errAlreadyActive) }
 
+// NewAuthService wires the service. A nil producer skips the user.registered
+// event.
 func NewAuthService(users *repo.Users, signer *jwtutil.Signer, producer *kafka.Producer, baseURL string) *AuthService {
 	return &AuthService{
 		users:    users,
@@ -81,6 +87,11 @@ func (s *AuthService) Register(in RegisterInput) (RegisterOutput, error) {
 		return RegisterOutput{}, err
 	}
 
+	locale := in.Locale
+	if locale == "" {
+		locale = defaultLocale
+	}
+
 	user := domain.User{
 		Name:              in.Name,
 		Surname:           in.Surname,
@@ -88,6 +99,7 @@ func (s *AuthService) Register(in RegisterInput) (RegisterOutput, error) {
 		PasswordHash:      pwHash,
 		Status:            domain.StatusPending,
 		VerificationToken: verifyToken,
+		Locale:            locale,
 		CreatedAt:         time.Now(),
 	}
 
@@ -98,12 +110,17 @@ func (s *AuthService) Register(in RegisterInput) (RegisterOutput, error) {
 		return RegisterOutput{}, err
 	}
 
+	if s.producer == nil {
+		return RegisterOutput{user.ID, user.Status}, nil
+	}
+
 	event := UserRegisteredEvent{
 		UserID:  user.ID,
 		Email:   user.Email,
 		Name:    user.Name,
 		Token:   verifyToken,
 		BaseURL: s.baseURL,
+		Locale:  user.Locale,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
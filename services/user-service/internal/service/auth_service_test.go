@@ -7,19 +7,28 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
-	dsn := "host=localhost user=test password=test dbname=test_db port=5432 sslmode=disable"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true})
 	require.NoError(t, err)
 	return db
 }
 
+// verify parses a token signed with the test secret.
+func verify(token string) (*jwtutil.Claims, error) {
+	var claims jwtutil.Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return []byte("test-secret-key"), nil
+	})
+	return &claims, err
+}
+
 func setupTestService(t *testing.T) (*AuthService, *gorm.DB, func()) {
 	db := setupTestDB(t)
 	err := db.AutoMigrate(&domain.User{})
@@ -27,7 +36,7 @@ func setupTestService(t *testing.T) (*AuthService, *gorm.DB, func()) {
 
 	users := repo.NewUsers(db)
 	signer := jwtutil.NewSigner("test-secret-key", time.Hour)
-	service := NewAuthService(users, signer)
+	service := NewAuthService(users, signer, nil, "")
 
 	cleanup := func() {
 		db.Exec("DELETE FROM users")
@@ -272,7 +281,7 @@ func TestAuthService_Login_TokenIsValid(t *testing.T) {
 	loginOutput, err := service.Login(loginInput)
 	require.NoError(t, err)
 
-	claims, err := service.signer.Verify(loginOutput.AccessToken)
+	claims, err := verify(loginOutput.AccessToken)
 
 	assert.NoError(t, err)
 	assert.Equal(t, regOutput.UserID, claims.UserID)
@@ -305,7 +314,7 @@ func TestAuthService_Register_Login_Workflow(t *testing.T) {
 	assert.Equal(t, regOutput.UserID, loginOutput.UserID)
 	assert.Equal(t, email, loginOutput.Email)
 
-	claims, err := service.signer.Verify(loginOutput.AccessToken)
+	claims, err := verify(loginOutput.AccessToken)
 	assert.NoError(t, err)
 	assert.Equal(t, regOutput.UserID, claims.UserID)
 	assert.Equal(t, email, claims.Email)
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"GoUser/internal/domain"
+	"GoUser/internal/repo"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var errUserNotFound = errors.New("user_not_found")
+
+func IsUserNotFound(err error) bool { return errors.Is(err, errUserNotFound) }
+
+// PublicProfile is what other users may see about someone, e.g. on a
+// seller's storefront.
+type PublicProfile struct {
+	UserID      uint
+	DisplayName string
+	MemberSince time.Time
+}
+
+type UserService struct {
+	users *repo.Users
+}
+
+func NewUserService(users *repo.Users) *UserService {
+	return &UserService{users: users}
+}
+
+// PublicProfile returns the profile of an active user. Accounts that have
+// not verified their email are not shown.
+func (s *UserService) PublicProfile(id uint) (*PublicProfile, error) {
+	u, err := s.users.ByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errUserNotFound
+		}
+		return nil, err
+	}
+	if u.Status != domain.StatusActive {
+		return nil, errUserNotFound
+	}
+	return &PublicProfile{UserID: u.ID, DisplayName: u.DisplayName(), MemberSince: u.CreatedAt}, nil
+}
@@ -0,0 +1,53 @@
+package service_test
+
+import (
+	"testing"
+
+	"GoUser/internal/domain"
+	"GoUser/internal/repo"
+	"GoUser/internal/service"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestUserService(t *testing.T) (*service.UserService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.User{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	return service.NewUserService(repo.NewUsers(db)), db
+}
+
+func TestUserService_PublicProfile(t *testing.T) {
+	svc, db := newTestUserService(t)
+
+	active := &domain.User{Name: "Aigerim", Surname: "Sadykova", Email: "a@example.com", PasswordHash: "x", Status: domain.StatusActive}
+	pending := &domain.User{Name: "Daniyar", Surname: "K", Email: "d@example.com", PasswordHash: "x", Status: domain.StatusPending}
+	for _, u := range []*domain.User{active, pending} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	p, err := svc.PublicProfile(active.ID)
+	if err != nil {
+		t.Fatalf("PublicProfile() error = %v", err)
+	}
+	if p.UserID != active.ID || p.DisplayName != "Aigerim S." || !p.MemberSince.Equal(active.CreatedAt) {
+		t.Errorf("profile = %+v", p)
+	}
+
+	// неподтверждённый аккаунт не отличить от несуществующего
+	if _, err := svc.PublicProfile(pending.ID); !service.IsUserNotFound(err) {
+		t.Errorf("unverified user: err = %v, want not found", err)
+	}
+	if _, err := svc.PublicProfile(999); !service.IsUserNotFound(err) {
+		t.Errorf("missing user: err = %v, want not found", err)
+	}
+}